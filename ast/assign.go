@@ -0,0 +1,391 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chronos-tachyon/go-spiderscript/internal/util"
+	"github.com/chronos-tachyon/go-spiderscript/operator"
+	"github.com/chronos-tachyon/go-spiderscript/token"
+	"github.com/chronos-tachyon/go-spiderscript/tokenpredicate"
+)
+
+var assignmentOperatorMap = map[token.Type]operator.Operator{
+	token.Equal:                   operator.Assign,
+	token.ColonEqual:              operator.DeclareAndAssign,
+	token.PlusEqual:               operator.AssignAdd,
+	token.MinusEqual:              operator.AssignSub,
+	token.StarEqual:               operator.AssignMul,
+	token.SlashEqual:              operator.AssignDiv,
+	token.PercentEqual:            operator.AssignMod,
+	token.StarStarEqual:           operator.AssignPow,
+	token.LessLessEqual:           operator.AssignLShift,
+	token.GreaterGreaterEqual:     operator.AssignRShift,
+	token.LessLessBarEqual:        operator.AssignLRotate,
+	token.GreaterGreaterBarEqual:  operator.AssignRRotate,
+	token.AmpersandEqual:          operator.AssignBitwiseAND,
+	token.CaretEqual:              operator.AssignBitwiseXOR,
+	token.BarEqual:                operator.AssignBitwiseOR,
+	token.AmpersandAmpersandEqual: operator.AssignLogicalAND,
+	token.CaretCaretEqual:         operator.AssignLogicalXOR,
+	token.BarBarEqual:             operator.AssignLogicalOR,
+	token.QuestionEqual:           operator.AssignElvis,
+}
+
+var mutationOperatorMap = map[token.Type]operator.Operator{
+	token.PlusPlus:   operator.MutateINC,
+	token.MinusMinus: operator.MutateDEC,
+	token.TildeTilde: operator.MutateBitwiseNOT,
+}
+
+// checkAssignable returns an error message if node cannot appear on the left
+// side of op, or "" if it can.  Only "=" and ":=" may destructure a tuple,
+// and ":=" may only bind plain names.
+func checkAssignable(node Node, op operator.Operator) string {
+	switch x := node.(type) {
+	case *IdentifierExpr:
+		if op == operator.DeclareAndAssign && !x.Module.IsEmpty() {
+			return fmt.Sprintf("cannot declare qualified name %v", x)
+		}
+		return ""
+
+	case *PlaceholderExpr:
+		if op != operator.Assign && op != operator.DeclareAndAssign {
+			return fmt.Sprintf("cannot use _ as the target of %v", op)
+		}
+		return ""
+
+	case *FieldExpr, *IndexExpr:
+		if op == operator.DeclareAndAssign {
+			return fmt.Sprintf("cannot declare %v", x)
+		}
+		return ""
+
+	case *UnaryOperatorExpr:
+		if x.Operator == OpDerefPointer && op != operator.DeclareAndAssign {
+			return ""
+		}
+
+	case *ParenExpr:
+		return checkAssignable(x.Inner, op)
+
+	case *TupleExpr:
+		if op != operator.Assign && op != operator.DeclareAndAssign {
+			return fmt.Sprintf("cannot use a tuple as the target of %v", op)
+		}
+		for _, item := range x.Items {
+			if msg := checkAssignable(item, op); msg != "" {
+				return msg
+			}
+		}
+		return ""
+	}
+	return fmt.Sprintf("cannot assign to %v", node)
+}
+
+// AssignmentStatement
+// {{{
+
+// AssignmentStatement is "Target op Value", where op is "=", ":=" or one of
+// the compound assignment operators such as "+=".  Target may be a tuple,
+// as in "(a, _) = f()".
+type AssignmentStatement struct {
+	extent
+
+	Op     operator.Operator
+	Target Node
+	Value  Node
+	WS0    InternalWhitespaceRun
+	WS1    InternalWhitespaceRun
+	WS2    InternalWhitespaceRun
+	WS3    TerminalWhitespaceRun
+}
+
+func (stmt *AssignmentStatement) Init() {
+	*stmt = AssignmentStatement{}
+}
+
+func (stmt *AssignmentStatement) Match(p *Parser) bool {
+	return stmt.match(p, true)
+}
+
+// match matches the statement, followed by its terminator if terminated is
+// true.  The clauses of a ForStatement are not terminated.
+func (stmt *AssignmentStatement) match(p *Parser, terminated bool) bool {
+	defer p.trackExtent(&stmt.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	stmt.WS0.Match(p)
+
+	var start token.Token
+	p.Peek(&start, tokenpredicate.Any())
+
+	if !MatchExpr(&stmt.Target, p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	stmt.WS1.Match(p)
+
+	var tok token.Token
+	if !p.Peek(&tok, tokenpredicate.Any()) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	op, found := assignmentOperatorMap[tok.Type]
+	if !found {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+	p.Consume(nil, tokenpredicate.Any())
+	stmt.Op = op
+
+	stmt.WS2.Match(p)
+
+	if !MatchExpr(&stmt.Value, p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if terminated && !stmt.WS3.Match(p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if msg := checkAssignable(stmt.Target, stmt.Op); msg != "" {
+		p.EmitError(&ParseError{Pos: start.Start, Message: msg})
+	}
+
+	return true
+}
+
+func (stmt *AssignmentStatement) String() string {
+	return util.StringImpl(stmt)
+}
+
+func (stmt *AssignmentStatement) GoString() string {
+	return util.GoStringImpl(stmt)
+}
+
+func (stmt *AssignmentStatement) EstimateStringLength() uint {
+	sum := uint(len(stmt.Op.String()))
+	sum += stmt.Target.EstimateStringLength()
+	sum += stmt.Value.EstimateStringLength()
+	sum += stmt.WS0.EstimateStringLength()
+	sum += stmt.WS1.EstimateStringLength()
+	sum += stmt.WS2.EstimateStringLength()
+	sum += stmt.WS3.EstimateStringLength()
+	return sum
+}
+
+func (stmt *AssignmentStatement) EstimateGoStringLength() uint {
+	sum := 26 + uint(len(stmt.Op.GoString()))
+	sum += stmt.Target.EstimateGoStringLength()
+	sum += stmt.Value.EstimateGoStringLength()
+	sum += stmt.WS0.EstimateGoStringLength()
+	sum += stmt.WS1.EstimateGoStringLength()
+	sum += stmt.WS2.EstimateGoStringLength()
+	sum += stmt.WS3.EstimateGoStringLength()
+	return sum
+}
+
+func (stmt *AssignmentStatement) WriteStringTo(out *strings.Builder) {
+	stmt.WS0.WriteStringTo(out)
+	stmt.Target.WriteStringTo(out)
+	stmt.WS1.WriteStringTo(out)
+	out.WriteString(stmt.Op.String())
+	stmt.WS2.WriteStringTo(out)
+	stmt.Value.WriteStringTo(out)
+	stmt.WS3.WriteStringTo(out)
+}
+
+func (stmt *AssignmentStatement) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("AssignmentStatement{")
+	out.WriteString(stmt.Op.GoString())
+	out.WriteByte(',')
+	stmt.Target.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.Value.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS1.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS2.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS3.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (stmt *AssignmentStatement) ComputeStringLengthEstimates() {
+	stmt.Target.ComputeStringLengthEstimates()
+	stmt.Value.ComputeStringLengthEstimates()
+	stmt.WS0.ComputeStringLengthEstimates()
+	stmt.WS1.ComputeStringLengthEstimates()
+	stmt.WS2.ComputeStringLengthEstimates()
+	stmt.WS3.ComputeStringLengthEstimates()
+}
+
+func (stmt *AssignmentStatement) Children() []Node {
+	list := make([]Node, 0, 2)
+	list = append(list, stmt.Target)
+	list = append(list, stmt.Value)
+	return list
+}
+
+func (stmt *AssignmentStatement) RewriteChildren(fn func(Node) Node) {
+	stmt.Target = fn(stmt.Target)
+	stmt.Value = fn(stmt.Value)
+}
+
+var _ Node = (*AssignmentStatement)(nil)
+
+// }}}
+
+// MutationStatement
+// {{{
+
+// MutationStatement is "Target++", "Target--" or "Target~~".
+type MutationStatement struct {
+	extent
+
+	Op     operator.Operator
+	Target Node
+	WS0    InternalWhitespaceRun
+	WS1    InternalWhitespaceRun
+	WS2    TerminalWhitespaceRun
+}
+
+func (stmt *MutationStatement) Init() {
+	*stmt = MutationStatement{}
+}
+
+func (stmt *MutationStatement) Match(p *Parser) bool {
+	return stmt.match(p, true)
+}
+
+// match matches the statement, followed by its terminator if terminated is
+// true.  The clauses of a ForStatement are not terminated.
+func (stmt *MutationStatement) match(p *Parser, terminated bool) bool {
+	defer p.trackExtent(&stmt.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	stmt.WS0.Match(p)
+
+	var start token.Token
+	p.Peek(&start, tokenpredicate.Any())
+
+	if !matchExpr24(&stmt.Target, p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	stmt.WS1.Match(p)
+
+	var tok token.Token
+	if !p.Peek(&tok, tokenpredicate.Any()) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	op, found := mutationOperatorMap[tok.Type]
+	if !found {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+	p.Consume(nil, tokenpredicate.Any())
+	stmt.Op = op
+
+	if terminated && !stmt.WS2.Match(p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if msg := checkAssignable(stmt.Target, stmt.Op); msg != "" {
+		p.EmitError(&ParseError{Pos: start.Start, Message: msg})
+	}
+
+	return true
+}
+
+func (stmt *MutationStatement) String() string {
+	return util.StringImpl(stmt)
+}
+
+func (stmt *MutationStatement) GoString() string {
+	return util.GoStringImpl(stmt)
+}
+
+func (stmt *MutationStatement) EstimateStringLength() uint {
+	sum := 2 + stmt.Target.EstimateStringLength()
+	sum += stmt.WS0.EstimateStringLength()
+	sum += stmt.WS1.EstimateStringLength()
+	sum += stmt.WS2.EstimateStringLength()
+	return sum
+}
+
+func (stmt *MutationStatement) EstimateGoStringLength() uint {
+	sum := 24 + uint(len(stmt.Op.GoString()))
+	sum += stmt.Target.EstimateGoStringLength()
+	sum += stmt.WS0.EstimateGoStringLength()
+	sum += stmt.WS1.EstimateGoStringLength()
+	sum += stmt.WS2.EstimateGoStringLength()
+	return sum
+}
+
+func (stmt *MutationStatement) WriteStringTo(out *strings.Builder) {
+	stmt.WS0.WriteStringTo(out)
+	stmt.Target.WriteStringTo(out)
+	stmt.WS1.WriteStringTo(out)
+	out.WriteString(stmt.Op.String())
+	stmt.WS2.WriteStringTo(out)
+}
+
+func (stmt *MutationStatement) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("MutationStatement{")
+	out.WriteString(stmt.Op.GoString())
+	out.WriteByte(',')
+	stmt.Target.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS1.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS2.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (stmt *MutationStatement) ComputeStringLengthEstimates() {
+	stmt.Target.ComputeStringLengthEstimates()
+	stmt.WS0.ComputeStringLengthEstimates()
+	stmt.WS1.ComputeStringLengthEstimates()
+	stmt.WS2.ComputeStringLengthEstimates()
+}
+
+func (stmt *MutationStatement) Children() []Node {
+	list := make([]Node, 0, 1)
+	list = append(list, stmt.Target)
+	return list
+}
+
+func (stmt *MutationStatement) RewriteChildren(fn func(Node) Node) {
+	stmt.Target = fn(stmt.Target)
+}
+
+var _ Node = (*MutationStatement)(nil)
+
+// }}}
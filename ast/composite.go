@@ -0,0 +1,465 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/chronos-tachyon/go-spiderscript/internal/util"
+	"github.com/chronos-tachyon/go-spiderscript/token"
+	"github.com/chronos-tachyon/go-spiderscript/tokenpredicate"
+)
+
+func isCompositeLiteralType(node Node) bool {
+	switch x := node.(type) {
+	case *IdentifierExpr:
+		return true
+	case *GenericInstantiationExpr:
+		return x.TypeSig != nil
+	default:
+		return false
+	}
+}
+
+func matchElement(ptr *Node, p *Parser) bool {
+	keyed := &KeyedElement{}
+	if keyed.Match(p) {
+		*ptr = keyed
+		return true
+	}
+
+	return MatchExpr(ptr, p)
+}
+
+func matchElements(p *Parser, closeType token.Type, elems *[]Node, ws0 *[]Node, ws1 *[]Node, ws2 *InternalWhitespaceRun, hasTrailingComma *bool) bool {
+	defer p.enterHeader(false)()
+
+	var ws InternalWhitespaceRun
+	ws.Match(p)
+
+	if p.Consume(nil, tokenpredicate.Type(closeType)) {
+		*ws2 = ws
+		return true
+	}
+
+	*elems = make([]Node, 0, 4)
+	*ws0 = make([]Node, 0, 4)
+	*ws1 = make([]Node, 0, 4)
+
+	for {
+		wsBefore := ws
+		ws.Init()
+
+		var elem Node
+		if !matchElement(&elem, p) {
+			return false
+		}
+
+		var wsAfter InternalWhitespaceRun
+		wsAfter.Match(p)
+
+		*elems = append(*elems, elem)
+		*ws0 = append(*ws0, &wsBefore)
+		*ws1 = append(*ws1, &wsAfter)
+
+		if p.Consume(nil, tokenpredicate.Type(closeType)) {
+			return true
+		}
+
+		if !p.Consume(nil, tokenpredicate.Type(token.Comma)) {
+			return false
+		}
+
+		ws.Match(p)
+
+		if p.Consume(nil, tokenpredicate.Type(closeType)) {
+			*ws2 = ws
+			*hasTrailingComma = true
+			return true
+		}
+	}
+}
+
+func estimateElementsStringLength(elems []Node, ws0 []Node, ws1 []Node, ws2 *InternalWhitespaceRun, hasTrailingComma bool) uint {
+	sum := 2 + uint(len(elems))
+	sum += sumStringLengthEstimates(elems)
+	sum += sumStringLengthEstimates(ws0)
+	sum += sumStringLengthEstimates(ws1)
+	sum += ws2.EstimateStringLength()
+	if len(elems) != 0 {
+		sum--
+	}
+	if hasTrailingComma {
+		sum++
+	}
+	return sum
+}
+
+func writeElementsTo(out *strings.Builder, open byte, close byte, elems []Node, ws0 []Node, ws1 []Node, ws2 *InternalWhitespaceRun, hasTrailingComma bool) {
+	out.WriteByte(open)
+	for index := uint(0); index < uint(len(elems)); index++ {
+		if index != 0 {
+			out.WriteByte(',')
+		}
+		ws0[index].WriteStringTo(out)
+		elems[index].WriteStringTo(out)
+		ws1[index].WriteStringTo(out)
+	}
+	if hasTrailingComma {
+		out.WriteByte(',')
+	}
+	ws2.WriteStringTo(out)
+	out.WriteByte(close)
+}
+
+func writeElementsGoStringTo(out *strings.Builder, elems []Node, ws0 []Node, ws1 []Node, ws2 *InternalWhitespaceRun) {
+	out.WriteByte('[')
+	writeGoStringsTo(out, elems)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, ws0)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, ws1)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	ws2.WriteGoStringTo(out)
+}
+
+func computeElementsStringLengthEstimates(elems []Node, ws0 []Node, ws1 []Node, ws2 *InternalWhitespaceRun) {
+	for index := uint(0); index < uint(len(elems)); index++ {
+		elems[index].ComputeStringLengthEstimates()
+		ws0[index].ComputeStringLengthEstimates()
+		ws1[index].ComputeStringLengthEstimates()
+	}
+	ws2.ComputeStringLengthEstimates()
+}
+
+// KeyedElement
+// {{{
+
+type KeyedElement struct {
+	extent
+
+	Key   Node
+	Value Node
+	WS0   InternalWhitespaceRun
+	WS1   InternalWhitespaceRun
+}
+
+func (elem *KeyedElement) Init() {
+	*elem = KeyedElement{}
+}
+
+func (elem *KeyedElement) Match(p *Parser) bool {
+	defer p.trackExtent(&elem.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	if !matchExpr25(&elem.Key, p) {
+		p.Rewind(mark)
+		return false
+	}
+
+	elem.WS0.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.Colon)) {
+		elem.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	elem.WS1.Match(p)
+
+	if !MatchExpr(&elem.Value, p) {
+		elem.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (elem *KeyedElement) String() string {
+	return util.StringImpl(elem)
+}
+
+func (elem *KeyedElement) GoString() string {
+	return util.GoStringImpl(elem)
+}
+
+func (elem *KeyedElement) EstimateStringLength() uint {
+	sum := 1 + elem.Key.EstimateStringLength()
+	sum += elem.Value.EstimateStringLength()
+	sum += elem.WS0.EstimateStringLength()
+	sum += elem.WS1.EstimateStringLength()
+	return sum
+}
+
+func (elem *KeyedElement) EstimateGoStringLength() uint {
+	sum := 17 + elem.Key.EstimateGoStringLength()
+	sum += elem.Value.EstimateGoStringLength()
+	sum += elem.WS0.EstimateGoStringLength()
+	sum += elem.WS1.EstimateGoStringLength()
+	return sum
+}
+
+func (elem *KeyedElement) WriteStringTo(out *strings.Builder) {
+	elem.Key.WriteStringTo(out)
+	elem.WS0.WriteStringTo(out)
+	out.WriteByte(':')
+	elem.WS1.WriteStringTo(out)
+	elem.Value.WriteStringTo(out)
+}
+
+func (elem *KeyedElement) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("KeyedElement{")
+	elem.Key.WriteGoStringTo(out)
+	out.WriteByte(',')
+	elem.Value.WriteGoStringTo(out)
+	out.WriteByte(',')
+	elem.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	elem.WS1.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (elem *KeyedElement) ComputeStringLengthEstimates() {
+	elem.Key.ComputeStringLengthEstimates()
+	elem.Value.ComputeStringLengthEstimates()
+	elem.WS0.ComputeStringLengthEstimates()
+	elem.WS1.ComputeStringLengthEstimates()
+}
+
+func (elem *KeyedElement) Children() []Node {
+	list := make([]Node, 0, 2)
+	list = append(list, elem.Key)
+	list = append(list, elem.Value)
+	return list
+}
+
+func (elem *KeyedElement) RewriteChildren(fn func(Node) Node) {
+	elem.Key = fn(elem.Key)
+	elem.Value = fn(elem.Value)
+}
+
+var _ Node = (*KeyedElement)(nil)
+
+// }}}
+
+// StructLiteralExpr
+// {{{
+
+type StructLiteralExpr struct {
+	extent
+
+	Type             Node
+	Elements         []Node
+	WS0              []Node
+	WS1              []Node
+	WS2              InternalWhitespaceRun
+	HasTrailingComma bool
+}
+
+func (expr *StructLiteralExpr) Init() {
+	*expr = StructLiteralExpr{}
+}
+
+func (expr *StructLiteralExpr) Match(p *Parser) bool {
+	defer p.trackExtent(&expr.extent)()
+
+	return matchPostfixOperand(p, func(node Node) bool {
+		if x, ok := node.(*StructLiteralExpr); ok {
+			*expr = *x
+			return true
+		}
+		return false
+	})
+}
+
+func (expr *StructLiteralExpr) matchSuffix(p *Parser) bool {
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.LBrace)) {
+		p.Rewind(mark)
+		return false
+	}
+
+	if !matchElements(p, token.RBrace, &expr.Elements, &expr.WS0, &expr.WS1, &expr.WS2, &expr.HasTrailingComma) {
+		*expr = StructLiteralExpr{Type: expr.Type}
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (expr *StructLiteralExpr) String() string {
+	return util.StringImpl(expr)
+}
+
+func (expr *StructLiteralExpr) GoString() string {
+	return util.GoStringImpl(expr)
+}
+
+func (expr *StructLiteralExpr) EstimateStringLength() uint {
+	sum := expr.Type.EstimateStringLength()
+	sum += estimateElementsStringLength(expr.Elements, expr.WS0, expr.WS1, &expr.WS2, expr.HasTrailingComma)
+	return sum
+}
+
+func (expr *StructLiteralExpr) EstimateGoStringLength() uint {
+	sum := 27 + expr.Type.EstimateGoStringLength()
+	sum += expr.WS2.EstimateGoStringLength()
+	sum += sumGoStringLengthEstimates(expr.Elements)
+	sum += sumGoStringLengthEstimates(expr.WS0)
+	sum += sumGoStringLengthEstimates(expr.WS1)
+	return sum
+}
+
+func (expr *StructLiteralExpr) WriteStringTo(out *strings.Builder) {
+	expr.Type.WriteStringTo(out)
+	writeElementsTo(out, '{', '}', expr.Elements, expr.WS0, expr.WS1, &expr.WS2, expr.HasTrailingComma)
+}
+
+func (expr *StructLiteralExpr) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("StructLiteralExpr{")
+	expr.Type.WriteGoStringTo(out)
+	out.WriteByte(',')
+	writeElementsGoStringTo(out, expr.Elements, expr.WS0, expr.WS1, &expr.WS2)
+	out.WriteByte('}')
+}
+
+func (expr *StructLiteralExpr) ComputeStringLengthEstimates() {
+	expr.Type.ComputeStringLengthEstimates()
+	computeElementsStringLengthEstimates(expr.Elements, expr.WS0, expr.WS1, &expr.WS2)
+}
+
+func (expr *StructLiteralExpr) Children() []Node {
+	list := make([]Node, 0, 1+len(expr.Elements))
+	list = append(list, expr.Type)
+	list = append(list, expr.Elements...)
+	return list
+}
+
+func (expr *StructLiteralExpr) RewriteChildren(fn func(Node) Node) {
+	expr.Type = fn(expr.Type)
+	rewriteNodes(expr.Elements, fn)
+}
+
+var _ Node = (*StructLiteralExpr)(nil)
+
+// }}}
+
+// ArrayLiteralExpr
+// {{{
+
+type ArrayLiteralExpr struct {
+	extent
+
+	Type             Node
+	Elements         []Node
+	WS0              []Node
+	WS1              []Node
+	WS2              InternalWhitespaceRun
+	HasTrailingComma bool
+}
+
+func (expr *ArrayLiteralExpr) Init() {
+	*expr = ArrayLiteralExpr{}
+}
+
+func (expr *ArrayLiteralExpr) Match(p *Parser) bool {
+	defer p.trackExtent(&expr.extent)()
+
+	return matchPostfixOperand(p, func(node Node) bool {
+		if x, ok := node.(*ArrayLiteralExpr); ok {
+			*expr = *x
+			return true
+		}
+		return false
+	})
+}
+
+func (expr *ArrayLiteralExpr) matchSuffix(p *Parser) bool {
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.DotDotDot)) {
+		p.Rewind(mark)
+		return false
+	}
+
+	if !p.Consume(nil, tokenpredicate.Type(token.LBracket)) {
+		p.Rewind(mark)
+		return false
+	}
+
+	if !matchElements(p, token.RBracket, &expr.Elements, &expr.WS0, &expr.WS1, &expr.WS2, &expr.HasTrailingComma) {
+		*expr = ArrayLiteralExpr{Type: expr.Type}
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (expr *ArrayLiteralExpr) String() string {
+	return util.StringImpl(expr)
+}
+
+func (expr *ArrayLiteralExpr) GoString() string {
+	return util.GoStringImpl(expr)
+}
+
+func (expr *ArrayLiteralExpr) EstimateStringLength() uint {
+	sum := 3 + expr.Type.EstimateStringLength()
+	sum += estimateElementsStringLength(expr.Elements, expr.WS0, expr.WS1, &expr.WS2, expr.HasTrailingComma)
+	return sum
+}
+
+func (expr *ArrayLiteralExpr) EstimateGoStringLength() uint {
+	sum := 26 + expr.Type.EstimateGoStringLength()
+	sum += expr.WS2.EstimateGoStringLength()
+	sum += sumGoStringLengthEstimates(expr.Elements)
+	sum += sumGoStringLengthEstimates(expr.WS0)
+	sum += sumGoStringLengthEstimates(expr.WS1)
+	return sum
+}
+
+func (expr *ArrayLiteralExpr) WriteStringTo(out *strings.Builder) {
+	expr.Type.WriteStringTo(out)
+	out.WriteString("...")
+	writeElementsTo(out, '[', ']', expr.Elements, expr.WS0, expr.WS1, &expr.WS2, expr.HasTrailingComma)
+}
+
+func (expr *ArrayLiteralExpr) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("ArrayLiteralExpr{")
+	expr.Type.WriteGoStringTo(out)
+	out.WriteByte(',')
+	writeElementsGoStringTo(out, expr.Elements, expr.WS0, expr.WS1, &expr.WS2)
+	out.WriteByte('}')
+}
+
+func (expr *ArrayLiteralExpr) ComputeStringLengthEstimates() {
+	expr.Type.ComputeStringLengthEstimates()
+	computeElementsStringLengthEstimates(expr.Elements, expr.WS0, expr.WS1, &expr.WS2)
+}
+
+func (expr *ArrayLiteralExpr) Children() []Node {
+	list := make([]Node, 0, 1+len(expr.Elements))
+	list = append(list, expr.Type)
+	list = append(list, expr.Elements...)
+	return list
+}
+
+func (expr *ArrayLiteralExpr) RewriteChildren(fn func(Node) Node) {
+	expr.Type = fn(expr.Type)
+	rewriteNodes(expr.Elements, fn)
+}
+
+var _ Node = (*ArrayLiteralExpr)(nil)
+
+// }}}
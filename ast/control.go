@@ -0,0 +1,1561 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chronos-tachyon/go-spiderscript/internal/util"
+	"github.com/chronos-tachyon/go-spiderscript/token"
+	"github.com/chronos-tachyon/go-spiderscript/tokenpredicate"
+)
+
+func writeOptionalGoStringTo(out *strings.Builder, node Node) {
+	if node == nil {
+		out.WriteString("nil")
+	} else {
+		node.WriteGoStringTo(out)
+	}
+}
+
+// matchHeaderExpr matches the expression in the header of a control
+// statement, which may not be a struct literal unless it is bracketed.
+func matchHeaderExpr(ptr *Node, p *Parser) bool {
+	defer p.enterHeader(true)()
+	return MatchExpr(ptr, p)
+}
+
+// ExpressionStatement
+// {{{
+
+type ExpressionStatement struct {
+	extent
+
+	Expr Node
+	WS0  InternalWhitespaceRun
+	WS1  TerminalWhitespaceRun
+}
+
+func (stmt *ExpressionStatement) Init() {
+	*stmt = ExpressionStatement{}
+}
+
+func (stmt *ExpressionStatement) Match(p *Parser) bool {
+	defer p.trackExtent(&stmt.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	stmt.WS0.Match(p)
+
+	if !MatchExpr(&stmt.Expr, p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !stmt.WS1.Match(p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (stmt *ExpressionStatement) String() string {
+	return util.StringImpl(stmt)
+}
+
+func (stmt *ExpressionStatement) GoString() string {
+	return util.GoStringImpl(stmt)
+}
+
+func (stmt *ExpressionStatement) EstimateStringLength() uint {
+	sum := stmt.Expr.EstimateStringLength()
+	sum += stmt.WS0.EstimateStringLength()
+	sum += stmt.WS1.EstimateStringLength()
+	return sum
+}
+
+func (stmt *ExpressionStatement) EstimateGoStringLength() uint {
+	sum := 23 + stmt.Expr.EstimateGoStringLength()
+	sum += stmt.WS0.EstimateGoStringLength()
+	sum += stmt.WS1.EstimateGoStringLength()
+	return sum
+}
+
+func (stmt *ExpressionStatement) WriteStringTo(out *strings.Builder) {
+	stmt.WS0.WriteStringTo(out)
+	stmt.Expr.WriteStringTo(out)
+	stmt.WS1.WriteStringTo(out)
+}
+
+func (stmt *ExpressionStatement) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("ExpressionStatement{")
+	stmt.Expr.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS1.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (stmt *ExpressionStatement) ComputeStringLengthEstimates() {
+	stmt.Expr.ComputeStringLengthEstimates()
+	stmt.WS0.ComputeStringLengthEstimates()
+	stmt.WS1.ComputeStringLengthEstimates()
+}
+
+func (stmt *ExpressionStatement) Children() []Node {
+	list := make([]Node, 0, 1)
+	list = append(list, stmt.Expr)
+	return list
+}
+
+func (stmt *ExpressionStatement) RewriteChildren(fn func(Node) Node) {
+	stmt.Expr = fn(stmt.Expr)
+}
+
+var _ Node = (*ExpressionStatement)(nil)
+
+// }}}
+
+// IfStatement
+// {{{
+
+// IfStatement is "if Cond Then", optionally followed by "else Else", where
+// Else is either a *BlockStatement or another *IfStatement.  In an "else if"
+// chain, only the outermost IfStatement has non-empty WS0 and WS5.
+type IfStatement struct {
+	extent
+
+	Cond Node
+	Then BlockStatement
+	Else Node
+	WS0  InternalWhitespaceRun
+	WS1  InternalWhitespaceRun
+	WS2  InternalWhitespaceRun
+	WS3  InternalWhitespaceRun
+	WS4  InternalWhitespaceRun
+	WS5  TerminalWhitespaceRun
+}
+
+func (stmt *IfStatement) Init() {
+	*stmt = IfStatement{}
+}
+
+func (stmt *IfStatement) Match(p *Parser) bool {
+	defer p.trackExtent(&stmt.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	stmt.WS0.Match(p)
+
+	if !stmt.matchChain(p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !stmt.WS5.Match(p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (stmt *IfStatement) matchChain(p *Parser) bool {
+	if !p.Consume(nil, tokenpredicate.Type(token.KeywordIf)) {
+		return false
+	}
+
+	stmt.WS1.Match(p)
+
+	if !matchHeaderExpr(&stmt.Cond, p) {
+		return false
+	}
+
+	stmt.WS2.Match(p)
+
+	if !stmt.Then.Match(p) {
+		return false
+	}
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	stmt.WS3.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.KeywordElse)) {
+		stmt.WS3.Init()
+		p.Rewind(mark)
+		return true
+	}
+
+	stmt.WS4.Match(p)
+
+	elseIf := &IfStatement{}
+	if elseIf.matchChain(p) {
+		stmt.Else = elseIf
+		return true
+	}
+
+	elseBlock := &BlockStatement{}
+	if elseBlock.Match(p) {
+		stmt.Else = elseBlock
+		return true
+	}
+
+	return false
+}
+
+func (stmt *IfStatement) String() string {
+	return util.StringImpl(stmt)
+}
+
+func (stmt *IfStatement) GoString() string {
+	return util.GoStringImpl(stmt)
+}
+
+func (stmt *IfStatement) EstimateStringLength() uint {
+	sum := 2 + stmt.Cond.EstimateStringLength()
+	sum += stmt.Then.EstimateStringLength()
+	if stmt.Else != nil {
+		sum += 4 + stmt.Else.EstimateStringLength()
+	}
+	sum += stmt.WS0.EstimateStringLength()
+	sum += stmt.WS1.EstimateStringLength()
+	sum += stmt.WS2.EstimateStringLength()
+	sum += stmt.WS3.EstimateStringLength()
+	sum += stmt.WS4.EstimateStringLength()
+	sum += stmt.WS5.EstimateStringLength()
+	return sum
+}
+
+func (stmt *IfStatement) EstimateGoStringLength() uint {
+	sum := 23 + stmt.Cond.EstimateGoStringLength()
+	sum += stmt.Then.EstimateGoStringLength()
+	if stmt.Else != nil {
+		sum += stmt.Else.EstimateGoStringLength()
+	}
+	sum += stmt.WS0.EstimateGoStringLength()
+	sum += stmt.WS1.EstimateGoStringLength()
+	sum += stmt.WS2.EstimateGoStringLength()
+	sum += stmt.WS3.EstimateGoStringLength()
+	sum += stmt.WS4.EstimateGoStringLength()
+	sum += stmt.WS5.EstimateGoStringLength()
+	return sum
+}
+
+func (stmt *IfStatement) WriteStringTo(out *strings.Builder) {
+	stmt.WS0.WriteStringTo(out)
+	out.WriteString("if")
+	stmt.WS1.WriteStringTo(out)
+	stmt.Cond.WriteStringTo(out)
+	stmt.WS2.WriteStringTo(out)
+	stmt.Then.WriteStringTo(out)
+	if stmt.Else != nil {
+		stmt.WS3.WriteStringTo(out)
+		out.WriteString("else")
+		stmt.WS4.WriteStringTo(out)
+		stmt.Else.WriteStringTo(out)
+	}
+	stmt.WS5.WriteStringTo(out)
+}
+
+func (stmt *IfStatement) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("IfStatement{")
+	stmt.Cond.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.Then.WriteGoStringTo(out)
+	out.WriteByte(',')
+	writeOptionalGoStringTo(out, stmt.Else)
+	out.WriteByte(',')
+	stmt.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS1.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS2.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS3.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS4.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS5.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (stmt *IfStatement) ComputeStringLengthEstimates() {
+	stmt.Cond.ComputeStringLengthEstimates()
+	stmt.Then.ComputeStringLengthEstimates()
+	if stmt.Else != nil {
+		stmt.Else.ComputeStringLengthEstimates()
+	}
+	stmt.WS0.ComputeStringLengthEstimates()
+	stmt.WS1.ComputeStringLengthEstimates()
+	stmt.WS2.ComputeStringLengthEstimates()
+	stmt.WS3.ComputeStringLengthEstimates()
+	stmt.WS4.ComputeStringLengthEstimates()
+	stmt.WS5.ComputeStringLengthEstimates()
+}
+
+func (stmt *IfStatement) Children() []Node {
+	list := make([]Node, 0, 3)
+	list = append(list, stmt.Cond)
+	list = append(list, &stmt.Then)
+	if stmt.Else != nil {
+		list = append(list, stmt.Else)
+	}
+	return list
+}
+
+func (stmt *IfStatement) RewriteChildren(fn func(Node) Node) {
+	stmt.Cond = fn(stmt.Cond)
+	stmt.Then = *rewriteSameType(&stmt.Then, fn).(*BlockStatement)
+	if stmt.Else != nil {
+		stmt.Else = fn(stmt.Else)
+	}
+}
+
+var _ Node = (*IfStatement)(nil)
+
+// }}}
+
+// WhileStatement
+// {{{
+
+type WhileStatement struct {
+	extent
+
+	Cond Node
+	Body BlockStatement
+	WS0  InternalWhitespaceRun
+	WS1  InternalWhitespaceRun
+	WS2  InternalWhitespaceRun
+	WS3  TerminalWhitespaceRun
+}
+
+func (stmt *WhileStatement) Init() {
+	*stmt = WhileStatement{}
+}
+
+func (stmt *WhileStatement) Match(p *Parser) bool {
+	defer p.trackExtent(&stmt.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	stmt.WS0.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.KeywordWhile)) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	stmt.WS1.Match(p)
+
+	if !matchHeaderExpr(&stmt.Cond, p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	stmt.WS2.Match(p)
+
+	if !stmt.Body.Match(p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !stmt.WS3.Match(p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (stmt *WhileStatement) String() string {
+	return util.StringImpl(stmt)
+}
+
+func (stmt *WhileStatement) GoString() string {
+	return util.GoStringImpl(stmt)
+}
+
+func (stmt *WhileStatement) EstimateStringLength() uint {
+	sum := 5 + stmt.Cond.EstimateStringLength()
+	sum += stmt.Body.EstimateStringLength()
+	sum += stmt.WS0.EstimateStringLength()
+	sum += stmt.WS1.EstimateStringLength()
+	sum += stmt.WS2.EstimateStringLength()
+	sum += stmt.WS3.EstimateStringLength()
+	return sum
+}
+
+func (stmt *WhileStatement) EstimateGoStringLength() uint {
+	sum := 20 + stmt.Cond.EstimateGoStringLength()
+	sum += stmt.Body.EstimateGoStringLength()
+	sum += stmt.WS0.EstimateGoStringLength()
+	sum += stmt.WS1.EstimateGoStringLength()
+	sum += stmt.WS2.EstimateGoStringLength()
+	sum += stmt.WS3.EstimateGoStringLength()
+	return sum
+}
+
+func (stmt *WhileStatement) WriteStringTo(out *strings.Builder) {
+	stmt.WS0.WriteStringTo(out)
+	out.WriteString("while")
+	stmt.WS1.WriteStringTo(out)
+	stmt.Cond.WriteStringTo(out)
+	stmt.WS2.WriteStringTo(out)
+	stmt.Body.WriteStringTo(out)
+	stmt.WS3.WriteStringTo(out)
+}
+
+func (stmt *WhileStatement) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("WhileStatement{")
+	stmt.Cond.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.Body.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS1.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS2.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS3.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (stmt *WhileStatement) ComputeStringLengthEstimates() {
+	stmt.Cond.ComputeStringLengthEstimates()
+	stmt.Body.ComputeStringLengthEstimates()
+	stmt.WS0.ComputeStringLengthEstimates()
+	stmt.WS1.ComputeStringLengthEstimates()
+	stmt.WS2.ComputeStringLengthEstimates()
+	stmt.WS3.ComputeStringLengthEstimates()
+}
+
+func (stmt *WhileStatement) Children() []Node {
+	list := make([]Node, 0, 2)
+	list = append(list, stmt.Cond)
+	list = append(list, &stmt.Body)
+	return list
+}
+
+func (stmt *WhileStatement) RewriteChildren(fn func(Node) Node) {
+	stmt.Cond = fn(stmt.Cond)
+	stmt.Body = *rewriteSameType(&stmt.Body, fn).(*BlockStatement)
+}
+
+var _ Node = (*WhileStatement)(nil)
+
+// }}}
+
+// ForStatement
+// {{{
+
+// ForStatement is "for Pre; Cond; Post Body".  Each of Pre, Cond and Post
+// may be nil.  Pre and Post are an unterminated AssignmentStatement or
+// MutationStatement, as in "for i := 0; i < n; i++ {}", or an expression.
+type ForStatement struct {
+	extent
+
+	Pre  Node
+	Cond Node
+	Post Node
+	Body BlockStatement
+	WS0  InternalWhitespaceRun
+	WS1  InternalWhitespaceRun
+	WS2  InternalWhitespaceRun
+	WS3  InternalWhitespaceRun
+	WS4  InternalWhitespaceRun
+	WS5  InternalWhitespaceRun
+	WS6  InternalWhitespaceRun
+	WS7  TerminalWhitespaceRun
+}
+
+func (stmt *ForStatement) Init() {
+	*stmt = ForStatement{}
+}
+
+func (stmt *ForStatement) Match(p *Parser) bool {
+	defer p.trackExtent(&stmt.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	stmt.WS0.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.KeywordFor)) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	stmt.WS1.Match(p)
+
+	if matchForClause(&stmt.Pre, p) {
+		stmt.WS2.Match(p)
+	}
+
+	if !p.Consume(nil, tokenpredicate.Type(token.Semicolon)) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	stmt.WS3.Match(p)
+
+	if matchHeaderExpr(&stmt.Cond, p) {
+		stmt.WS4.Match(p)
+	}
+
+	if !p.Consume(nil, tokenpredicate.Type(token.Semicolon)) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	stmt.WS5.Match(p)
+
+	if matchForClause(&stmt.Post, p) {
+		stmt.WS6.Match(p)
+	}
+
+	if !stmt.Body.Match(p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !stmt.WS7.Match(p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (stmt *ForStatement) String() string {
+	return util.StringImpl(stmt)
+}
+
+func (stmt *ForStatement) GoString() string {
+	return util.GoStringImpl(stmt)
+}
+
+func (stmt *ForStatement) EstimateStringLength() uint {
+	sum := 5 + stmt.Body.EstimateStringLength()
+	if stmt.Pre != nil {
+		sum += stmt.Pre.EstimateStringLength()
+	}
+	if stmt.Cond != nil {
+		sum += stmt.Cond.EstimateStringLength()
+	}
+	if stmt.Post != nil {
+		sum += stmt.Post.EstimateStringLength()
+	}
+	sum += stmt.WS0.EstimateStringLength()
+	sum += stmt.WS1.EstimateStringLength()
+	sum += stmt.WS2.EstimateStringLength()
+	sum += stmt.WS3.EstimateStringLength()
+	sum += stmt.WS4.EstimateStringLength()
+	sum += stmt.WS5.EstimateStringLength()
+	sum += stmt.WS6.EstimateStringLength()
+	sum += stmt.WS7.EstimateStringLength()
+	return sum
+}
+
+func (stmt *ForStatement) EstimateGoStringLength() uint {
+	sum := 33 + stmt.Body.EstimateGoStringLength()
+	if stmt.Pre != nil {
+		sum += stmt.Pre.EstimateGoStringLength()
+	}
+	if stmt.Cond != nil {
+		sum += stmt.Cond.EstimateGoStringLength()
+	}
+	if stmt.Post != nil {
+		sum += stmt.Post.EstimateGoStringLength()
+	}
+	sum += stmt.WS0.EstimateGoStringLength()
+	sum += stmt.WS1.EstimateGoStringLength()
+	sum += stmt.WS2.EstimateGoStringLength()
+	sum += stmt.WS3.EstimateGoStringLength()
+	sum += stmt.WS4.EstimateGoStringLength()
+	sum += stmt.WS5.EstimateGoStringLength()
+	sum += stmt.WS6.EstimateGoStringLength()
+	sum += stmt.WS7.EstimateGoStringLength()
+	return sum
+}
+
+func (stmt *ForStatement) WriteStringTo(out *strings.Builder) {
+	stmt.WS0.WriteStringTo(out)
+	out.WriteString("for")
+	stmt.WS1.WriteStringTo(out)
+	if stmt.Pre != nil {
+		stmt.Pre.WriteStringTo(out)
+		stmt.WS2.WriteStringTo(out)
+	}
+	out.WriteByte(';')
+	stmt.WS3.WriteStringTo(out)
+	if stmt.Cond != nil {
+		stmt.Cond.WriteStringTo(out)
+		stmt.WS4.WriteStringTo(out)
+	}
+	out.WriteByte(';')
+	stmt.WS5.WriteStringTo(out)
+	if stmt.Post != nil {
+		stmt.Post.WriteStringTo(out)
+		stmt.WS6.WriteStringTo(out)
+	}
+	stmt.Body.WriteStringTo(out)
+	stmt.WS7.WriteStringTo(out)
+}
+
+func (stmt *ForStatement) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("ForStatement{")
+	writeOptionalGoStringTo(out, stmt.Pre)
+	out.WriteByte(',')
+	writeOptionalGoStringTo(out, stmt.Cond)
+	out.WriteByte(',')
+	writeOptionalGoStringTo(out, stmt.Post)
+	out.WriteByte(',')
+	stmt.Body.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS1.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS2.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS3.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS4.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS5.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS6.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS7.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (stmt *ForStatement) ComputeStringLengthEstimates() {
+	if stmt.Pre != nil {
+		stmt.Pre.ComputeStringLengthEstimates()
+	}
+	if stmt.Cond != nil {
+		stmt.Cond.ComputeStringLengthEstimates()
+	}
+	if stmt.Post != nil {
+		stmt.Post.ComputeStringLengthEstimates()
+	}
+	stmt.Body.ComputeStringLengthEstimates()
+	stmt.WS0.ComputeStringLengthEstimates()
+	stmt.WS1.ComputeStringLengthEstimates()
+	stmt.WS2.ComputeStringLengthEstimates()
+	stmt.WS3.ComputeStringLengthEstimates()
+	stmt.WS4.ComputeStringLengthEstimates()
+	stmt.WS5.ComputeStringLengthEstimates()
+	stmt.WS6.ComputeStringLengthEstimates()
+	stmt.WS7.ComputeStringLengthEstimates()
+}
+
+func (stmt *ForStatement) Children() []Node {
+	list := make([]Node, 0, 4)
+	if stmt.Pre != nil {
+		list = append(list, stmt.Pre)
+	}
+	if stmt.Cond != nil {
+		list = append(list, stmt.Cond)
+	}
+	if stmt.Post != nil {
+		list = append(list, stmt.Post)
+	}
+	list = append(list, &stmt.Body)
+	return list
+}
+
+func (stmt *ForStatement) RewriteChildren(fn func(Node) Node) {
+	if stmt.Pre != nil {
+		stmt.Pre = fn(stmt.Pre)
+	}
+	if stmt.Cond != nil {
+		stmt.Cond = fn(stmt.Cond)
+	}
+	if stmt.Post != nil {
+		stmt.Post = fn(stmt.Post)
+	}
+	stmt.Body = *rewriteSameType(&stmt.Body, fn).(*BlockStatement)
+}
+
+var _ Node = (*ForStatement)(nil)
+
+// matchForClause matches the Pre or Post clause of a ForStatement.
+func matchForClause(out *Node, p *Parser) bool {
+	defer p.enterHeader(true)()
+
+	assign := &AssignmentStatement{}
+	if assign.match(p, false) {
+		*out = assign
+		return true
+	}
+
+	mutate := &MutationStatement{}
+	if mutate.match(p, false) {
+		*out = mutate
+		return true
+	}
+
+	return MatchExpr(out, p)
+}
+
+// }}}
+
+// ForEachStatement
+// {{{
+
+// ForEachStatement is "foreach Target: Iterable Body", where Target is an
+// identifier, a placeholder, or a tuple of those, e.g. "foreach (k, v): m".
+type ForEachStatement struct {
+	extent
+
+	Target   Node
+	Iterable Node
+	Body     BlockStatement
+	WS0      InternalWhitespaceRun
+	WS1      InternalWhitespaceRun
+	WS2      InternalWhitespaceRun
+	WS3      InternalWhitespaceRun
+	WS4      InternalWhitespaceRun
+	WS5      TerminalWhitespaceRun
+}
+
+func (stmt *ForEachStatement) Init() {
+	*stmt = ForEachStatement{}
+}
+
+func (stmt *ForEachStatement) Match(p *Parser) bool {
+	defer p.trackExtent(&stmt.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	stmt.WS0.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.KeywordForEach)) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	stmt.WS1.Match(p)
+
+	if !matchExpr25(&stmt.Target, p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	stmt.WS2.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.Colon)) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	stmt.WS3.Match(p)
+
+	if !matchHeaderExpr(&stmt.Iterable, p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	stmt.WS4.Match(p)
+
+	if !stmt.Body.Match(p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !stmt.WS5.Match(p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (stmt *ForEachStatement) String() string {
+	return util.StringImpl(stmt)
+}
+
+func (stmt *ForEachStatement) GoString() string {
+	return util.GoStringImpl(stmt)
+}
+
+func (stmt *ForEachStatement) EstimateStringLength() uint {
+	sum := 8 + stmt.Target.EstimateStringLength()
+	sum += stmt.Iterable.EstimateStringLength()
+	sum += stmt.Body.EstimateStringLength()
+	sum += stmt.WS0.EstimateStringLength()
+	sum += stmt.WS1.EstimateStringLength()
+	sum += stmt.WS2.EstimateStringLength()
+	sum += stmt.WS3.EstimateStringLength()
+	sum += stmt.WS4.EstimateStringLength()
+	sum += stmt.WS5.EstimateStringLength()
+	return sum
+}
+
+func (stmt *ForEachStatement) EstimateGoStringLength() uint {
+	sum := 25 + stmt.Target.EstimateGoStringLength()
+	sum += stmt.Iterable.EstimateGoStringLength()
+	sum += stmt.Body.EstimateGoStringLength()
+	sum += stmt.WS0.EstimateGoStringLength()
+	sum += stmt.WS1.EstimateGoStringLength()
+	sum += stmt.WS2.EstimateGoStringLength()
+	sum += stmt.WS3.EstimateGoStringLength()
+	sum += stmt.WS4.EstimateGoStringLength()
+	sum += stmt.WS5.EstimateGoStringLength()
+	return sum
+}
+
+func (stmt *ForEachStatement) WriteStringTo(out *strings.Builder) {
+	stmt.WS0.WriteStringTo(out)
+	out.WriteString("foreach")
+	stmt.WS1.WriteStringTo(out)
+	stmt.Target.WriteStringTo(out)
+	stmt.WS2.WriteStringTo(out)
+	out.WriteByte(':')
+	stmt.WS3.WriteStringTo(out)
+	stmt.Iterable.WriteStringTo(out)
+	stmt.WS4.WriteStringTo(out)
+	stmt.Body.WriteStringTo(out)
+	stmt.WS5.WriteStringTo(out)
+}
+
+func (stmt *ForEachStatement) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("ForEachStatement{")
+	stmt.Target.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.Iterable.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.Body.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS1.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS2.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS3.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS4.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS5.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (stmt *ForEachStatement) ComputeStringLengthEstimates() {
+	stmt.Target.ComputeStringLengthEstimates()
+	stmt.Iterable.ComputeStringLengthEstimates()
+	stmt.Body.ComputeStringLengthEstimates()
+	stmt.WS0.ComputeStringLengthEstimates()
+	stmt.WS1.ComputeStringLengthEstimates()
+	stmt.WS2.ComputeStringLengthEstimates()
+	stmt.WS3.ComputeStringLengthEstimates()
+	stmt.WS4.ComputeStringLengthEstimates()
+	stmt.WS5.ComputeStringLengthEstimates()
+}
+
+func (stmt *ForEachStatement) Children() []Node {
+	list := make([]Node, 0, 3)
+	list = append(list, stmt.Target)
+	list = append(list, stmt.Iterable)
+	list = append(list, &stmt.Body)
+	return list
+}
+
+func (stmt *ForEachStatement) RewriteChildren(fn func(Node) Node) {
+	stmt.Target = fn(stmt.Target)
+	stmt.Iterable = fn(stmt.Iterable)
+	stmt.Body = *rewriteSameType(&stmt.Body, fn).(*BlockStatement)
+}
+
+var _ Node = (*ForEachStatement)(nil)
+
+// }}}
+
+// CaseClause
+// {{{
+
+// CaseClause is one "case A, B: ..." arm of a SwitchStatement.  A case
+// whose only value is "_" is the default case.
+type CaseClause struct {
+	extent
+
+	Values     []Node
+	Statements []Node
+	WS1        []Node
+	WS2        []Node
+	WS0        InternalWhitespaceRun
+}
+
+func (clause *CaseClause) Init() {
+	*clause = CaseClause{}
+}
+
+func (clause *CaseClause) Match(p *Parser) bool {
+	defer p.trackExtent(&clause.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	clause.WS0.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.KeywordCase)) {
+		clause.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	for {
+		var ws1 InternalWhitespaceRun
+		ws1.Match(p)
+
+		var value Node
+		if !MatchExpr(&value, p) {
+			clause.Init()
+			p.Rewind(mark)
+			return false
+		}
+
+		var ws2 InternalWhitespaceRun
+		ws2.Match(p)
+
+		clause.Values = append(clause.Values, value)
+		clause.WS1 = append(clause.WS1, &ws1)
+		clause.WS2 = append(clause.WS2, &ws2)
+
+		if p.Consume(nil, tokenpredicate.Type(token.Colon)) {
+			break
+		}
+
+		if !p.Consume(nil, tokenpredicate.Type(token.Comma)) {
+			clause.Init()
+			p.Rewind(mark)
+			return false
+		}
+	}
+
+	clause.Statements = make([]Node, 0, 4)
+
+	defer p.enterBlock(statementBlock)()
+	for {
+		inner := p.Mark()
+
+		var ws InternalWhitespaceRun
+		ws.Match(p)
+
+		done := p.Peek(nil, tokenpredicate.Or(
+			tokenpredicate.Type(token.KeywordCase),
+			tokenpredicate.Type(token.RBrace),
+			tokenpredicate.Type(token.EOF)))
+
+		p.Rewind(inner)
+		p.Forget(inner)
+
+		if done {
+			return true
+		}
+
+		var child Node
+		if !MatchStatement(&child, p) {
+			clause.Init()
+			p.Rewind(mark)
+			return false
+		}
+		clause.Statements = append(clause.Statements, child)
+	}
+}
+
+func (clause *CaseClause) IsDefault() bool {
+	if len(clause.Values) != 1 {
+		return false
+	}
+	_, ok := clause.Values[0].(*PlaceholderExpr)
+	return ok
+}
+
+func (clause *CaseClause) String() string {
+	return util.StringImpl(clause)
+}
+
+func (clause *CaseClause) GoString() string {
+	return util.GoStringImpl(clause)
+}
+
+func (clause *CaseClause) EstimateStringLength() uint {
+	sum := 4 + uint(len(clause.Values))
+	sum += sumStringLengthEstimates(clause.Values)
+	sum += sumStringLengthEstimates(clause.Statements)
+	sum += sumStringLengthEstimates(clause.WS1)
+	sum += sumStringLengthEstimates(clause.WS2)
+	sum += clause.WS0.EstimateStringLength()
+	return sum
+}
+
+func (clause *CaseClause) EstimateGoStringLength() uint {
+	sum := 24 + clause.WS0.EstimateGoStringLength()
+	sum += sumGoStringLengthEstimates(clause.Values)
+	sum += sumGoStringLengthEstimates(clause.Statements)
+	sum += sumGoStringLengthEstimates(clause.WS1)
+	sum += sumGoStringLengthEstimates(clause.WS2)
+	return sum
+}
+
+func (clause *CaseClause) WriteStringTo(out *strings.Builder) {
+	clause.WS0.WriteStringTo(out)
+	out.WriteString("case")
+	for index := uint(0); index < uint(len(clause.Values)); index++ {
+		if index != 0 {
+			out.WriteByte(',')
+		}
+		clause.WS1[index].WriteStringTo(out)
+		clause.Values[index].WriteStringTo(out)
+		clause.WS2[index].WriteStringTo(out)
+	}
+	out.WriteByte(':')
+	writeStringsTo(out, clause.Statements)
+}
+
+func (clause *CaseClause) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("CaseClause{")
+	out.WriteByte('[')
+	writeGoStringsTo(out, clause.Values)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, clause.Statements)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, clause.WS1)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, clause.WS2)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	clause.WS0.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (clause *CaseClause) ComputeStringLengthEstimates() {
+	for index := uint(0); index < uint(len(clause.Values)); index++ {
+		clause.Values[index].ComputeStringLengthEstimates()
+		clause.WS1[index].ComputeStringLengthEstimates()
+		clause.WS2[index].ComputeStringLengthEstimates()
+	}
+	for _, child := range clause.Statements {
+		child.ComputeStringLengthEstimates()
+	}
+	clause.WS0.ComputeStringLengthEstimates()
+}
+
+func (clause *CaseClause) Children() []Node {
+	list := make([]Node, 0, len(clause.Values)+len(clause.Statements))
+	list = append(list, clause.Values...)
+	list = append(list, clause.Statements...)
+	return list
+}
+
+func (clause *CaseClause) RewriteChildren(fn func(Node) Node) {
+	rewriteNodes(clause.Values, fn)
+	rewriteNodes(clause.Statements, fn)
+}
+
+var _ Node = (*CaseClause)(nil)
+
+// }}}
+
+// SwitchStatement
+// {{{
+
+type SwitchStatement struct {
+	extent
+
+	Subject Node
+	Cases   []Node
+	WS0     InternalWhitespaceRun
+	WS1     InternalWhitespaceRun
+	WS2     InternalWhitespaceRun
+	WS3     InternalWhitespaceRun
+	WS4     TerminalWhitespaceRun
+}
+
+func (stmt *SwitchStatement) Init() {
+	*stmt = SwitchStatement{}
+}
+
+func (stmt *SwitchStatement) Match(p *Parser) bool {
+	defer p.trackExtent(&stmt.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	stmt.WS0.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.KeywordSwitch)) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	stmt.WS1.Match(p)
+
+	if !matchHeaderExpr(&stmt.Subject, p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	stmt.WS2.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.LBrace)) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	stmt.Cases = make([]Node, 0, 4)
+
+	for {
+		clause := &CaseClause{}
+		if clause.Match(p) {
+			stmt.Cases = append(stmt.Cases, clause)
+			continue
+		}
+		break
+	}
+
+	stmt.WS3.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.RBrace)) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !stmt.WS4.Match(p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (stmt *SwitchStatement) String() string {
+	return util.StringImpl(stmt)
+}
+
+func (stmt *SwitchStatement) GoString() string {
+	return util.GoStringImpl(stmt)
+}
+
+func (stmt *SwitchStatement) EstimateStringLength() uint {
+	sum := 8 + stmt.Subject.EstimateStringLength()
+	sum += sumStringLengthEstimates(stmt.Cases)
+	sum += stmt.WS0.EstimateStringLength()
+	sum += stmt.WS1.EstimateStringLength()
+	sum += stmt.WS2.EstimateStringLength()
+	sum += stmt.WS3.EstimateStringLength()
+	sum += stmt.WS4.EstimateStringLength()
+	return sum
+}
+
+func (stmt *SwitchStatement) EstimateGoStringLength() uint {
+	sum := 24 + stmt.Subject.EstimateGoStringLength()
+	sum += sumGoStringLengthEstimates(stmt.Cases)
+	sum += stmt.WS0.EstimateGoStringLength()
+	sum += stmt.WS1.EstimateGoStringLength()
+	sum += stmt.WS2.EstimateGoStringLength()
+	sum += stmt.WS3.EstimateGoStringLength()
+	sum += stmt.WS4.EstimateGoStringLength()
+	return sum
+}
+
+func (stmt *SwitchStatement) WriteStringTo(out *strings.Builder) {
+	stmt.WS0.WriteStringTo(out)
+	out.WriteString("switch")
+	stmt.WS1.WriteStringTo(out)
+	stmt.Subject.WriteStringTo(out)
+	stmt.WS2.WriteStringTo(out)
+	out.WriteByte('{')
+	writeStringsTo(out, stmt.Cases)
+	stmt.WS3.WriteStringTo(out)
+	out.WriteByte('}')
+	stmt.WS4.WriteStringTo(out)
+}
+
+func (stmt *SwitchStatement) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("SwitchStatement{")
+	stmt.Subject.WriteGoStringTo(out)
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, stmt.Cases)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	stmt.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS1.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS2.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS3.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS4.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (stmt *SwitchStatement) ComputeStringLengthEstimates() {
+	stmt.Subject.ComputeStringLengthEstimates()
+	for _, clause := range stmt.Cases {
+		clause.ComputeStringLengthEstimates()
+	}
+	stmt.WS0.ComputeStringLengthEstimates()
+	stmt.WS1.ComputeStringLengthEstimates()
+	stmt.WS2.ComputeStringLengthEstimates()
+	stmt.WS3.ComputeStringLengthEstimates()
+	stmt.WS4.ComputeStringLengthEstimates()
+}
+
+func (stmt *SwitchStatement) Children() []Node {
+	list := make([]Node, 0, 1+len(stmt.Cases))
+	list = append(list, stmt.Subject)
+	list = append(list, stmt.Cases...)
+	return list
+}
+
+func (stmt *SwitchStatement) RewriteChildren(fn func(Node) Node) {
+	stmt.Subject = fn(stmt.Subject)
+	rewriteNodes(stmt.Cases, fn)
+}
+
+var _ Node = (*SwitchStatement)(nil)
+
+// }}}
+
+// ReturnStatement
+// {{{
+
+type ReturnStatement struct {
+	extent
+
+	Value Node
+	WS0   InternalWhitespaceRun
+	WS1   InternalWhitespaceRun
+	WS2   TerminalWhitespaceRun
+}
+
+func (stmt *ReturnStatement) Init() {
+	*stmt = ReturnStatement{}
+}
+
+func (stmt *ReturnStatement) Match(p *Parser) bool {
+	defer p.trackExtent(&stmt.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	stmt.WS0.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.KeywordReturn)) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if stmt.WS2.Match(p) {
+		return true
+	}
+
+	stmt.WS1.Match(p)
+
+	if !MatchExpr(&stmt.Value, p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !stmt.WS2.Match(p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (stmt *ReturnStatement) String() string {
+	return util.StringImpl(stmt)
+}
+
+func (stmt *ReturnStatement) GoString() string {
+	return util.GoStringImpl(stmt)
+}
+
+func (stmt *ReturnStatement) EstimateStringLength() uint {
+	sum := 6 + stmt.WS0.EstimateStringLength()
+	if stmt.Value != nil {
+		sum += stmt.Value.EstimateStringLength()
+	}
+	sum += stmt.WS1.EstimateStringLength()
+	sum += stmt.WS2.EstimateStringLength()
+	return sum
+}
+
+func (stmt *ReturnStatement) EstimateGoStringLength() uint {
+	sum := 24 + stmt.WS0.EstimateGoStringLength()
+	if stmt.Value != nil {
+		sum += stmt.Value.EstimateGoStringLength()
+	}
+	sum += stmt.WS1.EstimateGoStringLength()
+	sum += stmt.WS2.EstimateGoStringLength()
+	return sum
+}
+
+func (stmt *ReturnStatement) WriteStringTo(out *strings.Builder) {
+	stmt.WS0.WriteStringTo(out)
+	out.WriteString("return")
+	if stmt.Value != nil {
+		stmt.WS1.WriteStringTo(out)
+		stmt.Value.WriteStringTo(out)
+	}
+	stmt.WS2.WriteStringTo(out)
+}
+
+func (stmt *ReturnStatement) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("ReturnStatement{")
+	writeOptionalGoStringTo(out, stmt.Value)
+	out.WriteByte(',')
+	stmt.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS1.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS2.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (stmt *ReturnStatement) ComputeStringLengthEstimates() {
+	if stmt.Value != nil {
+		stmt.Value.ComputeStringLengthEstimates()
+	}
+	stmt.WS0.ComputeStringLengthEstimates()
+	stmt.WS1.ComputeStringLengthEstimates()
+	stmt.WS2.ComputeStringLengthEstimates()
+}
+
+func (stmt *ReturnStatement) Children() []Node {
+	list := make([]Node, 0, 1)
+	if stmt.Value != nil {
+		list = append(list, stmt.Value)
+	}
+	return list
+}
+
+func (stmt *ReturnStatement) RewriteChildren(fn func(Node) Node) {
+	if stmt.Value != nil {
+		stmt.Value = fn(stmt.Value)
+	}
+}
+
+var _ Node = (*ReturnStatement)(nil)
+
+// }}}
+
+// BranchKind
+// {{{
+
+type BranchKind uint8
+
+const (
+	InvalidBranchKind BranchKind = iota
+	BranchBreak
+	BranchContinue
+	BranchGoto
+)
+
+var branchKindKeywords = []string{
+	"???",
+	"break",
+	"continue",
+	"goto",
+}
+
+var branchKindNames = []string{
+	"InvalidBranchKind",
+	"BranchBreak",
+	"BranchContinue",
+	"BranchGoto",
+}
+
+func (kind BranchKind) String() string {
+	if uint(kind) >= uint(len(branchKindKeywords)) {
+		kind = 0
+	}
+	return branchKindKeywords[kind]
+}
+
+func (kind BranchKind) GoString() string {
+	if uint(kind) >= uint(len(branchKindNames)) {
+		return fmt.Sprintf("BranchKind(%d)", uint(kind))
+	}
+	return branchKindNames[kind]
+}
+
+var _ fmt.Stringer = BranchKind(0)
+var _ fmt.GoStringer = BranchKind(0)
+
+// }}}
+
+// BranchStatement
+// {{{
+
+// BranchStatement is "break", "continue" or "goto Label".  The lexer has no
+// dedicated tokens for "break" and "continue", so they are matched as
+// identifiers, the same way ThisExpr matches "this".
+type BranchStatement struct {
+	extent
+
+	Kind  BranchKind
+	Label string
+	WS0   InternalWhitespaceRun
+	WS1   InternalWhitespaceRun
+	WS2   TerminalWhitespaceRun
+}
+
+func (stmt *BranchStatement) Init() {
+	*stmt = BranchStatement{}
+}
+
+func (stmt *BranchStatement) Match(p *Parser) bool {
+	defer p.trackExtent(&stmt.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	stmt.WS0.Match(p)
+
+	switch {
+	case p.Consume(nil, tokenpredicate.Type(token.KeywordGoto)):
+		stmt.Kind = BranchGoto
+	case p.Consume(nil, tokenpredicate.And(tokenpredicate.Type(token.Identifier), tokenpredicate.Literal("break"))):
+		stmt.Kind = BranchBreak
+	case p.Consume(nil, tokenpredicate.And(tokenpredicate.Type(token.Identifier), tokenpredicate.Literal("continue"))):
+		stmt.Kind = BranchContinue
+	default:
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if stmt.Kind != BranchGoto && stmt.WS2.Match(p) {
+		return true
+	}
+
+	if !stmt.WS1.Match(p) || !matchDeclName(&stmt.Label, p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !stmt.WS2.Match(p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (stmt *BranchStatement) String() string {
+	return util.StringImpl(stmt)
+}
+
+func (stmt *BranchStatement) GoString() string {
+	return util.GoStringImpl(stmt)
+}
+
+func (stmt *BranchStatement) EstimateStringLength() uint {
+	sum := 8 + uint(len(stmt.Label))
+	sum += stmt.WS0.EstimateStringLength()
+	sum += stmt.WS1.EstimateStringLength()
+	sum += stmt.WS2.EstimateStringLength()
+	return sum
+}
+
+func (stmt *BranchStatement) EstimateGoStringLength() uint {
+	sum := 36 + uint(len(stmt.Label))
+	sum += stmt.WS0.EstimateGoStringLength()
+	sum += stmt.WS1.EstimateGoStringLength()
+	sum += stmt.WS2.EstimateGoStringLength()
+	return sum
+}
+
+func (stmt *BranchStatement) WriteStringTo(out *strings.Builder) {
+	stmt.WS0.WriteStringTo(out)
+	out.WriteString(stmt.Kind.String())
+	if stmt.Label != "" {
+		stmt.WS1.WriteStringTo(out)
+		out.WriteString(stmt.Label)
+	}
+	stmt.WS2.WriteStringTo(out)
+}
+
+func (stmt *BranchStatement) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("BranchStatement{")
+	out.WriteString(stmt.Kind.GoString())
+	out.WriteByte(',')
+	out.WriteString(strconv.Quote(stmt.Label))
+	out.WriteByte(',')
+	stmt.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS1.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS2.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (stmt *BranchStatement) ComputeStringLengthEstimates() {
+	stmt.WS0.ComputeStringLengthEstimates()
+	stmt.WS1.ComputeStringLengthEstimates()
+	stmt.WS2.ComputeStringLengthEstimates()
+}
+
+func (stmt *BranchStatement) Children() []Node {
+	return nil
+}
+
+func (stmt *BranchStatement) RewriteChildren(fn func(Node) Node) {
+}
+
+var _ Node = (*BranchStatement)(nil)
+
+// }}}
@@ -0,0 +1,769 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chronos-tachyon/go-spiderscript/internal/util"
+	"github.com/chronos-tachyon/go-spiderscript/token"
+	"github.com/chronos-tachyon/go-spiderscript/tokenpredicate"
+	"github.com/chronos-tachyon/go-spiderscript/value"
+)
+
+func matchTypeExpr(ptr *Node, p *Parser) bool {
+	return matchExpr24(ptr, p)
+}
+
+func matchDeclName(out *string, p *Parser) bool {
+	var tok token.Token
+	if !p.Consume(&tok, tokenpredicate.Type(token.Identifier)) {
+		return false
+	}
+
+	name, err := value.ExtractLiteral(tok.Parsed, "<error>")
+	if err != nil {
+		p.EmitError(err)
+	}
+	*out = name
+	return true
+}
+
+func matchTrailingPragmas(p *Parser, pragmas *[]Node, ws *[]Node, term *TerminalWhitespaceRun) bool {
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	for {
+		if term.Match(p) {
+			return true
+		}
+
+		var ws0 InternalWhitespaceRun
+		ws0.Match(p)
+
+		var pragma Node
+		if MatchPragma(&pragma, p) {
+			*pragmas = append(*pragmas, pragma)
+			*ws = append(*ws, &ws0)
+			continue
+		}
+
+		*pragmas = nil
+		*ws = nil
+		term.Init()
+		p.Rewind(mark)
+		return false
+	}
+}
+
+func writePragmasTo(out *strings.Builder, pragmas []Node, ws []Node) {
+	for i := uint(0); i < uint(len(pragmas)); i++ {
+		ws[i].WriteStringTo(out)
+		pragmas[i].WriteStringTo(out)
+	}
+}
+
+// DeclKind
+// {{{
+
+type DeclKind uint8
+
+const (
+	InvalidDeclKind DeclKind = iota
+	DeclConst
+	DeclVar
+	DeclLet
+)
+
+var declKindKeywords = []string{
+	"???",
+	"const",
+	"var",
+	"let",
+}
+
+var declKindNames = []string{
+	"InvalidDeclKind",
+	"DeclConst",
+	"DeclVar",
+	"DeclLet",
+}
+
+var declKindMap = map[token.Type]DeclKind{
+	token.KeywordConst: DeclConst,
+	token.KeywordVar:   DeclVar,
+	token.KeywordLet:   DeclLet,
+}
+
+func (kind DeclKind) String() string {
+	if uint(kind) >= uint(len(declKindKeywords)) {
+		kind = 0
+	}
+	return declKindKeywords[kind]
+}
+
+func (kind DeclKind) GoString() string {
+	if uint(kind) >= uint(len(declKindNames)) {
+		return fmt.Sprintf("DeclKind(%d)", uint(kind))
+	}
+	return declKindNames[kind]
+}
+
+var _ fmt.Stringer = DeclKind(0)
+var _ fmt.GoStringer = DeclKind(0)
+
+// }}}
+
+// AliasStatement
+// {{{
+
+type AliasStatement struct {
+	extent
+
+	Name    string
+	Target  Symbol
+	Pragmas []Node
+	WS4     []Node
+	WS0     InternalWhitespaceRun
+	WS1     InternalWhitespaceRun
+	WS2     InternalWhitespaceRun
+	WS3     InternalWhitespaceRun
+	WS5     TerminalWhitespaceRun
+}
+
+func (stmt *AliasStatement) Init() {
+	*stmt = AliasStatement{}
+}
+
+func (stmt *AliasStatement) Match(p *Parser) bool {
+	defer p.trackExtent(&stmt.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	stmt.WS0.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.KeywordAlias)) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !stmt.WS1.Match(p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !matchDeclName(&stmt.Name, p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	stmt.WS2.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.Equal)) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	stmt.WS3.Match(p)
+
+	if !stmt.Target.Match(p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !matchTrailingPragmas(p, &stmt.Pragmas, &stmt.WS4, &stmt.WS5) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (stmt *AliasStatement) String() string {
+	return util.StringImpl(stmt)
+}
+
+func (stmt *AliasStatement) GoString() string {
+	return util.GoStringImpl(stmt)
+}
+
+func (stmt *AliasStatement) EstimateStringLength() uint {
+	sum := 6 + uint(len(stmt.Name))
+	sum += stmt.Target.EstimateStringLength()
+	sum += sumStringLengthEstimates(stmt.Pragmas)
+	sum += stmt.WS0.EstimateStringLength()
+	sum += stmt.WS1.EstimateStringLength()
+	sum += stmt.WS2.EstimateStringLength()
+	sum += stmt.WS3.EstimateStringLength()
+	sum += sumStringLengthEstimates(stmt.WS4)
+	sum += stmt.WS5.EstimateStringLength()
+	return sum
+}
+
+func (stmt *AliasStatement) EstimateGoStringLength() uint {
+	sum := 30 + uint(len(stmt.Name))
+	sum += stmt.Target.EstimateGoStringLength()
+	sum += sumGoStringLengthEstimates(stmt.Pragmas)
+	sum += stmt.WS0.EstimateGoStringLength()
+	sum += stmt.WS1.EstimateGoStringLength()
+	sum += stmt.WS2.EstimateGoStringLength()
+	sum += stmt.WS3.EstimateGoStringLength()
+	sum += sumGoStringLengthEstimates(stmt.WS4)
+	sum += stmt.WS5.EstimateGoStringLength()
+	return sum
+}
+
+func (stmt *AliasStatement) WriteStringTo(out *strings.Builder) {
+	stmt.WS0.WriteStringTo(out)
+	out.WriteString("alias")
+	stmt.WS1.WriteStringTo(out)
+	out.WriteString(stmt.Name)
+	stmt.WS2.WriteStringTo(out)
+	out.WriteByte('=')
+	stmt.WS3.WriteStringTo(out)
+	stmt.Target.WriteStringTo(out)
+	writePragmasTo(out, stmt.Pragmas, stmt.WS4)
+	stmt.WS5.WriteStringTo(out)
+}
+
+func (stmt *AliasStatement) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("AliasStatement{")
+	out.WriteString(strconv.Quote(stmt.Name))
+	out.WriteByte(',')
+	stmt.Target.WriteGoStringTo(out)
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, stmt.Pragmas)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	stmt.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS1.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS2.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS3.WriteGoStringTo(out)
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, stmt.WS4)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	stmt.WS5.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (stmt *AliasStatement) ComputeStringLengthEstimates() {
+	stmt.Target.ComputeStringLengthEstimates()
+	for i := uint(0); i < uint(len(stmt.Pragmas)); i++ {
+		stmt.Pragmas[i].ComputeStringLengthEstimates()
+		stmt.WS4[i].ComputeStringLengthEstimates()
+	}
+	stmt.WS0.ComputeStringLengthEstimates()
+	stmt.WS1.ComputeStringLengthEstimates()
+	stmt.WS2.ComputeStringLengthEstimates()
+	stmt.WS3.ComputeStringLengthEstimates()
+	stmt.WS5.ComputeStringLengthEstimates()
+}
+
+func (stmt *AliasStatement) Children() []Node {
+	list := make([]Node, 0, 1+len(stmt.Pragmas))
+	list = append(list, &stmt.Target)
+	list = append(list, stmt.Pragmas...)
+	return list
+}
+
+func (stmt *AliasStatement) RewriteChildren(fn func(Node) Node) {
+	stmt.Target = *rewriteSameType(&stmt.Target, fn).(*Symbol)
+	rewriteNodes(stmt.Pragmas, fn)
+}
+
+var _ Node = (*AliasStatement)(nil)
+
+// }}}
+
+// TypeDeclStatement
+// {{{
+
+// TypeDeclStatement is either "type Name = Target", which declares a new
+// type, or "type Name: Target", which asserts that Name can be used as
+// Target and attaches any trailing pragmas to the existing symbol.  A new
+// type may take generic parameters, as in "type Box[T: type] = struct { ...
+// }", in which case Generics is non-nil.
+type TypeDeclStatement struct {
+	extent
+
+	Name        string
+	Generics    *GenericParameterList
+	Target      Node
+	Pragmas     []Node
+	WS4         []Node
+	WS0         InternalWhitespaceRun
+	WS1         InternalWhitespaceRun
+	WS2         InternalWhitespaceRun
+	WS3         InternalWhitespaceRun
+	WS5         TerminalWhitespaceRun
+	IsAssertion bool
+}
+
+func (stmt *TypeDeclStatement) Init() {
+	*stmt = TypeDeclStatement{}
+}
+
+func (stmt *TypeDeclStatement) Match(p *Parser) bool {
+	defer p.trackExtent(&stmt.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	stmt.WS0.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.KeywordType)) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !stmt.WS1.Match(p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !matchDeclName(&stmt.Name, p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	var generics GenericParameterList
+	if generics.Match(p) {
+		stmt.Generics = &generics
+	}
+
+	stmt.WS2.Match(p)
+
+	var tok token.Token
+	if !p.Consume(&tok, tokenpredicate.Or(tokenpredicate.Type(token.Equal), tokenpredicate.Type(token.Colon))) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+	stmt.IsAssertion = (tok.Type == token.Colon)
+	if stmt.IsAssertion && stmt.Generics != nil {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	stmt.WS3.Match(p)
+
+	if !matchTypeExpr(&stmt.Target, p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !matchTrailingPragmas(p, &stmt.Pragmas, &stmt.WS4, &stmt.WS5) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (stmt *TypeDeclStatement) String() string {
+	return util.StringImpl(stmt)
+}
+
+func (stmt *TypeDeclStatement) GoString() string {
+	return util.GoStringImpl(stmt)
+}
+
+func (stmt *TypeDeclStatement) EstimateStringLength() uint {
+	sum := 5 + uint(len(stmt.Name))
+	if stmt.Generics != nil {
+		sum += stmt.Generics.EstimateStringLength()
+	}
+	sum += stmt.Target.EstimateStringLength()
+	sum += sumStringLengthEstimates(stmt.Pragmas)
+	sum += stmt.WS0.EstimateStringLength()
+	sum += stmt.WS1.EstimateStringLength()
+	sum += stmt.WS2.EstimateStringLength()
+	sum += stmt.WS3.EstimateStringLength()
+	sum += sumStringLengthEstimates(stmt.WS4)
+	sum += stmt.WS5.EstimateStringLength()
+	return sum
+}
+
+func (stmt *TypeDeclStatement) EstimateGoStringLength() uint {
+	sum := 42 + uint(len(stmt.Name))
+	if stmt.Generics != nil {
+		sum += stmt.Generics.EstimateGoStringLength()
+	}
+	sum += stmt.Target.EstimateGoStringLength()
+	sum += sumGoStringLengthEstimates(stmt.Pragmas)
+	sum += stmt.WS0.EstimateGoStringLength()
+	sum += stmt.WS1.EstimateGoStringLength()
+	sum += stmt.WS2.EstimateGoStringLength()
+	sum += stmt.WS3.EstimateGoStringLength()
+	sum += sumGoStringLengthEstimates(stmt.WS4)
+	sum += stmt.WS5.EstimateGoStringLength()
+	return sum
+}
+
+func (stmt *TypeDeclStatement) WriteStringTo(out *strings.Builder) {
+	stmt.WS0.WriteStringTo(out)
+	out.WriteString("type")
+	stmt.WS1.WriteStringTo(out)
+	out.WriteString(stmt.Name)
+	if stmt.Generics != nil {
+		stmt.Generics.WriteStringTo(out)
+	}
+	stmt.WS2.WriteStringTo(out)
+	if stmt.IsAssertion {
+		out.WriteByte(':')
+	} else {
+		out.WriteByte('=')
+	}
+	stmt.WS3.WriteStringTo(out)
+	stmt.Target.WriteStringTo(out)
+	writePragmasTo(out, stmt.Pragmas, stmt.WS4)
+	stmt.WS5.WriteStringTo(out)
+}
+
+func (stmt *TypeDeclStatement) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("TypeDeclStatement{")
+	out.WriteString(strconv.Quote(stmt.Name))
+	out.WriteByte(',')
+	if stmt.Generics == nil {
+		out.WriteString("nil")
+	} else {
+		stmt.Generics.WriteGoStringTo(out)
+	}
+	out.WriteByte(',')
+	stmt.Target.WriteGoStringTo(out)
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, stmt.Pragmas)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	stmt.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS1.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS2.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS3.WriteGoStringTo(out)
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, stmt.WS4)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	stmt.WS5.WriteGoStringTo(out)
+	out.WriteByte(',')
+	out.WriteString(strconv.FormatBool(stmt.IsAssertion))
+	out.WriteByte('}')
+}
+
+func (stmt *TypeDeclStatement) ComputeStringLengthEstimates() {
+	if stmt.Generics != nil {
+		stmt.Generics.ComputeStringLengthEstimates()
+	}
+	stmt.Target.ComputeStringLengthEstimates()
+	for i := uint(0); i < uint(len(stmt.Pragmas)); i++ {
+		stmt.Pragmas[i].ComputeStringLengthEstimates()
+		stmt.WS4[i].ComputeStringLengthEstimates()
+	}
+	stmt.WS0.ComputeStringLengthEstimates()
+	stmt.WS1.ComputeStringLengthEstimates()
+	stmt.WS2.ComputeStringLengthEstimates()
+	stmt.WS3.ComputeStringLengthEstimates()
+	stmt.WS5.ComputeStringLengthEstimates()
+}
+
+func (stmt *TypeDeclStatement) Children() []Node {
+	list := make([]Node, 0, 2+len(stmt.Pragmas))
+	if stmt.Generics != nil {
+		list = append(list, stmt.Generics)
+	}
+	list = append(list, stmt.Target)
+	list = append(list, stmt.Pragmas...)
+	return list
+}
+
+func (stmt *TypeDeclStatement) RewriteChildren(fn func(Node) Node) {
+	if stmt.Generics != nil {
+		stmt.Generics = rewriteSameType(stmt.Generics, fn).(*GenericParameterList)
+	}
+	stmt.Target = fn(stmt.Target)
+	rewriteNodes(stmt.Pragmas, fn)
+}
+
+var _ Node = (*TypeDeclStatement)(nil)
+
+// }}}
+
+// VarDeclStatement
+// {{{
+
+// VarDeclStatement covers "const", "var" and "let" declarations.  Both the
+// type and the initializer are optional; Type and Value are nil if absent.
+type VarDeclStatement struct {
+	extent
+
+	Kind    DeclKind
+	Name    string
+	Type    Node
+	Value   Node
+	Pragmas []Node
+	WS6     []Node
+	WS0     InternalWhitespaceRun
+	WS1     InternalWhitespaceRun
+	WS2     InternalWhitespaceRun
+	WS3     InternalWhitespaceRun
+	WS4     InternalWhitespaceRun
+	WS5     InternalWhitespaceRun
+	WS7     TerminalWhitespaceRun
+}
+
+func (stmt *VarDeclStatement) Init() {
+	*stmt = VarDeclStatement{}
+}
+
+func (stmt *VarDeclStatement) Match(p *Parser) bool {
+	defer p.trackExtent(&stmt.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	stmt.WS0.Match(p)
+
+	var tok token.Token
+	if !p.Consume(&tok, tokenpredicate.Or(
+		tokenpredicate.Type(token.KeywordConst),
+		tokenpredicate.Type(token.KeywordVar),
+		tokenpredicate.Type(token.KeywordLet),
+	)) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+	stmt.Kind = declKindMap[tok.Type]
+
+	if !stmt.WS1.Match(p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !matchDeclName(&stmt.Name, p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	inner := p.Mark()
+	stmt.WS2.Match(p)
+	if p.Consume(nil, tokenpredicate.Type(token.Colon)) {
+		stmt.WS3.Match(p)
+		if !matchTypeExpr(&stmt.Type, p) {
+			p.Forget(inner)
+			stmt.Init()
+			p.Rewind(mark)
+			return false
+		}
+	} else {
+		stmt.WS2.Init()
+		p.Rewind(inner)
+	}
+	p.Forget(inner)
+
+	inner = p.Mark()
+	stmt.WS4.Match(p)
+	if p.Consume(nil, tokenpredicate.Type(token.Equal)) {
+		stmt.WS5.Match(p)
+		if !MatchExpr(&stmt.Value, p) {
+			p.Forget(inner)
+			stmt.Init()
+			p.Rewind(mark)
+			return false
+		}
+	} else {
+		stmt.WS4.Init()
+		p.Rewind(inner)
+	}
+	p.Forget(inner)
+
+	if !matchTrailingPragmas(p, &stmt.Pragmas, &stmt.WS6, &stmt.WS7) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (stmt *VarDeclStatement) String() string {
+	return util.StringImpl(stmt)
+}
+
+func (stmt *VarDeclStatement) GoString() string {
+	return util.GoStringImpl(stmt)
+}
+
+func (stmt *VarDeclStatement) EstimateStringLength() uint {
+	sum := 5 + uint(len(stmt.Name))
+	if stmt.Type != nil {
+		sum += 1 + stmt.Type.EstimateStringLength()
+	}
+	if stmt.Value != nil {
+		sum += 1 + stmt.Value.EstimateStringLength()
+	}
+	sum += sumStringLengthEstimates(stmt.Pragmas)
+	sum += stmt.WS0.EstimateStringLength()
+	sum += stmt.WS1.EstimateStringLength()
+	sum += stmt.WS2.EstimateStringLength()
+	sum += stmt.WS3.EstimateStringLength()
+	sum += stmt.WS4.EstimateStringLength()
+	sum += stmt.WS5.EstimateStringLength()
+	sum += sumStringLengthEstimates(stmt.WS6)
+	sum += stmt.WS7.EstimateStringLength()
+	return sum
+}
+
+func (stmt *VarDeclStatement) EstimateGoStringLength() uint {
+	sum := 48 + uint(len(stmt.Name))
+	if stmt.Type != nil {
+		sum += stmt.Type.EstimateGoStringLength()
+	}
+	if stmt.Value != nil {
+		sum += stmt.Value.EstimateGoStringLength()
+	}
+	sum += sumGoStringLengthEstimates(stmt.Pragmas)
+	sum += stmt.WS0.EstimateGoStringLength()
+	sum += stmt.WS1.EstimateGoStringLength()
+	sum += stmt.WS2.EstimateGoStringLength()
+	sum += stmt.WS3.EstimateGoStringLength()
+	sum += stmt.WS4.EstimateGoStringLength()
+	sum += stmt.WS5.EstimateGoStringLength()
+	sum += sumGoStringLengthEstimates(stmt.WS6)
+	sum += stmt.WS7.EstimateGoStringLength()
+	return sum
+}
+
+func (stmt *VarDeclStatement) WriteStringTo(out *strings.Builder) {
+	stmt.WS0.WriteStringTo(out)
+	out.WriteString(stmt.Kind.String())
+	stmt.WS1.WriteStringTo(out)
+	out.WriteString(stmt.Name)
+	if stmt.Type != nil {
+		stmt.WS2.WriteStringTo(out)
+		out.WriteByte(':')
+		stmt.WS3.WriteStringTo(out)
+		stmt.Type.WriteStringTo(out)
+	}
+	if stmt.Value != nil {
+		stmt.WS4.WriteStringTo(out)
+		out.WriteByte('=')
+		stmt.WS5.WriteStringTo(out)
+		stmt.Value.WriteStringTo(out)
+	}
+	writePragmasTo(out, stmt.Pragmas, stmt.WS6)
+	stmt.WS7.WriteStringTo(out)
+}
+
+func (stmt *VarDeclStatement) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("VarDeclStatement{")
+	out.WriteString(stmt.Kind.GoString())
+	out.WriteByte(',')
+	out.WriteString(strconv.Quote(stmt.Name))
+	out.WriteByte(',')
+	if stmt.Type == nil {
+		out.WriteString("nil")
+	} else {
+		stmt.Type.WriteGoStringTo(out)
+	}
+	out.WriteByte(',')
+	if stmt.Value == nil {
+		out.WriteString("nil")
+	} else {
+		stmt.Value.WriteGoStringTo(out)
+	}
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, stmt.Pragmas)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	stmt.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS1.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS2.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS3.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS4.WriteGoStringTo(out)
+	out.WriteByte(',')
+	stmt.WS5.WriteGoStringTo(out)
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, stmt.WS6)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	stmt.WS7.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (stmt *VarDeclStatement) ComputeStringLengthEstimates() {
+	if stmt.Type != nil {
+		stmt.Type.ComputeStringLengthEstimates()
+	}
+	if stmt.Value != nil {
+		stmt.Value.ComputeStringLengthEstimates()
+	}
+	for i := uint(0); i < uint(len(stmt.Pragmas)); i++ {
+		stmt.Pragmas[i].ComputeStringLengthEstimates()
+		stmt.WS6[i].ComputeStringLengthEstimates()
+	}
+	stmt.WS0.ComputeStringLengthEstimates()
+	stmt.WS1.ComputeStringLengthEstimates()
+	stmt.WS2.ComputeStringLengthEstimates()
+	stmt.WS3.ComputeStringLengthEstimates()
+	stmt.WS4.ComputeStringLengthEstimates()
+	stmt.WS5.ComputeStringLengthEstimates()
+	stmt.WS7.ComputeStringLengthEstimates()
+}
+
+func (stmt *VarDeclStatement) Children() []Node {
+	list := make([]Node, 0, 2+len(stmt.Pragmas))
+	if stmt.Type != nil {
+		list = append(list, stmt.Type)
+	}
+	if stmt.Value != nil {
+		list = append(list, stmt.Value)
+	}
+	list = append(list, stmt.Pragmas...)
+	return list
+}
+
+func (stmt *VarDeclStatement) RewriteChildren(fn func(Node) Node) {
+	if stmt.Type != nil {
+		stmt.Type = fn(stmt.Type)
+	}
+	if stmt.Value != nil {
+		stmt.Value = fn(stmt.Value)
+	}
+	rewriteNodes(stmt.Pragmas, fn)
+}
+
+var _ Node = (*VarDeclStatement)(nil)
+
+// }}}
@@ -18,6 +18,12 @@ func MatchExpr(ptr *Node, p *Parser) bool {
 }
 
 func matchExpr0(ptr *Node, p *Parser) bool {
+	lambda := &LambdaExpr{}
+	if lambda.Match(p) {
+		*ptr = lambda
+		return true
+	}
+
 	return matchExpr1(ptr, p)
 }
 
@@ -38,7 +44,66 @@ func matchExpr4(ptr *Node, p *Parser) bool {
 }
 
 func matchExpr5(ptr *Node, p *Parser) bool {
-	return matchExpr6(ptr, p)
+	var cond Node
+	if !matchExpr6(&cond, p) {
+		return false
+	}
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	*ptr = cond
+
+	var ws0 InternalWhitespaceRun
+	ws0.Match(p)
+
+	var tok token.Token
+	if !p.Consume(&tok, tokenpredicate.Type(token.Question)) {
+		p.Rewind(mark)
+		return true
+	}
+
+	op := ternaryOperatorMap[tok.Type]
+
+	var ws1 InternalWhitespaceRun
+	ws1.Match(p)
+
+	var then Node
+	if !matchExpr5(&then, p) {
+		p.Rewind(mark)
+		return true
+	}
+
+	var ws2 InternalWhitespaceRun
+	ws2.Match(p)
+
+	if !p.Consume(&tok, tokenpredicate.Type(ternaryOperatorSecondTokenMap[op])) {
+		p.Rewind(mark)
+		return true
+	}
+
+	var ws3 InternalWhitespaceRun
+	ws3.Match(p)
+
+	var else_ Node
+	if !matchExpr5(&else_, p) {
+		p.Rewind(mark)
+		return true
+	}
+
+	expr := &TernaryOperatorExpr{
+		Operator: op,
+		Operand0: cond,
+		Operand1: then,
+		Operand2: else_,
+		WS0:      ws0,
+		WS1:      ws1,
+		WS2:      ws2,
+		WS3:      ws3,
+	}
+	p.extendExtent(&expr.extent, cond)
+	*ptr = expr
+	return true
 }
 
 func matchExpr6(ptr *Node, p *Parser) bool {
@@ -71,13 +136,15 @@ func matchExpr6(ptr *Node, p *Parser) bool {
 
 		p.Forget(mark)
 		mark = p.Mark()
-		left = &BinaryOperatorExpr{
+		expr := &BinaryOperatorExpr{
 			Operator: op,
 			Operand0: left,
 			Operand1: right,
 			WS0:      ws0,
 			WS1:      ws1,
 		}
+		p.extendExtent(&expr.extent, left)
+		left = expr
 	}
 
 	p.Rewind(mark)
@@ -115,13 +182,15 @@ func matchExpr7(ptr *Node, p *Parser) bool {
 
 		p.Forget(mark)
 		mark = p.Mark()
-		left = &BinaryOperatorExpr{
+		expr := &BinaryOperatorExpr{
 			Operator: op,
 			Operand0: left,
 			Operand1: right,
 			WS0:      ws0,
 			WS1:      ws1,
 		}
+		p.extendExtent(&expr.extent, left)
+		left = expr
 	}
 
 	p.Rewind(mark)
@@ -159,13 +228,15 @@ func matchExpr8(ptr *Node, p *Parser) bool {
 
 		p.Forget(mark)
 		mark = p.Mark()
-		left = &BinaryOperatorExpr{
+		expr := &BinaryOperatorExpr{
 			Operator: op,
 			Operand0: left,
 			Operand1: right,
 			WS0:      ws0,
 			WS1:      ws1,
 		}
+		p.extendExtent(&expr.extent, left)
+		left = expr
 	}
 
 	p.Rewind(mark)
@@ -203,13 +274,15 @@ func matchExpr9(ptr *Node, p *Parser) bool {
 
 		p.Forget(mark)
 		mark = p.Mark()
-		left = &BinaryOperatorExpr{
+		expr := &BinaryOperatorExpr{
 			Operator: op,
 			Operand0: left,
 			Operand1: right,
 			WS0:      ws0,
 			WS1:      ws1,
 		}
+		p.extendExtent(&expr.extent, left)
+		left = expr
 	}
 
 	p.Rewind(mark)
@@ -247,13 +320,15 @@ func matchExpr10(ptr *Node, p *Parser) bool {
 
 		p.Forget(mark)
 		mark = p.Mark()
-		left = &BinaryOperatorExpr{
+		expr := &BinaryOperatorExpr{
 			Operator: op,
 			Operand0: left,
 			Operand1: right,
 			WS0:      ws0,
 			WS1:      ws1,
 		}
+		p.extendExtent(&expr.extent, left)
+		left = expr
 	}
 
 	p.Rewind(mark)
@@ -299,13 +374,15 @@ func matchExpr11(ptr *Node, p *Parser) bool {
 
 		p.Forget(mark)
 		mark = p.Mark()
-		left = &BinaryOperatorExpr{
+		expr := &BinaryOperatorExpr{
 			Operator: op,
 			Operand0: left,
 			Operand1: right,
 			WS0:      ws0,
 			WS1:      ws1,
 		}
+		p.extendExtent(&expr.extent, left)
+		left = expr
 	}
 
 	p.Rewind(mark)
@@ -343,13 +420,15 @@ func matchExpr12(ptr *Node, p *Parser) bool {
 
 		p.Forget(mark)
 		mark = p.Mark()
-		left = &BinaryOperatorExpr{
+		expr := &BinaryOperatorExpr{
 			Operator: op,
 			Operand0: left,
 			Operand1: right,
 			WS0:      ws0,
 			WS1:      ws1,
 		}
+		p.extendExtent(&expr.extent, left)
+		left = expr
 	}
 
 	p.Rewind(mark)
@@ -387,13 +466,15 @@ func matchExpr13(ptr *Node, p *Parser) bool {
 
 		p.Forget(mark)
 		mark = p.Mark()
-		left = &BinaryOperatorExpr{
+		expr := &BinaryOperatorExpr{
 			Operator: op,
 			Operand0: left,
 			Operand1: right,
 			WS0:      ws0,
 			WS1:      ws1,
 		}
+		p.extendExtent(&expr.extent, left)
+		left = expr
 	}
 
 	p.Rewind(mark)
@@ -431,13 +512,15 @@ func matchExpr14(ptr *Node, p *Parser) bool {
 
 		p.Forget(mark)
 		mark = p.Mark()
-		left = &BinaryOperatorExpr{
+		expr := &BinaryOperatorExpr{
 			Operator: op,
 			Operand0: left,
 			Operand1: right,
 			WS0:      ws0,
 			WS1:      ws1,
 		}
+		p.extendExtent(&expr.extent, left)
+		left = expr
 	}
 
 	p.Rewind(mark)
@@ -481,13 +564,15 @@ func matchExpr15(ptr *Node, p *Parser) bool {
 		return true
 	}
 
-	left = &BinaryOperatorExpr{
+	expr := &BinaryOperatorExpr{
 		Operator: op,
 		Operand0: left,
 		Operand1: right,
 		WS0:      ws0,
 		WS1:      ws1,
 	}
+	p.extendExtent(&expr.extent, left)
+	left = expr
 	*ptr = left
 	return true
 }
@@ -525,13 +610,15 @@ func matchExpr16(ptr *Node, p *Parser) bool {
 
 		p.Forget(mark)
 		mark = p.Mark()
-		left = &BinaryOperatorExpr{
+		expr := &BinaryOperatorExpr{
 			Operator: op,
 			Operand0: left,
 			Operand1: right,
 			WS0:      ws0,
 			WS1:      ws1,
 		}
+		p.extendExtent(&expr.extent, left)
+		left = expr
 	}
 
 	p.Rewind(mark)
@@ -574,13 +661,15 @@ func matchExpr17(ptr *Node, p *Parser) bool {
 
 		p.Forget(mark)
 		mark = p.Mark()
-		left = &BinaryOperatorExpr{
+		expr := &BinaryOperatorExpr{
 			Operator: op,
 			Operand0: left,
 			Operand1: right,
 			WS0:      ws0,
 			WS1:      ws1,
 		}
+		p.extendExtent(&expr.extent, left)
+		left = expr
 	}
 
 	p.Rewind(mark)
@@ -619,13 +708,15 @@ func matchExpr18(ptr *Node, p *Parser) bool {
 		return true
 	}
 
-	left = &BinaryOperatorExpr{
+	expr := &BinaryOperatorExpr{
 		Operator: op,
 		Operand0: left,
 		Operand1: right,
 		WS0:      ws0,
 		WS1:      ws1,
 	}
+	p.extendExtent(&expr.extent, left)
+	left = expr
 	*ptr = left
 	return true
 }
@@ -651,17 +742,134 @@ func matchExpr23(ptr *Node, p *Parser) bool {
 }
 
 func matchExpr24(ptr *Node, p *Parser) bool {
-	return matchExpr25(ptr, p)
+	var left Node
+	if !matchExpr25(&left, p) {
+		return false
+	}
+
+	for {
+		call := &CallExpr{Callee: left}
+		if call.Args.Match(p) {
+			p.extendExtent(&call.extent, left)
+			left = call
+			continue
+		}
+
+		index := &IndexExpr{Operand: left}
+		if index.matchSuffix(p) {
+			p.extendExtent(&index.extent, left)
+			left = index
+			continue
+		}
+
+		generic := &GenericInstantiationExpr{Operand: left}
+		if generic.matchSuffix(p) {
+			p.extendExtent(&generic.extent, left)
+			left = generic
+			continue
+		}
+
+		field := &FieldExpr{Operand: left}
+		if field.matchSuffix(p) {
+			p.extendExtent(&field.extent, left)
+			left = field
+			continue
+		}
+
+		if isCompositeLiteralType(left) {
+			array := &ArrayLiteralExpr{Type: left}
+			if array.matchSuffix(p) {
+				p.extendExtent(&array.extent, left)
+				left = array
+				continue
+			}
+
+			structLit := &StructLiteralExpr{Type: left}
+			if !p.inHeader && structLit.matchSuffix(p) {
+				p.extendExtent(&structLit.extent, left)
+				left = structLit
+				continue
+			}
+		}
+
+		if p.Consume(nil, tokenpredicate.Type(token.DotDotDot)) {
+			splat := &SplatExpr{Operand: left}
+			p.extendExtent(&splat.extent, left)
+			left = splat
+			continue
+		}
+
+		break
+	}
+
+	*ptr = left
+	return true
 }
 
 func matchExpr25(ptr *Node, p *Parser) bool {
-	return false
+	num := &LiteralNumber{}
+	if num.Match(p) {
+		*ptr = num
+		return true
+	}
+
+	str := &LiteralString{}
+	if str.Match(p) {
+		*ptr = str
+		return true
+	}
+
+	rx := &LiteralRegex{}
+	if rx.Match(p) {
+		*ptr = rx
+		return true
+	}
+
+	peg := &LiteralPEG{}
+	if peg.Match(p) {
+		*ptr = peg
+		return true
+	}
+
+	null := &LiteralNull{}
+	if null.Match(p) {
+		*ptr = null
+		return true
+	}
+
+	this := &ThisExpr{}
+	if this.Match(p) {
+		*ptr = this
+		return true
+	}
+
+	placeholder := &PlaceholderExpr{}
+	if placeholder.Match(p) {
+		*ptr = placeholder
+		return true
+	}
+
+	body := &TypeBodyExpr{}
+	if body.Match(p) {
+		*ptr = body
+		return true
+	}
+
+	ident := &IdentifierExpr{}
+	if ident.Match(p) {
+		*ptr = ident
+		return true
+	}
+
+	return matchParenthesized(ptr, p)
 }
 
 // UnaryOperatorExpr
 // {{{
 
 type UnaryOperatorExpr struct {
+	extent
+
 	WS       InternalWhitespaceRun
 	Operand  Node
 	Operator UnaryOperator
@@ -672,6 +880,8 @@ func (expr *UnaryOperatorExpr) Init() {
 }
 
 func (expr *UnaryOperatorExpr) Match(p *Parser) bool {
+	defer p.trackExtent(&expr.extent)()
+
 	return false
 }
 
@@ -724,6 +934,16 @@ func (expr *UnaryOperatorExpr) ComputeStringLengthEstimates() {
 	expr.Operand.ComputeStringLengthEstimates()
 }
 
+func (expr *UnaryOperatorExpr) Children() []Node {
+	list := make([]Node, 0, 1)
+	list = append(list, expr.Operand)
+	return list
+}
+
+func (expr *UnaryOperatorExpr) RewriteChildren(fn func(Node) Node) {
+	expr.Operand = fn(expr.Operand)
+}
+
 var _ Node = (*UnaryOperatorExpr)(nil)
 
 // }}}
@@ -732,6 +952,8 @@ var _ Node = (*UnaryOperatorExpr)(nil)
 // {{{
 
 type BinaryOperatorExpr struct {
+	extent
+
 	WS0      InternalWhitespaceRun
 	WS1      InternalWhitespaceRun
 	Operand0 Node
@@ -744,6 +966,8 @@ func (expr *BinaryOperatorExpr) Init() {
 }
 
 func (expr *BinaryOperatorExpr) Match(p *Parser) bool {
+	defer p.trackExtent(&expr.extent)()
+
 	return false
 }
 
@@ -802,6 +1026,18 @@ func (expr *BinaryOperatorExpr) ComputeStringLengthEstimates() {
 	expr.WS1.ComputeStringLengthEstimates()
 }
 
+func (expr *BinaryOperatorExpr) Children() []Node {
+	list := make([]Node, 0, 2)
+	list = append(list, expr.Operand0)
+	list = append(list, expr.Operand1)
+	return list
+}
+
+func (expr *BinaryOperatorExpr) RewriteChildren(fn func(Node) Node) {
+	expr.Operand0 = fn(expr.Operand0)
+	expr.Operand1 = fn(expr.Operand1)
+}
+
 var _ Node = (*BinaryOperatorExpr)(nil)
 
 // }}}
@@ -810,6 +1046,8 @@ var _ Node = (*BinaryOperatorExpr)(nil)
 // {{{
 
 type TernaryOperatorExpr struct {
+	extent
+
 	WS0      InternalWhitespaceRun
 	WS1      InternalWhitespaceRun
 	WS2      InternalWhitespaceRun
@@ -825,6 +1063,8 @@ func (expr *TernaryOperatorExpr) Init() {
 }
 
 func (expr *TernaryOperatorExpr) Match(p *Parser) bool {
+	defer p.trackExtent(&expr.extent)()
+
 	return false
 }
 
@@ -911,6 +1151,20 @@ func (expr *TernaryOperatorExpr) ComputeStringLengthEstimates() {
 	expr.WS3.ComputeStringLengthEstimates()
 }
 
+func (expr *TernaryOperatorExpr) Children() []Node {
+	list := make([]Node, 0, 3)
+	list = append(list, expr.Operand0)
+	list = append(list, expr.Operand1)
+	list = append(list, expr.Operand2)
+	return list
+}
+
+func (expr *TernaryOperatorExpr) RewriteChildren(fn func(Node) Node) {
+	expr.Operand0 = fn(expr.Operand0)
+	expr.Operand1 = fn(expr.Operand1)
+	expr.Operand2 = fn(expr.Operand2)
+}
+
 var _ Node = (*TernaryOperatorExpr)(nil)
 
 // }}}
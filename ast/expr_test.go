@@ -0,0 +1,104 @@
+package ast
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchExpr_Primary(t *testing.T) {
+	testMatchExprRows(t, []parseTestRow{
+		{"foo", `IdentifierExpr{nil,"foo"}`},
+		{"module::name::symbol", `IdentifierExpr{ModuleName{"module::name"},"symbol"}`},
+		{"this", `ThisExpr{}`},
+		{"_", `PlaceholderExpr{}`},
+		{"null", `LiteralNull{}`},
+		{"42", `&value.Number{+,_,42,<nil>,_,+,<nil>}`},
+		{`"abc"`, `&value.String{"abc"}`},
+		{"#rx/a+b/i", `&value.Regex{"#rx/a+b/i"}`},
+		{"#peg{a <- b}", `&value.PEG{"#peg{a <- b}"}`},
+		{"(foo)", `ParenExpr{IdentifierExpr{nil,"foo"},InternalWhitespaceRun{},InternalWhitespaceRun{}}`},
+		{"( foo )", `ParenExpr{IdentifierExpr{nil,"foo"},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}}}`},
+		{"()", `TupleExpr{[],[],[],InternalWhitespaceRun{}}`},
+		{"(a, b)", `TupleExpr{[IdentifierExpr{nil,"a"},IdentifierExpr{nil,"b"}],[InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}}],[InternalWhitespaceRun{},InternalWhitespaceRun{}],InternalWhitespaceRun{}}`},
+		{"(a,)", `TupleExpr{[IdentifierExpr{nil,"a"}],[InternalWhitespaceRun{}],[InternalWhitespaceRun{}],InternalWhitespaceRun{}}`},
+		{"(a, _)", `TupleExpr{[IdentifierExpr{nil,"a"},PlaceholderExpr{}],[InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}}],[InternalWhitespaceRun{},InternalWhitespaceRun{}],InternalWhitespaceRun{}}`},
+		{"((a))", `ParenExpr{ParenExpr{IdentifierExpr{nil,"a"},InternalWhitespaceRun{},InternalWhitespaceRun{}},InternalWhitespaceRun{},InternalWhitespaceRun{}}`},
+		{"a + b * 2", `BinaryOperatorExpr{OpAdd,IdentifierExpr{nil,"a"},BinaryOperatorExpr{OpMul,IdentifierExpr{nil,"b"},&value.Number{+,_,2,<nil>,_,+,<nil>},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}}},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}}}`},
+		{"(a + b) * 2", `BinaryOperatorExpr{OpMul,ParenExpr{BinaryOperatorExpr{OpAdd,IdentifierExpr{nil,"a"},IdentifierExpr{nil,"b"},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}}},InternalWhitespaceRun{},InternalWhitespaceRun{}},&value.Number{+,_,2,<nil>,_,+,<nil>},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}}}`},
+		{"a ?: null", `BinaryOperatorExpr{OpElvis,IdentifierExpr{nil,"a"},LiteralNull{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}}}`},
+		{"x > 1 ? 2 : 3", `TernaryOperatorExpr{OpQuestionColon,BinaryOperatorExpr{OpCmpGT,IdentifierExpr{nil,"x"},&value.Number{+,_,1,<nil>,_,+,<nil>},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}}},&value.Number{+,_,2,<nil>,_,+,<nil>},&value.Number{+,_,3,<nil>,_,+,<nil>},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}}}`},
+		{"a ? b : c ? d : e", `TernaryOperatorExpr{OpQuestionColon,IdentifierExpr{nil,"a"},IdentifierExpr{nil,"b"},TernaryOperatorExpr{OpQuestionColon,IdentifierExpr{nil,"c"},IdentifierExpr{nil,"d"},IdentifierExpr{nil,"e"},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}}},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}}}`},
+	})
+}
+
+func TestMatchExpr_Postfix(t *testing.T) {
+	testMatchExprRows(t, []parseTestRow{
+		{"f()", `CallExpr{IdentifierExpr{nil,"f"},ArgumentList{[],[],[],InternalWhitespaceRun{}}}`},
+		{"f(a, foo: b)", `CallExpr{IdentifierExpr{nil,"f"},ArgumentList{[IdentifierExpr{nil,"a"},NamedArgument{"foo",IdentifierExpr{nil,"b"},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}}}],[InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}}],[InternalWhitespaceRun{},InternalWhitespaceRun{}],InternalWhitespaceRun{}}}`},
+		{"f(a,)", `CallExpr{IdentifierExpr{nil,"f"},ArgumentList{[IdentifierExpr{nil,"a"}],[InternalWhitespaceRun{}],[InternalWhitespaceRun{}],InternalWhitespaceRun{}}}`},
+		{`x["abc"]`, `IndexExpr{IdentifierExpr{nil,"x"},&value.String{"abc"},InternalWhitespaceRun{},InternalWhitespaceRun{}}`},
+		{"x.y", `FieldExpr{IdentifierExpr{nil,"x"},"y",InternalWhitespaceRun{},InternalWhitespaceRun{}}`},
+		{"this.y", `FieldExpr{ThisExpr{},"y",InternalWhitespaceRun{},InternalWhitespaceRun{}}`},
+		{"xs...", `SplatExpr{IdentifierExpr{nil,"xs"}}`},
+		{"f(xs...)", `CallExpr{IdentifierExpr{nil,"f"},ArgumentList{[SplatExpr{IdentifierExpr{nil,"xs"}}],[InternalWhitespaceRun{}],[InternalWhitespaceRun{}],InternalWhitespaceRun{}}}`},
+		{"symbol#[Void]", `GenericInstantiationExpr{IdentifierExpr{nil,"symbol"},TypeSignature{[IdentifierExpr{nil,"Void"}],[InternalWhitespaceRun{}],[InternalWhitespaceRun{}],InternalWhitespaceRun{}}}`},
+		{"symbol#(Int, foo: Bool): Int", `GenericInstantiationExpr{IdentifierExpr{nil,"symbol"},FuncSignature{ArgumentList{[IdentifierExpr{nil,"Int"},NamedArgument{"foo",IdentifierExpr{nil,"Bool"},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}}}],[InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}}],[InternalWhitespaceRun{},InternalWhitespaceRun{}],InternalWhitespaceRun{}},IdentifierExpr{nil,"Int"},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}}}}`},
+		{"symbol#(Int)", `GenericInstantiationExpr{IdentifierExpr{nil,"symbol"},FuncSignature{ArgumentList{[IdentifierExpr{nil,"Int"}],[InternalWhitespaceRun{}],[InternalWhitespaceRun{}],InternalWhitespaceRun{}},nil,InternalWhitespaceRun{},InternalWhitespaceRun{}}}`},
+		{`symbol.field["abc"]("abc", foo: true).inner`, ""},
+		{"symbol.field#[Void].inner", ""},
+		{"(symbol.field#(Int, String, foo: Bool): Int).inner", ""},
+		{"this#(Int, String, foo: Bool): Int", ""},
+		{"a.b . c", ""},
+		{"f(\n  a,\n  b,\n)", ""},
+	})
+}
+
+func TestMatchExpr_Lambda(t *testing.T) {
+	rows := []parseTestRow{
+		{"x => x", `LambdaExpr{Parameter{"x",nil,InternalWhitespaceRun{},InternalWhitespaceRun{}},nil,IdentifierExpr{nil,"x"},InternalWhitespaceRun{},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}}}`},
+		{"() => {}", `LambdaExpr{ParameterList{[],[],[],InternalWhitespaceRun{}},nil,BlockStatement{[],InternalWhitespaceRun{}},InternalWhitespaceRun{},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}}}`},
+		{"(x: Int): Int => x", `LambdaExpr{ParameterList{[Parameter{"x",IdentifierExpr{nil,"Int"},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}}}],[InternalWhitespaceRun{}],[InternalWhitespaceRun{}],InternalWhitespaceRun{}},IdentifierExpr{nil,"Int"},IdentifierExpr{nil,"x"},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}}}`},
+		{"(x)", `ParenExpr{IdentifierExpr{nil,"x"},InternalWhitespaceRun{},InternalWhitespaceRun{}}`},
+		{"(x, y)", `TupleExpr{[IdentifierExpr{nil,"x"},IdentifierExpr{nil,"y"}],[InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}}],[InternalWhitespaceRun{},InternalWhitespaceRun{}],InternalWhitespaceRun{}}`},
+		{"(x, y) => x + y", ""},
+		{"(x: Int, y: Int): Int => x + y", ""},
+		{"(x: Int, y: Int): Int => { return x + y }", ""},
+		{"(x: Int, ys: Int...,) => f(x, ys...)", ""},
+		{"x => y => x + y", ""},
+	}
+
+	for index, expr := range testMatchExprRows(t, rows) {
+		input := rows[index].Input
+		if _, ok := expr.(*LambdaExpr); expr != nil && !ok && strings.Contains(input, "=>") {
+			t.Errorf("MatchExpr(%q): expected *LambdaExpr, actual %T", input, expr)
+		}
+	}
+
+	input := "f(x => x * x, cb: (a, b) => a)"
+	if expr := testParseExpr(t, input); expr != nil {
+		if call, ok := expr.(*CallExpr); !ok {
+			t.Errorf("MatchExpr(%q): expected *CallExpr, actual %T", input, expr)
+		} else if _, ok := call.Args.Args[0].(*LambdaExpr); !ok {
+			t.Errorf("MatchExpr(%q): expected *LambdaExpr argument, actual %T", input, call.Args.Args[0])
+		}
+		if actual := expr.String(); actual != input {
+			t.Errorf("MatchExpr(%q).String(): expected %q, actual %q", input, input, actual)
+		}
+	}
+}
+
+func TestMatchExpr_Composite(t *testing.T) {
+	testMatchExprRows(t, []parseTestRow{
+		{"Struct{}", `StructLiteralExpr{IdentifierExpr{nil,"Struct"},[],[],[],InternalWhitespaceRun{}}`},
+		{"Struct{field: 42}", `StructLiteralExpr{IdentifierExpr{nil,"Struct"},[KeyedElement{IdentifierExpr{nil,"field"},&value.Number{+,_,42,<nil>,_,+,<nil>},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}}}],[InternalWhitespaceRun{}],[InternalWhitespaceRun{}],InternalWhitespaceRun{}}`},
+		{"Pair{a, b,}", `StructLiteralExpr{IdentifierExpr{nil,"Pair"},[IdentifierExpr{nil,"a"},IdentifierExpr{nil,"b"}],[InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}}],[InternalWhitespaceRun{},InternalWhitespaceRun{}],InternalWhitespaceRun{}}`},
+		{"Int...[]", `ArrayLiteralExpr{IdentifierExpr{nil,"Int"},[],[],[],InternalWhitespaceRun{}}`},
+		{"Int...[a, b]", `ArrayLiteralExpr{IdentifierExpr{nil,"Int"},[IdentifierExpr{nil,"a"},IdentifierExpr{nil,"b"}],[InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}}],[InternalWhitespaceRun{},InternalWhitespaceRun{}],InternalWhitespaceRun{}}`},
+		{"Int...[1, 2, 3]", ""},
+		{"Int...[1, 2, 3,]", ""},
+		{"Int...[0: a, 5: b]", ""},
+		{"module::Point{x: 1, y: 2}.x", ""},
+		{"Box#[Int]{value: f(x)}", ""},
+		{"Struct{\n  a: 1, // first\n  /* second */ b: Int...[],\n}", ""},
+	})
+}
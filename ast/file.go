@@ -8,8 +8,20 @@ import (
 	"github.com/chronos-tachyon/go-spiderscript/tokenpredicate"
 )
 
+// File is the root of a parsed source file.  The tree keeps every token,
+// including whitespace and comments, so File.String reproduces the source
+// exactly.
 type File struct {
+	extent
+
 	Statements []Node
+
+	// reach[i] is the rune offset just past the furthest token that was
+	// looked at while matching Statements[i], and errors[i] lists the
+	// errors emitted while matching it.  Parser.Reparse uses them to
+	// decide which statements an edit can affect.
+	reach  []uint
+	errors [][]error
 }
 
 func (file *File) Init() {
@@ -19,27 +31,40 @@ func (file *File) Init() {
 }
 
 func (file *File) Match(p *Parser) bool {
+	defer p.trackExtent(&file.extent)()
+
 	for {
 		if p.Consume(nil, tokenpredicate.Type(token.EOF)) {
 			return true
 		}
 
-		var stmt Node
-		if MatchStatement(&stmt, p) {
-			file.Statements = append(file.Statements, stmt)
-			continue
+		if !file.matchStatement(p) {
+			return true
 		}
-
-		return true
 	}
 }
 
-func (file *File) Children() []Node {
-	list := make([]Node, len(file.Statements))
-	for index, stmt := range file.Statements {
-		list[index] = stmt
+func (file *File) matchStatement(p *Parser) bool {
+	p.reach = p.tokenIndex
+	numErrors := len(p.errors)
+
+	var stmt Node
+	if !MatchStatement(&stmt, p) {
+		return false
 	}
-	return list
+
+	var reach uint
+	if p.reach > 0 {
+		reach = p.tokens[p.reach-1].End.RuneOffset
+	}
+
+	errors := make([]error, len(p.errors)-numErrors)
+	copy(errors, p.errors[numErrors:])
+
+	file.Statements = append(file.Statements, stmt)
+	file.reach = append(file.reach, reach)
+	file.errors = append(file.errors, errors)
+	return true
 }
 
 func (file *File) String() string {
@@ -89,4 +114,14 @@ func (file *File) ComputeStringLengthEstimates() {
 	}
 }
 
+func (file *File) Children() []Node {
+	list := make([]Node, len(file.Statements))
+	copy(list, file.Statements)
+	return list
+}
+
+func (file *File) RewriteChildren(fn func(Node) Node) {
+	rewriteNodes(file.Statements, fn)
+}
+
 var _ Node = (*File)(nil)
@@ -0,0 +1,1110 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chronos-tachyon/go-spiderscript/internal/util"
+	"github.com/chronos-tachyon/go-spiderscript/token"
+	"github.com/chronos-tachyon/go-spiderscript/tokenpredicate"
+)
+
+// FuncModifier
+// {{{
+
+type FuncModifier uint8
+
+const (
+	InvalidFuncModifier FuncModifier = iota
+	ModStatic
+	ModAsync
+	ModGenerator
+	ModCoroutine
+	ModProperty
+)
+
+var funcModifierKeywords = []string{
+	"???",
+	"static",
+	"async",
+	"generator",
+	"coroutine",
+	"property",
+}
+
+var funcModifierNames = []string{
+	"InvalidFuncModifier",
+	"ModStatic",
+	"ModAsync",
+	"ModGenerator",
+	"ModCoroutine",
+	"ModProperty",
+}
+
+var funcModifierMap = map[token.Type]FuncModifier{
+	token.KeywordStatic:    ModStatic,
+	token.KeywordAsync:     ModAsync,
+	token.KeywordGenerator: ModGenerator,
+	token.KeywordCoroutine: ModCoroutine,
+	token.KeywordProperty:  ModProperty,
+}
+
+func (mod FuncModifier) String() string {
+	if uint(mod) >= uint(len(funcModifierKeywords)) {
+		mod = 0
+	}
+	return funcModifierKeywords[mod]
+}
+
+func (mod FuncModifier) GoString() string {
+	if uint(mod) >= uint(len(funcModifierNames)) {
+		return fmt.Sprintf("FuncModifier(%d)", uint(mod))
+	}
+	return funcModifierNames[mod]
+}
+
+var _ fmt.Stringer = FuncModifier(0)
+var _ fmt.GoStringer = FuncModifier(0)
+
+// }}}
+
+// GenericParameter
+// {{{
+
+type GenericParameter struct {
+	extent
+
+	Name       string
+	Constraint Node
+	WS0        InternalWhitespaceRun
+	WS1        InternalWhitespaceRun
+	IsType     bool
+}
+
+func (param *GenericParameter) Init() {
+	*param = GenericParameter{}
+}
+
+func (param *GenericParameter) Match(p *Parser) bool {
+	defer p.trackExtent(&param.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	if !matchDeclName(&param.Name, p) {
+		param.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	param.WS0.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.Colon)) {
+		param.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	param.WS1.Match(p)
+
+	if p.Consume(nil, tokenpredicate.Type(token.KeywordType)) {
+		param.IsType = true
+		return true
+	}
+
+	if !matchTypeExpr(&param.Constraint, p) {
+		param.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (param *GenericParameter) String() string {
+	return util.StringImpl(param)
+}
+
+func (param *GenericParameter) GoString() string {
+	return util.GoStringImpl(param)
+}
+
+func (param *GenericParameter) EstimateStringLength() uint {
+	sum := 1 + uint(len(param.Name))
+	if param.IsType {
+		sum += 4
+	} else {
+		sum += param.Constraint.EstimateStringLength()
+	}
+	sum += param.WS0.EstimateStringLength()
+	sum += param.WS1.EstimateStringLength()
+	return sum
+}
+
+func (param *GenericParameter) EstimateGoStringLength() uint {
+	sum := 30 + uint(len(param.Name))
+	if !param.IsType {
+		sum += param.Constraint.EstimateGoStringLength()
+	}
+	sum += param.WS0.EstimateGoStringLength()
+	sum += param.WS1.EstimateGoStringLength()
+	return sum
+}
+
+func (param *GenericParameter) WriteStringTo(out *strings.Builder) {
+	out.WriteString(param.Name)
+	param.WS0.WriteStringTo(out)
+	out.WriteByte(':')
+	param.WS1.WriteStringTo(out)
+	if param.IsType {
+		out.WriteString("type")
+	} else {
+		param.Constraint.WriteStringTo(out)
+	}
+}
+
+func (param *GenericParameter) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("GenericParameter{")
+	out.WriteString(strconv.Quote(param.Name))
+	out.WriteByte(',')
+	if param.Constraint == nil {
+		out.WriteString("nil")
+	} else {
+		param.Constraint.WriteGoStringTo(out)
+	}
+	out.WriteByte(',')
+	param.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	param.WS1.WriteGoStringTo(out)
+	out.WriteByte(',')
+	out.WriteString(strconv.FormatBool(param.IsType))
+	out.WriteByte('}')
+}
+
+func (param *GenericParameter) ComputeStringLengthEstimates() {
+	if param.Constraint != nil {
+		param.Constraint.ComputeStringLengthEstimates()
+	}
+	param.WS0.ComputeStringLengthEstimates()
+	param.WS1.ComputeStringLengthEstimates()
+}
+
+func (param *GenericParameter) Children() []Node {
+	list := make([]Node, 0, 1)
+	if param.Constraint != nil {
+		list = append(list, param.Constraint)
+	}
+	return list
+}
+
+func (param *GenericParameter) RewriteChildren(fn func(Node) Node) {
+	if param.Constraint != nil {
+		param.Constraint = fn(param.Constraint)
+	}
+}
+
+var _ Node = (*GenericParameter)(nil)
+
+// }}}
+
+// GenericParameterList
+// {{{
+
+type GenericParameterList struct {
+	extent
+
+	Params           []Node
+	WS0              []Node
+	WS1              []Node
+	WS2              InternalWhitespaceRun
+	HasTrailingComma bool
+}
+
+func (list *GenericParameterList) Init() {
+	*list = GenericParameterList{}
+}
+
+func (list *GenericParameterList) Match(p *Parser) bool {
+	defer p.trackExtent(&list.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.LBracket)) {
+		p.Rewind(mark)
+		return false
+	}
+
+	var ws InternalWhitespaceRun
+	ws.Match(p)
+
+	if p.Consume(nil, tokenpredicate.Type(token.RBracket)) {
+		list.WS2 = ws
+		return true
+	}
+
+	list.Params = make([]Node, 0, 4)
+	list.WS0 = make([]Node, 0, 4)
+	list.WS1 = make([]Node, 0, 4)
+
+	for {
+		ws0 := ws
+		ws.Init()
+
+		param := &GenericParameter{}
+		if !param.Match(p) {
+			break
+		}
+
+		var ws1 InternalWhitespaceRun
+		ws1.Match(p)
+
+		list.Params = append(list.Params, param)
+		list.WS0 = append(list.WS0, &ws0)
+		list.WS1 = append(list.WS1, &ws1)
+
+		if p.Consume(nil, tokenpredicate.Type(token.RBracket)) {
+			return true
+		}
+
+		if !p.Consume(nil, tokenpredicate.Type(token.Comma)) {
+			break
+		}
+
+		ws.Match(p)
+
+		if p.Consume(nil, tokenpredicate.Type(token.RBracket)) {
+			list.WS2 = ws
+			list.HasTrailingComma = true
+			return true
+		}
+	}
+
+	list.Init()
+	p.Rewind(mark)
+	return false
+}
+
+func (list *GenericParameterList) String() string {
+	return util.StringImpl(list)
+}
+
+func (list *GenericParameterList) GoString() string {
+	return util.GoStringImpl(list)
+}
+
+func (list *GenericParameterList) EstimateStringLength() uint {
+	sum := 2 + uint(len(list.Params))
+	sum += sumStringLengthEstimates(list.Params)
+	sum += sumStringLengthEstimates(list.WS0)
+	sum += sumStringLengthEstimates(list.WS1)
+	sum += list.WS2.EstimateStringLength()
+	if len(list.Params) != 0 {
+		sum--
+	}
+	if list.HasTrailingComma {
+		sum++
+	}
+	return sum
+}
+
+func (list *GenericParameterList) EstimateGoStringLength() uint {
+	sum := 34 + list.WS2.EstimateGoStringLength()
+	sum += sumGoStringLengthEstimates(list.Params)
+	sum += sumGoStringLengthEstimates(list.WS0)
+	sum += sumGoStringLengthEstimates(list.WS1)
+	return sum
+}
+
+func (list *GenericParameterList) WriteStringTo(out *strings.Builder) {
+	out.WriteByte('[')
+	for index := uint(0); index < uint(len(list.Params)); index++ {
+		if index != 0 {
+			out.WriteByte(',')
+		}
+		list.WS0[index].WriteStringTo(out)
+		list.Params[index].WriteStringTo(out)
+		list.WS1[index].WriteStringTo(out)
+	}
+	if list.HasTrailingComma {
+		out.WriteByte(',')
+	}
+	list.WS2.WriteStringTo(out)
+	out.WriteByte(']')
+}
+
+func (list *GenericParameterList) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("GenericParameterList{")
+	out.WriteByte('[')
+	writeGoStringsTo(out, list.Params)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, list.WS0)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, list.WS1)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	list.WS2.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (list *GenericParameterList) ComputeStringLengthEstimates() {
+	for index := uint(0); index < uint(len(list.Params)); index++ {
+		list.Params[index].ComputeStringLengthEstimates()
+		list.WS0[index].ComputeStringLengthEstimates()
+		list.WS1[index].ComputeStringLengthEstimates()
+	}
+	list.WS2.ComputeStringLengthEstimates()
+}
+
+func (list *GenericParameterList) Children() []Node {
+	nodes := make([]Node, len(list.Params))
+	copy(nodes, list.Params)
+	return nodes
+}
+
+func (list *GenericParameterList) RewriteChildren(fn func(Node) Node) {
+	rewriteNodes(list.Params, fn)
+}
+
+var _ Node = (*GenericParameterList)(nil)
+
+// }}}
+
+// FuncParameterList
+// {{{
+
+// FuncParameterList is a ParameterList in which a ';' separates the
+// positional parameters from the named parameters, e.g.
+// "(a: Int, rest: Int...; verbose: Bool)".  The first NumPositional entries
+// of Params are positional.  WS3 holds any whitespace before a leading ';'
+// when there are no positional parameters.
+type FuncParameterList struct {
+	extent
+
+	Params           []Node
+	WS0              []Node
+	WS1              []Node
+	WS2              InternalWhitespaceRun
+	WS3              InternalWhitespaceRun
+	NumPositional    uint
+	HasSemicolon     bool
+	HasTrailingComma bool
+}
+
+func (list *FuncParameterList) Init() {
+	*list = FuncParameterList{}
+}
+
+func (list *FuncParameterList) Match(p *Parser) bool {
+	defer p.trackExtent(&list.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.LParen)) {
+		p.Rewind(mark)
+		return false
+	}
+
+	var ws InternalWhitespaceRun
+	ws.Match(p)
+
+	if p.Consume(nil, tokenpredicate.Type(token.RParen)) {
+		list.WS2 = ws
+		return true
+	}
+
+	if p.Consume(nil, tokenpredicate.Type(token.Semicolon)) {
+		list.WS3 = ws
+		list.HasSemicolon = true
+		ws.Init()
+		ws.Match(p)
+	}
+
+	list.Params = make([]Node, 0, 4)
+	list.WS0 = make([]Node, 0, 4)
+	list.WS1 = make([]Node, 0, 4)
+
+	for {
+		ws0 := ws
+		ws.Init()
+
+		param := &Parameter{}
+		if !param.Match(p) {
+			break
+		}
+
+		var ws1 InternalWhitespaceRun
+		ws1.Match(p)
+
+		list.Params = append(list.Params, param)
+		list.WS0 = append(list.WS0, &ws0)
+		list.WS1 = append(list.WS1, &ws1)
+
+		if p.Consume(nil, tokenpredicate.Type(token.RParen)) {
+			if !list.HasSemicolon {
+				list.NumPositional = uint(len(list.Params))
+			}
+			return true
+		}
+
+		if !list.HasSemicolon && p.Consume(nil, tokenpredicate.Type(token.Semicolon)) {
+			list.NumPositional = uint(len(list.Params))
+			list.HasSemicolon = true
+			ws.Match(p)
+			continue
+		}
+
+		if !p.Consume(nil, tokenpredicate.Type(token.Comma)) {
+			break
+		}
+
+		ws.Match(p)
+
+		if p.Consume(nil, tokenpredicate.Type(token.RParen)) {
+			if !list.HasSemicolon {
+				list.NumPositional = uint(len(list.Params))
+			}
+			list.WS2 = ws
+			list.HasTrailingComma = true
+			return true
+		}
+	}
+
+	list.Init()
+	p.Rewind(mark)
+	return false
+}
+
+func (list *FuncParameterList) Positional() []*Parameter {
+	out := make([]*Parameter, 0, list.NumPositional)
+	for _, param := range list.Params[:list.NumPositional] {
+		out = append(out, param.(*Parameter))
+	}
+	return out
+}
+
+func (list *FuncParameterList) Named() []*Parameter {
+	out := make([]*Parameter, 0, uint(len(list.Params))-list.NumPositional)
+	for _, param := range list.Params[list.NumPositional:] {
+		out = append(out, param.(*Parameter))
+	}
+	return out
+}
+
+func (list *FuncParameterList) String() string {
+	return util.StringImpl(list)
+}
+
+func (list *FuncParameterList) GoString() string {
+	return util.GoStringImpl(list)
+}
+
+func (list *FuncParameterList) EstimateStringLength() uint {
+	sum := 2 + uint(len(list.Params))
+	sum += sumStringLengthEstimates(list.Params)
+	sum += sumStringLengthEstimates(list.WS0)
+	sum += sumStringLengthEstimates(list.WS1)
+	sum += list.WS2.EstimateStringLength()
+	sum += list.WS3.EstimateStringLength()
+	if len(list.Params) != 0 {
+		sum--
+	}
+	if list.HasSemicolon && list.NumPositional == 0 {
+		sum++
+	}
+	if list.HasTrailingComma {
+		sum++
+	}
+	return sum
+}
+
+func (list *FuncParameterList) EstimateGoStringLength() uint {
+	sum := 64 + list.WS2.EstimateGoStringLength()
+	sum += list.WS3.EstimateGoStringLength()
+	sum += sumGoStringLengthEstimates(list.Params)
+	sum += sumGoStringLengthEstimates(list.WS0)
+	sum += sumGoStringLengthEstimates(list.WS1)
+	return sum
+}
+
+func (list *FuncParameterList) WriteStringTo(out *strings.Builder) {
+	out.WriteByte('(')
+	if list.HasSemicolon && list.NumPositional == 0 {
+		list.WS3.WriteStringTo(out)
+		out.WriteByte(';')
+	}
+	for index := uint(0); index < uint(len(list.Params)); index++ {
+		if index != 0 {
+			if list.HasSemicolon && index == list.NumPositional {
+				out.WriteByte(';')
+			} else {
+				out.WriteByte(',')
+			}
+		}
+		list.WS0[index].WriteStringTo(out)
+		list.Params[index].WriteStringTo(out)
+		list.WS1[index].WriteStringTo(out)
+	}
+	if list.HasTrailingComma {
+		out.WriteByte(',')
+	}
+	list.WS2.WriteStringTo(out)
+	out.WriteByte(')')
+}
+
+func (list *FuncParameterList) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("FuncParameterList{")
+	out.WriteByte('[')
+	writeGoStringsTo(out, list.Params)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, list.WS0)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, list.WS1)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	list.WS2.WriteGoStringTo(out)
+	out.WriteByte(',')
+	list.WS3.WriteGoStringTo(out)
+	out.WriteByte(',')
+	out.WriteString(strconv.FormatUint(uint64(list.NumPositional), 10))
+	out.WriteByte(',')
+	out.WriteString(strconv.FormatBool(list.HasSemicolon))
+	out.WriteByte('}')
+}
+
+func (list *FuncParameterList) ComputeStringLengthEstimates() {
+	for index := uint(0); index < uint(len(list.Params)); index++ {
+		list.Params[index].ComputeStringLengthEstimates()
+		list.WS0[index].ComputeStringLengthEstimates()
+		list.WS1[index].ComputeStringLengthEstimates()
+	}
+	list.WS2.ComputeStringLengthEstimates()
+	list.WS3.ComputeStringLengthEstimates()
+}
+
+func (list *FuncParameterList) Children() []Node {
+	nodes := make([]Node, len(list.Params))
+	copy(nodes, list.Params)
+	return nodes
+}
+
+func (list *FuncParameterList) RewriteChildren(fn func(Node) Node) {
+	rewriteNodes(list.Params, fn)
+}
+
+var _ Node = (*FuncParameterList)(nil)
+
+// }}}
+
+// funcDecl
+// {{{
+
+// funcDecl holds everything that FuncDeclStatement and MethodDeclStatement
+// have in common, i.e. everything except the method's receiver.
+//
+// WS0 is leading whitespace, WS1 follows each modifier, WS2 follows the
+// "func"/"method" keyword, WS3 follows "operator", WS4 precedes the
+// parameter list, WS5/WS6 surround the return type's ':', WS7 precedes each
+// pragma, WS8 precedes the body, and WS9 terminates the statement.
+type funcDecl struct {
+	extent
+
+	Modifiers  []FuncModifier
+	Name       string
+	Generics   *GenericParameterList
+	Params     FuncParameterList
+	Return     Node
+	Body       *BlockStatement
+	Pragmas    []Node
+	WS1        []Node
+	WS7        []Node
+	WS0        InternalWhitespaceRun
+	WS2        InternalWhitespaceRun
+	WS3        InternalWhitespaceRun
+	WS4        InternalWhitespaceRun
+	WS5        InternalWhitespaceRun
+	WS6        InternalWhitespaceRun
+	WS8        InternalWhitespaceRun
+	WS9        TerminalWhitespaceRun
+	IsOperator bool
+}
+
+func (decl *funcDecl) matchHead(p *Parser, keyword token.Type) bool {
+	decl.WS0.Match(p)
+
+	for {
+		var tok token.Token
+		if !p.Peek(&tok, tokenpredicate.Any()) {
+			return false
+		}
+
+		mod, found := funcModifierMap[tok.Type]
+		if !found {
+			break
+		}
+		p.Consume(nil, tokenpredicate.Any())
+
+		var ws InternalWhitespaceRun
+		if !ws.Match(p) {
+			return false
+		}
+
+		decl.Modifiers = append(decl.Modifiers, mod)
+		decl.WS1 = append(decl.WS1, &ws)
+	}
+
+	if !p.Consume(nil, tokenpredicate.Type(keyword)) {
+		return false
+	}
+
+	return decl.WS2.Match(p)
+}
+
+func (decl *funcDecl) matchName(p *Parser) bool {
+	if p.Consume(nil, tokenpredicate.Type(token.KeywordOperator)) {
+		decl.WS3.Match(p)
+
+		var tok token.Token
+		if !p.Consume(&tok, tokenpredicate.Func(isOverloadableOperatorToken)) {
+			return false
+		}
+
+		decl.Name = string(tok.Raw)
+		decl.IsOperator = true
+		return true
+	}
+
+	return matchDeclName(&decl.Name, p)
+}
+
+func (decl *funcDecl) matchTail(p *Parser) bool {
+	var generics GenericParameterList
+	if generics.Match(p) {
+		decl.Generics = &generics
+	}
+
+	decl.WS4.Match(p)
+
+	if !decl.Params.Match(p) {
+		return false
+	}
+
+	mark := p.Mark()
+	decl.WS5.Match(p)
+	if p.Consume(nil, tokenpredicate.Type(token.Colon)) {
+		decl.WS6.Match(p)
+		if !matchTypeExpr(&decl.Return, p) {
+			p.Forget(mark)
+			return false
+		}
+	} else {
+		decl.WS5.Init()
+		p.Rewind(mark)
+	}
+	p.Forget(mark)
+
+	for {
+		mark = p.Mark()
+
+		var ws InternalWhitespaceRun
+		ws.Match(p)
+
+		var pragma Node
+		if !MatchPragma(&pragma, p) {
+			p.Rewind(mark)
+			p.Forget(mark)
+			break
+		}
+		p.Forget(mark)
+
+		decl.Pragmas = append(decl.Pragmas, pragma)
+		decl.WS7 = append(decl.WS7, &ws)
+	}
+
+	mark = p.Mark()
+	decl.WS8.Match(p)
+	body := &BlockStatement{}
+	if body.Match(p) {
+		decl.Body = body
+	} else {
+		decl.WS8.Init()
+		p.Rewind(mark)
+	}
+	p.Forget(mark)
+
+	return decl.WS9.Match(p)
+}
+
+func (decl *funcDecl) estimateStringLength() uint {
+	sum := uint(len(decl.Name)) + uint(len(decl.Modifiers))*9
+	if decl.IsOperator {
+		sum += 8
+	}
+	if decl.Generics != nil {
+		sum += decl.Generics.EstimateStringLength()
+	}
+	sum += decl.Params.EstimateStringLength()
+	if decl.Return != nil {
+		sum += 1 + decl.Return.EstimateStringLength()
+	}
+	if decl.Body != nil {
+		sum += decl.Body.EstimateStringLength()
+	}
+	sum += sumStringLengthEstimates(decl.Pragmas)
+	sum += sumStringLengthEstimates(decl.WS1)
+	sum += sumStringLengthEstimates(decl.WS7)
+	sum += decl.WS0.EstimateStringLength()
+	sum += decl.WS2.EstimateStringLength()
+	sum += decl.WS3.EstimateStringLength()
+	sum += decl.WS4.EstimateStringLength()
+	sum += decl.WS5.EstimateStringLength()
+	sum += decl.WS6.EstimateStringLength()
+	sum += decl.WS8.EstimateStringLength()
+	sum += decl.WS9.EstimateStringLength()
+	return sum
+}
+
+func (decl *funcDecl) estimateGoStringLength() uint {
+	sum := 96 + uint(len(decl.Name)) + uint(len(decl.Modifiers))*13
+	if decl.Generics != nil {
+		sum += decl.Generics.EstimateGoStringLength()
+	}
+	sum += decl.Params.EstimateGoStringLength()
+	if decl.Return != nil {
+		sum += decl.Return.EstimateGoStringLength()
+	}
+	if decl.Body != nil {
+		sum += decl.Body.EstimateGoStringLength()
+	}
+	sum += sumGoStringLengthEstimates(decl.Pragmas)
+	sum += sumGoStringLengthEstimates(decl.WS1)
+	sum += sumGoStringLengthEstimates(decl.WS7)
+	sum += decl.WS0.EstimateGoStringLength()
+	sum += decl.WS2.EstimateGoStringLength()
+	sum += decl.WS3.EstimateGoStringLength()
+	sum += decl.WS4.EstimateGoStringLength()
+	sum += decl.WS5.EstimateGoStringLength()
+	sum += decl.WS6.EstimateGoStringLength()
+	sum += decl.WS8.EstimateGoStringLength()
+	sum += decl.WS9.EstimateGoStringLength()
+	return sum
+}
+
+func (decl *funcDecl) writeHeadTo(out *strings.Builder, keyword string) {
+	decl.WS0.WriteStringTo(out)
+	for index, mod := range decl.Modifiers {
+		out.WriteString(mod.String())
+		decl.WS1[index].WriteStringTo(out)
+	}
+	out.WriteString(keyword)
+	decl.WS2.WriteStringTo(out)
+}
+
+func (decl *funcDecl) writeTailTo(out *strings.Builder) {
+	if decl.IsOperator {
+		out.WriteString("operator")
+		decl.WS3.WriteStringTo(out)
+	}
+	out.WriteString(decl.Name)
+	if decl.Generics != nil {
+		decl.Generics.WriteStringTo(out)
+	}
+	decl.WS4.WriteStringTo(out)
+	decl.Params.WriteStringTo(out)
+	if decl.Return != nil {
+		decl.WS5.WriteStringTo(out)
+		out.WriteByte(':')
+		decl.WS6.WriteStringTo(out)
+		decl.Return.WriteStringTo(out)
+	}
+	writePragmasTo(out, decl.Pragmas, decl.WS7)
+	if decl.Body != nil {
+		decl.WS8.WriteStringTo(out)
+		decl.Body.WriteStringTo(out)
+	}
+	decl.WS9.WriteStringTo(out)
+}
+
+func (decl *funcDecl) writeGoStringTo(out *strings.Builder) {
+	out.WriteByte('[')
+	for index, mod := range decl.Modifiers {
+		if index != 0 {
+			out.WriteByte(',')
+		}
+		out.WriteString(mod.GoString())
+	}
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteString(strconv.Quote(decl.Name))
+	out.WriteByte(',')
+	if decl.Generics == nil {
+		out.WriteString("nil")
+	} else {
+		decl.Generics.WriteGoStringTo(out)
+	}
+	out.WriteByte(',')
+	decl.Params.WriteGoStringTo(out)
+	out.WriteByte(',')
+	if decl.Return == nil {
+		out.WriteString("nil")
+	} else {
+		decl.Return.WriteGoStringTo(out)
+	}
+	out.WriteByte(',')
+	if decl.Body == nil {
+		out.WriteString("nil")
+	} else {
+		decl.Body.WriteGoStringTo(out)
+	}
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, decl.Pragmas)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteString(strconv.FormatBool(decl.IsOperator))
+}
+
+func (decl *funcDecl) children() []Node {
+	list := make([]Node, 0, 3+len(decl.Pragmas))
+	if decl.Generics != nil {
+		list = append(list, decl.Generics)
+	}
+	list = append(list, &decl.Params)
+	if decl.Return != nil {
+		list = append(list, decl.Return)
+	}
+	list = append(list, decl.Pragmas...)
+	if decl.Body != nil {
+		list = append(list, decl.Body)
+	}
+	return list
+}
+
+func (decl *funcDecl) rewriteChildren(fn func(Node) Node) {
+	if decl.Generics != nil {
+		decl.Generics = rewriteSameType(decl.Generics, fn).(*GenericParameterList)
+	}
+	decl.Params = *rewriteSameType(&decl.Params, fn).(*FuncParameterList)
+	if decl.Return != nil {
+		decl.Return = fn(decl.Return)
+	}
+	rewriteNodes(decl.Pragmas, fn)
+	if decl.Body != nil {
+		decl.Body = rewriteSameType(decl.Body, fn).(*BlockStatement)
+	}
+}
+
+func (decl *funcDecl) computeStringLengthEstimates() {
+	if decl.Generics != nil {
+		decl.Generics.ComputeStringLengthEstimates()
+	}
+	decl.Params.ComputeStringLengthEstimates()
+	if decl.Return != nil {
+		decl.Return.ComputeStringLengthEstimates()
+	}
+	if decl.Body != nil {
+		decl.Body.ComputeStringLengthEstimates()
+	}
+	for index := uint(0); index < uint(len(decl.Pragmas)); index++ {
+		decl.Pragmas[index].ComputeStringLengthEstimates()
+		decl.WS7[index].ComputeStringLengthEstimates()
+	}
+	for _, ws := range decl.WS1 {
+		ws.ComputeStringLengthEstimates()
+	}
+	decl.WS0.ComputeStringLengthEstimates()
+	decl.WS2.ComputeStringLengthEstimates()
+	decl.WS3.ComputeStringLengthEstimates()
+	decl.WS4.ComputeStringLengthEstimates()
+	decl.WS5.ComputeStringLengthEstimates()
+	decl.WS6.ComputeStringLengthEstimates()
+	decl.WS8.ComputeStringLengthEstimates()
+	decl.WS9.ComputeStringLengthEstimates()
+}
+
+func isOverloadableOperatorToken(tok token.Token) bool {
+	switch tok.Type {
+	case token.Dot, token.ColonColon, token.QuestionColon:
+		return false
+	}
+	if _, found := binaryOperatorMap[tok.Type]; found {
+		return true
+	}
+	if _, found := unaryOperatorMap[tok.Type]; found {
+		return true
+	}
+	return false
+}
+
+// }}}
+
+// FuncDeclStatement
+// {{{
+
+type FuncDeclStatement struct {
+	funcDecl
+}
+
+func (stmt *FuncDeclStatement) Init() {
+	*stmt = FuncDeclStatement{}
+}
+
+func (stmt *FuncDeclStatement) Match(p *Parser) bool {
+	defer p.trackExtent(&stmt.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	if !stmt.matchHead(p, token.KeywordFunc) || !stmt.matchName(p) || !stmt.matchTail(p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (stmt *FuncDeclStatement) String() string {
+	return util.StringImpl(stmt)
+}
+
+func (stmt *FuncDeclStatement) GoString() string {
+	return util.GoStringImpl(stmt)
+}
+
+func (stmt *FuncDeclStatement) EstimateStringLength() uint {
+	return 4 + stmt.estimateStringLength()
+}
+
+func (stmt *FuncDeclStatement) EstimateGoStringLength() uint {
+	return 19 + stmt.estimateGoStringLength()
+}
+
+func (stmt *FuncDeclStatement) WriteStringTo(out *strings.Builder) {
+	stmt.writeHeadTo(out, "func")
+	stmt.writeTailTo(out)
+}
+
+func (stmt *FuncDeclStatement) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("FuncDeclStatement{")
+	stmt.writeGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (stmt *FuncDeclStatement) ComputeStringLengthEstimates() {
+	stmt.computeStringLengthEstimates()
+}
+
+func (stmt *FuncDeclStatement) Children() []Node {
+	return stmt.funcDecl.children()
+}
+
+func (stmt *FuncDeclStatement) RewriteChildren(fn func(Node) Node) {
+	stmt.funcDecl.rewriteChildren(fn)
+}
+
+var _ Node = (*FuncDeclStatement)(nil)
+
+// }}}
+
+// MethodDeclStatement
+// {{{
+
+type MethodDeclStatement struct {
+	funcDecl
+	Receiver string
+}
+
+func (stmt *MethodDeclStatement) Init() {
+	*stmt = MethodDeclStatement{}
+}
+
+func (stmt *MethodDeclStatement) Match(p *Parser) bool {
+	defer p.trackExtent(&stmt.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	if !stmt.matchHead(p, token.KeywordMethod) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !matchDeclName(&stmt.Receiver, p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !p.Consume(nil, tokenpredicate.Type(token.Dot)) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !stmt.matchName(p) || !stmt.matchTail(p) {
+		stmt.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (stmt *MethodDeclStatement) String() string {
+	return util.StringImpl(stmt)
+}
+
+func (stmt *MethodDeclStatement) GoString() string {
+	return util.GoStringImpl(stmt)
+}
+
+func (stmt *MethodDeclStatement) EstimateStringLength() uint {
+	return 7 + uint(len(stmt.Receiver)) + stmt.estimateStringLength()
+}
+
+func (stmt *MethodDeclStatement) EstimateGoStringLength() uint {
+	return 24 + uint(len(stmt.Receiver)) + stmt.estimateGoStringLength()
+}
+
+func (stmt *MethodDeclStatement) WriteStringTo(out *strings.Builder) {
+	stmt.writeHeadTo(out, "method")
+	out.WriteString(stmt.Receiver)
+	out.WriteByte('.')
+	stmt.writeTailTo(out)
+}
+
+func (stmt *MethodDeclStatement) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("MethodDeclStatement{")
+	out.WriteString(strconv.Quote(stmt.Receiver))
+	out.WriteByte(',')
+	stmt.writeGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (stmt *MethodDeclStatement) ComputeStringLengthEstimates() {
+	stmt.computeStringLengthEstimates()
+}
+
+func (stmt *MethodDeclStatement) Children() []Node {
+	return stmt.funcDecl.children()
+}
+
+func (stmt *MethodDeclStatement) RewriteChildren(fn func(Node) Node) {
+	stmt.funcDecl.rewriteChildren(fn)
+}
+
+var _ Node = (*MethodDeclStatement)(nil)
+
+// }}}
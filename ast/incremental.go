@@ -0,0 +1,324 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/chronos-tachyon/go-spiderscript/token"
+	"github.com/chronos-tachyon/go-spiderscript/tokenpredicate"
+)
+
+// Edit describes a change to source text: the runes from Start up to, but
+// not including, End are replaced with Text.  Offsets count runes in the
+// text as it was before the edit.
+type Edit struct {
+	Start uint
+	End   uint
+	Text  []rune
+}
+
+// Tokens returns the token stream that p parses.
+func (p *Parser) Tokens() []token.Token {
+	return p.tokens
+}
+
+// Reparse brings file up to date after an edit to the source text.  file
+// must be the unmodified result of the last Parse or Reparse on p, and input
+// must be the full text after the edit.
+//
+// Only the tokens around the edit are lexed again; the rest of the token
+// stream is kept, with positions after the edit moved to match the new
+// text.  Likewise, top-level statements whose tokens (including any tokens
+// the parser looked ahead at) lie entirely before or entirely after the
+// damaged tokens are reused as they are, and only the statements in between
+// are parsed again.  The result is the same as parsing input from scratch.
+func (p *Parser) Reparse(file *File, input []rune, edit Edit) bool {
+	old := p.tokens
+	if len(old) == 0 || len(file.reach) != len(file.Statements) {
+		path := ""
+		if len(old) != 0 {
+			path = old[0].Start.Path
+		}
+		var lexer token.Lexer
+		lexer.Init(path, input)
+		p.Init(&lexer)
+		return p.Parse(file)
+	}
+
+	oldLength := old[len(old)-1].End.RuneOffset
+	if edit.Start > edit.End || edit.End > oldLength {
+		panic(fmt.Errorf("edit [%d,%d) is out of range for text of length %d", edit.Start, edit.End, oldLength))
+	}
+	if expected := oldLength - (edit.End - edit.Start) + uint(len(edit.Text)); uint(len(input)) != expected {
+		panic(fmt.Errorf("edit [%d,%d) with %d runes of text should leave %d runes, got %d", edit.Start, edit.End, len(edit.Text), expected, len(input)))
+	}
+
+	tokens, diags, first, oldTail, newTail, s := relex(old, input, edit)
+	damage := old[first].Start.RuneOffset
+
+	// Diagnostics from the tokens that were not lexed again are kept.
+	diagnostics := make([]token.Diagnostic, 0, len(p.diagnostics)+len(diags))
+	for _, diag := range p.diagnostics {
+		if diag.Start.RuneOffset < damage {
+			diagnostics = append(diagnostics, diag)
+		}
+	}
+	diagnostics = append(diagnostics, diags...)
+	if oldTail < uint(len(old)) {
+		for _, diag := range p.diagnostics {
+			if diag.Start.RuneOffset >= old[oldTail].Start.RuneOffset {
+				diag.Start = s.position(diag.Start)
+				diag.End = s.position(diag.End)
+				diagnostics = append(diagnostics, diag)
+			}
+		}
+	}
+
+	statements := file.Statements
+	reach := file.reach
+	errors := file.errors
+	n := len(statements)
+
+	keep := 0
+	for keep < n && reach[keep] <= damage {
+		keep++
+	}
+
+	// tail maps the index of a new token to the old statement that starts
+	// there, for statements in the part of the text that the edit left
+	// alone.
+	tail := make(map[uint]int)
+	if oldTail < uint(len(old)) {
+		for i := keep; i < n; i++ {
+			offset := statements[i].FullSpan().Start.RuneOffset
+			if offset < old[oldTail].Start.RuneOffset {
+				continue
+			}
+			index := tokenIndexAt(old, offset)
+			tail[index-oldTail+newTail] = i
+		}
+	}
+
+	resume := uint(0)
+	switch {
+	case keep < n:
+		resume = statements[keep].FullSpan().Start.RuneOffset
+	case n > 0:
+		resume = statements[n-1].FullSpan().End.RuneOffset
+	}
+
+	file.Statements = make([]Node, keep, n+8)
+	file.reach = make([]uint, keep, n+8)
+	file.errors = make([][]error, keep, n+8)
+	copy(file.Statements, statements[:keep])
+	copy(file.reach, reach[:keep])
+	copy(file.errors, errors[:keep])
+
+	p.tokens = tokens
+	p.tokenIndex = tokenIndexAt(tokens, resume)
+	p.failIndex = 0
+	p.reach = 0
+	p.expected = p.expected[:0]
+	p.errors = p.errors[:0]
+	p.setDiagnostics(diagnostics)
+	for _, list := range file.errors {
+		p.errors = append(p.errors, list...)
+	}
+
+	for {
+		if i, ok := tail[p.tokenIndex]; ok {
+			for ; i < n; i++ {
+				seen := make(map[uintptr]bool)
+				shiftValue(reflect.ValueOf(statements[i]), s, seen)
+				for _, err := range errors[i] {
+					shiftValue(reflect.ValueOf(err), s, seen)
+				}
+				file.Statements = append(file.Statements, statements[i])
+				file.reach = append(file.reach, uint(int(reach[i])+s.runes))
+				file.errors = append(file.errors, errors[i])
+				p.errors = append(p.errors, errors[i]...)
+			}
+			end := statements[n-1].FullSpan().End.RuneOffset
+			p.tokenIndex = tokenIndexAt(tokens, end)
+		}
+
+		if p.Consume(nil, tokenpredicate.Type(token.EOF)) {
+			break
+		}
+		if !file.matchStatement(p) {
+			break
+		}
+	}
+
+	p.setExtent(&file.extent, 0)
+	return true
+}
+
+// relex lexes input again around edit.  It returns the new token stream,
+// the diagnostics for the tokens that were lexed again, the index of the
+// first token that was lexed again (the same in old and new), and the
+// indices in old and new of the first token of the unchanged tail, together
+// with the shift that maps old tail positions to new ones.  If the new
+// tokens never line up with the old ones again, the tail is empty.
+func relex(old []token.Token, input []rune, edit Edit) ([]token.Token, []token.Diagnostic, uint, uint, uint, shift) {
+	// The lexer looks one rune past the end of each token, so a token
+	// that ends where the edit starts must be lexed again too.
+	first := uint(sort.Search(len(old), func(i int) bool {
+		return old[i].End.RuneOffset >= edit.Start
+	}))
+
+	runes := len(edit.Text) - int(edit.End-edit.Start)
+
+	tokens := make([]token.Token, first, len(old)+16)
+	copy(tokens, old[:first])
+
+	var lexer token.Lexer
+	lexer.InitAt(input, old[first].Start)
+
+	j := first
+	for lexer.HasNext() {
+		tok := lexer.Token()
+
+		// Once a new token starts in the same lexer state as an old token
+		// after the edit, the rest of the old tokens follow unchanged.
+		for j < uint(len(old)) && (old[j].Start.RuneOffset < edit.End || int(old[j].Start.RuneOffset)+runes < int(tok.Start.RuneOffset)) {
+			j++
+		}
+		if j < uint(len(old)) && sameLexerState(old[j].Start, tok.Start, runes) {
+			s := shift{
+				runes: runes,
+				lines: int(tok.Start.RawLineNumber) - int(old[j].Start.RawLineNumber),
+			}
+			newTail := uint(len(tokens))
+			for _, t := range old[j:] {
+				t.Start = s.position(t.Start)
+				t.End = s.position(t.End)
+				t.Raw = input[t.Start.RuneOffset:t.End.RuneOffset]
+				tokens = append(tokens, t)
+			}
+			return tokens, lexedBefore(lexer.Diagnostics(), tok.Start.RuneOffset), first, j, newTail, s
+		}
+
+		tokens = append(tokens, tok)
+	}
+	return tokens, lexer.Diagnostics(), first, uint(len(old)), uint(len(tokens)), shift{}
+}
+
+// lexedBefore returns the diagnostics that start before offset.
+func lexedBefore(list []token.Diagnostic, offset uint) []token.Diagnostic {
+	n := 0
+	for n < len(list) && list[n].Start.RuneOffset < offset {
+		n++
+	}
+	return list[:n]
+}
+
+// sameLexerState reports whether the lexer, starting a token at b in the new
+// text, is in the same state as it was starting a token at a in the old
+// text, given that the new text is runes longer.
+func sameLexerState(a token.Position, b token.Position, runes int) bool {
+	if a.RuneOffset == 0 || b.RuneOffset == 0 {
+		return false
+	}
+	return int(a.RuneOffset)+runes == int(b.RuneOffset) &&
+		a.RawColumnNumber == b.RawColumnNumber &&
+		a.ConsumeNextLF == b.ConsumeNextLF
+}
+
+// tokenIndexAt returns the index of the first token in tokens that starts at
+// or after offset.
+func tokenIndexAt(tokens []token.Token, offset uint) uint {
+	return uint(sort.Search(len(tokens), func(i int) bool {
+		return tokens[i].Start.RuneOffset >= offset
+	}))
+}
+
+// shift moves positions after an edit to where they are in the new text.
+// Columns do not change, because reused tokens start at the same column.
+type shift struct {
+	runes int
+	lines int
+}
+
+func (s shift) position(pos token.Position) token.Position {
+	pos.RuneOffset = uint(int(pos.RuneOffset) + s.runes)
+	pos.RawLineNumber = uint(int(pos.RawLineNumber) + s.lines)
+	return pos
+}
+
+// shiftExtent moves ext by s.  Nodes that were never matched, such as an
+// absent optional whitespace run, keep their zero extent.
+func (ext *extent) shiftExtent(s shift) {
+	if ext.fullSpan == (Span{}) {
+		return
+	}
+	ext.span = Span{s.position(ext.span.Start), s.position(ext.span.End)}
+	ext.fullSpan = Span{s.position(ext.fullSpan.Start), s.position(ext.fullSpan.End)}
+}
+
+type extentShifter interface {
+	shiftExtent(s shift)
+}
+
+var positionType = reflect.TypeOf(token.Position{})
+
+// shiftValue applies s to every position reachable from v.  It walks struct
+// fields rather than Children, because whitespace runs record spans too but
+// are not children.  seen keeps shared pointers, such as an ErrorStatement's
+// Err that was also emitted as an error, from being moved twice.
+func shiftValue(v reflect.Value, s shift, seen map[uintptr]bool) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if !v.IsNil() {
+			shiftValue(v.Elem(), s, seen)
+		}
+
+	case reflect.Ptr:
+		if v.IsNil() || seen[v.Pointer()] {
+			return
+		}
+		seen[v.Pointer()] = true
+		if v.CanInterface() {
+			if x, ok := v.Interface().(extentShifter); ok {
+				x.shiftExtent(s)
+			}
+		}
+		shiftValue(v.Elem(), s, seen)
+
+	case reflect.Slice:
+		switch v.Type().Elem().Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Struct, reflect.Slice:
+			for i := 0; i < v.Len(); i++ {
+				shiftValue(v.Index(i), s, seen)
+			}
+		}
+
+	case reflect.Struct:
+		if v.Type() == positionType {
+			if v.CanSet() {
+				v.Set(reflect.ValueOf(s.position(v.Interface().(token.Position))))
+			}
+			return
+		}
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" && !field.Anonymous {
+				continue
+			}
+			fv := v.Field(i)
+			if fv.Kind() == reflect.Struct && fv.CanAddr() && fv.Addr().CanInterface() {
+				if x, ok := fv.Addr().Interface().(extentShifter); ok && field.Type != extentType {
+					x.shiftExtent(s)
+				}
+			}
+			if field.Type == extentType {
+				continue
+			}
+			shiftValue(fv, s, seen)
+		}
+	}
+}
+
+var extentType = reflect.TypeOf(extent{})
@@ -0,0 +1,223 @@
+package ast
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/chronos-tachyon/go-spiderscript/token"
+)
+
+func applyEdit(input []rune, edit Edit) []rune {
+	output := make([]rune, 0, len(input)+len(edit.Text))
+	output = append(output, input[:edit.Start]...)
+	output = append(output, edit.Text...)
+	output = append(output, input[edit.End:]...)
+	return output
+}
+
+// nodeSpans lists the spans of every node reachable from node, including the
+// whitespace runs that Children leaves out.
+func nodeSpans(node Node) []string {
+	var list []string
+	var visit func(v reflect.Value)
+	visit = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Interface, reflect.Ptr:
+			if !v.IsNil() {
+				visit(v.Elem())
+			}
+
+		case reflect.Slice:
+			for i := 0; i < v.Len(); i++ {
+				visit(v.Index(i))
+			}
+
+		case reflect.Struct:
+			if v.CanAddr() && v.Addr().CanInterface() {
+				if n, ok := v.Addr().Interface().(Node); ok {
+					list = append(list, fmt.Sprintf("%T %v %v", n, n.Span(), n.FullSpan()))
+				}
+			}
+			t := v.Type()
+			for i := 0; i < v.NumField(); i++ {
+				if field := t.Field(i); field.PkgPath == "" || (field.Anonymous && field.Type != extentType) {
+					visit(v.Field(i))
+				}
+			}
+		}
+	}
+	visit(reflect.ValueOf(node))
+	return list
+}
+
+func checkReparse(t *testing.T, name string, p *Parser, file *File, input []rune) {
+	t.Helper()
+
+	expectedParser := NewParser(token.NewLexer("test.spider", input))
+	var expected File
+	expectedParser.Parse(&expected)
+
+	if actual, expected := file.String(), expected.String(); actual != expected {
+		t.Errorf("%s: String(): expected %q, actual %q", name, expected, actual)
+		return
+	}
+	if actual, expected := file.GoString(), expected.GoString(); actual != expected {
+		t.Errorf("%s: GoString():\n\texpected: %s\n\t  actual: %s", name, expected, actual)
+		return
+	}
+
+	actualTokens, expectedTokens := p.Tokens(), expectedParser.Tokens()
+	if len(actualTokens) != len(expectedTokens) {
+		t.Errorf("%s: expected %d tokens, got %d", name, len(expectedTokens), len(actualTokens))
+		return
+	}
+	for index := range expectedTokens {
+		a, b := actualTokens[index], expectedTokens[index]
+		if a.Type != b.Type || string(a.Raw) != string(b.Raw) || a.Start != b.Start || a.End != b.End {
+			t.Errorf("%s: token %d: expected %v %v-%v, got %v %v-%v", name, index, b, b.Start, b.End, a, a.Start, a.End)
+			return
+		}
+	}
+
+	actualSpans, expectedSpans := nodeSpans(file), nodeSpans(&expected)
+	if !reflect.DeepEqual(actualSpans, expectedSpans) {
+		for index := range expectedSpans {
+			if index >= len(actualSpans) || actualSpans[index] != expectedSpans[index] {
+				t.Errorf("%s: span %d: expected %s, got %v", name, index, expectedSpans[index], actualSpans[index:])
+				return
+			}
+		}
+		t.Errorf("%s: spans differ", name)
+		return
+	}
+
+	actualErrors := fmt.Sprint(p.Errors())
+	expectedErrors := fmt.Sprint(expectedParser.Errors())
+	if actualErrors != expectedErrors {
+		t.Errorf("%s: errors:\n\texpected: %s\n\t  actual: %s", name, expectedErrors, actualErrors)
+	}
+}
+
+func TestReparse(t *testing.T) {
+	type testRow struct {
+		Name  string
+		Input string
+		Start uint
+		End   uint
+		Text  string
+	}
+
+	testData := []testRow{
+		{"insert-in-expr", "let x = 1\nlet y = 2\nlet z = 3\n", 18, 18, " + 4"},
+		{"replace-name", "let x = 1\nlet y = 2\nlet z = 3\n", 14, 15, "why"},
+		{"delete-line", "let x = 1\nlet y = 2\nlet z = 3\n", 10, 20, ""},
+		{"insert-line", "let x = 1\nlet z = 3\n", 10, 10, "let y = 2\n"},
+		{"join-lines", "let x = 1\nlet y = 2\n", 9, 10, " "},
+		{"extend-identifier", "f(ab)\ng()\n", 4, 4, "c"},
+		{"at-start", "let x = 1\nlet y = 2\n", 0, 0, "#!/bin/spider\n"},
+		{"at-end", "let x = 1\nlet y = 2", 19, 19, " + 3\n"},
+		{"open-comment", "let x = 1\nlet y = 2\nlet z = 3\n", 10, 10, "/*"},
+		{"close-comment", "let x = 1\n/*let y = 2\nlet z = 3\n", 10, 12, ""},
+		{"open-brace", "func f() {\n\treturn 1\n}\nlet x = 1\n", 9, 11, ""},
+		{"syntax-error", "let x = 1\nlet y = 2\nlet z = 3\n", 18, 18, "= ="},
+		{"fix-syntax-error", "let x = 1\nlet y = = 2\nlet z = 3\n", 16, 18, ""},
+		{"crlf", "let x = 1\r\nlet y = 2\r\n", 9, 9, "\r"},
+		{"func-then-block", "func f()\nlet x = 1\n", 9, 18, "{\n}"},
+		{"everything", "let x = 1\n", 0, 10, "import foo\n"},
+	}
+
+	for _, row := range testData {
+		t.Run(row.Name, func(t *testing.T) {
+			input := []rune(row.Input)
+			p := newTestParser(row.Input)
+			var file File
+			p.Parse(&file)
+
+			edit := Edit{Start: row.Start, End: row.End, Text: []rune(row.Text)}
+			input = applyEdit(input, edit)
+			p.Reparse(&file, input, edit)
+			checkReparse(t, row.Name, p, &file, input)
+		})
+	}
+}
+
+func TestReparse_ReusesStatements(t *testing.T) {
+	input := "func f() {\n\treturn 1\n}\n\nfunc g() {\n\treturn 2\n}\n\nfunc h() {\n\treturn 3\n}\n"
+	p := newTestParser(input)
+	var file File
+	p.Parse(&file)
+	before := make([]Node, len(file.Statements))
+	copy(before, file.Statements)
+
+	// Change "return 2" to "return 22".
+	runes := []rune(input)
+	edit := Edit{Start: 44, End: 44, Text: []rune("2")}
+	runes = applyEdit(runes, edit)
+	p.Reparse(&file, runes, edit)
+	checkReparse(t, "reuse", p, &file, runes)
+
+	if len(file.Statements) != len(before) {
+		t.Fatalf("expected %d statements, got %d", len(before), len(file.Statements))
+	}
+	if file.Statements[0] != before[0] {
+		t.Errorf("statement 0 was parsed again")
+	}
+	if file.Statements[1] == before[1] {
+		t.Errorf("statement 1 was reused")
+	}
+	if file.Statements[2] != before[2] {
+		t.Errorf("statement 2 was parsed again")
+	}
+}
+
+func TestReparse_Random(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.spider"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fragments := []string{
+		"", "x", "1", " ", "\t", "\n", "\r\n", "{", "}", "(", ")", ";",
+		"\"", "/*", "*/", "//", " + ", "=", "#inline", "import foo\n",
+		"func g() {\n\treturn 1\n}\n", "let y = [1, 2]\n",
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for _, path := range paths {
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		input := []rune(string(raw))
+		p := NewParser(token.NewLexer("test.spider", input))
+		var file File
+		p.Parse(&file)
+
+		for step := 0; step < 100; step++ {
+			start := uint(rng.Intn(len(input) + 1))
+			end := start + uint(rng.Intn(8))
+			if end > uint(len(input)) {
+				end = uint(len(input))
+			}
+			edit := Edit{
+				Start: start,
+				End:   end,
+				Text:  []rune(fragments[rng.Intn(len(fragments))]),
+			}
+
+			input = applyEdit(input, edit)
+			p.Reparse(&file, input, edit)
+
+			name := fmt.Sprintf("%s: step %d: edit [%d,%d) %q", path, step, edit.Start, edit.End, string(edit.Text))
+			checkReparse(t, name, p, &file, input)
+			if t.Failed() {
+				return
+			}
+		}
+	}
+}
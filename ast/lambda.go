@@ -0,0 +1,504 @@
+package ast
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/chronos-tachyon/go-spiderscript/internal/util"
+	"github.com/chronos-tachyon/go-spiderscript/token"
+	"github.com/chronos-tachyon/go-spiderscript/tokenpredicate"
+	"github.com/chronos-tachyon/go-spiderscript/value"
+)
+
+// Parameter
+// {{{
+
+type Parameter struct {
+	extent
+
+	Name string
+	Type Node
+	WS0  InternalWhitespaceRun
+	WS1  InternalWhitespaceRun
+}
+
+func (param *Parameter) Init() {
+	*param = Parameter{}
+}
+
+func (param *Parameter) Match(p *Parser) bool {
+	defer p.trackExtent(&param.extent)()
+
+	var tok token.Token
+	if !p.Consume(&tok, tokenpredicate.Type(token.Identifier)) {
+		return false
+	}
+
+	name, err := value.ExtractLiteral(tok.Parsed, "<error>")
+	if err != nil {
+		p.EmitError(err)
+	}
+	param.Name = name
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	param.WS0.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.Colon)) {
+		param.WS0.Init()
+		p.Rewind(mark)
+		return true
+	}
+
+	param.WS1.Match(p)
+
+	if !matchExpr24(&param.Type, p) {
+		param.WS0.Init()
+		param.WS1.Init()
+		p.Rewind(mark)
+		return true
+	}
+
+	return true
+}
+
+func (param *Parameter) IsRepeated() bool {
+	_, ok := param.Type.(*SplatExpr)
+	return ok
+}
+
+func (param *Parameter) String() string {
+	return util.StringImpl(param)
+}
+
+func (param *Parameter) GoString() string {
+	return util.GoStringImpl(param)
+}
+
+func (param *Parameter) EstimateStringLength() uint {
+	sum := uint(len(param.Name))
+	if param.Type != nil {
+		sum += 1 + param.Type.EstimateStringLength()
+		sum += param.WS0.EstimateStringLength()
+		sum += param.WS1.EstimateStringLength()
+	}
+	return sum
+}
+
+func (param *Parameter) EstimateGoStringLength() uint {
+	sum := 18 + uint(len(param.Name))
+	if param.Type != nil {
+		sum += param.Type.EstimateGoStringLength()
+	}
+	sum += param.WS0.EstimateGoStringLength()
+	sum += param.WS1.EstimateGoStringLength()
+	return sum
+}
+
+func (param *Parameter) WriteStringTo(out *strings.Builder) {
+	out.WriteString(param.Name)
+	if param.Type != nil {
+		param.WS0.WriteStringTo(out)
+		out.WriteByte(':')
+		param.WS1.WriteStringTo(out)
+		param.Type.WriteStringTo(out)
+	}
+}
+
+func (param *Parameter) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("Parameter{")
+	out.WriteString(strconv.Quote(param.Name))
+	out.WriteByte(',')
+	if param.Type == nil {
+		out.WriteString("nil")
+	} else {
+		param.Type.WriteGoStringTo(out)
+	}
+	out.WriteByte(',')
+	param.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	param.WS1.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (param *Parameter) ComputeStringLengthEstimates() {
+	if param.Type != nil {
+		param.Type.ComputeStringLengthEstimates()
+	}
+	param.WS0.ComputeStringLengthEstimates()
+	param.WS1.ComputeStringLengthEstimates()
+}
+
+func (param *Parameter) Children() []Node {
+	list := make([]Node, 0, 1)
+	if param.Type != nil {
+		list = append(list, param.Type)
+	}
+	return list
+}
+
+func (param *Parameter) RewriteChildren(fn func(Node) Node) {
+	if param.Type != nil {
+		param.Type = fn(param.Type)
+	}
+}
+
+var _ Node = (*Parameter)(nil)
+
+// }}}
+
+// ParameterList
+// {{{
+
+type ParameterList struct {
+	extent
+
+	Params           []Node
+	WS0              []Node
+	WS1              []Node
+	WS2              InternalWhitespaceRun
+	HasTrailingComma bool
+}
+
+func (list *ParameterList) Init() {
+	*list = ParameterList{}
+}
+
+func (list *ParameterList) Match(p *Parser) bool {
+	defer p.trackExtent(&list.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.LParen)) {
+		p.Rewind(mark)
+		return false
+	}
+
+	var ws InternalWhitespaceRun
+	ws.Match(p)
+
+	if p.Consume(nil, tokenpredicate.Type(token.RParen)) {
+		list.WS2 = ws
+		return true
+	}
+
+	list.Params = make([]Node, 0, 4)
+	list.WS0 = make([]Node, 0, 4)
+	list.WS1 = make([]Node, 0, 4)
+
+	for {
+		ws0 := ws
+		ws.Init()
+
+		param := &Parameter{}
+		if !param.Match(p) {
+			break
+		}
+
+		var ws1 InternalWhitespaceRun
+		ws1.Match(p)
+
+		list.Params = append(list.Params, param)
+		list.WS0 = append(list.WS0, &ws0)
+		list.WS1 = append(list.WS1, &ws1)
+
+		if p.Consume(nil, tokenpredicate.Type(token.RParen)) {
+			return true
+		}
+
+		if !p.Consume(nil, tokenpredicate.Type(token.Comma)) {
+			break
+		}
+
+		ws.Match(p)
+
+		if p.Consume(nil, tokenpredicate.Type(token.RParen)) {
+			list.WS2 = ws
+			list.HasTrailingComma = true
+			return true
+		}
+	}
+
+	list.Init()
+	p.Rewind(mark)
+	return false
+}
+
+func (list *ParameterList) String() string {
+	return util.StringImpl(list)
+}
+
+func (list *ParameterList) GoString() string {
+	return util.GoStringImpl(list)
+}
+
+func (list *ParameterList) EstimateStringLength() uint {
+	sum := 2 + uint(len(list.Params))
+	sum += sumStringLengthEstimates(list.Params)
+	sum += sumStringLengthEstimates(list.WS0)
+	sum += sumStringLengthEstimates(list.WS1)
+	sum += list.WS2.EstimateStringLength()
+	if len(list.Params) != 0 {
+		sum--
+	}
+	if list.HasTrailingComma {
+		sum++
+	}
+	return sum
+}
+
+func (list *ParameterList) EstimateGoStringLength() uint {
+	sum := 27 + list.WS2.EstimateGoStringLength()
+	sum += sumGoStringLengthEstimates(list.Params)
+	sum += sumGoStringLengthEstimates(list.WS0)
+	sum += sumGoStringLengthEstimates(list.WS1)
+	return sum
+}
+
+func (list *ParameterList) WriteStringTo(out *strings.Builder) {
+	out.WriteByte('(')
+	for index := uint(0); index < uint(len(list.Params)); index++ {
+		if index != 0 {
+			out.WriteByte(',')
+		}
+		list.WS0[index].WriteStringTo(out)
+		list.Params[index].WriteStringTo(out)
+		list.WS1[index].WriteStringTo(out)
+	}
+	if list.HasTrailingComma {
+		out.WriteByte(',')
+	}
+	list.WS2.WriteStringTo(out)
+	out.WriteByte(')')
+}
+
+func (list *ParameterList) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("ParameterList{")
+	out.WriteByte('[')
+	writeGoStringsTo(out, list.Params)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, list.WS0)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, list.WS1)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	list.WS2.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (list *ParameterList) ComputeStringLengthEstimates() {
+	for index := uint(0); index < uint(len(list.Params)); index++ {
+		list.Params[index].ComputeStringLengthEstimates()
+		list.WS0[index].ComputeStringLengthEstimates()
+		list.WS1[index].ComputeStringLengthEstimates()
+	}
+	list.WS2.ComputeStringLengthEstimates()
+}
+
+func (list *ParameterList) Children() []Node {
+	nodes := make([]Node, len(list.Params))
+	copy(nodes, list.Params)
+	return nodes
+}
+
+func (list *ParameterList) RewriteChildren(fn func(Node) Node) {
+	rewriteNodes(list.Params, fn)
+}
+
+var _ Node = (*ParameterList)(nil)
+
+// }}}
+
+// LambdaExpr
+// {{{
+
+type LambdaExpr struct {
+	extent
+
+	Params Node
+	Return Node
+	Body   Node
+	WS0    InternalWhitespaceRun
+	WS1    InternalWhitespaceRun
+	WS2    InternalWhitespaceRun
+	WS3    InternalWhitespaceRun
+}
+
+func (expr *LambdaExpr) Init() {
+	*expr = LambdaExpr{}
+}
+
+func (expr *LambdaExpr) Match(p *Parser) bool {
+	defer p.trackExtent(&expr.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	list := &ParameterList{}
+	if list.Match(p) {
+		expr.Params = list
+
+		inner := p.Mark()
+		expr.WS0.Match(p)
+		if p.Consume(nil, tokenpredicate.Type(token.Colon)) {
+			expr.WS1.Match(p)
+			if !matchExpr24(&expr.Return, p) {
+				expr.Init()
+				p.Rewind(mark)
+				return false
+			}
+		} else {
+			expr.WS0.Init()
+			p.Rewind(inner)
+		}
+		p.Forget(inner)
+	} else {
+		var tok token.Token
+		if !p.Consume(&tok, tokenpredicate.Type(token.Identifier)) {
+			p.Rewind(mark)
+			return false
+		}
+
+		name, err := value.ExtractLiteral(tok.Parsed, "<error>")
+		if err != nil {
+			p.EmitError(err)
+		}
+		param := &Parameter{Name: name}
+		p.setExtent(&param.extent, p.tokenIndex-1)
+		expr.Params = param
+	}
+
+	expr.WS2.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.EqualGreater)) {
+		expr.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	expr.WS3.Match(p)
+
+	block := &BlockStatement{}
+	if block.Match(p) {
+		expr.Body = block
+		return true
+	}
+
+	if MatchExpr(&expr.Body, p) {
+		return true
+	}
+
+	expr.Init()
+	p.Rewind(mark)
+	return false
+}
+
+func (expr *LambdaExpr) String() string {
+	return util.StringImpl(expr)
+}
+
+func (expr *LambdaExpr) GoString() string {
+	return util.GoStringImpl(expr)
+}
+
+func (expr *LambdaExpr) EstimateStringLength() uint {
+	sum := 2 + expr.Params.EstimateStringLength()
+	if expr.Return != nil {
+		sum += 1 + expr.Return.EstimateStringLength()
+	}
+	sum += expr.Body.EstimateStringLength()
+	sum += expr.WS0.EstimateStringLength()
+	sum += expr.WS1.EstimateStringLength()
+	sum += expr.WS2.EstimateStringLength()
+	sum += expr.WS3.EstimateStringLength()
+	return sum
+}
+
+func (expr *LambdaExpr) EstimateGoStringLength() uint {
+	sum := 17 + expr.Params.EstimateGoStringLength()
+	if expr.Return != nil {
+		sum += expr.Return.EstimateGoStringLength()
+	}
+	sum += expr.Body.EstimateGoStringLength()
+	sum += expr.WS0.EstimateGoStringLength()
+	sum += expr.WS1.EstimateGoStringLength()
+	sum += expr.WS2.EstimateGoStringLength()
+	sum += expr.WS3.EstimateGoStringLength()
+	return sum
+}
+
+func (expr *LambdaExpr) WriteStringTo(out *strings.Builder) {
+	expr.Params.WriteStringTo(out)
+	if expr.Return != nil {
+		expr.WS0.WriteStringTo(out)
+		out.WriteByte(':')
+		expr.WS1.WriteStringTo(out)
+		expr.Return.WriteStringTo(out)
+	}
+	expr.WS2.WriteStringTo(out)
+	out.WriteString("=>")
+	expr.WS3.WriteStringTo(out)
+	expr.Body.WriteStringTo(out)
+}
+
+func (expr *LambdaExpr) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("LambdaExpr{")
+	expr.Params.WriteGoStringTo(out)
+	out.WriteByte(',')
+	if expr.Return == nil {
+		out.WriteString("nil")
+	} else {
+		expr.Return.WriteGoStringTo(out)
+	}
+	out.WriteByte(',')
+	expr.Body.WriteGoStringTo(out)
+	out.WriteByte(',')
+	expr.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	expr.WS1.WriteGoStringTo(out)
+	out.WriteByte(',')
+	expr.WS2.WriteGoStringTo(out)
+	out.WriteByte(',')
+	expr.WS3.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (expr *LambdaExpr) ComputeStringLengthEstimates() {
+	expr.Params.ComputeStringLengthEstimates()
+	if expr.Return != nil {
+		expr.Return.ComputeStringLengthEstimates()
+	}
+	expr.Body.ComputeStringLengthEstimates()
+	expr.WS0.ComputeStringLengthEstimates()
+	expr.WS1.ComputeStringLengthEstimates()
+	expr.WS2.ComputeStringLengthEstimates()
+	expr.WS3.ComputeStringLengthEstimates()
+}
+
+func (expr *LambdaExpr) Children() []Node {
+	list := make([]Node, 0, 3)
+	list = append(list, expr.Params)
+	if expr.Return != nil {
+		list = append(list, expr.Return)
+	}
+	list = append(list, expr.Body)
+	return list
+}
+
+func (expr *LambdaExpr) RewriteChildren(fn func(Node) Node) {
+	expr.Params = fn(expr.Params)
+	if expr.Return != nil {
+		expr.Return = fn(expr.Return)
+	}
+	expr.Body = fn(expr.Body)
+}
+
+var _ Node = (*LambdaExpr)(nil)
+
+// }}}
@@ -14,6 +14,8 @@ import (
 // {{{
 
 type ModuleName struct {
+	extent
+
 	Names []string
 }
 
@@ -22,6 +24,8 @@ func (mod *ModuleName) Init() {
 }
 
 func (mod *ModuleName) Match(p *Parser) bool {
+	defer p.trackExtent(&mod.extent)()
+
 	var tok token.Token
 	if !p.Consume(&tok, tokenpredicate.Type(token.Identifier)) {
 		return false
@@ -150,6 +154,13 @@ func (mod *ModuleName) Intern() *ModuleName {
 	return mod
 }
 
+func (mod *ModuleName) Children() []Node {
+	return nil
+}
+
+func (mod *ModuleName) RewriteChildren(fn func(Node) Node) {
+}
+
 var _ Node = (*ModuleName)(nil)
 
 // }}}
@@ -158,6 +169,8 @@ var _ Node = (*ModuleName)(nil)
 // {{{
 
 type Identifier struct {
+	extent
+
 	Name string
 }
 
@@ -166,6 +179,8 @@ func (ident *Identifier) Init() {
 }
 
 func (ident *Identifier) Match(p *Parser) bool {
+	defer p.trackExtent(&ident.extent)()
+
 	var tok token.Token
 	if !p.Consume(&tok, tokenpredicate.Type(token.Identifier)) {
 		return false
@@ -254,6 +269,13 @@ func (ident *Identifier) Intern() *Identifier {
 	return ident
 }
 
+func (ident *Identifier) Children() []Node {
+	return nil
+}
+
+func (ident *Identifier) RewriteChildren(fn func(Node) Node) {
+}
+
 var _ Node = (*Identifier)(nil)
 
 // }}}
@@ -262,6 +284,8 @@ var _ Node = (*Identifier)(nil)
 // {{{
 
 type Symbol struct {
+	extent
+
 	Module     *ModuleName
 	Identifier *Identifier
 	TypeSig    *TypeSignature
@@ -273,6 +297,8 @@ func (sym *Symbol) Init() {
 }
 
 func (sym *Symbol) Match(p *Parser) bool {
+	defer p.trackExtent(&sym.extent)()
+
 	var tok token.Token
 	if !p.Consume(&tok, tokenpredicate.Type(token.Identifier)) {
 		return false
@@ -371,6 +397,12 @@ func (sym *Symbol) WriteStringTo(out *strings.Builder) {
 		}
 	}
 	out.WriteString(sym.Identifier.Name)
+	if sym.TypeSig != nil {
+		sym.TypeSig.WriteStringTo(out)
+	}
+	if sym.FuncSig != nil {
+		sym.FuncSig.WriteStringTo(out)
+	}
 }
 
 func (sym *Symbol) WriteGoStringTo(out *strings.Builder) {
@@ -419,6 +451,26 @@ func (sym *Symbol) Intern() *Symbol {
 	return sym
 }
 
+func (sym *Symbol) Children() []Node {
+	list := make([]Node, 0, 2)
+	if sym.TypeSig != nil {
+		list = append(list, sym.TypeSig)
+	}
+	if sym.FuncSig != nil {
+		list = append(list, sym.FuncSig)
+	}
+	return list
+}
+
+func (sym *Symbol) RewriteChildren(fn func(Node) Node) {
+	if sym.TypeSig != nil {
+		sym.TypeSig = rewriteSameType(sym.TypeSig, fn).(*TypeSignature)
+	}
+	if sym.FuncSig != nil {
+		sym.FuncSig = rewriteSameType(sym.FuncSig, fn).(*FuncSignature)
+	}
+}
+
 var _ Node = (*Symbol)(nil)
 
 // }}}
@@ -427,6 +479,8 @@ var _ Node = (*Symbol)(nil)
 // {{{
 
 type TypeSignature struct {
+	extent
+
 	Args             []Node
 	WS0              []Node
 	WS1              []Node
@@ -439,6 +493,8 @@ func (sig *TypeSignature) Init() {
 }
 
 func (sig *TypeSignature) Match(p *Parser) bool {
+	defer p.trackExtent(&sig.extent)()
+
 	mark := p.Mark()
 	defer p.Forget(mark)
 
@@ -486,6 +542,7 @@ func (sig *TypeSignature) Match(p *Parser) bool {
 		sig.WS1 = append(sig.WS1, &ws1)
 
 		if p.Consume(nil, tokenpredicate.Type(token.RBracket)) {
+			sig.WS2 = &ws
 			return true
 		}
 
@@ -551,6 +608,7 @@ func (sig *TypeSignature) WriteStringTo(out *strings.Builder) {
 		out.WriteByte(',')
 	}
 	sig.WS2.WriteStringTo(out)
+	out.WriteByte(']')
 }
 
 func (sig *TypeSignature) WriteGoStringTo(out *strings.Builder) {
@@ -580,6 +638,16 @@ func (sig *TypeSignature) ComputeStringLengthEstimates() {
 	sig.WS2.ComputeStringLengthEstimates()
 }
 
+func (sig *TypeSignature) Children() []Node {
+	list := make([]Node, len(sig.Args))
+	copy(list, sig.Args)
+	return list
+}
+
+func (sig *TypeSignature) RewriteChildren(fn func(Node) Node) {
+	rewriteNodes(sig.Args, fn)
+}
+
 var _ Node = (*TypeSignature)(nil)
 
 // }}}
@@ -588,6 +656,12 @@ var _ Node = (*TypeSignature)(nil)
 // {{{
 
 type FuncSignature struct {
+	extent
+
+	Args   ArgumentList
+	Return Node
+	WS0    InternalWhitespaceRun
+	WS1    InternalWhitespaceRun
 }
 
 func (sig *FuncSignature) Init() {
@@ -595,6 +669,8 @@ func (sig *FuncSignature) Init() {
 }
 
 func (sig *FuncSignature) Match(p *Parser) bool {
+	defer p.trackExtent(&sig.extent)()
+
 	mark := p.Mark()
 	defer p.Forget(mark)
 
@@ -602,13 +678,33 @@ func (sig *FuncSignature) Match(p *Parser) bool {
 		p.Rewind(mark)
 		return false
 	}
-	if !p.Consume(nil, tokenpredicate.Type(token.LParen)) {
+
+	if !sig.Args.Match(p) {
 		p.Rewind(mark)
 		return false
 	}
 
-	p.Rewind(mark)
-	return false
+	p.Forget(mark)
+	mark = p.Mark()
+
+	sig.WS0.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.Colon)) {
+		sig.WS0.Init()
+		p.Rewind(mark)
+		return true
+	}
+
+	sig.WS1.Match(p)
+
+	if !matchExpr24(&sig.Return, p) {
+		sig.WS0.Init()
+		sig.WS1.Init()
+		p.Rewind(mark)
+		return true
+	}
+
+	return true
 }
 
 func (sig *FuncSignature) String() string {
@@ -620,20 +716,75 @@ func (sig *FuncSignature) GoString() string {
 }
 
 func (sig *FuncSignature) EstimateStringLength() uint {
-	return 0
+	sum := 1 + sig.Args.EstimateStringLength()
+	if sig.Return != nil {
+		sum += 1 + sig.Return.EstimateStringLength()
+		sum += sig.WS0.EstimateStringLength()
+		sum += sig.WS1.EstimateStringLength()
+	}
+	return sum
 }
 
 func (sig *FuncSignature) EstimateGoStringLength() uint {
-	return 0
+	sum := 22 + sig.Args.EstimateGoStringLength()
+	if sig.Return != nil {
+		sum += sig.Return.EstimateGoStringLength()
+	}
+	sum += sig.WS0.EstimateGoStringLength()
+	sum += sig.WS1.EstimateGoStringLength()
+	return sum
 }
 
 func (sig *FuncSignature) WriteStringTo(out *strings.Builder) {
+	out.WriteByte('#')
+	sig.Args.WriteStringTo(out)
+	if sig.Return != nil {
+		sig.WS0.WriteStringTo(out)
+		out.WriteByte(':')
+		sig.WS1.WriteStringTo(out)
+		sig.Return.WriteStringTo(out)
+	}
 }
 
 func (sig *FuncSignature) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("FuncSignature{")
+	sig.Args.WriteGoStringTo(out)
+	out.WriteByte(',')
+	if sig.Return == nil {
+		out.WriteString("nil")
+	} else {
+		sig.Return.WriteGoStringTo(out)
+	}
+	out.WriteByte(',')
+	sig.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	sig.WS1.WriteGoStringTo(out)
+	out.WriteByte('}')
 }
 
 func (sig *FuncSignature) ComputeStringLengthEstimates() {
+	sig.Args.ComputeStringLengthEstimates()
+	if sig.Return != nil {
+		sig.Return.ComputeStringLengthEstimates()
+	}
+	sig.WS0.ComputeStringLengthEstimates()
+	sig.WS1.ComputeStringLengthEstimates()
+}
+
+func (sig *FuncSignature) Children() []Node {
+	list := make([]Node, 0, 2)
+	list = append(list, &sig.Args)
+	if sig.Return != nil {
+		list = append(list, sig.Return)
+	}
+	return list
+}
+
+func (sig *FuncSignature) RewriteChildren(fn func(Node) Node) {
+	sig.Args = *rewriteSameType(&sig.Args, fn).(*ArgumentList)
+	if sig.Return != nil {
+		sig.Return = fn(sig.Return)
+	}
 }
 
 var _ Node = (*FuncSignature)(nil)
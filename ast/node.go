@@ -16,6 +16,19 @@ type Node interface {
 	WriteStringTo(out *strings.Builder)
 	WriteGoStringTo(out *strings.Builder)
 	ComputeStringLengthEstimates()
+
+	// Children returns the node's sub-nodes in source order.  Names are
+	// attributes rather than children, and whitespace runs are omitted.
+	Children() []Node
+
+	// RewriteChildren replaces each child c returned by Children with
+	// fn(c).  See Rewrite.
+	RewriteChildren(fn func(Node) Node)
+
+	// Span returns the source range that the node was parsed from, without
+	// any surrounding whitespace.  FullSpan includes it.
+	Span() Span
+	FullSpan() Span
 }
 
 func sumStringLengthEstimates(list []Node) uint {
@@ -3,12 +3,9 @@ package ast
 import (
 	"fmt"
 
-	"github.com/chronos-tachyon/go-spiderscript/operators"
 	"github.com/chronos-tachyon/go-spiderscript/token"
 )
 
-var _ = operators.InvalidOperator
-
 // UnaryOperator
 // {{{
 
@@ -2,6 +2,8 @@ package ast
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/chronos-tachyon/go-spiderscript/token"
 	"github.com/chronos-tachyon/go-spiderscript/tokenpredicate"
@@ -9,10 +11,53 @@ import (
 
 var ErrBadParse = errors.New("bad parse")
 
+// ParseError reports input that matched the grammar but is not meaningful,
+// such as a literal on the left side of an assignment.
+type ParseError struct {
+	Pos     token.Position
+	Message string
+}
+
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("%v: %s", err.Pos, err.Message)
+}
+
+var _ error = (*ParseError)(nil)
+
+// SyntaxError reports a statement that could not be parsed.  Found is the
+// furthest token that the parser tried and failed to match, and Expected
+// lists the token types that would have been accepted there.  Start and End
+// span the tokens that were skipped to resynchronize.
+type SyntaxError struct {
+	Start    token.Position
+	End      token.Position
+	Found    token.Token
+	Expected []token.Type
+}
+
+func (err *SyntaxError) Error() string {
+	if len(err.Expected) == 0 {
+		return fmt.Sprintf("%v: unexpected %v", err.Found.Start, err.Found.Type)
+	}
+	names := make([]string, len(err.Expected))
+	for index, tt := range err.Expected {
+		names[index] = tt.String()
+	}
+	return fmt.Sprintf("%v: unexpected %v, expected one of [%s]", err.Found.Start, err.Found.Type, strings.Join(names, " "))
+}
+
+var _ error = (*SyntaxError)(nil)
+
 type Parser struct {
-	errors     []error
-	tokens     []token.Token
-	tokenIndex uint
+	errors      []error
+	diagnostics []token.Diagnostic
+	tokens      []token.Token
+	tokenIndex  uint
+	failIndex   uint
+	reach       uint
+	blocks      []blockKind
+	expected    []token.Type
+	inHeader    bool
 }
 
 type Mark struct {
@@ -28,12 +73,31 @@ func NewParser(lexer *token.Lexer) *Parser {
 
 func (p *Parser) Init(lexer *token.Lexer) {
 	*p = Parser{
-		errors: make([]error, 0, 4),
-		tokens: make([]token.Token, 0, 1024),
+		errors:   make([]error, 0, 4),
+		tokens:   make([]token.Token, 0, 1024),
+		expected: make([]token.Type, 0, 16),
 	}
 	for lexer.HasNext() {
 		p.tokens = append(p.tokens, lexer.Token())
 	}
+	p.setDiagnostics(lexer.Diagnostics())
+}
+
+// setDiagnostics records the lexer's diagnostics, and reports the ones that
+// are errors as parse errors too.
+func (p *Parser) setDiagnostics(list []token.Diagnostic) {
+	p.diagnostics = list
+	for index := range list {
+		if list[index].Severity == token.SeverityError {
+			p.errors = append(p.errors, &list[index])
+		}
+	}
+}
+
+// Diagnostics returns the problems that the lexer found in the input,
+// including warnings that Errors leaves out.
+func (p *Parser) Diagnostics() []token.Diagnostic {
+	return p.diagnostics
 }
 
 func (p *Parser) Errors() []error {
@@ -63,6 +127,51 @@ func (p *Parser) Forget(mark Mark) {
 	_ = mark
 }
 
+// blockKind tells what a pair of braces holds, for the purposes of ending
+// the last item before the '}'.
+type blockKind uint8
+
+const (
+	statementBlock blockKind = iota
+	memberBlock
+)
+
+// enterBlock notes that the parser is inside a brace-delimited list of
+// statements or type members, where a '}' may end the last item without a
+// ';' or a newline.  The caller must call the returned function on the way
+// out.
+func (p *Parser) enterBlock(kind blockKind) func() {
+	p.blocks = append(p.blocks, kind)
+	restore := p.enterHeader(false)
+	return func() {
+		restore()
+		p.blocks = p.blocks[:len(p.blocks)-1]
+	}
+}
+
+// enterHeader notes whether the parser is inside the header of an if,
+// while, for, for-each or switch statement.  As in Go, a '{' that follows a
+// name in a header opens the body of the statement, not a struct literal;
+// brackets and blocks within the header lift the restriction.  The caller
+// must call the returned function on the way out.
+func (p *Parser) enterHeader(inHeader bool) func() {
+	saved := p.inHeader
+	p.inHeader = inHeader
+	return func() { p.inHeader = saved }
+}
+
+// atBlockEnd returns true if the next token is a '}' that closes the
+// innermost block.
+func (p *Parser) atBlockEnd() bool {
+	return len(p.blocks) != 0 && p.Peek(nil, tokenpredicate.Type(token.RBrace))
+}
+
+// inMemberBlock returns true if the innermost block holds type members,
+// which may also be separated by ','.
+func (p *Parser) inMemberBlock() bool {
+	return len(p.blocks) != 0 && p.blocks[len(p.blocks)-1] == memberBlock
+}
+
 func (p *Parser) Parse(node Node) bool {
 	node.Init()
 	if node.Match(p) {
@@ -72,7 +181,18 @@ func (p *Parser) Parse(node Node) bool {
 	return false
 }
 
+// rawSince returns the exact source text of the tokens consumed since the
+// token at index first.
+func (p *Parser) rawSince(first uint) string {
+	var buf strings.Builder
+	for _, tok := range p.tokens[first:p.tokenIndex] {
+		buf.WriteString(string(tok.Raw))
+	}
+	return buf.String()
+}
+
 func (p *Parser) Peek(out *token.Token, pred tokenpredicate.TokenPredicate) bool {
+	p.noteReach(p.tokenIndex)
 	if p.tokenIndex < uint(len(p.tokens)) {
 		tok := p.tokens[p.tokenIndex]
 		if pred.MatchToken(tok) {
@@ -82,12 +202,77 @@ func (p *Parser) Peek(out *token.Token, pred tokenpredicate.TokenPredicate) bool
 			return true
 		}
 	}
+	p.noteExpected(pred)
 	if out != nil {
 		*out = token.Token{}
 	}
 	return false
 }
 
+// ResetExpected forgets which tokens were expected so far, so that a later
+// SyntaxError describes only what happened after the current position.
+func (p *Parser) ResetExpected() {
+	p.failIndex = p.tokenIndex
+	p.expected = p.expected[:0]
+}
+
+// Furthest returns the furthest token that failed to match since the last
+// call to ResetExpected, along with the token types that were expected.
+func (p *Parser) Furthest() (token.Token, []token.Type) {
+	index := p.failIndex
+	if index >= uint(len(p.tokens)) {
+		if len(p.tokens) == 0 {
+			return token.Token{}, nil
+		}
+		index = uint(len(p.tokens)) - 1
+	}
+	list := make([]token.Type, len(p.expected))
+	copy(list, p.expected)
+	return p.tokens[index], list
+}
+
+// noteReach records that the token at index was looked at.  File uses the
+// furthest such token to tell which statements an edit can affect.
+func (p *Parser) noteReach(index uint) {
+	index++
+	if index > uint(len(p.tokens)) {
+		index = uint(len(p.tokens))
+	}
+	if index > p.reach {
+		p.reach = index
+	}
+}
+
+func (p *Parser) noteExpected(pred tokenpredicate.TokenPredicate) {
+	if p.tokenIndex < p.failIndex {
+		return
+	}
+
+	list := tokenpredicate.ExpectedTypes(pred)
+	if len(list) == 0 {
+		return
+	}
+
+	if p.tokenIndex > p.failIndex {
+		p.failIndex = p.tokenIndex
+		p.expected = p.expected[:0]
+	}
+
+outer:
+	for _, tt := range list {
+		switch tt {
+		case token.HWS, token.VWS, token.SingleLineComment, token.MultiLineComment:
+			continue outer
+		}
+		for _, existing := range p.expected {
+			if existing == tt {
+				continue outer
+			}
+		}
+		p.expected = append(p.expected, tt)
+	}
+}
+
 func (p *Parser) Consume(out *token.Token, pred tokenpredicate.TokenPredicate) bool {
 	ok := p.Peek(out, pred)
 	if ok {
@@ -99,6 +284,7 @@ func (p *Parser) Consume(out *token.Token, pred tokenpredicate.TokenPredicate) b
 func (p *Parser) ConsumeWhile(out *[]token.Token, pred tokenpredicate.TokenPredicate) bool {
 	atLeastOne := false
 	for p.tokenIndex < uint(len(p.tokens)) {
+		p.noteReach(p.tokenIndex)
 		tok := p.tokens[p.tokenIndex]
 		if !pred.MatchToken(tok) {
 			break
@@ -115,6 +301,7 @@ func (p *Parser) ConsumeWhile(out *[]token.Token, pred tokenpredicate.TokenPredi
 func (p *Parser) ConsumeUntil(out *[]token.Token, pred tokenpredicate.TokenPredicate) bool {
 	atLeastOne := false
 	for p.tokenIndex < uint(len(p.tokens)) {
+		p.noteReach(p.tokenIndex)
 		tok := p.tokens[p.tokenIndex]
 		if pred.MatchToken(tok) {
 			break
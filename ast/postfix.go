@@ -0,0 +1,840 @@
+package ast
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/chronos-tachyon/go-spiderscript/internal/util"
+	"github.com/chronos-tachyon/go-spiderscript/token"
+	"github.com/chronos-tachyon/go-spiderscript/tokenpredicate"
+	"github.com/chronos-tachyon/go-spiderscript/value"
+)
+
+func matchPostfixOperand(p *Parser, fn func(Node) bool) bool {
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	var node Node
+	if !matchExpr24(&node, p) {
+		p.Rewind(mark)
+		return false
+	}
+
+	if !fn(node) {
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func matchArgument(ptr *Node, p *Parser) bool {
+	named := &NamedArgument{}
+	if named.Match(p) {
+		*ptr = named
+		return true
+	}
+
+	return MatchExpr(ptr, p)
+}
+
+// NamedArgument
+// {{{
+
+type NamedArgument struct {
+	extent
+
+	Name  string
+	Value Node
+	WS0   InternalWhitespaceRun
+	WS1   InternalWhitespaceRun
+}
+
+func (arg *NamedArgument) Init() {
+	*arg = NamedArgument{}
+}
+
+func (arg *NamedArgument) Match(p *Parser) bool {
+	defer p.trackExtent(&arg.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	var tok token.Token
+	if !p.Consume(&tok, tokenpredicate.Type(token.Identifier)) {
+		p.Rewind(mark)
+		return false
+	}
+
+	arg.WS0.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.Colon)) {
+		p.Rewind(mark)
+		return false
+	}
+
+	arg.WS1.Match(p)
+
+	if !MatchExpr(&arg.Value, p) {
+		p.Rewind(mark)
+		return false
+	}
+
+	name, err := value.ExtractLiteral(tok.Parsed, "<error>")
+	if err != nil {
+		p.EmitError(err)
+	}
+	arg.Name = name
+	return true
+}
+
+func (arg *NamedArgument) String() string {
+	return util.StringImpl(arg)
+}
+
+func (arg *NamedArgument) GoString() string {
+	return util.GoStringImpl(arg)
+}
+
+func (arg *NamedArgument) EstimateStringLength() uint {
+	sum := 1 + uint(len(arg.Name))
+	sum += arg.Value.EstimateStringLength()
+	sum += arg.WS0.EstimateStringLength()
+	sum += arg.WS1.EstimateStringLength()
+	return sum
+}
+
+func (arg *NamedArgument) EstimateGoStringLength() uint {
+	sum := 20 + uint(len(arg.Name))
+	sum += arg.Value.EstimateGoStringLength()
+	sum += arg.WS0.EstimateGoStringLength()
+	sum += arg.WS1.EstimateGoStringLength()
+	return sum
+}
+
+func (arg *NamedArgument) WriteStringTo(out *strings.Builder) {
+	out.WriteString(arg.Name)
+	arg.WS0.WriteStringTo(out)
+	out.WriteByte(':')
+	arg.WS1.WriteStringTo(out)
+	arg.Value.WriteStringTo(out)
+}
+
+func (arg *NamedArgument) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("NamedArgument{")
+	out.WriteString(strconv.Quote(arg.Name))
+	out.WriteByte(',')
+	arg.Value.WriteGoStringTo(out)
+	out.WriteByte(',')
+	arg.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	arg.WS1.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (arg *NamedArgument) ComputeStringLengthEstimates() {
+	arg.Value.ComputeStringLengthEstimates()
+	arg.WS0.ComputeStringLengthEstimates()
+	arg.WS1.ComputeStringLengthEstimates()
+}
+
+func (arg *NamedArgument) Children() []Node {
+	list := make([]Node, 0, 1)
+	list = append(list, arg.Value)
+	return list
+}
+
+func (arg *NamedArgument) RewriteChildren(fn func(Node) Node) {
+	arg.Value = fn(arg.Value)
+}
+
+var _ Node = (*NamedArgument)(nil)
+
+// }}}
+
+// ArgumentList
+// {{{
+
+type ArgumentList struct {
+	extent
+
+	Args             []Node
+	WS0              []Node
+	WS1              []Node
+	WS2              InternalWhitespaceRun
+	HasTrailingComma bool
+}
+
+func (list *ArgumentList) Init() {
+	*list = ArgumentList{}
+}
+
+func (list *ArgumentList) Match(p *Parser) bool {
+	defer p.trackExtent(&list.extent)()
+	defer p.enterHeader(false)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.LParen)) {
+		p.Rewind(mark)
+		return false
+	}
+
+	var ws InternalWhitespaceRun
+	ws.Match(p)
+
+	if p.Consume(nil, tokenpredicate.Type(token.RParen)) {
+		list.WS2 = ws
+		return true
+	}
+
+	list.Args = make([]Node, 0, 4)
+	list.WS0 = make([]Node, 0, 4)
+	list.WS1 = make([]Node, 0, 4)
+
+	for {
+		ws0 := ws
+		ws.Init()
+
+		var arg Node
+		if !matchArgument(&arg, p) {
+			break
+		}
+
+		var ws1 InternalWhitespaceRun
+		ws1.Match(p)
+
+		list.Args = append(list.Args, arg)
+		list.WS0 = append(list.WS0, &ws0)
+		list.WS1 = append(list.WS1, &ws1)
+
+		if p.Consume(nil, tokenpredicate.Type(token.RParen)) {
+			return true
+		}
+
+		if !p.Consume(nil, tokenpredicate.Type(token.Comma)) {
+			break
+		}
+
+		ws.Match(p)
+
+		if p.Consume(nil, tokenpredicate.Type(token.RParen)) {
+			list.WS2 = ws
+			list.HasTrailingComma = true
+			return true
+		}
+	}
+
+	list.Init()
+	p.Rewind(mark)
+	return false
+}
+
+func (list *ArgumentList) Positional() []Node {
+	out := make([]Node, 0, len(list.Args))
+	for _, arg := range list.Args {
+		if _, ok := arg.(*NamedArgument); !ok {
+			out = append(out, arg)
+		}
+	}
+	return out
+}
+
+func (list *ArgumentList) Named() []*NamedArgument {
+	out := make([]*NamedArgument, 0, len(list.Args))
+	for _, arg := range list.Args {
+		if named, ok := arg.(*NamedArgument); ok {
+			out = append(out, named)
+		}
+	}
+	return out
+}
+
+func (list *ArgumentList) String() string {
+	return util.StringImpl(list)
+}
+
+func (list *ArgumentList) GoString() string {
+	return util.GoStringImpl(list)
+}
+
+func (list *ArgumentList) EstimateStringLength() uint {
+	sum := 2 + uint(len(list.Args))
+	sum += sumStringLengthEstimates(list.Args)
+	sum += sumStringLengthEstimates(list.WS0)
+	sum += sumStringLengthEstimates(list.WS1)
+	sum += list.WS2.EstimateStringLength()
+	if len(list.Args) != 0 {
+		sum--
+	}
+	if list.HasTrailingComma {
+		sum++
+	}
+	return sum
+}
+
+func (list *ArgumentList) EstimateGoStringLength() uint {
+	sum := 26 + list.WS2.EstimateGoStringLength()
+	sum += sumGoStringLengthEstimates(list.Args)
+	sum += sumGoStringLengthEstimates(list.WS0)
+	sum += sumGoStringLengthEstimates(list.WS1)
+	return sum
+}
+
+func (list *ArgumentList) WriteStringTo(out *strings.Builder) {
+	out.WriteByte('(')
+	for index := uint(0); index < uint(len(list.Args)); index++ {
+		if index != 0 {
+			out.WriteByte(',')
+		}
+		list.WS0[index].WriteStringTo(out)
+		list.Args[index].WriteStringTo(out)
+		list.WS1[index].WriteStringTo(out)
+	}
+	if list.HasTrailingComma {
+		out.WriteByte(',')
+	}
+	list.WS2.WriteStringTo(out)
+	out.WriteByte(')')
+}
+
+func (list *ArgumentList) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("ArgumentList{")
+	out.WriteByte('[')
+	writeGoStringsTo(out, list.Args)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, list.WS0)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, list.WS1)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	list.WS2.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (list *ArgumentList) ComputeStringLengthEstimates() {
+	for index := uint(0); index < uint(len(list.Args)); index++ {
+		list.Args[index].ComputeStringLengthEstimates()
+		list.WS0[index].ComputeStringLengthEstimates()
+		list.WS1[index].ComputeStringLengthEstimates()
+	}
+	list.WS2.ComputeStringLengthEstimates()
+}
+
+func (list *ArgumentList) Children() []Node {
+	nodes := make([]Node, len(list.Args))
+	copy(nodes, list.Args)
+	return nodes
+}
+
+func (list *ArgumentList) RewriteChildren(fn func(Node) Node) {
+	rewriteNodes(list.Args, fn)
+}
+
+var _ Node = (*ArgumentList)(nil)
+
+// }}}
+
+// CallExpr
+// {{{
+
+type CallExpr struct {
+	extent
+
+	Callee Node
+	Args   ArgumentList
+}
+
+func (expr *CallExpr) Init() {
+	*expr = CallExpr{}
+}
+
+func (expr *CallExpr) Match(p *Parser) bool {
+	defer p.trackExtent(&expr.extent)()
+
+	return matchPostfixOperand(p, func(node Node) bool {
+		if x, ok := node.(*CallExpr); ok {
+			*expr = *x
+			return true
+		}
+		return false
+	})
+}
+
+func (expr *CallExpr) String() string {
+	return util.StringImpl(expr)
+}
+
+func (expr *CallExpr) GoString() string {
+	return util.GoStringImpl(expr)
+}
+
+func (expr *CallExpr) EstimateStringLength() uint {
+	return expr.Callee.EstimateStringLength() + expr.Args.EstimateStringLength()
+}
+
+func (expr *CallExpr) EstimateGoStringLength() uint {
+	return 10 + expr.Callee.EstimateGoStringLength() + expr.Args.EstimateGoStringLength()
+}
+
+func (expr *CallExpr) WriteStringTo(out *strings.Builder) {
+	expr.Callee.WriteStringTo(out)
+	expr.Args.WriteStringTo(out)
+}
+
+func (expr *CallExpr) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("CallExpr{")
+	expr.Callee.WriteGoStringTo(out)
+	out.WriteByte(',')
+	expr.Args.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (expr *CallExpr) ComputeStringLengthEstimates() {
+	expr.Callee.ComputeStringLengthEstimates()
+	expr.Args.ComputeStringLengthEstimates()
+}
+
+func (expr *CallExpr) Children() []Node {
+	list := make([]Node, 0, 2)
+	list = append(list, expr.Callee)
+	list = append(list, &expr.Args)
+	return list
+}
+
+func (expr *CallExpr) RewriteChildren(fn func(Node) Node) {
+	expr.Callee = fn(expr.Callee)
+	expr.Args = *rewriteSameType(&expr.Args, fn).(*ArgumentList)
+}
+
+var _ Node = (*CallExpr)(nil)
+
+// }}}
+
+// IndexExpr
+// {{{
+
+type IndexExpr struct {
+	extent
+
+	Operand Node
+	Index   Node
+	WS0     InternalWhitespaceRun
+	WS1     InternalWhitespaceRun
+}
+
+func (expr *IndexExpr) Init() {
+	*expr = IndexExpr{}
+}
+
+func (expr *IndexExpr) Match(p *Parser) bool {
+	defer p.trackExtent(&expr.extent)()
+
+	return matchPostfixOperand(p, func(node Node) bool {
+		if x, ok := node.(*IndexExpr); ok {
+			*expr = *x
+			return true
+		}
+		return false
+	})
+}
+
+func (expr *IndexExpr) matchSuffix(p *Parser) bool {
+	defer p.enterHeader(false)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.LBracket)) {
+		p.Rewind(mark)
+		return false
+	}
+
+	expr.WS0.Match(p)
+
+	if !MatchExpr(&expr.Index, p) {
+		p.Rewind(mark)
+		return false
+	}
+
+	expr.WS1.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.RBracket)) {
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (expr *IndexExpr) String() string {
+	return util.StringImpl(expr)
+}
+
+func (expr *IndexExpr) GoString() string {
+	return util.GoStringImpl(expr)
+}
+
+func (expr *IndexExpr) EstimateStringLength() uint {
+	sum := 2 + expr.Operand.EstimateStringLength()
+	sum += expr.Index.EstimateStringLength()
+	sum += expr.WS0.EstimateStringLength()
+	sum += expr.WS1.EstimateStringLength()
+	return sum
+}
+
+func (expr *IndexExpr) EstimateGoStringLength() uint {
+	sum := 14 + expr.Operand.EstimateGoStringLength()
+	sum += expr.Index.EstimateGoStringLength()
+	sum += expr.WS0.EstimateGoStringLength()
+	sum += expr.WS1.EstimateGoStringLength()
+	return sum
+}
+
+func (expr *IndexExpr) WriteStringTo(out *strings.Builder) {
+	expr.Operand.WriteStringTo(out)
+	out.WriteByte('[')
+	expr.WS0.WriteStringTo(out)
+	expr.Index.WriteStringTo(out)
+	expr.WS1.WriteStringTo(out)
+	out.WriteByte(']')
+}
+
+func (expr *IndexExpr) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("IndexExpr{")
+	expr.Operand.WriteGoStringTo(out)
+	out.WriteByte(',')
+	expr.Index.WriteGoStringTo(out)
+	out.WriteByte(',')
+	expr.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	expr.WS1.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (expr *IndexExpr) ComputeStringLengthEstimates() {
+	expr.Operand.ComputeStringLengthEstimates()
+	expr.Index.ComputeStringLengthEstimates()
+	expr.WS0.ComputeStringLengthEstimates()
+	expr.WS1.ComputeStringLengthEstimates()
+}
+
+func (expr *IndexExpr) Children() []Node {
+	list := make([]Node, 0, 2)
+	list = append(list, expr.Operand)
+	list = append(list, expr.Index)
+	return list
+}
+
+func (expr *IndexExpr) RewriteChildren(fn func(Node) Node) {
+	expr.Operand = fn(expr.Operand)
+	expr.Index = fn(expr.Index)
+}
+
+var _ Node = (*IndexExpr)(nil)
+
+// }}}
+
+// FieldExpr
+// {{{
+
+type FieldExpr struct {
+	extent
+
+	Operand Node
+	Field   string
+	WS0     InternalWhitespaceRun
+	WS1     InternalWhitespaceRun
+}
+
+func (expr *FieldExpr) Init() {
+	*expr = FieldExpr{}
+}
+
+func (expr *FieldExpr) Match(p *Parser) bool {
+	defer p.trackExtent(&expr.extent)()
+
+	return matchPostfixOperand(p, func(node Node) bool {
+		if x, ok := node.(*FieldExpr); ok {
+			*expr = *x
+			return true
+		}
+		return false
+	})
+}
+
+func (expr *FieldExpr) matchSuffix(p *Parser) bool {
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	expr.WS0.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.Dot)) {
+		p.Rewind(mark)
+		return false
+	}
+
+	expr.WS1.Match(p)
+
+	var tok token.Token
+	if !p.Consume(&tok, tokenpredicate.Type(token.Identifier)) {
+		p.Rewind(mark)
+		return false
+	}
+
+	field, err := value.ExtractLiteral(tok.Parsed, "<error>")
+	if err != nil {
+		p.EmitError(err)
+	}
+	expr.Field = field
+	return true
+}
+
+func (expr *FieldExpr) String() string {
+	return util.StringImpl(expr)
+}
+
+func (expr *FieldExpr) GoString() string {
+	return util.GoStringImpl(expr)
+}
+
+func (expr *FieldExpr) EstimateStringLength() uint {
+	sum := 1 + uint(len(expr.Field))
+	sum += expr.Operand.EstimateStringLength()
+	sum += expr.WS0.EstimateStringLength()
+	sum += expr.WS1.EstimateStringLength()
+	return sum
+}
+
+func (expr *FieldExpr) EstimateGoStringLength() uint {
+	sum := 16 + uint(len(expr.Field))
+	sum += expr.Operand.EstimateGoStringLength()
+	sum += expr.WS0.EstimateGoStringLength()
+	sum += expr.WS1.EstimateGoStringLength()
+	return sum
+}
+
+func (expr *FieldExpr) WriteStringTo(out *strings.Builder) {
+	expr.Operand.WriteStringTo(out)
+	expr.WS0.WriteStringTo(out)
+	out.WriteByte('.')
+	expr.WS1.WriteStringTo(out)
+	out.WriteString(expr.Field)
+}
+
+func (expr *FieldExpr) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("FieldExpr{")
+	expr.Operand.WriteGoStringTo(out)
+	out.WriteByte(',')
+	out.WriteString(strconv.Quote(expr.Field))
+	out.WriteByte(',')
+	expr.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	expr.WS1.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (expr *FieldExpr) ComputeStringLengthEstimates() {
+	expr.Operand.ComputeStringLengthEstimates()
+	expr.WS0.ComputeStringLengthEstimates()
+	expr.WS1.ComputeStringLengthEstimates()
+}
+
+func (expr *FieldExpr) Children() []Node {
+	list := make([]Node, 0, 1)
+	list = append(list, expr.Operand)
+	return list
+}
+
+func (expr *FieldExpr) RewriteChildren(fn func(Node) Node) {
+	expr.Operand = fn(expr.Operand)
+}
+
+var _ Node = (*FieldExpr)(nil)
+
+// }}}
+
+// GenericInstantiationExpr
+// {{{
+
+type GenericInstantiationExpr struct {
+	extent
+
+	Operand Node
+	TypeSig *TypeSignature
+	FuncSig *FuncSignature
+}
+
+func (expr *GenericInstantiationExpr) Init() {
+	*expr = GenericInstantiationExpr{}
+}
+
+func (expr *GenericInstantiationExpr) Match(p *Parser) bool {
+	defer p.trackExtent(&expr.extent)()
+
+	return matchPostfixOperand(p, func(node Node) bool {
+		if x, ok := node.(*GenericInstantiationExpr); ok {
+			*expr = *x
+			return true
+		}
+		return false
+	})
+}
+
+func (expr *GenericInstantiationExpr) matchSuffix(p *Parser) bool {
+	var tsig TypeSignature
+	if tsig.Match(p) {
+		expr.TypeSig = &tsig
+		return true
+	}
+
+	var fsig FuncSignature
+	if fsig.Match(p) {
+		expr.FuncSig = &fsig
+		return true
+	}
+
+	return false
+}
+
+func (expr *GenericInstantiationExpr) signature() Node {
+	if expr.TypeSig != nil {
+		return expr.TypeSig
+	}
+	return expr.FuncSig
+}
+
+func (expr *GenericInstantiationExpr) String() string {
+	return util.StringImpl(expr)
+}
+
+func (expr *GenericInstantiationExpr) GoString() string {
+	return util.GoStringImpl(expr)
+}
+
+func (expr *GenericInstantiationExpr) EstimateStringLength() uint {
+	return expr.Operand.EstimateStringLength() + expr.signature().EstimateStringLength()
+}
+
+func (expr *GenericInstantiationExpr) EstimateGoStringLength() uint {
+	return 26 + expr.Operand.EstimateGoStringLength() + expr.signature().EstimateGoStringLength()
+}
+
+func (expr *GenericInstantiationExpr) WriteStringTo(out *strings.Builder) {
+	expr.Operand.WriteStringTo(out)
+	expr.signature().WriteStringTo(out)
+}
+
+func (expr *GenericInstantiationExpr) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("GenericInstantiationExpr{")
+	expr.Operand.WriteGoStringTo(out)
+	out.WriteByte(',')
+	expr.signature().WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (expr *GenericInstantiationExpr) ComputeStringLengthEstimates() {
+	expr.Operand.ComputeStringLengthEstimates()
+	expr.signature().ComputeStringLengthEstimates()
+}
+
+func (expr *GenericInstantiationExpr) Children() []Node {
+	list := make([]Node, 0, 3)
+	list = append(list, expr.Operand)
+	if expr.TypeSig != nil {
+		list = append(list, expr.TypeSig)
+	}
+	if expr.FuncSig != nil {
+		list = append(list, expr.FuncSig)
+	}
+	return list
+}
+
+func (expr *GenericInstantiationExpr) RewriteChildren(fn func(Node) Node) {
+	expr.Operand = fn(expr.Operand)
+	if expr.TypeSig != nil {
+		expr.TypeSig = rewriteSameType(expr.TypeSig, fn).(*TypeSignature)
+	}
+	if expr.FuncSig != nil {
+		expr.FuncSig = rewriteSameType(expr.FuncSig, fn).(*FuncSignature)
+	}
+}
+
+var _ Node = (*GenericInstantiationExpr)(nil)
+
+// }}}
+
+// SplatExpr
+// {{{
+
+type SplatExpr struct {
+	extent
+
+	Operand Node
+}
+
+func (expr *SplatExpr) Init() {
+	*expr = SplatExpr{}
+}
+
+func (expr *SplatExpr) Match(p *Parser) bool {
+	defer p.trackExtent(&expr.extent)()
+
+	return matchPostfixOperand(p, func(node Node) bool {
+		if x, ok := node.(*SplatExpr); ok {
+			*expr = *x
+			return true
+		}
+		return false
+	})
+}
+
+func (expr *SplatExpr) String() string {
+	return util.StringImpl(expr)
+}
+
+func (expr *SplatExpr) GoString() string {
+	return util.GoStringImpl(expr)
+}
+
+func (expr *SplatExpr) EstimateStringLength() uint {
+	return 3 + expr.Operand.EstimateStringLength()
+}
+
+func (expr *SplatExpr) EstimateGoStringLength() uint {
+	return 11 + expr.Operand.EstimateGoStringLength()
+}
+
+func (expr *SplatExpr) WriteStringTo(out *strings.Builder) {
+	expr.Operand.WriteStringTo(out)
+	out.WriteString("...")
+}
+
+func (expr *SplatExpr) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("SplatExpr{")
+	expr.Operand.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (expr *SplatExpr) ComputeStringLengthEstimates() {
+	expr.Operand.ComputeStringLengthEstimates()
+}
+
+func (expr *SplatExpr) Children() []Node {
+	list := make([]Node, 0, 1)
+	list = append(list, expr.Operand)
+	return list
+}
+
+func (expr *SplatExpr) RewriteChildren(fn func(Node) Node) {
+	expr.Operand = fn(expr.Operand)
+}
+
+var _ Node = (*SplatExpr)(nil)
+
+// }}}
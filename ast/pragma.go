@@ -30,9 +30,15 @@ func MatchPragma(ptr *Node, p *Parser) bool {
 // {{{
 
 type VersionPragma struct {
+	extent
+
 	Major uint32
 	Minor uint32
 	Patch uint32
+
+	// Raw is the exact source text, if known.  It is written in place of
+	// the version numbers so that spacing and omitted parts survive.
+	Raw string
 }
 
 func (pragma *VersionPragma) Init() {
@@ -40,6 +46,10 @@ func (pragma *VersionPragma) Init() {
 }
 
 func (pragma *VersionPragma) Match(p *Parser) bool {
+	defer p.trackExtent(&pragma.extent)()
+
+	first := p.tokenIndex
+
 	mark := p.Mark()
 	defer p.Forget(mark)
 
@@ -116,6 +126,7 @@ func (pragma *VersionPragma) Match(p *Parser) bool {
 		p.EmitError(err)
 	}
 
+	pragma.Raw = p.rawSince(first)
 	return true
 }
 
@@ -128,6 +139,9 @@ func (pragma *VersionPragma) GoString() string {
 }
 
 func (pragma *VersionPragma) EstimateStringLength() uint {
+	if pragma.Raw != "" {
+		return uint(len(pragma.Raw))
+	}
 	return 42
 }
 
@@ -136,6 +150,10 @@ func (pragma *VersionPragma) EstimateGoStringLength() uint {
 }
 
 func (pragma *VersionPragma) WriteStringTo(out *strings.Builder) {
+	if pragma.Raw != "" {
+		out.WriteString(pragma.Raw)
+		return
+	}
 	out.WriteString("#version(")
 	out.WriteString(strconv.FormatUint(uint64(pragma.Major), 10))
 	out.WriteByte(',')
@@ -158,6 +176,13 @@ func (pragma *VersionPragma) WriteGoStringTo(out *strings.Builder) {
 func (pragma *VersionPragma) ComputeStringLengthEstimates() {
 }
 
+func (pragma *VersionPragma) Children() []Node {
+	return nil
+}
+
+func (pragma *VersionPragma) RewriteChildren(fn func(Node) Node) {
+}
+
 var _ Node = (*VersionPragma)(nil)
 
 // }}}
@@ -165,19 +190,33 @@ var _ Node = (*VersionPragma)(nil)
 // GenericPragma
 // {{{
 
+// GenericPragma is "#name" optionally followed by a parenthesized argument
+// list.  WS0 precedes the "(", WS1 and WS2 surround each expression, and
+// WS3 fills an empty "( )".
 type GenericPragma struct {
+	extent
+
 	Name        string
 	Expressions []Node
+	WS1         []Node
+	WS2         []Node
+	WS0         HorizontalWhitespace
+	WS3         HorizontalWhitespace
+	HasParens   bool
 }
 
 func (pragma *GenericPragma) Init() {
 	*pragma = GenericPragma{
 		Name:        "#<error>",
 		Expressions: make([]Node, 0, 4),
+		WS1:         make([]Node, 0, 4),
+		WS2:         make([]Node, 0, 4),
 	}
 }
 
 func (pragma *GenericPragma) Match(p *Parser) bool {
+	defer p.trackExtent(&pragma.extent)()
+
 	mark := p.Mark()
 	defer p.Forget(mark)
 
@@ -196,51 +235,55 @@ func (pragma *GenericPragma) Match(p *Parser) bool {
 	p.Forget(mark)
 	mark = p.Mark()
 
-	p.DropWhile(tokenpredicate.Type(token.HWS))
-
-	if !p.Consume(nil, tokenpredicate.Type(token.LParen)) {
-		pragma.Expressions = nil
+	if !pragma.matchArgs(p) {
 		p.Rewind(mark)
-		return true
+		pragma.Init()
+		pragma.Name = name
 	}
+	return true
+}
 
-	p.DropWhile(tokenpredicate.Type(token.HWS))
+func (pragma *GenericPragma) matchArgs(p *Parser) bool {
+	pragma.WS0.Match(p)
 
-	if p.Consume(nil, tokenpredicate.Type(token.RParen)) {
-		pragma.Expressions = nil
-		return true
+	if !p.Consume(nil, tokenpredicate.Type(token.LParen)) {
+		return false
 	}
+	pragma.HasParens = true
 
-	var expr Node
-	if !MatchPragmaExpr(&expr, p) {
-		pragma.Expressions = nil
-		p.Rewind(mark)
+	var ws0 HorizontalWhitespace
+	ws0.Match(p)
+
+	if p.Consume(nil, tokenpredicate.Type(token.RParen)) {
+		pragma.WS3 = ws0
 		return true
 	}
-	pragma.Expressions = append(pragma.Expressions, expr)
 
 	for {
-		p.DropWhile(tokenpredicate.Type(token.HWS))
+		var expr Node
+		if !MatchPragmaExpr(&expr, p) {
+			return false
+		}
+
+		var ws1 HorizontalWhitespace
+		ws1.Match(p)
+
+		before := ws0
+		pragma.Expressions = append(pragma.Expressions, expr)
+		pragma.WS1 = append(pragma.WS1, &before)
+		pragma.WS2 = append(pragma.WS2, &ws1)
 
 		if p.Consume(nil, tokenpredicate.Type(token.RParen)) {
 			return true
 		}
 
-		if p.Consume(nil, tokenpredicate.Type(token.Comma)) {
-			expr = nil
-			p.DropWhile(tokenpredicate.Type(token.HWS))
-			if MatchPragmaExpr(&expr, p) {
-				pragma.Expressions = append(pragma.Expressions, expr)
-				continue
-			}
+		if !p.Consume(nil, tokenpredicate.Type(token.Comma)) {
+			return false
 		}
 
-		break
+		ws0 = HorizontalWhitespace{}
+		ws0.Match(p)
 	}
-
-	p.Rewind(mark)
-	pragma.Expressions = nil
-	return true
 }
 
 func (pragma *GenericPragma) String() string {
@@ -252,29 +295,42 @@ func (pragma *GenericPragma) GoString() string {
 }
 
 func (pragma *GenericPragma) EstimateStringLength() uint {
-	sum := 1 + uint(len(pragma.Name)) + uint(len(pragma.Expressions))
-	for _, expr := range pragma.Expressions {
-		sum += expr.EstimateStringLength()
-	}
+	sum := 2 + uint(len(pragma.Name)) + uint(len(pragma.Expressions))
+	sum += sumStringLengthEstimates(pragma.Expressions)
+	sum += sumStringLengthEstimates(pragma.WS1)
+	sum += sumStringLengthEstimates(pragma.WS2)
+	sum += pragma.WS0.EstimateStringLength()
+	sum += pragma.WS3.EstimateStringLength()
 	return sum
 }
 
 func (pragma *GenericPragma) EstimateGoStringLength() uint {
-	sum := 17 + uint(len(pragma.Name)) + uint(len(pragma.Expressions))
-	for _, expr := range pragma.Expressions {
-		sum += expr.EstimateGoStringLength()
-	}
+	sum := 28 + uint(len(pragma.Name))
+	sum += sumGoStringLengthEstimates(pragma.Expressions)
+	sum += sumGoStringLengthEstimates(pragma.WS1)
+	sum += sumGoStringLengthEstimates(pragma.WS2)
+	sum += pragma.WS0.EstimateGoStringLength()
+	sum += pragma.WS3.EstimateGoStringLength()
 	return sum
 }
 
 func (pragma *GenericPragma) WriteStringTo(out *strings.Builder) {
 	out.WriteString(pragma.Name)
+	if !pragma.HasParens {
+		return
+	}
+	pragma.WS0.WriteStringTo(out)
 	out.WriteByte('(')
+	if len(pragma.Expressions) == 0 {
+		pragma.WS3.WriteStringTo(out)
+	}
 	for index, expr := range pragma.Expressions {
 		if index > 0 {
 			out.WriteByte(',')
 		}
+		pragma.WS1[index].WriteStringTo(out)
 		expr.WriteStringTo(out)
+		pragma.WS2[index].WriteStringTo(out)
 	}
 	out.WriteByte(')')
 }
@@ -282,17 +338,45 @@ func (pragma *GenericPragma) WriteStringTo(out *strings.Builder) {
 func (pragma *GenericPragma) WriteGoStringTo(out *strings.Builder) {
 	out.WriteString("GenericPragma{")
 	out.WriteString(strconv.Quote(pragma.Name))
-	for _, expr := range pragma.Expressions {
-		out.WriteByte(',')
-		expr.WriteGoStringTo(out)
-	}
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, pragma.Expressions)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, pragma.WS1)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, pragma.WS2)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	pragma.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	pragma.WS3.WriteGoStringTo(out)
+	out.WriteByte(',')
+	out.WriteString(strconv.FormatBool(pragma.HasParens))
 	out.WriteByte('}')
 }
 
 func (pragma *GenericPragma) ComputeStringLengthEstimates() {
-	for _, expr := range pragma.Expressions {
+	for index, expr := range pragma.Expressions {
 		expr.ComputeStringLengthEstimates()
+		pragma.WS1[index].ComputeStringLengthEstimates()
+		pragma.WS2[index].ComputeStringLengthEstimates()
 	}
+	pragma.WS0.ComputeStringLengthEstimates()
+	pragma.WS3.ComputeStringLengthEstimates()
+}
+
+func (pragma *GenericPragma) Children() []Node {
+	list := make([]Node, len(pragma.Expressions))
+	copy(list, pragma.Expressions)
+	return list
+}
+
+func (pragma *GenericPragma) RewriteChildren(fn func(Node) Node) {
+	rewriteNodes(pragma.Expressions, fn)
 }
 
 var _ Node = (*GenericPragma)(nil)
@@ -0,0 +1,536 @@
+package ast
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/chronos-tachyon/go-spiderscript/internal/util"
+	"github.com/chronos-tachyon/go-spiderscript/token"
+	"github.com/chronos-tachyon/go-spiderscript/tokenpredicate"
+	"github.com/chronos-tachyon/go-spiderscript/value"
+)
+
+func matchParenthesized(ptr *Node, p *Parser) bool {
+	defer p.enterHeader(false)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.LParen)) {
+		p.Rewind(mark)
+		return false
+	}
+
+	var ws InternalWhitespaceRun
+	ws.Match(p)
+
+	if p.Consume(nil, tokenpredicate.Type(token.RParen)) {
+		tuple := &TupleExpr{WS2: ws}
+		p.setExtent(&tuple.extent, mark.savedTokenIndex)
+		*ptr = tuple
+		return true
+	}
+
+	tuple := &TupleExpr{
+		Items: make([]Node, 0, 4),
+		WS0:   make([]Node, 0, 4),
+		WS1:   make([]Node, 0, 4),
+	}
+
+	for {
+		ws0 := ws
+		ws.Init()
+
+		var item Node
+		if !MatchExpr(&item, p) {
+			break
+		}
+
+		var ws1 InternalWhitespaceRun
+		ws1.Match(p)
+
+		tuple.Items = append(tuple.Items, item)
+		tuple.WS0 = append(tuple.WS0, &ws0)
+		tuple.WS1 = append(tuple.WS1, &ws1)
+
+		if p.Consume(nil, tokenpredicate.Type(token.RParen)) {
+			if len(tuple.Items) == 1 {
+				paren := &ParenExpr{
+					Inner: item,
+					WS0:   ws0,
+					WS1:   ws1,
+				}
+				p.setExtent(&paren.extent, mark.savedTokenIndex)
+				*ptr = paren
+				return true
+			}
+			p.setExtent(&tuple.extent, mark.savedTokenIndex)
+			*ptr = tuple
+			return true
+		}
+
+		if !p.Consume(nil, tokenpredicate.Type(token.Comma)) {
+			break
+		}
+
+		ws.Match(p)
+
+		if p.Consume(nil, tokenpredicate.Type(token.RParen)) {
+			tuple.WS2 = ws
+			tuple.HasTrailingComma = true
+			p.setExtent(&tuple.extent, mark.savedTokenIndex)
+			*ptr = tuple
+			return true
+		}
+	}
+
+	p.Rewind(mark)
+	return false
+}
+
+// IdentifierExpr
+// {{{
+
+type IdentifierExpr struct {
+	extent
+
+	Module ModuleName
+	Name   string
+}
+
+func (expr *IdentifierExpr) Init() {
+	*expr = IdentifierExpr{}
+}
+
+func (expr *IdentifierExpr) Match(p *Parser) bool {
+	defer p.trackExtent(&expr.extent)()
+
+	var tok token.Token
+	if !p.Consume(&tok, tokenpredicate.Type(token.Identifier)) {
+		return false
+	}
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	words := make([]string, 0, 4)
+	word, err := value.ExtractLiteral(tok.Parsed, "<error>")
+	if err != nil {
+		p.EmitError(err)
+	}
+	words = append(words, word)
+
+	for {
+		if !p.Consume(nil, tokenpredicate.Type(token.ColonColon)) {
+			p.Rewind(mark)
+			break
+		}
+
+		if !p.Consume(&tok, tokenpredicate.Type(token.Identifier)) {
+			p.Rewind(mark)
+			break
+		}
+
+		p.Forget(mark)
+		mark = p.Mark()
+
+		word, err = value.ExtractLiteral(tok.Parsed, "<error>")
+		if err != nil {
+			p.EmitError(err)
+		}
+		words = append(words, word)
+	}
+
+	n := uint(len(words)) - 1
+	if n != 0 {
+		expr.Module.Names = words[:n]
+	}
+	expr.Name = words[n]
+	return true
+}
+
+func (expr *IdentifierExpr) String() string {
+	return util.StringImpl(expr)
+}
+
+func (expr *IdentifierExpr) GoString() string {
+	return util.GoStringImpl(expr)
+}
+
+func (expr *IdentifierExpr) EstimateStringLength() uint {
+	sum := uint(len(expr.Name))
+	for _, word := range expr.Module.Names {
+		sum += 2 + uint(len(word))
+	}
+	return sum
+}
+
+func (expr *IdentifierExpr) EstimateGoStringLength() uint {
+	return 19 + uint(len(expr.Name)) + expr.Module.EstimateGoStringLength()
+}
+
+func (expr *IdentifierExpr) WriteStringTo(out *strings.Builder) {
+	for _, word := range expr.Module.Names {
+		out.WriteString(word)
+		out.WriteString("::")
+	}
+	out.WriteString(expr.Name)
+}
+
+func (expr *IdentifierExpr) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("IdentifierExpr{")
+	expr.Module.WriteGoStringTo(out)
+	out.WriteByte(',')
+	out.WriteString(strconv.Quote(expr.Name))
+	out.WriteByte('}')
+}
+
+func (expr *IdentifierExpr) ComputeStringLengthEstimates() {
+}
+
+func (expr *IdentifierExpr) Children() []Node {
+	return nil
+}
+
+func (expr *IdentifierExpr) RewriteChildren(fn func(Node) Node) {
+}
+
+var _ Node = (*IdentifierExpr)(nil)
+
+// }}}
+
+// ThisExpr
+// {{{
+
+type ThisExpr struct {
+	extent
+}
+
+func (expr *ThisExpr) Init() {
+	*expr = ThisExpr{}
+}
+
+func (expr *ThisExpr) Match(p *Parser) bool {
+	defer p.trackExtent(&expr.extent)()
+
+	return p.Consume(nil,
+		tokenpredicate.And(
+			tokenpredicate.Type(token.Identifier),
+			tokenpredicate.Literal("this")))
+}
+
+func (expr *ThisExpr) String() string {
+	return util.StringImpl(expr)
+}
+
+func (expr *ThisExpr) GoString() string {
+	return util.GoStringImpl(expr)
+}
+
+func (expr *ThisExpr) EstimateStringLength() uint {
+	return 4
+}
+
+func (expr *ThisExpr) EstimateGoStringLength() uint {
+	return 10
+}
+
+func (expr *ThisExpr) WriteStringTo(out *strings.Builder) {
+	out.WriteString("this")
+}
+
+func (expr *ThisExpr) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("ThisExpr{}")
+}
+
+func (expr *ThisExpr) ComputeStringLengthEstimates() {
+}
+
+func (expr *ThisExpr) Children() []Node {
+	return nil
+}
+
+func (expr *ThisExpr) RewriteChildren(fn func(Node) Node) {
+}
+
+var _ Node = (*ThisExpr)(nil)
+
+// }}}
+
+// PlaceholderExpr
+// {{{
+
+type PlaceholderExpr struct {
+	extent
+}
+
+func (expr *PlaceholderExpr) Init() {
+	*expr = PlaceholderExpr{}
+}
+
+func (expr *PlaceholderExpr) Match(p *Parser) bool {
+	defer p.trackExtent(&expr.extent)()
+
+	return p.Consume(nil, tokenpredicate.Type(token.KeywordPlaceholder))
+}
+
+func (expr *PlaceholderExpr) String() string {
+	return util.StringImpl(expr)
+}
+
+func (expr *PlaceholderExpr) GoString() string {
+	return util.GoStringImpl(expr)
+}
+
+func (expr *PlaceholderExpr) EstimateStringLength() uint {
+	return 1
+}
+
+func (expr *PlaceholderExpr) EstimateGoStringLength() uint {
+	return 17
+}
+
+func (expr *PlaceholderExpr) WriteStringTo(out *strings.Builder) {
+	out.WriteByte('_')
+}
+
+func (expr *PlaceholderExpr) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("PlaceholderExpr{}")
+}
+
+func (expr *PlaceholderExpr) ComputeStringLengthEstimates() {
+}
+
+func (expr *PlaceholderExpr) Children() []Node {
+	return nil
+}
+
+func (expr *PlaceholderExpr) RewriteChildren(fn func(Node) Node) {
+}
+
+var _ Node = (*PlaceholderExpr)(nil)
+
+// }}}
+
+// ParenExpr
+// {{{
+
+type ParenExpr struct {
+	extent
+
+	Inner Node
+	WS0   InternalWhitespaceRun
+	WS1   InternalWhitespaceRun
+}
+
+func (expr *ParenExpr) Init() {
+	*expr = ParenExpr{}
+}
+
+func (expr *ParenExpr) Match(p *Parser) bool {
+	defer p.trackExtent(&expr.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	var node Node
+	if !matchParenthesized(&node, p) {
+		p.Rewind(mark)
+		return false
+	}
+
+	if x, ok := node.(*ParenExpr); ok {
+		*expr = *x
+		return true
+	}
+
+	p.Rewind(mark)
+	return false
+}
+
+func (expr *ParenExpr) String() string {
+	return util.StringImpl(expr)
+}
+
+func (expr *ParenExpr) GoString() string {
+	return util.GoStringImpl(expr)
+}
+
+func (expr *ParenExpr) EstimateStringLength() uint {
+	sum := 2 + expr.Inner.EstimateStringLength()
+	sum += expr.WS0.EstimateStringLength()
+	sum += expr.WS1.EstimateStringLength()
+	return sum
+}
+
+func (expr *ParenExpr) EstimateGoStringLength() uint {
+	sum := 13 + expr.Inner.EstimateGoStringLength()
+	sum += expr.WS0.EstimateGoStringLength()
+	sum += expr.WS1.EstimateGoStringLength()
+	return sum
+}
+
+func (expr *ParenExpr) WriteStringTo(out *strings.Builder) {
+	out.WriteByte('(')
+	expr.WS0.WriteStringTo(out)
+	expr.Inner.WriteStringTo(out)
+	expr.WS1.WriteStringTo(out)
+	out.WriteByte(')')
+}
+
+func (expr *ParenExpr) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("ParenExpr{")
+	expr.Inner.WriteGoStringTo(out)
+	out.WriteByte(',')
+	expr.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	expr.WS1.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (expr *ParenExpr) ComputeStringLengthEstimates() {
+	expr.Inner.ComputeStringLengthEstimates()
+	expr.WS0.ComputeStringLengthEstimates()
+	expr.WS1.ComputeStringLengthEstimates()
+}
+
+func (expr *ParenExpr) Children() []Node {
+	list := make([]Node, 0, 1)
+	list = append(list, expr.Inner)
+	return list
+}
+
+func (expr *ParenExpr) RewriteChildren(fn func(Node) Node) {
+	expr.Inner = fn(expr.Inner)
+}
+
+var _ Node = (*ParenExpr)(nil)
+
+// }}}
+
+// TupleExpr
+// {{{
+
+type TupleExpr struct {
+	extent
+
+	Items            []Node
+	WS0              []Node
+	WS1              []Node
+	WS2              InternalWhitespaceRun
+	HasTrailingComma bool
+}
+
+func (expr *TupleExpr) Init() {
+	*expr = TupleExpr{}
+}
+
+func (expr *TupleExpr) Match(p *Parser) bool {
+	defer p.trackExtent(&expr.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	var node Node
+	if !matchParenthesized(&node, p) {
+		p.Rewind(mark)
+		return false
+	}
+
+	if x, ok := node.(*TupleExpr); ok {
+		*expr = *x
+		return true
+	}
+
+	p.Rewind(mark)
+	return false
+}
+
+func (expr *TupleExpr) String() string {
+	return util.StringImpl(expr)
+}
+
+func (expr *TupleExpr) GoString() string {
+	return util.GoStringImpl(expr)
+}
+
+func (expr *TupleExpr) EstimateStringLength() uint {
+	sum := 2 + uint(len(expr.Items))
+	sum += sumStringLengthEstimates(expr.Items)
+	sum += sumStringLengthEstimates(expr.WS0)
+	sum += sumStringLengthEstimates(expr.WS1)
+	sum += expr.WS2.EstimateStringLength()
+	if len(expr.Items) != 0 {
+		sum--
+	}
+	if expr.HasTrailingComma {
+		sum++
+	}
+	return sum
+}
+
+func (expr *TupleExpr) EstimateGoStringLength() uint {
+	sum := 23 + expr.WS2.EstimateGoStringLength()
+	sum += sumGoStringLengthEstimates(expr.Items)
+	sum += sumGoStringLengthEstimates(expr.WS0)
+	sum += sumGoStringLengthEstimates(expr.WS1)
+	return sum
+}
+
+func (expr *TupleExpr) WriteStringTo(out *strings.Builder) {
+	out.WriteByte('(')
+	for index := uint(0); index < uint(len(expr.Items)); index++ {
+		if index != 0 {
+			out.WriteByte(',')
+		}
+		expr.WS0[index].WriteStringTo(out)
+		expr.Items[index].WriteStringTo(out)
+		expr.WS1[index].WriteStringTo(out)
+	}
+	if expr.HasTrailingComma {
+		out.WriteByte(',')
+	}
+	expr.WS2.WriteStringTo(out)
+	out.WriteByte(')')
+}
+
+func (expr *TupleExpr) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("TupleExpr{")
+	out.WriteByte('[')
+	writeGoStringsTo(out, expr.Items)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, expr.WS0)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, expr.WS1)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	expr.WS2.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (expr *TupleExpr) ComputeStringLengthEstimates() {
+	for index := uint(0); index < uint(len(expr.Items)); index++ {
+		expr.Items[index].ComputeStringLengthEstimates()
+		expr.WS0[index].ComputeStringLengthEstimates()
+		expr.WS1[index].ComputeStringLengthEstimates()
+	}
+	expr.WS2.ComputeStringLengthEstimates()
+}
+
+func (expr *TupleExpr) Children() []Node {
+	list := make([]Node, len(expr.Items))
+	copy(list, expr.Items)
+	return list
+}
+
+func (expr *TupleExpr) RewriteChildren(fn func(Node) Node) {
+	rewriteNodes(expr.Items, fn)
+}
+
+var _ Node = (*TupleExpr)(nil)
+
+// }}}
@@ -0,0 +1,83 @@
+package ast
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestRoundTrip_Corpus(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.spider"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no *.spider files found in testdata")
+	}
+
+	for _, path := range paths {
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		input := string(raw)
+
+		file := testParseFile(t, input)
+		if file == nil {
+			continue
+		}
+		if actual := file.String(); actual != input {
+			line, expected, actual := firstDifferentLine(input, actual)
+			t.Errorf("%s:%d: String() does not reproduce the input: expected %q, actual %q", path, line, expected, actual)
+		}
+	}
+}
+
+func firstDifferentLine(a, b string) (int, string, string) {
+	aLines := strings.SplitAfter(a, "\n")
+	bLines := strings.SplitAfter(b, "\n")
+	for index := 0; index < len(aLines) || index < len(bLines); index++ {
+		var aLine, bLine string
+		if index < len(aLines) {
+			aLine = aLines[index]
+		}
+		if index < len(bLines) {
+			bLine = bLines[index]
+		}
+		if aLine != bLine {
+			return index + 1, aLine, bLine
+		}
+	}
+	return 0, "", ""
+}
+
+func FuzzParse(f *testing.F) {
+	paths, _ := filepath.Glob(filepath.Join("testdata", "*.spider"))
+	for _, path := range paths {
+		if raw, err := ioutil.ReadFile(path); err == nil {
+			f.Add(string(raw))
+		}
+	}
+	f.Add("")
+	f.Add("let x = )\nlet y = 1\n")
+	f.Add("#A(0, 0)\n")
+
+	f.Fuzz(func(t *testing.T, input string) {
+		if !utf8.ValidString(input) {
+			// The lexer works on runes, so invalid UTF-8 cannot round trip.
+			return
+		}
+
+		p := newTestParser(input)
+		var file File
+		if !p.Parse(&file) {
+			return
+		}
+		if actual := file.String(); actual != input {
+			t.Errorf("Parse(%q).String(): expected %q, actual %q", input, input, actual)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package ast
+
+import (
+	"github.com/chronos-tachyon/go-spiderscript/token"
+)
+
+// Span is the range of source text covered by a node, from the start of its
+// first token to the end of its last token.
+type Span struct {
+	Start token.Position
+	End   token.Position
+}
+
+// extent is embedded in every node to record the spans it was matched from.
+// The full span includes the whitespace runs and comments at either end of
+// the node; the plain span does not.
+type extent struct {
+	span     Span
+	fullSpan Span
+}
+
+// Span returns the source range of the node, excluding any leading or
+// trailing whitespace and comments.  Nodes that consist only of whitespace
+// have an empty span at the start of their full span.
+func (ext *extent) Span() Span {
+	return ext.span
+}
+
+// FullSpan returns the source range of the node, including any whitespace
+// runs and comments that it absorbed at either end.
+func (ext *extent) FullSpan() Span {
+	return ext.fullSpan
+}
+
+func isTrivia(tt token.Type) bool {
+	switch tt {
+	case token.HWS, token.VWS, token.SingleLineComment, token.MultiLineComment, token.EOF:
+		return true
+	default:
+		return false
+	}
+}
+
+// trackExtent records the current position and returns a function that sets
+// ext to cover the tokens consumed since then.  It is meant to be deferred:
+//
+//	defer p.trackExtent(&node.extent)()
+func (p *Parser) trackExtent(ext *extent) func() {
+	first := p.tokenIndex
+	return func() {
+		p.setExtent(ext, first)
+	}
+}
+
+// setExtent sets ext to cover the tokens from index first up to the current
+// position.
+func (p *Parser) setExtent(ext *extent, first uint) {
+	end := p.tokenIndex
+	if first >= end {
+		pos := p.positionAt(first)
+		ext.span = Span{pos, pos}
+		ext.fullSpan = ext.span
+		return
+	}
+
+	ext.fullSpan = Span{p.tokens[first].Start, p.tokens[end-1].End}
+
+	i, j := first, end
+	for i < j && isTrivia(p.tokens[i].Type) {
+		i++
+	}
+	for j > i && isTrivia(p.tokens[j-1].Type) {
+		j--
+	}
+	if i == j {
+		ext.span = Span{ext.fullSpan.Start, ext.fullSpan.Start}
+		return
+	}
+	ext.span = Span{p.tokens[i].Start, p.tokens[j-1].End}
+}
+
+// extendExtent sets ext to cover everything from the start of first up to
+// the current position.  It is used by nodes that are assembled around an
+// operand that was already matched, such as BinaryOperatorExpr or CallExpr.
+func (p *Parser) extendExtent(ext *extent, first Node) {
+	end := p.tokenIndex
+	j := end
+	for j > 0 && isTrivia(p.tokens[j-1].Type) {
+		j--
+	}
+	ext.span = Span{first.Span().Start, p.tokens[j-1].End}
+	ext.fullSpan = Span{first.FullSpan().Start, p.tokens[end-1].End}
+}
+
+func (p *Parser) positionAt(index uint) token.Position {
+	if index < uint(len(p.tokens)) {
+		return p.tokens[index].Start
+	}
+	if len(p.tokens) != 0 {
+		return p.tokens[len(p.tokens)-1].End
+	}
+	return token.Position{}
+}
@@ -0,0 +1,47 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestSpan(t *testing.T) {
+	input := "import foo\n\nlet x = a + f(b)[0] * c // note\n"
+	file := testParseFile(t, input)
+	if file == nil {
+		return
+	}
+
+	text := func(span Span) string {
+		return input[span.Start.RuneOffset:span.End.RuneOffset]
+	}
+
+	type testRow struct {
+		Node     Node
+		Span     string
+		FullSpan string
+	}
+
+	decl := file.Statements[1].(*VarDeclStatement)
+	sum := decl.Value.(*BinaryOperatorExpr)
+	product := sum.Operand1.(*BinaryOperatorExpr)
+
+	data := []testRow{
+		{file, "import foo\n\nlet x = a + f(b)[0] * c", input},
+		{file.Statements[0], "import foo", "import foo\n\n"},
+		{decl, "let x = a + f(b)[0] * c", "let x = a + f(b)[0] * c // note\n"},
+		{sum, "a + f(b)[0] * c", "a + f(b)[0] * c"},
+		{product, "f(b)[0] * c", "f(b)[0] * c"},
+		{product.Operand0, "f(b)[0]", "f(b)[0]"},
+		{product.Operand0.(*IndexExpr).Operand, "f(b)", "f(b)"},
+		{&decl.WS5, "", " "},
+	}
+
+	for index, row := range data {
+		if actual := text(row.Node.Span()); actual != row.Span {
+			t.Errorf("[%d] %T.Span(): expected %q, actual %q", index, row.Node, row.Span, actual)
+		}
+		if actual := text(row.Node.FullSpan()); actual != row.FullSpan {
+			t.Errorf("[%d] %T.FullSpan(): expected %q, actual %q", index, row.Node, row.FullSpan, actual)
+		}
+	}
+}
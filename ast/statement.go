@@ -12,7 +12,7 @@ import (
 )
 
 func MatchStatement(ptr *Node, p *Parser) bool {
-	p.DropWhile(tokenpredicate.Type(token.HWS))
+	p.ResetExpected()
 
 	stmt0 := &ShebangLineStatement{}
 	if stmt0.Match(p) {
@@ -38,16 +38,116 @@ func MatchStatement(ptr *Node, p *Parser) bool {
 		return true
 	}
 
-	stmt99 := &AnyStatement{}
-	stmt99.Match(p)
-	*ptr = stmt99
-	return true
+	stmt4 := &AliasStatement{}
+	if stmt4.Match(p) {
+		*ptr = stmt4
+		return true
+	}
+
+	stmt5 := &TypeDeclStatement{}
+	if stmt5.Match(p) {
+		*ptr = stmt5
+		return true
+	}
+
+	stmt6 := &VarDeclStatement{}
+	if stmt6.Match(p) {
+		*ptr = stmt6
+		return true
+	}
+
+	stmt7 := &FuncDeclStatement{}
+	if stmt7.Match(p) {
+		*ptr = stmt7
+		return true
+	}
+
+	stmt8 := &MethodDeclStatement{}
+	if stmt8.Match(p) {
+		*ptr = stmt8
+		return true
+	}
+
+	stmt9 := &BlockStatement{}
+	if stmt9.Match(p) {
+		*ptr = stmt9
+		return true
+	}
+
+	stmt10 := &IfStatement{}
+	if stmt10.Match(p) {
+		*ptr = stmt10
+		return true
+	}
+
+	stmt11 := &WhileStatement{}
+	if stmt11.Match(p) {
+		*ptr = stmt11
+		return true
+	}
+
+	stmt12 := &ForStatement{}
+	if stmt12.Match(p) {
+		*ptr = stmt12
+		return true
+	}
+
+	stmt13 := &ForEachStatement{}
+	if stmt13.Match(p) {
+		*ptr = stmt13
+		return true
+	}
+
+	stmt14 := &SwitchStatement{}
+	if stmt14.Match(p) {
+		*ptr = stmt14
+		return true
+	}
+
+	stmt15 := &ReturnStatement{}
+	if stmt15.Match(p) {
+		*ptr = stmt15
+		return true
+	}
+
+	stmt16 := &BranchStatement{}
+	if stmt16.Match(p) {
+		*ptr = stmt16
+		return true
+	}
+
+	stmt17 := &AssignmentStatement{}
+	if stmt17.Match(p) {
+		*ptr = stmt17
+		return true
+	}
+
+	stmt18 := &MutationStatement{}
+	if stmt18.Match(p) {
+		*ptr = stmt18
+		return true
+	}
+
+	stmt19 := &ExpressionStatement{}
+	if stmt19.Match(p) {
+		*ptr = stmt19
+		return true
+	}
+
+	stmt99 := &ErrorStatement{}
+	if stmt99.Match(p) {
+		*ptr = stmt99
+		return true
+	}
+	return false
 }
 
 // ShebangLineStatement
 // {{{
 
 type ShebangLineStatement struct {
+	extent
+
 	Text string
 	VWS  VerticalWhitespace
 }
@@ -57,6 +157,8 @@ func (stmt *ShebangLineStatement) Init() {
 }
 
 func (stmt *ShebangLineStatement) Match(p *Parser) bool {
+	defer p.trackExtent(&stmt.extent)()
+
 	var tok token.Token
 	if !p.Consume(&tok, tokenpredicate.Type(token.ShebangLine)) {
 		return false
@@ -111,6 +213,13 @@ func (stmt *ShebangLineStatement) ComputeStringLengthEstimates() {
 	stmt.VWS.ComputeStringLengthEstimates()
 }
 
+func (stmt *ShebangLineStatement) Children() []Node {
+	return nil
+}
+
+func (stmt *ShebangLineStatement) RewriteChildren(fn func(Node) Node) {
+}
+
 var _ Node = (*ShebangLineStatement)(nil)
 
 // }}}
@@ -119,6 +228,8 @@ var _ Node = (*ShebangLineStatement)(nil)
 // {{{
 
 type EmptyStatement struct {
+	extent
+
 	WS TerminalWhitespaceRun
 }
 
@@ -127,6 +238,8 @@ func (stmt *EmptyStatement) Init() {
 }
 
 func (stmt *EmptyStatement) Match(p *Parser) bool {
+	defer p.trackExtent(&stmt.extent)()
+
 	return stmt.WS.Match(p)
 }
 
@@ -160,6 +273,13 @@ func (stmt *EmptyStatement) ComputeStringLengthEstimates() {
 	stmt.WS.ComputeStringLengthEstimates()
 }
 
+func (stmt *EmptyStatement) Children() []Node {
+	return nil
+}
+
+func (stmt *EmptyStatement) RewriteChildren(fn func(Node) Node) {
+}
+
 var _ Node = (*EmptyStatement)(nil)
 
 // }}}
@@ -168,6 +288,8 @@ var _ Node = (*EmptyStatement)(nil)
 // {{{
 
 type PragmaStatement struct {
+	extent
+
 	Pragma Node
 	WS0    InternalWhitespaceRun
 	WS1    TerminalWhitespaceRun
@@ -178,6 +300,8 @@ func (stmt *PragmaStatement) Init() {
 }
 
 func (stmt *PragmaStatement) Match(p *Parser) bool {
+	defer p.trackExtent(&stmt.extent)()
+
 	mark := p.Mark()
 	defer p.Forget(mark)
 
@@ -240,6 +364,16 @@ func (stmt *PragmaStatement) ComputeStringLengthEstimates() {
 	stmt.WS1.ComputeStringLengthEstimates()
 }
 
+func (stmt *PragmaStatement) Children() []Node {
+	list := make([]Node, 0, 1)
+	list = append(list, stmt.Pragma)
+	return list
+}
+
+func (stmt *PragmaStatement) RewriteChildren(fn func(Node) Node) {
+	stmt.Pragma = fn(stmt.Pragma)
+}
+
 var _ Node = (*PragmaStatement)(nil)
 
 // }}}
@@ -248,6 +382,8 @@ var _ Node = (*PragmaStatement)(nil)
 // {{{
 
 type ImportStatement struct {
+	extent
+
 	ModuleName ModuleName
 	Pragmas    []Node
 	WS2        []Node
@@ -261,6 +397,8 @@ func (stmt *ImportStatement) Init() {
 }
 
 func (stmt *ImportStatement) Match(p *Parser) bool {
+	defer p.trackExtent(&stmt.extent)()
+
 	mark := p.Mark()
 	defer p.Forget(mark)
 
@@ -364,57 +502,249 @@ func (stmt *ImportStatement) WriteGoStringTo(out *strings.Builder) {
 func (stmt *ImportStatement) ComputeStringLengthEstimates() {
 }
 
+func (stmt *ImportStatement) Children() []Node {
+	list := make([]Node, len(stmt.Pragmas))
+	copy(list, stmt.Pragmas)
+	return list
+}
+
+func (stmt *ImportStatement) RewriteChildren(fn func(Node) Node) {
+	rewriteNodes(stmt.Pragmas, fn)
+}
+
 var _ Node = (*ImportStatement)(nil)
 
 // }}}
 
-// AnyStatement
+// BlockStatement
 // {{{
 
-type AnyStatement struct {
-	Token token.Token
+type BlockStatement struct {
+	extent
+
+	Statements []Node
+	WS         InternalWhitespaceRun
 }
 
-func (stmt *AnyStatement) Init() {
-	*stmt = AnyStatement{}
+func (stmt *BlockStatement) Init() {
+	*stmt = BlockStatement{}
 }
 
-func (stmt *AnyStatement) Match(p *Parser) bool {
-	p.Consume(&stmt.Token, tokenpredicate.Any())
+func (stmt *BlockStatement) Match(p *Parser) bool {
+	defer p.trackExtent(&stmt.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.LBrace)) {
+		p.Rewind(mark)
+		return false
+	}
+
+	stmt.Statements = make([]Node, 0, 8)
+
+	defer p.enterBlock(statementBlock)()
+	for {
+		inner := p.Mark()
+
+		var ws InternalWhitespaceRun
+		ws.Match(p)
+
+		if p.Consume(nil, tokenpredicate.Type(token.RBrace)) {
+			p.Forget(inner)
+			stmt.WS = ws
+			return true
+		}
+
+		p.Rewind(inner)
+		p.Forget(inner)
+
+		if p.Peek(nil, tokenpredicate.Type(token.EOF)) {
+			break
+		}
+
+		var child Node
+		if !MatchStatement(&child, p) {
+			break
+		}
+		stmt.Statements = append(stmt.Statements, child)
+	}
+
+	stmt.Init()
+	p.Rewind(mark)
+	return false
+}
+
+func (stmt *BlockStatement) String() string {
+	return util.StringImpl(stmt)
+}
+
+func (stmt *BlockStatement) GoString() string {
+	return util.GoStringImpl(stmt)
+}
+
+func (stmt *BlockStatement) EstimateStringLength() uint {
+	sum := 2 + stmt.WS.EstimateStringLength()
+	sum += sumStringLengthEstimates(stmt.Statements)
+	return sum
+}
+
+func (stmt *BlockStatement) EstimateGoStringLength() uint {
+	sum := 19 + stmt.WS.EstimateGoStringLength()
+	sum += sumGoStringLengthEstimates(stmt.Statements)
+	return sum
+}
+
+func (stmt *BlockStatement) WriteStringTo(out *strings.Builder) {
+	out.WriteByte('{')
+	writeStringsTo(out, stmt.Statements)
+	stmt.WS.WriteStringTo(out)
+	out.WriteByte('}')
+}
+
+func (stmt *BlockStatement) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("BlockStatement{")
+	out.WriteByte('[')
+	writeGoStringsTo(out, stmt.Statements)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	stmt.WS.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (stmt *BlockStatement) ComputeStringLengthEstimates() {
+	for _, child := range stmt.Statements {
+		child.ComputeStringLengthEstimates()
+	}
+	stmt.WS.ComputeStringLengthEstimates()
+}
+
+func (stmt *BlockStatement) Children() []Node {
+	list := make([]Node, len(stmt.Statements))
+	copy(list, stmt.Statements)
+	return list
+}
+
+func (stmt *BlockStatement) RewriteChildren(fn func(Node) Node) {
+	rewriteNodes(stmt.Statements, fn)
+}
+
+var _ Node = (*BlockStatement)(nil)
+
+// }}}
+
+// ErrorStatement
+// {{{
+
+// ErrorStatement holds the tokens that were skipped after a statement failed
+// to parse.  Skipping stops after the next ";" or line break, or before a
+// "}" that closes an enclosing block, whichever comes first.  Err is also
+// reported through Parser.Errors.
+type ErrorStatement struct {
+	extent
+
+	Tokens []token.Token
+	Err    *SyntaxError
+}
+
+func (stmt *ErrorStatement) Init() {
+	*stmt = ErrorStatement{}
+}
+
+func (stmt *ErrorStatement) Match(p *Parser) bool {
+	defer p.trackExtent(&stmt.extent)()
+
+	found, expected := p.Furthest()
+
+	depth := uint(0)
+	for {
+		var tok token.Token
+		if !p.Peek(&tok, tokenpredicate.Not(tokenpredicate.Type(token.EOF))) {
+			break
+		}
+		if tok.Type == token.RBrace && depth == 0 && len(stmt.Tokens) != 0 {
+			break
+		}
+
+		p.Consume(nil, tokenpredicate.Any())
+		stmt.Tokens = append(stmt.Tokens, tok)
+
+		if tok.Type == token.LBrace {
+			depth++
+		} else if tok.Type == token.RBrace && depth != 0 {
+			depth--
+		} else if (tok.Type == token.Semicolon || tok.Type == token.VWS) && depth == 0 {
+			break
+		}
+	}
+
+	if len(stmt.Tokens) == 0 {
+		return false
+	}
+
+	stmt.Err = &SyntaxError{
+		Start:    stmt.Tokens[0].Start,
+		End:      stmt.Tokens[len(stmt.Tokens)-1].End,
+		Found:    found,
+		Expected: expected,
+	}
+	p.EmitError(stmt.Err)
 	return true
 }
 
-func (stmt *AnyStatement) String() string {
+func (stmt *ErrorStatement) String() string {
 	return util.StringImpl(stmt)
 }
 
-func (stmt *AnyStatement) GoString() string {
+func (stmt *ErrorStatement) GoString() string {
 	return util.GoStringImpl(stmt)
 }
 
-func (stmt *AnyStatement) EstimateStringLength() uint {
-	return 11 + stmt.Token.EstimateStringLength()
+func (stmt *ErrorStatement) EstimateStringLength() uint {
+	var sum uint
+	for _, tok := range stmt.Tokens {
+		sum += uint(len(tok.Raw))
+	}
+	return sum
 }
 
-func (stmt *AnyStatement) EstimateGoStringLength() uint {
-	return 10 + stmt.Token.EstimateGoStringLength()
+func (stmt *ErrorStatement) EstimateGoStringLength() uint {
+	sum := 17 + uint(len(stmt.Tokens))
+	for _, tok := range stmt.Tokens {
+		sum += tok.EstimateGoStringLength()
+	}
+	return sum
 }
 
-func (stmt *AnyStatement) WriteStringTo(out *strings.Builder) {
-	out.WriteString("AnyStatement[")
-	stmt.Token.WriteStringTo(out)
-	out.WriteString("]\n")
+func (stmt *ErrorStatement) WriteStringTo(out *strings.Builder) {
+	for _, tok := range stmt.Tokens {
+		out.WriteString(string(tok.Raw))
+	}
 }
 
-func (stmt *AnyStatement) WriteGoStringTo(out *strings.Builder) {
-	out.WriteString("AnyStatement{")
-	stmt.Token.WriteGoStringTo(out)
+func (stmt *ErrorStatement) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("ErrorStatement{")
+	out.WriteByte('[')
+	for index, tok := range stmt.Tokens {
+		if index != 0 {
+			out.WriteByte(',')
+		}
+		tok.WriteGoStringTo(out)
+	}
+	out.WriteByte(']')
 	out.WriteByte('}')
 }
 
-func (stmt *AnyStatement) ComputeStringLengthEstimates() {
+func (stmt *ErrorStatement) ComputeStringLengthEstimates() {
+}
+
+func (stmt *ErrorStatement) Children() []Node {
+	return nil
+}
+
+func (stmt *ErrorStatement) RewriteChildren(fn func(Node) Node) {
 }
 
-var _ Node = (*AnyStatement)(nil)
+var _ Node = (*ErrorStatement)(nil)
 
 // }}}
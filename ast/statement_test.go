@@ -0,0 +1,222 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/chronos-tachyon/go-spiderscript/token"
+)
+
+func TestMatchStatement_Decl(t *testing.T) {
+	testParseRows(t, []parseTestRow{
+		{"alias foo = bar::baz\n", `File{AliasStatement{"foo",Symbol{ModuleName{"bar"},Identifier{"baz"}},[],InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},[],TerminalWhitespaceRun{VerticalWhitespace{1}}}}`},
+		{"type Tuple[A: type] = struct { first: A }\n", `File{TypeDeclStatement{"Tuple",GenericParameterList{[GenericParameter{"A",nil,InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},true}],[InternalWhitespaceRun{}],[InternalWhitespaceRun{}],InternalWhitespaceRun{}},TypeBodyExpr{TypeBodyStruct,nil,[FieldMember{[],"first",IdentifierExpr{nil,"A"},nil,[],[],[],InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{},InternalWhitespaceRun{},TerminalWhitespaceRun{HorizontalWhitespace{1}},false}],InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{},InternalWhitespaceRun{},InternalWhitespaceRun{}},[],InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},[],TerminalWhitespaceRun{VerticalWhitespace{1}},false}}`},
+		{"type MyType = Int;", `File{TypeDeclStatement{"MyType",nil,IdentifierExpr{nil,"Int"},[],InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},[],TerminalWhitespaceRun{StatementTerminatorWhitespace{}},false}}`},
+		{"let x = y", `File{VarDeclStatement{DeclLet,"x",nil,IdentifierExpr{nil,"y"},[],InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},[],TerminalWhitespaceRun{}}}`},
+		{"alias x = y #version(1,2,0)\n", ""},
+		{"type MyType: OtherType\n", ""},
+		{"type MyType: _ #version(1,0,0)\n", ""},
+		{"type Box = Container#[Int]\n", ""},
+		{"type Pair[A: type, B: type] = struct {\nfirst: A\nsecond: B\n}\n", ""},
+		{"type Buffer[N: U64, K: Order,] = struct { len: U64 }\n", ""},
+		{"const answer: Int = 42\n", ""},
+		{"var counter: U64;", ""},
+		{"let name = \"abc\" #deprecated()\n", ""},
+		{"var mySymbol: _ #version(1,0,0)\n", ""},
+	})
+}
+
+func TestMatchStatement_Func(t *testing.T) {
+	testParseRows(t, []parseTestRow{
+		{"func f()\n", `File{FuncDeclStatement{[],"f",nil,FuncParameterList{[],[],[],InternalWhitespaceRun{},InternalWhitespaceRun{},0,false},nil,nil,[],false}}`},
+		{"func f(a: Int; b: Bool) {}\n", `File{FuncDeclStatement{[],"f",nil,FuncParameterList{[Parameter{"a",IdentifierExpr{nil,"Int"},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}}},Parameter{"b",IdentifierExpr{nil,"Bool"},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}}}],[InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}}],[InternalWhitespaceRun{},InternalWhitespaceRun{}],InternalWhitespaceRun{},InternalWhitespaceRun{},1,true},nil,BlockStatement{[],InternalWhitespaceRun{}},[],false}}`},
+		{"static method T.new[U: type](): T;", `File{MethodDeclStatement{"T",[ModStatic],"new",GenericParameterList{[GenericParameter{"U",nil,InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},true}],[InternalWhitespaceRun{}],[InternalWhitespaceRun{}],InternalWhitespaceRun{}},FuncParameterList{[],[],[],InternalWhitespaceRun{},InternalWhitespaceRun{},0,false},IdentifierExpr{nil,"T"},nil,[],false}}`},
+		{"func add(x: Int, y: Int): Int {\nlet z = x + y\n}\n", ""},
+		{"func sum(xs: Int...): Int {}\n", ""},
+		{"func f(a: Int): Int { return a }\n", ""},
+		{"func open(path: String; mode: U32, create: Bool,): File\n", ""},
+		{"func flags(; verbose: Bool)\n", ""},
+		{"async generator func ticks[N: U64](interval: Duration): Int #version(1,0,0) {}\n", ""},
+		{"func operator +(a: Vec, b: Vec): Vec {}\n", ""},
+		{"method Vec.operator ==(other: Vec): Bool\n", ""},
+		{"property method Vec.length(): F64 {}\n", ""},
+		{"coroutine func run[T: type, K: MyEnum]() {}\n", ""},
+	})
+
+	file := testParseFile(t, "func f(a: Int, rest: Int...; verbose: Bool)\n")
+	if file != nil {
+		decl := file.Statements[0].(*FuncDeclStatement)
+		pos := decl.Params.Positional()
+		named := decl.Params.Named()
+		if len(pos) != 2 || pos[0].IsRepeated() || !pos[1].IsRepeated() {
+			t.Errorf("Positional(): unexpected %#v", pos)
+		}
+		if len(named) != 1 || named[0].Name != "verbose" {
+			t.Errorf("Named(): unexpected %#v", named)
+		}
+	}
+}
+
+func TestMatchStatement_Control(t *testing.T) {
+	testParseRows(t, []parseTestRow{
+		{"return\n", `File{ReturnStatement{nil,InternalWhitespaceRun{},InternalWhitespaceRun{},TerminalWhitespaceRun{VerticalWhitespace{1}}}}`},
+		{"break;", `File{BranchStatement{BranchBreak,"",InternalWhitespaceRun{},InternalWhitespaceRun{},TerminalWhitespaceRun{StatementTerminatorWhitespace{}}}}`},
+		{"while x {}\n", `File{WhileStatement{IdentifierExpr{nil,"x"},BlockStatement{[],InternalWhitespaceRun{}},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},TerminalWhitespaceRun{VerticalWhitespace{1}}}}`},
+		{"for i := 0; i < 10; i++ {}\n", `File{ForStatement{AssignmentStatement{DeclareAndAssign,IdentifierExpr{nil,"i"},&value.Number{+,_,0,<nil>,_,+,<nil>},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},TerminalWhitespaceRun{}},BinaryOperatorExpr{OpCmpLT,IdentifierExpr{nil,"i"},&value.Number{+,_,10,<nil>,_,+,<nil>},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}}},MutationStatement{MutateINC,IdentifierExpr{nil,"i"},InternalWhitespaceRun{},InternalWhitespaceRun{},TerminalWhitespaceRun{}},BlockStatement{[],InternalWhitespaceRun{}},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},TerminalWhitespaceRun{VerticalWhitespace{1}}}}`},
+		{"if x {\nf()\n}\n", ""},
+		{"if x {} else {}\n", ""},
+		{"if a {\nreturn x\n} else if b {\nreturn y\n} else {\nreturn\n}\n", ""},
+		{"for start(); i < n; next() {\ncontinue\n}\n", ""},
+		{"for ;; {}\n", ""},
+		{"for i = 0; i < n; i += 2 {\nf(i)\n}\n", ""},
+		{"for ; x; x-- { f() }\n", ""},
+		{"foreach (k, v): items {\nf(k, v)\n}\n", ""},
+		{"foreach _: items {}\n", ""},
+		{"switch x {\ncase 1, 2:\nf()\ncase _:\nbreak\n}\n", ""},
+		{"return x + y;", ""},
+		{"goto done\n", ""},
+		{"{\nlet x = y\n}", ""},
+		{"if a { b() } else if c { d() } else { e() }\n", ""},
+		{"while true { break }\n", ""},
+		{"switch x { case 1: y() }\n", ""},
+		{"switch x { case 1: y(); case _: z() }", ""},
+		{"{ let x = y; f(x) }", ""},
+		{"while ok{ step() }\n", `File{WhileStatement{IdentifierExpr{nil,"ok"},BlockStatement{[ExpressionStatement{CallExpr{IdentifierExpr{nil,"step"},ArgumentList{[],[],[],InternalWhitespaceRun{}}},InternalWhitespaceRun{HorizontalWhitespace{1}},TerminalWhitespaceRun{HorizontalWhitespace{1}}}],InternalWhitespaceRun{}},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{},TerminalWhitespaceRun{VerticalWhitespace{1}}}}`},
+		{"if ok{ step() }\n", ""},
+		{"foreach x: items{ f(x) }\n", ""},
+		{"switch v{ case 1: f() }\n", ""},
+		{"if (p == Point{x: 1}) { f() }\n", ""},
+		{"if f(Point{x: 1}) {}\n", ""},
+		{"while ok { let p = Point{x: 1} }\n", ""},
+	})
+
+	file := testParseFile(t, "switch x {\ncase 1:\nf()\ncase _:\ng()\n}\n")
+	if file != nil {
+		stmt := file.Statements[0].(*SwitchStatement)
+		if len(stmt.Cases) != 2 || stmt.Cases[0].(*CaseClause).IsDefault() || !stmt.Cases[1].(*CaseClause).IsDefault() {
+			t.Errorf("IsDefault(): unexpected %#v", stmt.Cases)
+		}
+	}
+}
+
+func TestMatchStatement_TypeBody(t *testing.T) {
+	testParseRows(t, []parseTestRow{
+		{"type T = struct {}\n", `File{TypeDeclStatement{"T",nil,TypeBodyExpr{TypeBodyStruct,nil,[],InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{},InternalWhitespaceRun{},InternalWhitespaceRun{}},[],InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},[],TerminalWhitespaceRun{VerticalWhitespace{1}},false}}`},
+		{"type T = struct { first: A, second: B }\n", `File{TypeDeclStatement{"T",nil,TypeBodyExpr{TypeBodyStruct,nil,[FieldMember{[],"first",IdentifierExpr{nil,"A"},nil,[],[],[],InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{},InternalWhitespaceRun{},TerminalWhitespaceRun{MemberSeparatorWhitespace{}},false},FieldMember{[],"second",IdentifierExpr{nil,"B"},nil,[],[],[],InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{},InternalWhitespaceRun{},TerminalWhitespaceRun{HorizontalWhitespace{1}},false}],InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{},InternalWhitespaceRun{},InternalWhitespaceRun{}},[],InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},[],TerminalWhitespaceRun{VerticalWhitespace{1}},false}}`},
+		{"type T = struct { a: Int; b: Int }\n", ""},
+		{"type T = struct {\na: Int,\nb: Int,\n}\n", ""},
+		{"type E = enum { A, B = 4, C }\n", ""},
+		{"type U = union { tag k: K; case A { x: Int, y: Int } }\n", ""},
+		{"type Point = struct {\n#align(8)\n#preserve_order()\nx: F64\ny: F64 #minsize(16)\n}\n", ""},
+		{"type Counter = struct {\nstatic instances: U64\nstatic const limit: U64 = 100\nconst zero: Int = 0\n#omit_copy()\n}\n", ""},
+		{"type Color = enum: S8 {\nRed = 0\nGreen = 1\nBlue\nBLUE = Blue\n}\n", ""},
+		{"type Flags = bitfield: U32 {\nRead = 1\nWrite = 2 #deprecated()\n}\n", ""},
+		{"type Shape = union {\ntag kind: ShapeKind\ncase Circle {\nradius: F64\n}\ncase Square {\nside: F64\n}\n}\n", ""},
+		{"type Reader = interface {\nname: String\nproperty size: U64\nfunc read(buf: Bytes): U64\n}\n", ""},
+		{"let p: struct {\nx: Int\n} = q\n", ""},
+	})
+
+	file := testParseFile(t, "type E = enum {\nA = 1\nB = A\nC\n}\n")
+	if file != nil {
+		body := file.Statements[0].(*TypeDeclStatement).Target.(*TypeBodyExpr)
+		if len(body.Members) != 4 {
+			t.Fatalf("Members: expected 4, actual %d: %#v", len(body.Members), body)
+		}
+		a := body.Members[1].(*EnumMember)
+		b := body.Members[2].(*EnumMember)
+		c := body.Members[3].(*EnumMember)
+		if a.IsAlias() || !b.IsAlias() || c.IsAlias() || c.Value != nil {
+			t.Errorf("IsAlias(): unexpected %#v", body.Members)
+		}
+	}
+}
+
+func TestMatchStatement_Assign(t *testing.T) {
+	testParseRows(t, []parseTestRow{
+		{"x = y\n", `File{AssignmentStatement{Assign,IdentifierExpr{nil,"x"},IdentifierExpr{nil,"y"},InternalWhitespaceRun{},InternalWhitespaceRun{HorizontalWhitespace{1}},InternalWhitespaceRun{HorizontalWhitespace{1}},TerminalWhitespaceRun{VerticalWhitespace{1}}}}`},
+		{"n++;", `File{MutationStatement{MutateINC,IdentifierExpr{nil,"n"},InternalWhitespaceRun{},InternalWhitespaceRun{},TerminalWhitespaceRun{StatementTerminatorWhitespace{}}}}`},
+		{"(a, _) = f()\n", ""},
+		{"(x, y) := (1, 2)\n", ""},
+		{"total += item.price * count\n", ""},
+		{"flags |= mask;", ""},
+		{"cache[key] ?= compute(key)\n", ""},
+		{"obj.field = null\n", ""},
+		{"bits~~\n", ""},
+		{"counter.value--\n", ""},
+	})
+
+	rejected := []string{
+		"1 = x\n",
+		"f() = x\n",
+		"(a, 1) = f()\n",
+		"(a, b) += f()\n",
+		"obj.field := x\n",
+		"f()++\n",
+	}
+
+	for _, input := range rejected {
+		p := newTestParser(input)
+		var file File
+		if !p.Parse(&file) {
+			t.Errorf("Parse(%q): failed to match", input)
+			continue
+		}
+		errs := p.Errors()
+		if len(errs) != 1 {
+			t.Errorf("Parse(%q): expected 1 error, actual %v", input, errs)
+			continue
+		}
+		if _, ok := errs[0].(*ParseError); !ok {
+			t.Errorf("Parse(%q): expected *ParseError, actual %#v", input, errs[0])
+		}
+	}
+}
+
+func TestMatchStatement_ErrorRecovery(t *testing.T) {
+	type testRow struct {
+		Input    string
+		Count    int
+		Recovery []int
+		Found    token.Type
+	}
+
+	data := []testRow{
+		{"let x = )\nlet y = 1\n", 2, []int{0}, token.RParen},
+		{"let x = ); let y = 1\n", 2, []int{0}, token.RParen},
+		{"func f() {\n\t) ) )\n\treturn x\n}\nlet y = 1\n", 2, nil, token.RParen},
+		{"let x = { ) }\nlet y = 1\n", 2, []int{0}, token.LBrace},
+	}
+
+	for _, row := range data {
+		p := newTestParser(row.Input)
+		var file File
+		if !p.Parse(&file) {
+			t.Errorf("Parse(%q): failed to match", row.Input)
+			continue
+		}
+		if len(file.Statements) != row.Count {
+			t.Errorf("Parse(%q): expected %d statements, actual %d: %#v", row.Input, row.Count, len(file.Statements), &file)
+			continue
+		}
+		for _, index := range row.Recovery {
+			if _, ok := file.Statements[index].(*ErrorStatement); !ok {
+				t.Errorf("Parse(%q): expected statement %d to be *ErrorStatement, actual %T", row.Input, index, file.Statements[index])
+			}
+		}
+		errs := p.Errors()
+		if len(errs) != 1 {
+			t.Errorf("Parse(%q): expected 1 error, actual %d: %v", row.Input, len(errs), errs)
+			continue
+		}
+		err, ok := errs[0].(*SyntaxError)
+		if !ok {
+			t.Errorf("Parse(%q): expected *SyntaxError, actual %T", row.Input, errs[0])
+			continue
+		}
+		if err.Found.Type != row.Found {
+			t.Errorf("Parse(%q): expected error at %v, actual %v", row.Input, row.Found, err.Found)
+		}
+		if len(err.Expected) == 0 {
+			t.Errorf("Parse(%q): expected a non-empty expected set: %v", row.Input, err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/chronos-tachyon/go-spiderscript/token"
+	"github.com/chronos-tachyon/go-spiderscript/tokenpredicate"
+)
+
+func newTestParser(input string) *Parser {
+	return NewParser(token.NewLexer("test.spider", []rune(input)))
+}
+
+func testParseExpr(t *testing.T, input string) Node {
+	t.Helper()
+	p := newTestParser(input)
+
+	var expr Node
+	if !MatchExpr(&expr, p) {
+		t.Errorf("MatchExpr(%q): failed to match", input)
+		return nil
+	}
+
+	var tok token.Token
+	if !p.Peek(&tok, tokenpredicate.Type(token.EOF)) {
+		p.Peek(&tok, tokenpredicate.Any())
+		t.Errorf("MatchExpr(%q): unexpected trailing %v", input, tok)
+		return nil
+	}
+
+	for _, err := range p.Errors() {
+		t.Errorf("MatchExpr(%q): unexpected error: %v", input, err)
+	}
+
+	return expr
+}
+
+func testParseFile(t *testing.T, input string) *File {
+	t.Helper()
+	p := newTestParser(input)
+
+	var file File
+	if !p.Parse(&file) {
+		t.Errorf("Parse(%q): failed to match", input)
+		return nil
+	}
+
+	for _, err := range p.Errors() {
+		t.Errorf("Parse(%q): unexpected error: %v", input, err)
+	}
+
+	return &file
+}
+
+// parseTestRow is one input for testMatchExprRows or testParseRows.  The
+// input must format back to itself and, unless GoString is empty, its parse
+// tree must have that GoString.
+type parseTestRow struct {
+	Input    string
+	GoString string
+}
+
+// testMatchExprRows checks that each input is one expression, and returns
+// the expressions, with nil for the inputs that failed to parse.
+func testMatchExprRows(t *testing.T, rows []parseTestRow) []Node {
+	t.Helper()
+	out := make([]Node, len(rows))
+	for index, row := range rows {
+		expr := testParseExpr(t, row.Input)
+		if expr == nil {
+			continue
+		}
+		if actual := expr.String(); actual != row.Input {
+			t.Errorf("MatchExpr(%q).String(): expected %q, actual %q", row.Input, row.Input, actual)
+		}
+		if actual := expr.GoString(); row.GoString != "" && actual != row.GoString {
+			t.Errorf("MatchExpr(%q).GoString(): expected %s, actual %s", row.Input, row.GoString, actual)
+		}
+		out[index] = expr
+	}
+	return out
+}
+
+// testParseRows checks that each input is a file of one statement.
+func testParseRows(t *testing.T, rows []parseTestRow) {
+	t.Helper()
+	for _, row := range rows {
+		file := testParseFile(t, row.Input)
+		if file == nil {
+			continue
+		}
+		if len(file.Statements) != 1 {
+			t.Errorf("Parse(%q): expected 1 statement, actual %d: %#v", row.Input, len(file.Statements), file)
+			continue
+		}
+		if actual := file.String(); actual != row.Input {
+			t.Errorf("Parse(%q).String(): expected %q, actual %q", row.Input, row.Input, actual)
+		}
+		if actual := file.GoString(); row.GoString != "" && actual != row.GoString {
+			t.Errorf("Parse(%q).GoString(): expected %s, actual %s", row.Input, row.GoString, actual)
+		}
+	}
+}
@@ -0,0 +1,1091 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chronos-tachyon/go-spiderscript/internal/util"
+	"github.com/chronos-tachyon/go-spiderscript/token"
+	"github.com/chronos-tachyon/go-spiderscript/tokenpredicate"
+)
+
+// matchTypeMember matches one line inside the braces of a TypeBodyExpr or a
+// UnionCaseMember.  Which member forms are accepted depends on kind.
+func matchTypeMember(ptr *Node, p *Parser, kind TypeBodyKind) bool {
+	member0 := &EmptyStatement{}
+	if member0.Match(p) {
+		*ptr = member0
+		return true
+	}
+
+	member1 := &PragmaStatement{}
+	if member1.Match(p) {
+		*ptr = member1
+		return true
+	}
+
+	member2 := &FieldMember{}
+	if member2.Match(p) {
+		*ptr = member2
+		return true
+	}
+
+	switch kind {
+	case TypeBodyUnion:
+		member3 := &UnionTagMember{}
+		if member3.Match(p) {
+			*ptr = member3
+			return true
+		}
+
+		member4 := &UnionCaseMember{}
+		if member4.Match(p) {
+			*ptr = member4
+			return true
+		}
+
+	case TypeBodyEnum, TypeBodyBitfield:
+		member3 := &EnumMember{}
+		if member3.Match(p) {
+			*ptr = member3
+			return true
+		}
+
+	case TypeBodyInterface:
+		member3 := &FuncDeclStatement{}
+		if member3.Match(p) {
+			*ptr = member3
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchTypeMembers matches "{", any number of members, and "}".  On
+// failure, the caller is responsible for rewinding.
+func matchTypeMembers(p *Parser, kind TypeBodyKind, members *[]Node, ws *InternalWhitespaceRun) bool {
+	if !p.Consume(nil, tokenpredicate.Type(token.LBrace)) {
+		return false
+	}
+
+	*members = make([]Node, 0, 8)
+
+	defer p.enterBlock(memberBlock)()
+	for {
+		inner := p.Mark()
+
+		var ws0 InternalWhitespaceRun
+		ws0.Match(p)
+
+		if p.Consume(nil, tokenpredicate.Type(token.RBrace)) {
+			p.Forget(inner)
+			*ws = ws0
+			return true
+		}
+
+		p.Rewind(inner)
+		p.Forget(inner)
+
+		if p.Peek(nil, tokenpredicate.Type(token.EOF)) {
+			return false
+		}
+
+		var member Node
+		if !matchTypeMember(&member, p, kind) {
+			return false
+		}
+		*members = append(*members, member)
+	}
+}
+
+func computeTypeMembersStringLengthEstimates(members []Node) {
+	for _, member := range members {
+		member.ComputeStringLengthEstimates()
+	}
+}
+
+// TypeBodyKind
+// {{{
+
+type TypeBodyKind uint8
+
+const (
+	InvalidTypeBodyKind TypeBodyKind = iota
+	TypeBodyStruct
+	TypeBodyUnion
+	TypeBodyEnum
+	TypeBodyBitfield
+	TypeBodyInterface
+)
+
+var typeBodyKindKeywords = []string{
+	"???",
+	"struct",
+	"union",
+	"enum",
+	"bitfield",
+	"interface",
+}
+
+var typeBodyKindNames = []string{
+	"InvalidTypeBodyKind",
+	"TypeBodyStruct",
+	"TypeBodyUnion",
+	"TypeBodyEnum",
+	"TypeBodyBitfield",
+	"TypeBodyInterface",
+}
+
+var typeBodyKindMap = map[token.Type]TypeBodyKind{
+	token.KeywordStruct:    TypeBodyStruct,
+	token.KeywordUnion:     TypeBodyUnion,
+	token.KeywordBitfield:  TypeBodyBitfield,
+	token.KeywordInterface: TypeBodyInterface,
+}
+
+func (kind TypeBodyKind) String() string {
+	if uint(kind) >= uint(len(typeBodyKindKeywords)) {
+		kind = 0
+	}
+	return typeBodyKindKeywords[kind]
+}
+
+func (kind TypeBodyKind) GoString() string {
+	if uint(kind) >= uint(len(typeBodyKindNames)) {
+		return fmt.Sprintf("TypeBodyKind(%d)", uint(kind))
+	}
+	return typeBodyKindNames[kind]
+}
+
+// HasUnderlyingKind returns true if the type body may be preceded by ": T",
+// naming the integer type that backs each value.
+func (kind TypeBodyKind) HasUnderlyingKind() bool {
+	return kind == TypeBodyEnum || kind == TypeBodyBitfield
+}
+
+var _ fmt.Stringer = TypeBodyKind(0)
+var _ fmt.GoStringer = TypeBodyKind(0)
+
+// }}}
+
+// TypeBodyExpr
+// {{{
+
+// TypeBodyExpr is an anonymous "struct { ... }", "union { ... }",
+// "enum { ... }", "bitfield { ... }" or "interface { ... }" type.  Enums and
+// bitfields may name their underlying integer type, as in "enum: S8 { ... }",
+// in which case Underlying is non-nil.  Each member corresponds to one
+// exprtree.Statement; type-level pragmas such as "#align(+8)" and
+// "#omit_copy()" appear as PragmaStatement members.
+type TypeBodyExpr struct {
+	extent
+
+	Kind       TypeBodyKind
+	Underlying Node
+	Members    []Node
+	WS0        InternalWhitespaceRun
+	WS1        InternalWhitespaceRun
+	WS2        InternalWhitespaceRun
+	WS3        InternalWhitespaceRun
+}
+
+func (expr *TypeBodyExpr) Init() {
+	*expr = TypeBodyExpr{}
+}
+
+func (expr *TypeBodyExpr) Match(p *Parser) bool {
+	defer p.trackExtent(&expr.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	var tok token.Token
+	switch {
+	case p.Consume(nil, tokenpredicate.And(tokenpredicate.Type(token.Identifier), tokenpredicate.Literal("enum"))):
+		expr.Kind = TypeBodyEnum
+	case p.Consume(&tok, tokenpredicate.Or(
+		tokenpredicate.Type(token.KeywordStruct),
+		tokenpredicate.Type(token.KeywordUnion),
+		tokenpredicate.Type(token.KeywordBitfield),
+		tokenpredicate.Type(token.KeywordInterface),
+	)):
+		expr.Kind = typeBodyKindMap[tok.Type]
+	default:
+		expr.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	expr.WS0.Match(p)
+
+	if expr.Kind.HasUnderlyingKind() && p.Consume(nil, tokenpredicate.Type(token.Colon)) {
+		expr.WS1.Match(p)
+		if !matchTypeExpr(&expr.Underlying, p) {
+			expr.Init()
+			p.Rewind(mark)
+			return false
+		}
+		expr.WS2.Match(p)
+	}
+
+	if !matchTypeMembers(p, expr.Kind, &expr.Members, &expr.WS3) {
+		expr.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (expr *TypeBodyExpr) String() string {
+	return util.StringImpl(expr)
+}
+
+func (expr *TypeBodyExpr) GoString() string {
+	return util.GoStringImpl(expr)
+}
+
+func (expr *TypeBodyExpr) EstimateStringLength() uint {
+	sum := 11 + sumStringLengthEstimates(expr.Members)
+	if expr.Underlying != nil {
+		sum += 1 + expr.Underlying.EstimateStringLength()
+	}
+	sum += expr.WS0.EstimateStringLength()
+	sum += expr.WS1.EstimateStringLength()
+	sum += expr.WS2.EstimateStringLength()
+	sum += expr.WS3.EstimateStringLength()
+	return sum
+}
+
+func (expr *TypeBodyExpr) EstimateGoStringLength() uint {
+	sum := 40 + sumGoStringLengthEstimates(expr.Members)
+	if expr.Underlying != nil {
+		sum += expr.Underlying.EstimateGoStringLength()
+	}
+	sum += expr.WS0.EstimateGoStringLength()
+	sum += expr.WS1.EstimateGoStringLength()
+	sum += expr.WS2.EstimateGoStringLength()
+	sum += expr.WS3.EstimateGoStringLength()
+	return sum
+}
+
+func (expr *TypeBodyExpr) WriteStringTo(out *strings.Builder) {
+	out.WriteString(expr.Kind.String())
+	expr.WS0.WriteStringTo(out)
+	if expr.Underlying != nil {
+		out.WriteByte(':')
+		expr.WS1.WriteStringTo(out)
+		expr.Underlying.WriteStringTo(out)
+		expr.WS2.WriteStringTo(out)
+	}
+	out.WriteByte('{')
+	writeStringsTo(out, expr.Members)
+	expr.WS3.WriteStringTo(out)
+	out.WriteByte('}')
+}
+
+func (expr *TypeBodyExpr) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("TypeBodyExpr{")
+	out.WriteString(expr.Kind.GoString())
+	out.WriteByte(',')
+	writeOptionalGoStringTo(out, expr.Underlying)
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, expr.Members)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	expr.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	expr.WS1.WriteGoStringTo(out)
+	out.WriteByte(',')
+	expr.WS2.WriteGoStringTo(out)
+	out.WriteByte(',')
+	expr.WS3.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (expr *TypeBodyExpr) ComputeStringLengthEstimates() {
+	if expr.Underlying != nil {
+		expr.Underlying.ComputeStringLengthEstimates()
+	}
+	computeTypeMembersStringLengthEstimates(expr.Members)
+	expr.WS0.ComputeStringLengthEstimates()
+	expr.WS1.ComputeStringLengthEstimates()
+	expr.WS2.ComputeStringLengthEstimates()
+	expr.WS3.ComputeStringLengthEstimates()
+}
+
+func (expr *TypeBodyExpr) Children() []Node {
+	list := make([]Node, 0, 1+len(expr.Members))
+	if expr.Underlying != nil {
+		list = append(list, expr.Underlying)
+	}
+	list = append(list, expr.Members...)
+	return list
+}
+
+func (expr *TypeBodyExpr) RewriteChildren(fn func(Node) Node) {
+	if expr.Underlying != nil {
+		expr.Underlying = fn(expr.Underlying)
+	}
+	rewriteNodes(expr.Members, fn)
+}
+
+var _ Node = (*TypeBodyExpr)(nil)
+
+// }}}
+
+// FieldMember
+// {{{
+
+// FieldMember is "name: Type", optionally preceded by "static" and/or
+// "property" and optionally by "const".  A const field must have a value,
+// as in "const zero: Int = +0", and other fields must not.
+type FieldMember struct {
+	extent
+
+	Modifiers []FuncModifier
+	Name      string
+	Type      Node
+	Value     Node
+	Pragmas   []Node
+	WS1       []Node
+	WS7       []Node
+	WS0       InternalWhitespaceRun
+	WS2       InternalWhitespaceRun
+	WS3       InternalWhitespaceRun
+	WS4       InternalWhitespaceRun
+	WS5       InternalWhitespaceRun
+	WS6       InternalWhitespaceRun
+	WS8       TerminalWhitespaceRun
+	IsConst   bool
+}
+
+func (member *FieldMember) Init() {
+	*member = FieldMember{}
+}
+
+func (member *FieldMember) Match(p *Parser) bool {
+	defer p.trackExtent(&member.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	member.WS0.Match(p)
+
+	for {
+		var tok token.Token
+		if !p.Consume(&tok, tokenpredicate.Or(
+			tokenpredicate.Type(token.KeywordStatic),
+			tokenpredicate.Type(token.KeywordProperty),
+		)) {
+			break
+		}
+
+		var ws InternalWhitespaceRun
+		if !ws.Match(p) {
+			member.Init()
+			p.Rewind(mark)
+			return false
+		}
+
+		member.Modifiers = append(member.Modifiers, funcModifierMap[tok.Type])
+		member.WS1 = append(member.WS1, &ws)
+	}
+
+	if p.Consume(nil, tokenpredicate.Type(token.KeywordConst)) {
+		member.IsConst = true
+		if !member.WS2.Match(p) {
+			member.Init()
+			p.Rewind(mark)
+			return false
+		}
+	}
+
+	if !matchDeclName(&member.Name, p) {
+		member.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	member.WS3.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.Colon)) {
+		member.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	member.WS4.Match(p)
+
+	if !matchTypeExpr(&member.Type, p) {
+		member.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if member.IsConst {
+		member.WS5.Match(p)
+
+		if !p.Consume(nil, tokenpredicate.Type(token.Equal)) {
+			member.Init()
+			p.Rewind(mark)
+			return false
+		}
+
+		member.WS6.Match(p)
+
+		if !MatchExpr(&member.Value, p) {
+			member.Init()
+			p.Rewind(mark)
+			return false
+		}
+	}
+
+	if !matchTrailingPragmas(p, &member.Pragmas, &member.WS7, &member.WS8) {
+		member.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (member *FieldMember) HasModifier(mod FuncModifier) bool {
+	for _, m := range member.Modifiers {
+		if m == mod {
+			return true
+		}
+	}
+	return false
+}
+
+func (member *FieldMember) String() string {
+	return util.StringImpl(member)
+}
+
+func (member *FieldMember) GoString() string {
+	return util.GoStringImpl(member)
+}
+
+func (member *FieldMember) EstimateStringLength() uint {
+	sum := 1 + uint(len(member.Name)) + member.Type.EstimateStringLength()
+	for _, mod := range member.Modifiers {
+		sum += uint(len(mod.String()))
+	}
+	if member.IsConst {
+		sum += 6 + member.Value.EstimateStringLength()
+	}
+	sum += sumStringLengthEstimates(member.Pragmas)
+	sum += sumStringLengthEstimates(member.WS1)
+	sum += sumStringLengthEstimates(member.WS7)
+	sum += member.WS0.EstimateStringLength()
+	sum += member.WS2.EstimateStringLength()
+	sum += member.WS3.EstimateStringLength()
+	sum += member.WS4.EstimateStringLength()
+	sum += member.WS5.EstimateStringLength()
+	sum += member.WS6.EstimateStringLength()
+	sum += member.WS8.EstimateStringLength()
+	return sum
+}
+
+func (member *FieldMember) EstimateGoStringLength() uint {
+	sum := 32 + uint(len(member.Name)) + member.Type.EstimateGoStringLength()
+	for _, mod := range member.Modifiers {
+		sum += 1 + uint(len(mod.GoString()))
+	}
+	if member.Value != nil {
+		sum += member.Value.EstimateGoStringLength()
+	}
+	sum += sumGoStringLengthEstimates(member.Pragmas)
+	sum += sumGoStringLengthEstimates(member.WS1)
+	sum += sumGoStringLengthEstimates(member.WS7)
+	sum += member.WS0.EstimateGoStringLength()
+	sum += member.WS2.EstimateGoStringLength()
+	sum += member.WS3.EstimateGoStringLength()
+	sum += member.WS4.EstimateGoStringLength()
+	sum += member.WS5.EstimateGoStringLength()
+	sum += member.WS6.EstimateGoStringLength()
+	sum += member.WS8.EstimateGoStringLength()
+	return sum
+}
+
+func (member *FieldMember) WriteStringTo(out *strings.Builder) {
+	member.WS0.WriteStringTo(out)
+	for i := uint(0); i < uint(len(member.Modifiers)); i++ {
+		out.WriteString(member.Modifiers[i].String())
+		member.WS1[i].WriteStringTo(out)
+	}
+	if member.IsConst {
+		out.WriteString("const")
+		member.WS2.WriteStringTo(out)
+	}
+	out.WriteString(member.Name)
+	member.WS3.WriteStringTo(out)
+	out.WriteByte(':')
+	member.WS4.WriteStringTo(out)
+	member.Type.WriteStringTo(out)
+	if member.Value != nil {
+		member.WS5.WriteStringTo(out)
+		out.WriteByte('=')
+		member.WS6.WriteStringTo(out)
+		member.Value.WriteStringTo(out)
+	}
+	writePragmasTo(out, member.Pragmas, member.WS7)
+	member.WS8.WriteStringTo(out)
+}
+
+func (member *FieldMember) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("FieldMember{")
+	out.WriteByte('[')
+	for index, mod := range member.Modifiers {
+		if index != 0 {
+			out.WriteByte(',')
+		}
+		out.WriteString(mod.GoString())
+	}
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteString(strconv.Quote(member.Name))
+	out.WriteByte(',')
+	member.Type.WriteGoStringTo(out)
+	out.WriteByte(',')
+	writeOptionalGoStringTo(out, member.Value)
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, member.Pragmas)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, member.WS1)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, member.WS7)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	member.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	member.WS2.WriteGoStringTo(out)
+	out.WriteByte(',')
+	member.WS3.WriteGoStringTo(out)
+	out.WriteByte(',')
+	member.WS4.WriteGoStringTo(out)
+	out.WriteByte(',')
+	member.WS5.WriteGoStringTo(out)
+	out.WriteByte(',')
+	member.WS6.WriteGoStringTo(out)
+	out.WriteByte(',')
+	member.WS8.WriteGoStringTo(out)
+	out.WriteByte(',')
+	out.WriteString(strconv.FormatBool(member.IsConst))
+	out.WriteByte('}')
+}
+
+func (member *FieldMember) ComputeStringLengthEstimates() {
+	member.Type.ComputeStringLengthEstimates()
+	if member.Value != nil {
+		member.Value.ComputeStringLengthEstimates()
+	}
+	for i := uint(0); i < uint(len(member.Modifiers)); i++ {
+		member.WS1[i].ComputeStringLengthEstimates()
+	}
+	for i := uint(0); i < uint(len(member.Pragmas)); i++ {
+		member.Pragmas[i].ComputeStringLengthEstimates()
+		member.WS7[i].ComputeStringLengthEstimates()
+	}
+	member.WS0.ComputeStringLengthEstimates()
+	member.WS2.ComputeStringLengthEstimates()
+	member.WS3.ComputeStringLengthEstimates()
+	member.WS4.ComputeStringLengthEstimates()
+	member.WS5.ComputeStringLengthEstimates()
+	member.WS6.ComputeStringLengthEstimates()
+	member.WS8.ComputeStringLengthEstimates()
+}
+
+func (member *FieldMember) Children() []Node {
+	list := make([]Node, 0, 2+len(member.Pragmas))
+	list = append(list, member.Type)
+	if member.Value != nil {
+		list = append(list, member.Value)
+	}
+	list = append(list, member.Pragmas...)
+	return list
+}
+
+func (member *FieldMember) RewriteChildren(fn func(Node) Node) {
+	member.Type = fn(member.Type)
+	if member.Value != nil {
+		member.Value = fn(member.Value)
+	}
+	rewriteNodes(member.Pragmas, fn)
+}
+
+var _ Node = (*FieldMember)(nil)
+
+// }}}
+
+// EnumMember
+// {{{
+
+// EnumMember is one item of an enum or bitfield: "Name", "Name = +1" or
+// "Name = OtherName".  Value is nil if the item is numbered implicitly.
+type EnumMember struct {
+	extent
+
+	Name    string
+	Value   Node
+	Pragmas []Node
+	WS3     []Node
+	WS0     InternalWhitespaceRun
+	WS1     InternalWhitespaceRun
+	WS2     InternalWhitespaceRun
+	WS4     TerminalWhitespaceRun
+}
+
+func (member *EnumMember) Init() {
+	*member = EnumMember{}
+}
+
+func (member *EnumMember) Match(p *Parser) bool {
+	defer p.trackExtent(&member.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	member.WS0.Match(p)
+
+	if !matchDeclName(&member.Name, p) {
+		member.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	inner := p.Mark()
+	member.WS1.Match(p)
+	if p.Consume(nil, tokenpredicate.Type(token.Equal)) {
+		member.WS2.Match(p)
+		if !MatchExpr(&member.Value, p) {
+			p.Forget(inner)
+			member.Init()
+			p.Rewind(mark)
+			return false
+		}
+	} else {
+		member.WS1.Init()
+		p.Rewind(inner)
+	}
+	p.Forget(inner)
+
+	if !matchTrailingPragmas(p, &member.Pragmas, &member.WS3, &member.WS4) {
+		member.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+// IsAlias returns true if this item is another name for an existing item.
+func (member *EnumMember) IsAlias() bool {
+	_, ok := member.Value.(*IdentifierExpr)
+	return ok
+}
+
+func (member *EnumMember) String() string {
+	return util.StringImpl(member)
+}
+
+func (member *EnumMember) GoString() string {
+	return util.GoStringImpl(member)
+}
+
+func (member *EnumMember) EstimateStringLength() uint {
+	sum := uint(len(member.Name))
+	if member.Value != nil {
+		sum += 1 + member.Value.EstimateStringLength()
+	}
+	sum += sumStringLengthEstimates(member.Pragmas)
+	sum += sumStringLengthEstimates(member.WS3)
+	sum += member.WS0.EstimateStringLength()
+	sum += member.WS1.EstimateStringLength()
+	sum += member.WS2.EstimateStringLength()
+	sum += member.WS4.EstimateStringLength()
+	return sum
+}
+
+func (member *EnumMember) EstimateGoStringLength() uint {
+	sum := 22 + uint(len(member.Name))
+	if member.Value != nil {
+		sum += member.Value.EstimateGoStringLength()
+	}
+	sum += sumGoStringLengthEstimates(member.Pragmas)
+	sum += sumGoStringLengthEstimates(member.WS3)
+	sum += member.WS0.EstimateGoStringLength()
+	sum += member.WS1.EstimateGoStringLength()
+	sum += member.WS2.EstimateGoStringLength()
+	sum += member.WS4.EstimateGoStringLength()
+	return sum
+}
+
+func (member *EnumMember) WriteStringTo(out *strings.Builder) {
+	member.WS0.WriteStringTo(out)
+	out.WriteString(member.Name)
+	if member.Value != nil {
+		member.WS1.WriteStringTo(out)
+		out.WriteByte('=')
+		member.WS2.WriteStringTo(out)
+		member.Value.WriteStringTo(out)
+	}
+	writePragmasTo(out, member.Pragmas, member.WS3)
+	member.WS4.WriteStringTo(out)
+}
+
+func (member *EnumMember) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("EnumMember{")
+	out.WriteString(strconv.Quote(member.Name))
+	out.WriteByte(',')
+	writeOptionalGoStringTo(out, member.Value)
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, member.Pragmas)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, member.WS3)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	member.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	member.WS1.WriteGoStringTo(out)
+	out.WriteByte(',')
+	member.WS2.WriteGoStringTo(out)
+	out.WriteByte(',')
+	member.WS4.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (member *EnumMember) ComputeStringLengthEstimates() {
+	if member.Value != nil {
+		member.Value.ComputeStringLengthEstimates()
+	}
+	for i := uint(0); i < uint(len(member.Pragmas)); i++ {
+		member.Pragmas[i].ComputeStringLengthEstimates()
+		member.WS3[i].ComputeStringLengthEstimates()
+	}
+	member.WS0.ComputeStringLengthEstimates()
+	member.WS1.ComputeStringLengthEstimates()
+	member.WS2.ComputeStringLengthEstimates()
+	member.WS4.ComputeStringLengthEstimates()
+}
+
+func (member *EnumMember) Children() []Node {
+	list := make([]Node, 0, 1+len(member.Pragmas))
+	if member.Value != nil {
+		list = append(list, member.Value)
+	}
+	list = append(list, member.Pragmas...)
+	return list
+}
+
+func (member *EnumMember) RewriteChildren(fn func(Node) Node) {
+	if member.Value != nil {
+		member.Value = fn(member.Value)
+	}
+	rewriteNodes(member.Pragmas, fn)
+}
+
+var _ Node = (*EnumMember)(nil)
+
+// }}}
+
+// UnionTagMember
+// {{{
+
+// UnionTagMember is "tag name: EnumType", which declares the discriminator
+// of a union.  As with "break", "tag" is not a reserved word.
+type UnionTagMember struct {
+	extent
+
+	Name string
+	Type Node
+	WS0  InternalWhitespaceRun
+	WS1  InternalWhitespaceRun
+	WS2  InternalWhitespaceRun
+	WS3  InternalWhitespaceRun
+	WS4  TerminalWhitespaceRun
+}
+
+func (member *UnionTagMember) Init() {
+	*member = UnionTagMember{}
+}
+
+func (member *UnionTagMember) Match(p *Parser) bool {
+	defer p.trackExtent(&member.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	member.WS0.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.And(tokenpredicate.Type(token.Identifier), tokenpredicate.Literal("tag"))) {
+		member.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !member.WS1.Match(p) || !matchDeclName(&member.Name, p) {
+		member.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	member.WS2.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.Colon)) {
+		member.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	member.WS3.Match(p)
+
+	if !matchTypeExpr(&member.Type, p) {
+		member.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !member.WS4.Match(p) {
+		member.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (member *UnionTagMember) String() string {
+	return util.StringImpl(member)
+}
+
+func (member *UnionTagMember) GoString() string {
+	return util.GoStringImpl(member)
+}
+
+func (member *UnionTagMember) EstimateStringLength() uint {
+	sum := 4 + uint(len(member.Name)) + member.Type.EstimateStringLength()
+	sum += member.WS0.EstimateStringLength()
+	sum += member.WS1.EstimateStringLength()
+	sum += member.WS2.EstimateStringLength()
+	sum += member.WS3.EstimateStringLength()
+	sum += member.WS4.EstimateStringLength()
+	return sum
+}
+
+func (member *UnionTagMember) EstimateGoStringLength() uint {
+	sum := 23 + uint(len(member.Name)) + member.Type.EstimateGoStringLength()
+	sum += member.WS0.EstimateGoStringLength()
+	sum += member.WS1.EstimateGoStringLength()
+	sum += member.WS2.EstimateGoStringLength()
+	sum += member.WS3.EstimateGoStringLength()
+	sum += member.WS4.EstimateGoStringLength()
+	return sum
+}
+
+func (member *UnionTagMember) WriteStringTo(out *strings.Builder) {
+	member.WS0.WriteStringTo(out)
+	out.WriteString("tag")
+	member.WS1.WriteStringTo(out)
+	out.WriteString(member.Name)
+	member.WS2.WriteStringTo(out)
+	out.WriteByte(':')
+	member.WS3.WriteStringTo(out)
+	member.Type.WriteStringTo(out)
+	member.WS4.WriteStringTo(out)
+}
+
+func (member *UnionTagMember) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("UnionTagMember{")
+	out.WriteString(strconv.Quote(member.Name))
+	out.WriteByte(',')
+	member.Type.WriteGoStringTo(out)
+	out.WriteByte(',')
+	member.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	member.WS1.WriteGoStringTo(out)
+	out.WriteByte(',')
+	member.WS2.WriteGoStringTo(out)
+	out.WriteByte(',')
+	member.WS3.WriteGoStringTo(out)
+	out.WriteByte(',')
+	member.WS4.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (member *UnionTagMember) ComputeStringLengthEstimates() {
+	member.Type.ComputeStringLengthEstimates()
+	member.WS0.ComputeStringLengthEstimates()
+	member.WS1.ComputeStringLengthEstimates()
+	member.WS2.ComputeStringLengthEstimates()
+	member.WS3.ComputeStringLengthEstimates()
+	member.WS4.ComputeStringLengthEstimates()
+}
+
+func (member *UnionTagMember) Children() []Node {
+	list := make([]Node, 0, 1)
+	list = append(list, member.Type)
+	return list
+}
+
+func (member *UnionTagMember) RewriteChildren(fn func(Node) Node) {
+	member.Type = fn(member.Type)
+}
+
+var _ Node = (*UnionTagMember)(nil)
+
+// }}}
+
+// UnionCaseMember
+// {{{
+
+// UnionCaseMember is "case Item { ... }", which lists the fields that are
+// present when the union's tag is Item.  Its members use struct syntax.
+type UnionCaseMember struct {
+	extent
+
+	Tag     string
+	Members []Node
+	WS0     InternalWhitespaceRun
+	WS1     InternalWhitespaceRun
+	WS2     InternalWhitespaceRun
+	WS3     InternalWhitespaceRun
+	WS4     TerminalWhitespaceRun
+}
+
+func (member *UnionCaseMember) Init() {
+	*member = UnionCaseMember{}
+}
+
+func (member *UnionCaseMember) Match(p *Parser) bool {
+	defer p.trackExtent(&member.extent)()
+
+	mark := p.Mark()
+	defer p.Forget(mark)
+
+	member.WS0.Match(p)
+
+	if !p.Consume(nil, tokenpredicate.Type(token.KeywordCase)) {
+		member.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !member.WS1.Match(p) || !matchDeclName(&member.Tag, p) {
+		member.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	member.WS2.Match(p)
+
+	if !matchTypeMembers(p, TypeBodyStruct, &member.Members, &member.WS3) {
+		member.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	if !member.WS4.Match(p) {
+		member.Init()
+		p.Rewind(mark)
+		return false
+	}
+
+	return true
+}
+
+func (member *UnionCaseMember) String() string {
+	return util.StringImpl(member)
+}
+
+func (member *UnionCaseMember) GoString() string {
+	return util.GoStringImpl(member)
+}
+
+func (member *UnionCaseMember) EstimateStringLength() uint {
+	sum := 6 + uint(len(member.Tag)) + sumStringLengthEstimates(member.Members)
+	sum += member.WS0.EstimateStringLength()
+	sum += member.WS1.EstimateStringLength()
+	sum += member.WS2.EstimateStringLength()
+	sum += member.WS3.EstimateStringLength()
+	sum += member.WS4.EstimateStringLength()
+	return sum
+}
+
+func (member *UnionCaseMember) EstimateGoStringLength() uint {
+	sum := 26 + uint(len(member.Tag)) + sumGoStringLengthEstimates(member.Members)
+	sum += member.WS0.EstimateGoStringLength()
+	sum += member.WS1.EstimateGoStringLength()
+	sum += member.WS2.EstimateGoStringLength()
+	sum += member.WS3.EstimateGoStringLength()
+	sum += member.WS4.EstimateGoStringLength()
+	return sum
+}
+
+func (member *UnionCaseMember) WriteStringTo(out *strings.Builder) {
+	member.WS0.WriteStringTo(out)
+	out.WriteString("case")
+	member.WS1.WriteStringTo(out)
+	out.WriteString(member.Tag)
+	member.WS2.WriteStringTo(out)
+	out.WriteByte('{')
+	writeStringsTo(out, member.Members)
+	member.WS3.WriteStringTo(out)
+	out.WriteByte('}')
+	member.WS4.WriteStringTo(out)
+}
+
+func (member *UnionCaseMember) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("UnionCaseMember{")
+	out.WriteString(strconv.Quote(member.Tag))
+	out.WriteByte(',')
+	out.WriteByte('[')
+	writeGoStringsTo(out, member.Members)
+	out.WriteByte(']')
+	out.WriteByte(',')
+	member.WS0.WriteGoStringTo(out)
+	out.WriteByte(',')
+	member.WS1.WriteGoStringTo(out)
+	out.WriteByte(',')
+	member.WS2.WriteGoStringTo(out)
+	out.WriteByte(',')
+	member.WS3.WriteGoStringTo(out)
+	out.WriteByte(',')
+	member.WS4.WriteGoStringTo(out)
+	out.WriteByte('}')
+}
+
+func (member *UnionCaseMember) ComputeStringLengthEstimates() {
+	computeTypeMembersStringLengthEstimates(member.Members)
+	member.WS0.ComputeStringLengthEstimates()
+	member.WS1.ComputeStringLengthEstimates()
+	member.WS2.ComputeStringLengthEstimates()
+	member.WS3.ComputeStringLengthEstimates()
+	member.WS4.ComputeStringLengthEstimates()
+}
+
+func (member *UnionCaseMember) Children() []Node {
+	list := make([]Node, len(member.Members))
+	copy(list, member.Members)
+	return list
+}
+
+func (member *UnionCaseMember) RewriteChildren(fn func(Node) Node) {
+	rewriteNodes(member.Members, fn)
+}
+
+var _ Node = (*UnionCaseMember)(nil)
+
+// }}}
@@ -58,7 +58,13 @@ func MatchPragmaExpr(ptr *Node, p *Parser) bool {
 // {{{
 
 type LiteralNumber struct {
+	extent
+
 	Value value.Number
+
+	// Raw is the exact source text, if known.  It is written in place of
+	// Value so that the literal keeps its original spelling.
+	Raw string
 }
 
 func (num *LiteralNumber) Init() {
@@ -66,17 +72,20 @@ func (num *LiteralNumber) Init() {
 }
 
 func (num *LiteralNumber) Match(p *Parser) bool {
+	defer p.trackExtent(&num.extent)()
+
 	var tok token.Token
 	if !p.Consume(&tok, tokenpredicate.Type(token.Number)) {
 		return false
 	}
 
+	num.Raw = string(tok.Raw)
+
 	switch x := tok.Parsed.(type) {
 	case *value.Number:
 		num.Value = *x
 
 	case *value.Error:
-		p.EmitError(x.Err)
 		num.Value = *value.NewZero()
 
 	default:
@@ -95,6 +104,9 @@ func (num *LiteralNumber) GoString() string {
 }
 
 func (num *LiteralNumber) EstimateStringLength() uint {
+	if num.Raw != "" {
+		return uint(len(num.Raw))
+	}
 	return num.Value.EstimateStringLength()
 }
 
@@ -103,6 +115,10 @@ func (num *LiteralNumber) EstimateGoStringLength() uint {
 }
 
 func (num *LiteralNumber) WriteStringTo(out *strings.Builder) {
+	if num.Raw != "" {
+		out.WriteString(num.Raw)
+		return
+	}
 	num.Value.WriteStringTo(out)
 }
 
@@ -113,6 +129,13 @@ func (num *LiteralNumber) WriteGoStringTo(out *strings.Builder) {
 func (num *LiteralNumber) ComputeStringLengthEstimates() {
 }
 
+func (num *LiteralNumber) Children() []Node {
+	return nil
+}
+
+func (num *LiteralNumber) RewriteChildren(fn func(Node) Node) {
+}
+
 var _ Node = (*LiteralNumber)(nil)
 
 // }}}
@@ -121,7 +144,13 @@ var _ Node = (*LiteralNumber)(nil)
 // {{{
 
 type LiteralString struct {
+	extent
+
 	Value value.String
+
+	// Raw is the exact source text, if known.  It is written in place of
+	// Value so that the literal keeps its original spelling.
+	Raw string
 }
 
 func (str *LiteralString) Init() {
@@ -129,18 +158,21 @@ func (str *LiteralString) Init() {
 }
 
 func (str *LiteralString) Match(p *Parser) bool {
+	defer p.trackExtent(&str.extent)()
+
 	var tok token.Token
 	if !p.Consume(&tok, tokenpredicate.Type(token.String)) {
 		return false
 	}
 
+	str.Raw = string(tok.Raw)
+
 	switch x := tok.Parsed.(type) {
 	case *value.String:
 		str.Value = *x
 
 	case *value.Error:
 		str.Value = *value.NewEmptyString()
-		p.EmitError(x.Err)
 
 	default:
 		panic(fmt.Errorf("expected *value.String or *value.Error, got %T", tok.Parsed))
@@ -158,6 +190,9 @@ func (str *LiteralString) GoString() string {
 }
 
 func (str *LiteralString) EstimateStringLength() uint {
+	if str.Raw != "" {
+		return uint(len(str.Raw))
+	}
 	return str.Value.EstimateStringLength()
 }
 
@@ -166,6 +201,10 @@ func (str *LiteralString) EstimateGoStringLength() uint {
 }
 
 func (str *LiteralString) WriteStringTo(out *strings.Builder) {
+	if str.Raw != "" {
+		out.WriteString(str.Raw)
+		return
+	}
 	str.Value.WriteStringTo(out)
 }
 
@@ -177,6 +216,240 @@ func (str *LiteralString) ComputeStringLengthEstimates() {
 	util.EstimateLengths(&str.Value)
 }
 
+func (str *LiteralString) Children() []Node {
+	return nil
+}
+
+func (str *LiteralString) RewriteChildren(fn func(Node) Node) {
+}
+
 var _ Node = (*LiteralString)(nil)
 
 // }}}
+
+// LiteralRegex
+// {{{
+
+type LiteralRegex struct {
+	extent
+
+	Value value.Regex
+
+	// Raw is the exact source text, if known.  It is written in place of
+	// Value so that the literal keeps its original spelling.
+	Raw string
+}
+
+func (rx *LiteralRegex) Init() {
+	*rx = LiteralRegex{}
+}
+
+func (rx *LiteralRegex) Match(p *Parser) bool {
+	defer p.trackExtent(&rx.extent)()
+
+	var tok token.Token
+	if !p.Consume(&tok, tokenpredicate.Type(token.Regex)) {
+		return false
+	}
+
+	rx.Raw = string(tok.Raw)
+
+	switch x := tok.Parsed.(type) {
+	case *value.Regex:
+		rx.Value = *x
+
+	case *value.Error:
+		rx.Value = value.Regex{Input: string(tok.Raw)}
+
+	default:
+		panic(fmt.Errorf("expected *value.Regex or *value.Error, got %T", tok.Parsed))
+	}
+
+	return true
+}
+
+func (rx *LiteralRegex) String() string {
+	return util.StringImpl(rx)
+}
+
+func (rx *LiteralRegex) GoString() string {
+	return util.GoStringImpl(rx)
+}
+
+func (rx *LiteralRegex) EstimateStringLength() uint {
+	if rx.Raw != "" {
+		return uint(len(rx.Raw))
+	}
+	return rx.Value.EstimateStringLength()
+}
+
+func (rx *LiteralRegex) EstimateGoStringLength() uint {
+	return rx.Value.EstimateGoStringLength()
+}
+
+func (rx *LiteralRegex) WriteStringTo(out *strings.Builder) {
+	if rx.Raw != "" {
+		out.WriteString(rx.Raw)
+		return
+	}
+	rx.Value.WriteStringTo(out)
+}
+
+func (rx *LiteralRegex) WriteGoStringTo(out *strings.Builder) {
+	rx.Value.WriteGoStringTo(out)
+}
+
+func (rx *LiteralRegex) ComputeStringLengthEstimates() {
+}
+
+func (rx *LiteralRegex) Children() []Node {
+	return nil
+}
+
+func (rx *LiteralRegex) RewriteChildren(fn func(Node) Node) {
+}
+
+var _ Node = (*LiteralRegex)(nil)
+
+// }}}
+
+// LiteralPEG
+// {{{
+
+type LiteralPEG struct {
+	extent
+
+	Value value.PEG
+
+	// Raw is the exact source text, if known.  It is written in place of
+	// Value so that the literal keeps its original spelling.
+	Raw string
+}
+
+func (peg *LiteralPEG) Init() {
+	*peg = LiteralPEG{}
+}
+
+func (peg *LiteralPEG) Match(p *Parser) bool {
+	defer p.trackExtent(&peg.extent)()
+
+	var tok token.Token
+	if !p.Consume(&tok, tokenpredicate.Type(token.PEG)) {
+		return false
+	}
+
+	peg.Raw = string(tok.Raw)
+
+	switch x := tok.Parsed.(type) {
+	case *value.PEG:
+		peg.Value = *x
+
+	case *value.Error:
+		peg.Value = value.PEG{Input: string(tok.Raw)}
+
+	default:
+		panic(fmt.Errorf("expected *value.PEG or *value.Error, got %T", tok.Parsed))
+	}
+
+	return true
+}
+
+func (peg *LiteralPEG) String() string {
+	return util.StringImpl(peg)
+}
+
+func (peg *LiteralPEG) GoString() string {
+	return util.GoStringImpl(peg)
+}
+
+func (peg *LiteralPEG) EstimateStringLength() uint {
+	if peg.Raw != "" {
+		return uint(len(peg.Raw))
+	}
+	return peg.Value.EstimateStringLength()
+}
+
+func (peg *LiteralPEG) EstimateGoStringLength() uint {
+	return peg.Value.EstimateGoStringLength()
+}
+
+func (peg *LiteralPEG) WriteStringTo(out *strings.Builder) {
+	if peg.Raw != "" {
+		out.WriteString(peg.Raw)
+		return
+	}
+	peg.Value.WriteStringTo(out)
+}
+
+func (peg *LiteralPEG) WriteGoStringTo(out *strings.Builder) {
+	peg.Value.WriteGoStringTo(out)
+}
+
+func (peg *LiteralPEG) ComputeStringLengthEstimates() {
+}
+
+func (peg *LiteralPEG) Children() []Node {
+	return nil
+}
+
+func (peg *LiteralPEG) RewriteChildren(fn func(Node) Node) {
+}
+
+var _ Node = (*LiteralPEG)(nil)
+
+// }}}
+
+// LiteralNull
+// {{{
+
+type LiteralNull struct {
+	extent
+}
+
+func (null *LiteralNull) Init() {
+	*null = LiteralNull{}
+}
+
+func (null *LiteralNull) Match(p *Parser) bool {
+	defer p.trackExtent(&null.extent)()
+
+	return p.Consume(nil, tokenpredicate.Type(token.KeywordNull))
+}
+
+func (null *LiteralNull) String() string {
+	return util.StringImpl(null)
+}
+
+func (null *LiteralNull) GoString() string {
+	return util.GoStringImpl(null)
+}
+
+func (null *LiteralNull) EstimateStringLength() uint {
+	return 4
+}
+
+func (null *LiteralNull) EstimateGoStringLength() uint {
+	return 13
+}
+
+func (null *LiteralNull) WriteStringTo(out *strings.Builder) {
+	out.WriteString("null")
+}
+
+func (null *LiteralNull) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("LiteralNull{}")
+}
+
+func (null *LiteralNull) ComputeStringLengthEstimates() {
+}
+
+func (null *LiteralNull) Children() []Node {
+	return nil
+}
+
+func (null *LiteralNull) RewriteChildren(fn func(Node) Node) {
+}
+
+var _ Node = (*LiteralNull)(nil)
+
+// }}}
@@ -0,0 +1,99 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TraversalOrder
+// {{{
+
+type TraversalOrder uint8
+
+const (
+	InvalidTraversalOrder TraversalOrder = iota
+	PreOrder
+	PostOrder
+)
+
+var traversalOrderNames = []string{
+	"InvalidTraversalOrder",
+	"PreOrder",
+	"PostOrder",
+}
+
+func (order TraversalOrder) String() string {
+	if uint(order) >= uint(len(traversalOrderNames)) {
+		return fmt.Sprintf("TraversalOrder(%d)", uint(order))
+	}
+	return traversalOrderNames[order]
+}
+
+func (order TraversalOrder) GoString() string {
+	return order.String()
+}
+
+var _ fmt.Stringer = TraversalOrder(0)
+var _ fmt.GoStringer = TraversalOrder(0)
+
+// }}}
+
+// Walk calls fn for node and each of its descendants, visiting each node
+// either before (PreOrder) or after (PostOrder) its children.
+func Walk(node Node, order TraversalOrder, fn func(Node)) {
+	switch order {
+	case PreOrder:
+		fn(node)
+		for _, child := range node.Children() {
+			Walk(child, order, fn)
+		}
+
+	case PostOrder:
+		for _, child := range node.Children() {
+			Walk(child, order, fn)
+		}
+		fn(node)
+
+	default:
+		panic(fmt.Errorf("unknown TraversalOrder %v", order))
+	}
+}
+
+// Inspect calls fn for node and each of its descendants in pre-order.  If fn
+// returns false, the children of that node are skipped.
+func Inspect(node Node, fn func(Node) bool) {
+	if fn(node) {
+		for _, child := range node.Children() {
+			Inspect(child, fn)
+		}
+	}
+}
+
+// Rewrite replaces each descendant of node with the result of calling fn on
+// it, then returns fn(node).  Children are rewritten before their parents,
+// so fn always sees a subtree that has already been rewritten.  To leave a
+// node unchanged, fn should return its argument; fn must never return nil.
+//
+// Some children are stored by value rather than as a Node, such as the Body
+// of a WhileStatement; fn may only replace those with a node of the same
+// type.
+func Rewrite(node Node, fn func(Node) Node) Node {
+	node.RewriteChildren(func(child Node) Node {
+		return Rewrite(child, fn)
+	})
+	return fn(node)
+}
+
+func rewriteNodes(list []Node, fn func(Node) Node) {
+	for index := range list {
+		list[index] = fn(list[index])
+	}
+}
+
+func rewriteSameType(node Node, fn func(Node) Node) Node {
+	replacement := fn(node)
+	if reflect.TypeOf(replacement) != reflect.TypeOf(node) {
+		panic(fmt.Errorf("cannot replace %T with %T", node, replacement))
+	}
+	return replacement
+}
@@ -0,0 +1,85 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func nodeNames(list []Node) string {
+	names := make([]string, len(list))
+	for index, node := range list {
+		names[index] = strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast.")
+	}
+	return strings.Join(names, " ")
+}
+
+func TestWalk(t *testing.T) {
+	file := testParseFile(t, "while x {\ny = f(z)\n}")
+	if file == nil {
+		return
+	}
+
+	type testRow struct {
+		Order    TraversalOrder
+		Expected string
+	}
+
+	data := []testRow{
+		{PreOrder, "File WhileStatement IdentifierExpr BlockStatement EmptyStatement AssignmentStatement IdentifierExpr CallExpr IdentifierExpr ArgumentList IdentifierExpr"},
+		{PostOrder, "IdentifierExpr EmptyStatement IdentifierExpr IdentifierExpr IdentifierExpr ArgumentList CallExpr AssignmentStatement BlockStatement WhileStatement File"},
+	}
+
+	for _, row := range data {
+		var visited []Node
+		Walk(file, row.Order, func(node Node) {
+			visited = append(visited, node)
+		})
+		if actual := nodeNames(visited); actual != row.Expected {
+			t.Errorf("Walk(%v): expected %q, actual %q", row.Order, row.Expected, actual)
+		}
+	}
+}
+
+func TestInspect(t *testing.T) {
+	file := testParseFile(t, "func f() {\nreturn g(x)\n}\nlet y = h(z)\n")
+	if file == nil {
+		return
+	}
+
+	var visited []Node
+	Inspect(file, func(node Node) bool {
+		visited = append(visited, node)
+		_, isFunc := node.(*FuncDeclStatement)
+		return !isFunc
+	})
+
+	expected := "File FuncDeclStatement VarDeclStatement CallExpr IdentifierExpr ArgumentList IdentifierExpr"
+	if actual := nodeNames(visited); actual != expected {
+		t.Errorf("Inspect: expected %q, actual %q", expected, actual)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	input := "if a {\nb = a + c\n} else {\na(b)\n}\n"
+	expected := "if renamed {\nb = renamed + c\n} else {\nrenamed(b)\n}\n"
+
+	file := testParseFile(t, input)
+	if file == nil {
+		return
+	}
+
+	result := Rewrite(file, func(node Node) Node {
+		if x, ok := node.(*IdentifierExpr); ok && x.Name == "a" {
+			return &IdentifierExpr{Name: "renamed"}
+		}
+		return node
+	})
+
+	if result != Node(file) {
+		t.Errorf("Rewrite: expected the root to be unchanged, actual %#v", result)
+	}
+	if actual := file.String(); actual != expected {
+		t.Errorf("Rewrite: expected %q, actual %q", expected, actual)
+	}
+}
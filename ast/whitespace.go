@@ -15,7 +15,13 @@ import (
 // {{{
 
 type HorizontalWhitespace struct {
+	extent
+
 	Count uint
+
+	// Raw is the exact source text, if known.  It is written in place of
+	// Count so that tabs and "\r\n" line endings survive a round trip.
+	Raw string
 }
 
 func (ws *HorizontalWhitespace) Init() {
@@ -23,6 +29,10 @@ func (ws *HorizontalWhitespace) Init() {
 }
 
 func (ws *HorizontalWhitespace) Match(p *Parser) bool {
+	defer p.trackExtent(&ws.extent)()
+
+	first := p.tokenIndex
+
 	var tok token.Token
 	if !p.Consume(&tok, tokenpredicate.Type(token.HWS)) {
 		return false
@@ -38,6 +48,7 @@ func (ws *HorizontalWhitespace) Match(p *Parser) bool {
 		startColumn = endColumn
 	}
 	ws.Count = endColumn - startColumn
+	ws.Raw = p.rawSince(first)
 	return true
 }
 
@@ -50,6 +61,9 @@ func (ws *HorizontalWhitespace) GoString() string {
 }
 
 func (ws *HorizontalWhitespace) EstimateStringLength() uint {
+	if ws.Raw != "" {
+		return uint(len(ws.Raw))
+	}
 	return uint(ws.Count)
 }
 
@@ -58,6 +72,10 @@ func (ws *HorizontalWhitespace) EstimateGoStringLength() uint {
 }
 
 func (ws *HorizontalWhitespace) WriteStringTo(out *strings.Builder) {
+	if ws.Raw != "" {
+		out.WriteString(ws.Raw)
+		return
+	}
 	for i := uint(0); i < ws.Count; i++ {
 		out.WriteByte(' ')
 	}
@@ -72,6 +90,13 @@ func (ws *HorizontalWhitespace) WriteGoStringTo(out *strings.Builder) {
 func (ws *HorizontalWhitespace) ComputeStringLengthEstimates() {
 }
 
+func (ws *HorizontalWhitespace) Children() []Node {
+	return nil
+}
+
+func (ws *HorizontalWhitespace) RewriteChildren(fn func(Node) Node) {
+}
+
 var _ Node = (*HorizontalWhitespace)(nil)
 
 // }}}
@@ -80,7 +105,13 @@ var _ Node = (*HorizontalWhitespace)(nil)
 // {{{
 
 type VerticalWhitespace struct {
+	extent
+
 	Count uint
+
+	// Raw is the exact source text, if known.  It is written in place of
+	// Count so that tabs and "\r\n" line endings survive a round trip.
+	Raw string
 }
 
 func (ws *VerticalWhitespace) Init() {
@@ -88,6 +119,10 @@ func (ws *VerticalWhitespace) Init() {
 }
 
 func (ws *VerticalWhitespace) Match(p *Parser) bool {
+	defer p.trackExtent(&ws.extent)()
+
+	first := p.tokenIndex
+
 	var tok token.Token
 	if !p.Consume(&tok, tokenpredicate.Type(token.VWS)) {
 		return false
@@ -103,6 +138,7 @@ func (ws *VerticalWhitespace) Match(p *Parser) bool {
 		startLine = endLine
 	}
 	ws.Count = endLine - startLine
+	ws.Raw = p.rawSince(first)
 	return true
 }
 
@@ -115,6 +151,9 @@ func (ws *VerticalWhitespace) GoString() string {
 }
 
 func (ws *VerticalWhitespace) EstimateStringLength() uint {
+	if ws.Raw != "" {
+		return uint(len(ws.Raw))
+	}
 	return uint(ws.Count)
 }
 
@@ -123,6 +162,10 @@ func (ws *VerticalWhitespace) EstimateGoStringLength() uint {
 }
 
 func (ws *VerticalWhitespace) WriteStringTo(out *strings.Builder) {
+	if ws.Raw != "" {
+		out.WriteString(ws.Raw)
+		return
+	}
 	for i := uint(0); i < ws.Count; i++ {
 		out.WriteByte('\n')
 	}
@@ -137,6 +180,13 @@ func (ws *VerticalWhitespace) WriteGoStringTo(out *strings.Builder) {
 func (ws *VerticalWhitespace) ComputeStringLengthEstimates() {
 }
 
+func (ws *VerticalWhitespace) Children() []Node {
+	return nil
+}
+
+func (ws *VerticalWhitespace) RewriteChildren(fn func(Node) Node) {
+}
+
 var _ Node = (*VerticalWhitespace)(nil)
 
 // }}}
@@ -145,7 +195,13 @@ var _ Node = (*VerticalWhitespace)(nil)
 // {{{
 
 type SingleLineCommentWhitespace struct {
+	extent
+
 	Text string
+
+	// Raw is the exact source text, if known, including any line break
+	// that follows the comment.
+	Raw string
 }
 
 func (ws *SingleLineCommentWhitespace) Init() {
@@ -153,6 +209,10 @@ func (ws *SingleLineCommentWhitespace) Init() {
 }
 
 func (ws *SingleLineCommentWhitespace) Match(p *Parser) bool {
+	defer p.trackExtent(&ws.extent)()
+
+	first := p.tokenIndex
+
 	var tok token.Token
 	if !p.Consume(&tok, tokenpredicate.Type(token.SingleLineComment)) {
 		return false
@@ -170,6 +230,7 @@ func (ws *SingleLineCommentWhitespace) Match(p *Parser) bool {
 
 	text = text[2:]
 	ws.Text = text
+	ws.Raw = p.rawSince(first)
 	return true
 }
 
@@ -182,6 +243,9 @@ func (ws *SingleLineCommentWhitespace) GoString() string {
 }
 
 func (ws *SingleLineCommentWhitespace) EstimateStringLength() uint {
+	if ws.Raw != "" {
+		return uint(len(ws.Raw))
+	}
 	return 3 + uint(len(ws.Text))
 }
 
@@ -190,6 +254,10 @@ func (ws *SingleLineCommentWhitespace) EstimateGoStringLength() uint {
 }
 
 func (ws *SingleLineCommentWhitespace) WriteStringTo(out *strings.Builder) {
+	if ws.Raw != "" {
+		out.WriteString(ws.Raw)
+		return
+	}
 	out.WriteString("//")
 	out.WriteString(ws.Text)
 	out.WriteByte('\n')
@@ -204,6 +272,13 @@ func (ws *SingleLineCommentWhitespace) WriteGoStringTo(out *strings.Builder) {
 func (ws *SingleLineCommentWhitespace) ComputeStringLengthEstimates() {
 }
 
+func (ws *SingleLineCommentWhitespace) Children() []Node {
+	return nil
+}
+
+func (ws *SingleLineCommentWhitespace) RewriteChildren(fn func(Node) Node) {
+}
+
 var _ Node = (*SingleLineCommentWhitespace)(nil)
 
 // }}}
@@ -212,7 +287,13 @@ var _ Node = (*SingleLineCommentWhitespace)(nil)
 // {{{
 
 type MultiLineCommentWhitespace struct {
+	extent
+
 	Text string
+
+	// Raw is the exact source text, if known, including any line break
+	// that follows the comment.
+	Raw string
 }
 
 func (ws *MultiLineCommentWhitespace) Init() {
@@ -220,6 +301,10 @@ func (ws *MultiLineCommentWhitespace) Init() {
 }
 
 func (ws *MultiLineCommentWhitespace) Match(p *Parser) bool {
+	defer p.trackExtent(&ws.extent)()
+
+	first := p.tokenIndex
+
 	var tok token.Token
 	if !p.Consume(&tok, tokenpredicate.Type(token.MultiLineComment)) {
 		return false
@@ -242,6 +327,7 @@ func (ws *MultiLineCommentWhitespace) Match(p *Parser) bool {
 	text = text[2:]
 	text = text[:len(text)-2]
 	ws.Text = text
+	ws.Raw = p.rawSince(first)
 	return true
 }
 
@@ -254,6 +340,9 @@ func (ws *MultiLineCommentWhitespace) GoString() string {
 }
 
 func (ws *MultiLineCommentWhitespace) EstimateStringLength() uint {
+	if ws.Raw != "" {
+		return uint(len(ws.Raw))
+	}
 	return 4 + uint(len(ws.Text))
 }
 
@@ -262,6 +351,10 @@ func (ws *MultiLineCommentWhitespace) EstimateGoStringLength() uint {
 }
 
 func (ws *MultiLineCommentWhitespace) WriteStringTo(out *strings.Builder) {
+	if ws.Raw != "" {
+		out.WriteString(ws.Raw)
+		return
+	}
 	out.WriteString("/*")
 	out.WriteString(ws.Text)
 	out.WriteString("*/")
@@ -276,6 +369,13 @@ func (ws *MultiLineCommentWhitespace) WriteGoStringTo(out *strings.Builder) {
 func (ws *MultiLineCommentWhitespace) ComputeStringLengthEstimates() {
 }
 
+func (ws *MultiLineCommentWhitespace) Children() []Node {
+	return nil
+}
+
+func (ws *MultiLineCommentWhitespace) RewriteChildren(fn func(Node) Node) {
+}
+
 var _ Node = (*MultiLineCommentWhitespace)(nil)
 
 // }}}
@@ -284,6 +384,7 @@ var _ Node = (*MultiLineCommentWhitespace)(nil)
 // {{{
 
 type StatementTerminatorWhitespace struct {
+	extent
 }
 
 func (ws *StatementTerminatorWhitespace) Init() {
@@ -291,6 +392,8 @@ func (ws *StatementTerminatorWhitespace) Init() {
 }
 
 func (ws *StatementTerminatorWhitespace) Match(p *Parser) bool {
+	defer p.trackExtent(&ws.extent)()
+
 	return p.Consume(nil, tokenpredicate.Type(token.Semicolon))
 }
 
@@ -321,15 +424,81 @@ func (ws *StatementTerminatorWhitespace) WriteGoStringTo(out *strings.Builder) {
 func (ws *StatementTerminatorWhitespace) ComputeStringLengthEstimates() {
 }
 
+func (ws *StatementTerminatorWhitespace) Children() []Node {
+	return nil
+}
+
+func (ws *StatementTerminatorWhitespace) RewriteChildren(fn func(Node) Node) {
+}
+
 var _ Node = (*StatementTerminatorWhitespace)(nil)
 
 // }}}
 
+// MemberSeparatorWhitespace
+// {{{
+
+// MemberSeparatorWhitespace is a ',' that ends a member of a type body, as in
+// "struct { first: A, second: B }".
+type MemberSeparatorWhitespace struct {
+	extent
+}
+
+func (ws *MemberSeparatorWhitespace) Init() {
+	*ws = MemberSeparatorWhitespace{}
+}
+
+func (ws *MemberSeparatorWhitespace) Match(p *Parser) bool {
+	defer p.trackExtent(&ws.extent)()
+
+	return p.Consume(nil, tokenpredicate.Type(token.Comma))
+}
+
+func (ws *MemberSeparatorWhitespace) String() string {
+	return util.StringImpl(ws)
+}
+
+func (ws *MemberSeparatorWhitespace) GoString() string {
+	return util.GoStringImpl(ws)
+}
+
+func (ws *MemberSeparatorWhitespace) EstimateStringLength() uint {
+	return 1
+}
+
+func (ws *MemberSeparatorWhitespace) EstimateGoStringLength() uint {
+	return 27
+}
+
+func (ws *MemberSeparatorWhitespace) WriteStringTo(out *strings.Builder) {
+	out.WriteByte(',')
+}
+
+func (ws *MemberSeparatorWhitespace) WriteGoStringTo(out *strings.Builder) {
+	out.WriteString("MemberSeparatorWhitespace{}")
+}
+
+func (ws *MemberSeparatorWhitespace) ComputeStringLengthEstimates() {
+}
+
+func (ws *MemberSeparatorWhitespace) Children() []Node {
+	return nil
+}
+
+func (ws *MemberSeparatorWhitespace) RewriteChildren(fn func(Node) Node) {
+}
+
+var _ Node = (*MemberSeparatorWhitespace)(nil)
+
+// }}}
+
 // InternalWhitespaceRun
 // {{{
 
 type InternalWhitespaceRun struct {
-	Children []Node
+	extent
+
+	Items []Node
 
 	PrecomputedStringLength   uint
 	PrecomputedGoStringLength uint
@@ -340,8 +509,10 @@ func (ws *InternalWhitespaceRun) Init() {
 }
 
 func (ws *InternalWhitespaceRun) Match(p *Parser) bool {
-	if ws.Children == nil {
-		ws.Children = make([]Node, 0, 16)
+	defer p.trackExtent(&ws.extent)()
+
+	if ws.Items == nil {
+		ws.Items = make([]Node, 0, 16)
 	}
 
 	atLeastOne := false
@@ -349,28 +520,28 @@ func (ws *InternalWhitespaceRun) Match(p *Parser) bool {
 		var ws0 HorizontalWhitespace
 		if ws0.Match(p) {
 			atLeastOne = true
-			ws.Children = append(ws.Children, &ws0)
+			ws.Items = append(ws.Items, &ws0)
 			continue
 		}
 
 		var ws1 VerticalWhitespace
 		if ws1.Match(p) {
 			atLeastOne = true
-			ws.Children = append(ws.Children, &ws1)
+			ws.Items = append(ws.Items, &ws1)
 			continue
 		}
 
 		var ws2 SingleLineCommentWhitespace
 		if ws2.Match(p) {
 			atLeastOne = true
-			ws.Children = append(ws.Children, &ws2)
+			ws.Items = append(ws.Items, &ws2)
 			continue
 		}
 
 		var ws3 MultiLineCommentWhitespace
 		if ws3.Match(p) {
 			atLeastOne = true
-			ws.Children = append(ws.Children, &ws3)
+			ws.Items = append(ws.Items, &ws3)
 			continue
 		}
 
@@ -396,14 +567,14 @@ func (ws *InternalWhitespaceRun) EstimateGoStringLength() uint {
 }
 
 func (ws *InternalWhitespaceRun) WriteStringTo(out *strings.Builder) {
-	for _, item := range ws.Children {
+	for _, item := range ws.Items {
 		item.WriteStringTo(out)
 	}
 }
 
 func (ws *InternalWhitespaceRun) WriteGoStringTo(out *strings.Builder) {
 	out.WriteString("InternalWhitespaceRun{")
-	for index, item := range ws.Children {
+	for index, item := range ws.Items {
 		if index != 0 {
 			out.WriteByte(',')
 		}
@@ -424,6 +595,16 @@ func (ws *InternalWhitespaceRun) ComputeStringLengthEstimates() {
 	util.EstimateLengths(ws)
 }
 
+func (ws *InternalWhitespaceRun) Children() []Node {
+	list := make([]Node, len(ws.Items))
+	copy(list, ws.Items)
+	return list
+}
+
+func (ws *InternalWhitespaceRun) RewriteChildren(fn func(Node) Node) {
+	rewriteNodes(ws.Items, fn)
+}
+
 var _ Node = (*InternalWhitespaceRun)(nil)
 
 // }}}
@@ -432,7 +613,9 @@ var _ Node = (*InternalWhitespaceRun)(nil)
 // {{{
 
 type TerminalWhitespaceRun struct {
-	Children []Node
+	extent
+
+	Items []Node
 
 	PrecomputedStringLength   uint
 	PrecomputedGoStringLength uint
@@ -443,51 +626,64 @@ func (ws *TerminalWhitespaceRun) Init() {
 }
 
 func (ws *TerminalWhitespaceRun) Match(p *Parser) bool {
-	if ws.Children == nil {
-		ws.Children = make([]Node, 0, 16)
+	defer p.trackExtent(&ws.extent)()
+
+	if ws.Items == nil {
+		ws.Items = make([]Node, 0, 16)
 	}
 
 	mark := p.Mark()
 	defer p.Forget(mark)
 
+	saved := len(ws.Items)
+
 	for {
-		if p.Peek(nil, tokenpredicate.Type(token.EOF)) {
+		if p.Peek(nil, tokenpredicate.Type(token.EOF)) || p.atBlockEnd() {
 			return true
 		}
 
 		var ws0 StatementTerminatorWhitespace
 		if ws0.Match(p) {
-			ws.Children = append(ws.Children, &ws0)
+			ws.Items = append(ws.Items, &ws0)
 			return true
 		}
 
+		if p.inMemberBlock() {
+			var sep MemberSeparatorWhitespace
+			if sep.Match(p) {
+				ws.Items = append(ws.Items, &sep)
+				return true
+			}
+		}
+
 		var ws1 VerticalWhitespace
 		if ws1.Match(p) {
-			ws.Children = append(ws.Children, &ws1)
+			ws.Items = append(ws.Items, &ws1)
 			return true
 		}
 
 		var ws2 SingleLineCommentWhitespace
 		if ws2.Match(p) {
-			ws.Children = append(ws.Children, &ws2)
+			ws.Items = append(ws.Items, &ws2)
 			return true
 		}
 
 		var ws3 HorizontalWhitespace
 		if ws3.Match(p) {
-			ws.Children = append(ws.Children, &ws3)
+			ws.Items = append(ws.Items, &ws3)
 			continue
 		}
 
 		var ws4 MultiLineCommentWhitespace
 		if ws4.Match(p) {
-			ws.Children = append(ws.Children, &ws4)
+			ws.Items = append(ws.Items, &ws4)
 			continue
 		}
 
 		break
 	}
 
+	ws.Items = ws.Items[:saved]
 	p.Rewind(mark)
 	return false
 }
@@ -509,14 +705,14 @@ func (ws *TerminalWhitespaceRun) EstimateGoStringLength() uint {
 }
 
 func (ws *TerminalWhitespaceRun) WriteStringTo(out *strings.Builder) {
-	for _, item := range ws.Children {
+	for _, item := range ws.Items {
 		item.WriteStringTo(out)
 	}
 }
 
 func (ws *TerminalWhitespaceRun) WriteGoStringTo(out *strings.Builder) {
 	out.WriteString("TerminalWhitespaceRun{")
-	for index, item := range ws.Children {
+	for index, item := range ws.Items {
 		if index != 0 {
 			out.WriteByte(',')
 		}
@@ -537,6 +733,16 @@ func (ws *TerminalWhitespaceRun) ComputeStringLengthEstimates() {
 	util.EstimateLengths(ws)
 }
 
+func (ws *TerminalWhitespaceRun) Children() []Node {
+	list := make([]Node, len(ws.Items))
+	copy(list, ws.Items)
+	return list
+}
+
+func (ws *TerminalWhitespaceRun) RewriteChildren(fn func(Node) Node) {
+	rewriteNodes(ws.Items, fn)
+}
+
 var _ Node = (*TerminalWhitespaceRun)(nil)
 
 // }}}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/chronos-tachyon/go-spiderscript/exprtree"
+)
+
+// declareBuiltins declares the functions that connect a script to the
+// outside world in mod:
+//
+//	print(x)            writes x and a newline to out, for x a String,
+//	                    SInt64, UInt64, Float64 or Bool
+//	argc(): SInt64      returns the number of arguments in args
+//	arg(i): String      returns args[i]
+func declareBuiltins(mod *exprtree.Module, out io.Writer, args []string) error {
+	interp := mod.Interp()
+
+	printValue := func(_ *exprtree.Value, in []exprtree.Value) error {
+		_, err := fmt.Fprintln(out, formatValue(in[0]))
+		return err
+	}
+	for _, t := range []*exprtree.Type{
+		interp.StringType(),
+		interp.SInt64Type(),
+		interp.UInt64Type(),
+		interp.Float64Type(),
+		interp.BoolType(),
+	} {
+		builder := interp.FunctionSignatureBuilder().WithPositionalArg(t)
+		if err := declareBuiltin(mod, "print", builder, []string{"x"}, printValue); err != nil {
+			return err
+		}
+	}
+
+	builder := interp.FunctionSignatureBuilder().WithReturn(interp.SInt64Type())
+	err := declareBuiltin(mod, "argc", builder, nil, func(result *exprtree.Value, _ []exprtree.Value) error {
+		return result.Set(int64(len(args)))
+	})
+	if err != nil {
+		return err
+	}
+
+	builder = interp.FunctionSignatureBuilder().WithPositionalArg(interp.SInt64Type()).WithReturn(interp.StringType())
+	return declareBuiltin(mod, "arg", builder, []string{"i"}, func(result *exprtree.Value, in []exprtree.Value) error {
+		i := in[0].Get().(int64)
+		if i < 0 || i >= int64(len(args)) {
+			return fmt.Errorf("arg(%d): index out of range [0..%d)", i, len(args))
+		}
+		buf := interp.NewBuffer()
+		buf.AppendString(args[i])
+		return result.Set(&exprtree.String{Buffer: buf, Offset: 0, Length: buf.Len()})
+	})
+}
+
+func declareBuiltin(mod *exprtree.Module, name string, builder *exprtree.FunctionSignatureBuilder, posNames []string, fn exprtree.NativeFunc) error {
+	sym, err := mod.Symbols().NewSymbol(exprtree.SymbolData{
+		Kind: exprtree.SimpleFunctionSymbol,
+		Name: name,
+		Type: mod.Interp().VoidType(),
+		Function: exprtree.FunctionSymbolData{
+			Signature:       builder.Build(),
+			PositionalNames: posNames,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	f, err := mod.Interp().NewNativeFunction(sym, fn)
+	if err != nil {
+		return err
+	}
+	sym.SetCompileTimeValue(f)
+	return nil
+}
+
+// formatValue formats a value for print and for the REPL: Bool values by
+// name, String values as their text, and numbers as Go formats them.
+func formatValue(value exprtree.Value) string {
+	switch x := value.Get().(type) {
+	case *exprtree.EnumItem:
+		return x.Name()
+	case exprtree.String:
+		return x.Buffer.String()[x.Offset : x.Offset+x.Length]
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// exitStatus returns the exit status for the value of a top-level return
+// statement, which must be nothing or an integer in [0..255].
+func exitStatus(value exprtree.Value) (int, error) {
+	if value.Type().Is(value.Interp().VoidType()) {
+		return 0, nil
+	}
+
+	var n int64
+	switch x := value.Get().(type) {
+	case int8:
+		n = int64(x)
+	case int16:
+		n = int64(x)
+	case int32:
+		n = int64(x)
+	case int64:
+		n = x
+	case uint8:
+		n = int64(x)
+	case uint16:
+		n = int64(x)
+	case uint32:
+		n = int64(x)
+	case uint64:
+		if x > math.MaxInt64 {
+			x = math.MaxInt64
+		}
+		n = int64(x)
+	default:
+		return 0, fmt.Errorf("exit status must be an integer, not %s", value.Type().CanonicalName())
+	}
+	if n < 0 || n > 255 {
+		return 0, fmt.Errorf("exit status %s is out of range [0..255]", formatValue(value))
+	}
+	return int(n), nil
+}
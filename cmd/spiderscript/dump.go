@@ -0,0 +1,302 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/chronos-tachyon/go-spiderscript/ast"
+	"github.com/chronos-tachyon/go-spiderscript/token"
+)
+
+type jsonPosition struct {
+	Offset uint `json:"offset"`
+	Line   uint `json:"line"`
+	Column uint `json:"column"`
+}
+
+func toJSONPosition(pos token.Position) jsonPosition {
+	return jsonPosition{
+		Offset: pos.RuneOffset,
+		Line:   pos.LineNumber(),
+		Column: pos.ColumnNumber(),
+	}
+}
+
+type jsonToken struct {
+	Type  string       `json:"type"`
+	Raw   string       `json:"raw"`
+	Value string       `json:"value,omitempty"`
+	Start jsonPosition `json:"start"`
+	End   jsonPosition `json:"end"`
+}
+
+type jsonDiagnostic struct {
+	Severity string       `json:"severity"`
+	Code     string       `json:"code"`
+	Message  string       `json:"message"`
+	Start    jsonPosition `json:"start"`
+	End      jsonPosition `json:"end"`
+}
+
+type jsonNode struct {
+	Type     string            `json:"type"`
+	Start    jsonPosition      `json:"start"`
+	End      jsonPosition      `json:"end"`
+	Value    string            `json:"value,omitempty"`
+	Attrs    map[string]string `json:"attrs,omitempty"`
+	Children []*jsonNode       `json:"children,omitempty"`
+}
+
+type jsonTokensDump struct {
+	Path        string           `json:"path"`
+	Tokens      []jsonToken      `json:"tokens"`
+	Diagnostics []jsonDiagnostic `json:"diagnostics"`
+}
+
+type jsonASTDump struct {
+	Path   string    `json:"path"`
+	AST    *jsonNode `json:"ast"`
+	Errors []string  `json:"errors"`
+}
+
+// runTokens implements "spiderscript tokens".  It returns the exit status:
+// 0 on success, 1 if a file could not be read, and 2 for usage errors.
+func runTokens(args []string) int {
+	return runDump("tokens", args, func(path string, lexer *token.Lexer, asJSON bool) {
+		var tokens []token.Token
+		for lexer.HasNext() {
+			tokens = append(tokens, lexer.Token())
+		}
+		diags := lexer.Diagnostics()
+
+		if asJSON {
+			dump := jsonTokensDump{
+				Path:        path,
+				Tokens:      make([]jsonToken, len(tokens)),
+				Diagnostics: make([]jsonDiagnostic, len(diags)),
+			}
+			for index, tok := range tokens {
+				dump.Tokens[index] = jsonToken{
+					Type:  tok.Type.String(),
+					Raw:   string(tok.Raw),
+					Value: parsedGoString(tok),
+					Start: toJSONPosition(tok.Start),
+					End:   toJSONPosition(tok.End),
+				}
+			}
+			for index, diag := range diags {
+				dump.Diagnostics[index] = jsonDiagnostic{
+					Severity: diag.Severity.String(),
+					Code:     diag.Code.String(),
+					Message:  diag.Message,
+					Start:    toJSONPosition(diag.Start),
+					End:      toJSONPosition(diag.End),
+				}
+			}
+			writeJSON(dump)
+			return
+		}
+
+		for _, tok := range tokens {
+			fmt.Printf("%s\t%s\t%q", formatRange(tok.Start, tok.End), tok.Type, string(tok.Raw))
+			if value := parsedGoString(tok); value != "" {
+				fmt.Printf("\t%s", value)
+			}
+			fmt.Println()
+		}
+		for _, diag := range diags {
+			fmt.Fprintf(os.Stderr, "%v: %v\n", diag.Severity, &diag)
+		}
+	})
+}
+
+// runAST implements "spiderscript ast".  It returns the exit status: 0 on
+// success, 1 if a file could not be read, and 2 for usage errors.
+func runAST(args []string) int {
+	return runDump("ast", args, func(path string, lexer *token.Lexer, asJSON bool) {
+		var parser ast.Parser
+		parser.Init(lexer)
+
+		var file ast.File
+		parser.Parse(&file)
+		errs := parser.Errors()
+
+		if asJSON {
+			dump := jsonASTDump{
+				Path:   path,
+				AST:    toJSONNode(&file),
+				Errors: make([]string, len(errs)),
+			}
+			for index, err := range errs {
+				dump.Errors[index] = err.Error()
+			}
+			writeJSON(dump)
+			return
+		}
+
+		printNode(os.Stdout, &file, 0)
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+	})
+}
+
+// runDump parses the flags shared by the dump subcommands, then calls fn
+// with a lexer for each named file, or for standard input if there are
+// none.
+func runDump(name string, args []string, fn func(path string, lexer *token.Lexer, asJSON bool)) int {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: spiderscript %s [--json] [file...]\n", name)
+		flags.PrintDefaults()
+	}
+	asJSON := flags.Bool("json", false, "print JSON instead of text")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	process := func(path string, r io.Reader) {
+		var lexer token.Lexer
+		lexer.InitReader(path, r)
+		fn(path, &lexer, *asJSON)
+	}
+
+	if flags.NArg() == 0 {
+		process("<stdin>", os.Stdin)
+		return 0
+	}
+
+	status := 0
+	for _, path := range flags.Args() {
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			status = 1
+			continue
+		}
+		process(path, f)
+		f.Close()
+	}
+	return status
+}
+
+// parsedGoString returns the GoString of the token's parsed value, or "" if
+// it has none.
+func parsedGoString(tok token.Token) string {
+	if tok.Parsed == nil {
+		return ""
+	}
+	return tok.Parsed.GoString()
+}
+
+// formatRange returns "L:C-L:C" for the range from start to end.
+func formatRange(start token.Position, end token.Position) string {
+	return fmt.Sprintf("%d:%d-%d:%d", start.LineNumber(), start.ColumnNumber(), end.LineNumber(), end.ColumnNumber())
+}
+
+// nodeName returns the node's type name without the package qualifier.
+func nodeName(node ast.Node) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast.")
+}
+
+// printNode writes node to w as an indented tree, one node per line.
+// Leaves are followed by their GoString, and other nodes by their own
+// attributes; see nodeAttrs.
+func printNode(w io.Writer, node ast.Node, depth int) {
+	span := node.Span()
+	children := node.Children()
+	fmt.Fprintf(w, "%s%s %s", strings.Repeat("  ", depth), nodeName(node), formatRange(span.Start, span.End))
+	if len(children) == 0 {
+		fmt.Fprintf(w, " %s", node.GoString())
+	} else {
+		for _, attr := range nodeAttrs(node) {
+			fmt.Fprintf(w, " %s=%s", attr.Name, attr.Value)
+		}
+	}
+	fmt.Fprintln(w)
+	for _, child := range children {
+		printNode(w, child, depth+1)
+	}
+}
+
+func toJSONNode(node ast.Node) *jsonNode {
+	span := node.Span()
+	children := node.Children()
+	out := &jsonNode{
+		Type:  nodeName(node),
+		Start: toJSONPosition(span.Start),
+		End:   toJSONPosition(span.End),
+	}
+	if len(children) == 0 {
+		out.Value = node.GoString()
+	} else if attrs := nodeAttrs(node); len(attrs) != 0 {
+		out.Attrs = make(map[string]string, len(attrs))
+		for _, attr := range attrs {
+			out.Attrs[attr.Name] = attr.Value
+		}
+	}
+	for _, child := range children {
+		out.Children = append(out.Children, toJSONNode(child))
+	}
+	return out
+}
+
+// nodeAttr is one attribute of a node, such as its name or operator.
+type nodeAttr struct {
+	Name  string
+	Value string
+}
+
+// nodeAttrs returns the attributes that node holds itself rather than in
+// its children: its exported string, boolean and integer fields, including
+// those of embedded structs.  Strings are quoted, and enums are printed by
+// name.
+func nodeAttrs(node ast.Node) []nodeAttr {
+	var out []nodeAttr
+	var visit func(v reflect.Value)
+	visit = func(v reflect.Value) {
+		t := v.Type()
+		for index := 0; index < t.NumField(); index++ {
+			field := t.Field(index)
+			value := v.Field(index)
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				visit(value)
+				continue
+			}
+			if field.PkgPath != "" {
+				continue
+			}
+			switch field.Type.Kind() {
+			case reflect.String:
+				out = append(out, nodeAttr{field.Name, strconv.Quote(value.String())})
+			case reflect.Bool,
+				reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				out = append(out, nodeAttr{field.Name, fmt.Sprint(value.Interface())})
+			}
+		}
+	}
+
+	v := reflect.ValueOf(node)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct {
+		visit(v)
+	}
+	return out
+}
+
+func writeJSON(v interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		panic(err)
+	}
+}
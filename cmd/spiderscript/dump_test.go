@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chronos-tachyon/go-spiderscript/ast"
+	"github.com/chronos-tachyon/go-spiderscript/token"
+)
+
+func testParse(t *testing.T, input string) *ast.File {
+	t.Helper()
+	var lexer token.Lexer
+	lexer.InitReader("test.spider", strings.NewReader(input))
+
+	var parser ast.Parser
+	parser.Init(&lexer)
+
+	var file ast.File
+	parser.Parse(&file)
+	if errs := parser.Errors(); errs != nil {
+		t.Fatalf("Parse(%q): unexpected errors: %v", input, errs)
+	}
+	return &file
+}
+
+func TestPrintNode(t *testing.T) {
+	type testRow struct {
+		Input    string
+		Expected []string
+	}
+
+	testData := []testRow{
+		{"let x = a + b\n", []string{`VarDeclStatement 1:1-1:14 Kind=let Name="x"`, "BinaryOperatorExpr 1:9-1:14 Operator=+"}},
+		{"let x = a - b\n", []string{"BinaryOperatorExpr 1:9-1:14 Operator=-"}},
+		{"func f() { return a.b }\n", []string{`FuncDeclStatement 1:1-1:24 Name="f"`, `FieldExpr 1:19-1:22 Field="b"`, `IdentifierExpr 1:19-1:20 IdentifierExpr{nil,"a"}`}},
+	}
+
+	for _, row := range testData {
+		var out strings.Builder
+		printNode(&out, testParse(t, row.Input), 0)
+		for _, expected := range row.Expected {
+			if !strings.Contains(out.String(), expected) {
+				t.Errorf("printNode(%q): expected a line with %q, got:\n%s", row.Input, expected, out.String())
+			}
+		}
+	}
+}
+
+func TestToJSONNode(t *testing.T) {
+	stmt := toJSONNode(testParse(t, "let x = a - b\n")).Children[0]
+	if stmt.Attrs["Name"] != `"x"` || stmt.Attrs["Kind"] != "let" {
+		t.Errorf("VarDeclStatement: wrong attrs %v", stmt.Attrs)
+	}
+	if stmt.Value != "" {
+		t.Errorf("VarDeclStatement: expected no value, got %q", stmt.Value)
+	}
+
+	expr := stmt.Children[0]
+	if expr.Attrs["Operator"] != "-" {
+		t.Errorf("BinaryOperatorExpr: wrong attrs %v", expr.Attrs)
+	}
+
+	leaf := expr.Children[0]
+	if leaf.Attrs != nil || leaf.Value != `IdentifierExpr{nil,"a"}` {
+		t.Errorf("IdentifierExpr: expected only a value, got attrs %v and value %q", leaf.Attrs, leaf.Value)
+	}
+}
@@ -1,41 +1,51 @@
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"os"
-	"unicode/utf8"
 
 	"github.com/chronos-tachyon/go-spiderscript/ast"
+	"github.com/chronos-tachyon/go-spiderscript/format"
+	"github.com/chronos-tachyon/go-spiderscript/internal/diff"
 	"github.com/chronos-tachyon/go-spiderscript/token"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "fmt":
+			os.Exit(runFmt(os.Args[2:]))
+		case "tokens":
+			os.Exit(runTokens(os.Args[2:]))
+		case "ast":
+			os.Exit(runAST(os.Args[2:]))
+		case "run":
+			os.Exit(runRun(os.Args[2:]))
+		case "repl":
+			os.Exit(runREPL(os.Args[2:]))
+		}
+		if isScript(os.Args[1]) {
+			os.Exit(runRun(os.Args[1:]))
+		}
+	}
+
 	flag.Parse()
 
 	for _, inputFile := range flag.Args() {
-		bytes, err := ioutil.ReadFile(inputFile)
+		f, err := os.Open(inputFile)
 		if err != nil {
 			panic(err)
 		}
 
-		runes := make([]rune, 0, len(bytes))
-		offset := uint(0)
-		for offset < uint(len(bytes)) {
-			ch, n := utf8.DecodeRune(bytes[offset:])
-			if n < 1 {
-				n = 1
-			}
-			runes = append(runes, ch)
-			offset += uint(n)
-		}
-
 		var lexer token.Lexer
-		lexer.Init(inputFile, runes)
+		lexer.InitReader(inputFile, f)
 
 		var parser ast.Parser
 		parser.Init(&lexer)
+		f.Close()
 
 		var file ast.File
 		parser.Parse(&file)
@@ -50,3 +60,75 @@ func main() {
 		}
 	}
 }
+
+// runFmt implements "spiderscript fmt".  It returns the exit status: 0 on
+// success, 1 if a file could not be formatted or if -l or -d found a file
+// that is not formatted, and 2 for usage errors.
+func runFmt(args []string) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: spiderscript fmt [-l] [-d] [-w] [file...]\n")
+		flags.PrintDefaults()
+	}
+	list := flags.Bool("l", false, "list files whose formatting differs")
+	showDiff := flags.Bool("d", false, "display diffs instead of rewriting files")
+	write := flags.Bool("w", false, "write the result back to the source file")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	status := 0
+	process := func(path string, src []byte) {
+		res, err := format.Source(path, src)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			status = 1
+			return
+		}
+
+		changed := !bytes.Equal(src, res)
+		if changed && (*list || *showDiff) {
+			status = 1
+		}
+		if *list && changed {
+			fmt.Println(path)
+		}
+		if *showDiff && changed {
+			fmt.Print(diff.Unified(path+".orig", path, string(src), string(res)))
+		}
+		if *write && changed {
+			if err := ioutil.WriteFile(path, res, 0666); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				status = 1
+			}
+		}
+		if !*list && !*showDiff && !*write {
+			os.Stdout.Write(res)
+		}
+	}
+
+	if flags.NArg() == 0 {
+		if *write {
+			fmt.Fprintf(os.Stderr, "error: cannot use -w with standard input\n")
+			return 2
+		}
+		src, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+		process("<stdin>", src)
+		return status
+	}
+
+	for _, path := range flags.Args() {
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			status = 1
+			continue
+		}
+		process(path, src)
+	}
+	return status
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chronos-tachyon/go-spiderscript/ast"
+	"github.com/chronos-tachyon/go-spiderscript/exprtree"
+	"github.com/chronos-tachyon/go-spiderscript/sema"
+	"github.com/chronos-tachyon/go-spiderscript/token"
+)
+
+const (
+	replPrompt             = "> "
+	replContinuationPrompt = "... "
+)
+
+// runREPL implements "spiderscript repl".  It returns the exit status: 0 at
+// the end of the input, 1 if the input could not be read, and 2 for usage
+// errors.
+func runREPL(args []string) int {
+	flags := flag.NewFlagSet("repl", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: spiderscript repl\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	r, err := newREPL(os.Stdin, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	return r.Run()
+}
+
+// repl reads SpiderScript from in and runs it in a persistent main module,
+// one chunk at a time.  A chunk is one line, or several if the first line
+// leaves a bracket, string, regex, PEG or comment open.  Prompts, results
+// and errors all go to out, so that a session is plain text.
+//
+// The session is lexed as one text, so that positions in errors count from
+// the start of the session; pos is where the next chunk starts.
+type repl struct {
+	in     *bufio.Reader
+	out    io.Writer
+	text   []rune
+	pos    token.Position
+	interp *exprtree.Interp
+	module *exprtree.Module
+}
+
+func newREPL(in io.Reader, out io.Writer) (*repl, error) {
+	interp := exprtree.NewSystemInterp()
+	module, err := interp.NewMainModule()
+	if err != nil {
+		return nil, err
+	}
+	if err := declareBuiltins(module, out, nil); err != nil {
+		return nil, err
+	}
+	r := &repl{
+		in:     bufio.NewReader(in),
+		out:    out,
+		pos:    token.Position{Path: "<stdin>"},
+		interp: interp,
+		module: module,
+	}
+	return r, nil
+}
+
+// Run reads and runs chunks until the end of the input.
+func (r *repl) Run() int {
+	for {
+		prompt := replPrompt
+		if uint(len(r.text)) > r.pos.RuneOffset {
+			prompt = replContinuationPrompt
+		}
+		fmt.Fprint(r.out, prompt)
+
+		line, err := r.in.ReadString('\n')
+		if err != nil && err != io.EOF {
+			fmt.Fprintf(r.out, "\nerror: %v\n", err)
+			return 1
+		}
+		r.text = append(r.text, []rune(line)...)
+
+		atEOF := (err == io.EOF)
+		if atEOF {
+			fmt.Fprintln(r.out)
+		}
+		r.eval(atEOF)
+		if atEOF {
+			return 0
+		}
+	}
+}
+
+// eval parses the chunk that starts at pos, lowers it into the module and
+// runs it, printing the value and type of each expression statement.  It
+// does nothing if the chunk is incomplete and more input may follow.  A
+// chunk with errors declares nothing, so that it can be fixed and entered
+// again.
+func (r *repl) eval(atEOF bool) {
+	var lexer token.Lexer
+	lexer.InitAt(r.text, r.pos)
+
+	var parser ast.Parser
+	parser.Init(&lexer)
+
+	var file ast.File
+	parser.Parse(&file)
+
+	if !atEOF && isIncomplete(&parser) {
+		return
+	}
+
+	tokens := parser.Tokens()
+	r.pos = tokens[len(tokens)-1].End
+
+	errs := parser.Errors()
+	var prog *sema.Program
+	if errs == nil {
+		undo := r.checkpoint()
+		if prog, errs = sema.Lower(r.module, &file); errs != nil {
+			undo()
+		}
+	}
+	for _, err := range errs {
+		fmt.Fprintf(r.out, "error: %v\n", err)
+	}
+	if errs != nil {
+		return
+	}
+
+	value, returned, err := prog.Run(r.show)
+	if err != nil {
+		fmt.Fprintf(r.out, "error: %v\n", err)
+	} else if returned {
+		r.show(value)
+	}
+}
+
+// checkpoint records the symbols and imports of the module, and returns a
+// function that removes any that were added since.
+func (r *repl) checkpoint() func() {
+	symbols := make(map[string]*exprtree.Symbol)
+	r.module.Symbols().All(symbols)
+	imports := make(map[string]*exprtree.Module)
+	r.module.AllImports(imports)
+
+	return func() {
+		after := make(map[string]*exprtree.Symbol)
+		r.module.Symbols().All(after)
+		for name := range after {
+			if _, found := symbols[name]; !found {
+				r.module.Symbols().Remove(name)
+			}
+		}
+
+		afterImports := make(map[string]*exprtree.Module)
+		r.module.AllImports(afterImports)
+		for name := range afterImports {
+			if _, found := imports[name]; !found {
+				r.module.RemoveImport(name)
+			}
+		}
+	}
+}
+
+// show prints a value and its type, unless the value is Void.
+func (r *repl) show(value exprtree.Value) {
+	t := value.Type()
+	if t.Is(r.interp.VoidType()) {
+		return
+	}
+	if k := t.Kind(); k == exprtree.MutableKind || k == exprtree.ConstKind {
+		t = t.Details().(*exprtree.Type)
+	}
+	fmt.Fprintf(r.out, "%s : %s\n", formatValue(value), t.CanonicalName())
+}
+
+// isIncomplete reports whether the parsed input stops in the middle of
+// something: inside a bracket, string, regex, PEG or comment, or in the
+// middle of a statement.
+func isIncomplete(p *ast.Parser) bool {
+	for _, diag := range p.Diagnostics() {
+		switch diag.Code {
+		case token.CodeUnterminatedString, token.CodeUnterminatedRegex, token.CodeUnterminatedPEG, token.CodeUnterminatedComment:
+			return true
+		}
+	}
+
+	depth := 0
+	for _, tok := range p.Tokens() {
+		switch tok.Type {
+		case token.LParen, token.LBracket, token.LBrace:
+			depth++
+		case token.RParen, token.RBracket, token.RBrace:
+			depth--
+		}
+	}
+	if depth > 0 {
+		return true
+	}
+
+	for _, err := range p.Errors() {
+		if se, ok := err.(*ast.SyntaxError); ok && se.Found.Type == token.EOF {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestREPL(t *testing.T) {
+	type testRow struct {
+		Name     string
+		Input    string
+		Expected string
+	}
+
+	testData := []testRow{
+		{
+			Name:     "empty",
+			Input:    "",
+			Expected: "> \n",
+		},
+		{
+			Name:     "comments",
+			Input:    "// one\n\n/* two */\n",
+			Expected: "> > > > \n",
+		},
+		{
+			Name:     "values",
+			Input:    "let x = 1\nx + 1\nx == 1\n\"spider\"\n",
+			Expected: "> > 2 : builtin::SInt64\n> true : builtin::Bool\n> spider : builtin::String\n> \n",
+		},
+		{
+			Name:     "variables",
+			Input:    "var x = 40\nx += 2\nx\n",
+			Expected: "> > 42 : builtin::SInt64\n> 42 : builtin::SInt64\n> \n",
+		},
+		{
+			Name:     "redeclare-after-error",
+			Input:    "func f(): SInt64 { return \"x\" }\nfunc f(): SInt64 { return 1 }\nf()\n",
+			Expected: "> error: \"<stdin>\"(L1,C27,+26): return value: expected builtin::SInt64, got builtin::String\n> > 1 : builtin::SInt64\n> \n",
+		},
+		{
+			Name:     "print",
+			Input:    "print(\"hello\")\n",
+			Expected: "> hello\n> \n",
+		},
+		{
+			Name:     "return",
+			Input:    "return 2.5\n",
+			Expected: "> 2.5 : builtin::Float64\n> \n",
+		},
+		{
+			Name:     "open-brace",
+			Input:    "func f(): SInt64 {\n\treturn 1\n}\nf()\n",
+			Expected: "> ... ... > 1 : builtin::SInt64\n> \n",
+		},
+		{
+			Name:     "unterminated-string",
+			Input:    "// x\nlet s = \"a\nb\"\ns\n",
+			Expected: "> > ... > a\nb : builtin::String\n> \n",
+		},
+		{
+			Name:     "type-error",
+			Input:    "let x = 1\nx + \"a\"\nx\n",
+			Expected: "> > error: \"<stdin>\"(L2,C1,+10): right operand of +: expected builtin::SInt64, got builtin::String\n> 1 : builtin::SInt64\n> \n",
+		},
+		{
+			Name:     "runtime-error",
+			Input:    "arg(0)\n",
+			Expected: "> error: arg(0): index out of range [0..0)\n> \n",
+		},
+		{
+			Name:     "unterminated-comment",
+			Input:    "/* a\nb\n*/\n",
+			Expected: "> ... ... > \n",
+		},
+		{
+			Name:     "syntax-error",
+			Input:    "let = 1\n// ok\n",
+			Expected: "> error: \"<stdin>\"(L1,C5,+4): unexpected Equal, expected one of [Identifier]\n> > \n",
+		},
+		{
+			Name:     "incomplete-at-eof",
+			Input:    "/* a\n",
+			Expected: "> ... \nerror: \"<stdin>\"(L1,C1,+0): unterminated comment\nerror: \"<stdin>\"(L1,C1,+0): unexpected Partial, expected one of [ShebangLine EOF Semicolon Pragma KeywordImport KeywordAlias KeywordType KeywordConst KeywordVar KeywordLet KeywordFunc KeywordMethod LBrace KeywordIf KeywordWhile KeywordFor KeywordForEach KeywordSwitch KeywordReturn KeywordGoto Identifier LParen Number String Regex PEG KeywordNull KeywordPlaceholder KeywordStruct KeywordUnion KeywordBitfield KeywordInterface]\n",
+		},
+	}
+
+	for _, row := range testData {
+		t.Run(row.Name, func(t *testing.T) {
+			var out strings.Builder
+			r, err := newREPL(strings.NewReader(row.Input), &out)
+			if err != nil {
+				t.Fatalf("newREPL: unexpected error: %v", err)
+			}
+			if status := r.Run(); status != 0 {
+				t.Errorf("Run: expected status 0, got %d", status)
+			}
+			if actual := out.String(); actual != row.Expected {
+				t.Errorf("wrong output:\n\texpected: %q\n\t  actual: %q", row.Expected, actual)
+			}
+		})
+	}
+}
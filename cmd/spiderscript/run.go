@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chronos-tachyon/go-spiderscript/ast"
+	"github.com/chronos-tachyon/go-spiderscript/exprtree"
+	"github.com/chronos-tachyon/go-spiderscript/sema"
+	"github.com/chronos-tachyon/go-spiderscript/token"
+)
+
+// runRun implements "spiderscript run FILE".  Everything after FILE is left
+// for the script, which reads it with argc() and arg(i).  It returns the exit
+// status: the script's own, 1 if the script could not be loaded or run, and
+// 2 for usage errors.
+//
+// The script's own exit status is the integer value of a top-level return
+// statement, or 0 if the script returns nothing or runs to the end.
+func runRun(args []string) int {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: spiderscript run FILE [arg...]\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	script := &script{
+		Path:   flags.Arg(0),
+		Args:   flags.Args()[1:],
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+	return runScript(script)
+}
+
+// runScript loads and runs s, and returns its exit status.
+func runScript(s *script) int {
+	if err := s.Load(); err != nil {
+		fmt.Fprintf(s.Stderr, "error: %v\n", err)
+		return 1
+	}
+	if errs := s.Errors; errs != nil {
+		for _, err := range errs {
+			fmt.Fprintf(s.Stderr, "error: %v\n", err)
+		}
+		return 1
+	}
+	return s.Run()
+}
+
+// isScript reports whether path names a file that starts with a "#!" line.
+// Such a file is run rather than dumped, so that a script can start with
+// "#!/usr/bin/env spiderscript".
+func isScript(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	var magic [2]byte
+	n, _ := io.ReadFull(f, magic[:])
+	return n == 2 && magic[0] == '#' && magic[1] == '!'
+}
+
+// script is a SpiderScript file loaded as the main module of an interpreter.
+// The script prints to Stdout, and errors go to Stderr.
+type script struct {
+	Path    string
+	Args    []string
+	Stdout  io.Writer
+	Stderr  io.Writer
+	File    ast.File
+	Errors  []error
+	Interp  *exprtree.Interp
+	Module  *exprtree.Module
+	Program *sema.Program
+}
+
+// Load parses the script, creates the interpreter and its main module, and
+// lowers the script into the module.  Syntax and type errors are left in
+// Errors.
+func (s *script) Load() error {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var lexer token.Lexer
+	lexer.InitReader(s.Path, f)
+
+	var parser ast.Parser
+	parser.Init(&lexer)
+	parser.Parse(&s.File)
+	s.Errors = parser.Errors()
+
+	s.Interp = exprtree.NewSystemInterp()
+	s.Module, err = s.Interp.NewMainModule()
+	if err != nil {
+		return err
+	}
+	if err := declareBuiltins(s.Module, s.Stdout, s.Args); err != nil {
+		return err
+	}
+	if s.Errors == nil {
+		s.Program, s.Errors = sema.Lower(s.Module, &s.File)
+	}
+	return nil
+}
+
+// Run runs the script's top-level statements in order and returns its exit
+// status.
+func (s *script) Run() int {
+	value, returned, err := s.Program.Run(nil)
+	status := 0
+	if err == nil && returned {
+		status, err = exitStatus(value)
+	}
+	if err != nil {
+		fmt.Fprintf(s.Stderr, "error: %v\n", err)
+		return 1
+	}
+	return status
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunScript(t *testing.T) {
+	type testRow struct {
+		Name   string
+		Input  string
+		Args   []string
+		Status int
+		Stdout string
+		Stderr string
+	}
+
+	testData := []testRow{
+		{
+			Name:   "empty",
+			Input:  "#!/usr/bin/env spiderscript\n// nothing\n",
+			Status: 0,
+		},
+		{
+			Name:   "print",
+			Input:  "print(\"hello\")\nprint(6 * 7)\nprint(1.5)\nprint(1 < 2)\n",
+			Stdout: "hello\n42\n1.5\ntrue\n",
+		},
+		{
+			Name:   "functions",
+			Input:  "func fib(n: SInt64): SInt64 {\nif n < 2 { return n }\nlet a = fib(n - 1)\nlet b = fib(n - 2)\nreturn a + b\n}\nfor i := 0; i < 8; i++ {\nprint(fib(i))\n}\n",
+			Stdout: "0\n1\n1\n2\n3\n5\n8\n13\n",
+		},
+		{
+			Name:   "args",
+			Input:  "for i := 0; i < argc(); i++ {\nprint(arg(i))\n}\nreturn argc()\n",
+			Args:   []string{"one", "two"},
+			Status: 2,
+			Stdout: "one\ntwo\n",
+		},
+		{
+			Name:   "exit-status",
+			Input:  "print(\"before\")\nreturn 3\nprint(\"after\")\n",
+			Status: 3,
+			Stdout: "before\n",
+		},
+		{
+			Name:   "return-nothing",
+			Input:  "return\n",
+			Status: 0,
+		},
+		{
+			Name:   "bad-exit-status",
+			Input:  "return \"x\"\n",
+			Status: 1,
+			Stderr: "error: exit status must be an integer, not builtin::String\n",
+		},
+		{
+			Name:   "out-of-range-exit-status",
+			Input:  "return 256\n",
+			Status: 1,
+			Stderr: "error: exit status 256 is out of range [0..255]\n",
+		},
+		{
+			Name:   "runtime-error",
+			Input:  "print(\"before\")\nprint(arg(0))\nprint(\"after\")\n",
+			Status: 1,
+			Stdout: "before\n",
+			Stderr: "error: arg(0): index out of range [0..0)\n",
+		},
+		{
+			Name:   "type-error",
+			Input:  "print(\"before\")\nlet x: UInt8 = \"x\"\n",
+			Status: 1,
+			Stderr: "error: \"test.spider\"(L2,C16,+31): initializer: expected builtin::UInt8, got builtin::String\n",
+		},
+		{
+			Name:   "syntax-error",
+			Input:  "let = 1\n",
+			Status: 1,
+			Stderr: "error: \"test.spider\"(L1,C5,+4): unexpected Equal, expected one of [Identifier]\n",
+		},
+	}
+
+	dir, err := ioutil.TempDir("", "spiderscript-run-")
+	if err != nil {
+		t.Fatalf("TempDir: unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, row := range testData {
+		t.Run(row.Name, func(t *testing.T) {
+			path := filepath.Join(dir, "test.spider")
+			if err := ioutil.WriteFile(path, []byte(row.Input), 0666); err != nil {
+				t.Fatalf("WriteFile: unexpected error: %v", err)
+			}
+
+			var stdout, stderr strings.Builder
+			s := &script{
+				Path:   path,
+				Args:   row.Args,
+				Stdout: &stdout,
+				Stderr: &stderr,
+			}
+			if status := runScript(s); status != row.Status {
+				t.Errorf("runScript: expected status %d, got %d", row.Status, status)
+			}
+			if actual := stdout.String(); actual != row.Stdout {
+				t.Errorf("wrong output:\n\texpected: %q\n\t  actual: %q", row.Stdout, actual)
+			}
+			if actual := strings.Replace(stderr.String(), path, "test.spider", -1); actual != row.Stderr {
+				t.Errorf("wrong errors:\n\texpected: %q\n\t  actual: %q", row.Stderr, actual)
+			}
+		})
+	}
+}
@@ -219,6 +219,8 @@ const (
 	MutableKind
 	ConstKind
 	NamedKind
+
+	TypeParamKind
 )
 
 var typeKindNames = []string{
@@ -252,6 +254,7 @@ var typeKindNames = []string{
 	"MutableKind",
 	"ConstKind",
 	"NamedKind",
+	"TypeParamKind",
 }
 
 func (kind TypeKind) String() string {
@@ -32,10 +32,16 @@ func TestTypeKind(t *testing.T) {
 			ExpectedGoString: "NamedKind",
 		},
 		{
-			Name:             "NamedKind+1",
-			Input:            NamedKind + 1,
-			ExpectedString:   "TypeKind(30)",
-			ExpectedGoString: "TypeKind(30)",
+			Name:             "TypeParamKind",
+			Input:            TypeParamKind,
+			ExpectedString:   "TypeParamKind",
+			ExpectedGoString: "TypeParamKind",
+		},
+		{
+			Name:             "TypeParamKind+1",
+			Input:            TypeParamKind + 1,
+			ExpectedString:   "TypeKind(31)",
+			ExpectedGoString: "TypeKind(31)",
 		},
 	}
 
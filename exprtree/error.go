@@ -3,7 +3,10 @@ package exprtree
 import (
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
+
+	"github.com/chronos-tachyon/go-spiderscript/operator"
 )
 
 // StackTrace
@@ -271,3 +274,148 @@ func (err *DuplicateSymbolError) Error() string {
 var _ error = (*DuplicateSymbolError)(nil)
 
 // }}}
+
+// DivisionByZeroError
+// {{{
+
+type DivisionByZeroError struct {
+	Type *Type
+}
+
+func (err *DivisionByZeroError) Error() string {
+	return fmt.Sprintf("division by zero: %s", err.Type.CanonicalName())
+}
+
+var _ error = (*DivisionByZeroError)(nil)
+
+// }}}
+
+// IndexOutOfRangeError
+// {{{
+
+type IndexOutOfRangeError struct {
+	Index  int64
+	Length uint
+}
+
+func (err *IndexOutOfRangeError) Error() string {
+	return fmt.Sprintf("index out of range: index %d, length %d", err.Index, err.Length)
+}
+
+var _ error = (*IndexOutOfRangeError)(nil)
+
+// }}}
+
+// TypeMismatchError
+// {{{
+
+type TypeMismatchError struct {
+	Context  string
+	Expected *Type
+	Actual   *Type
+}
+
+func (err *TypeMismatchError) Error() string {
+	return fmt.Sprintf("%s: expected %s, got %s", err.Context, err.Expected.CanonicalName(), err.Actual.CanonicalName())
+}
+
+var _ error = (*TypeMismatchError)(nil)
+
+// }}}
+
+// OperandTypeError
+// {{{
+
+type OperandTypeError struct {
+	Operator operator.Operator
+	Types    []*Type
+}
+
+func (err *OperandTypeError) Error() string {
+	names := make([]string, len(err.Types))
+	for index, t := range err.Types {
+		names[index] = t.CanonicalName()
+	}
+	return fmt.Sprintf("operator %v cannot be applied to %s", err.Operator, strings.Join(names, " and "))
+}
+
+var _ error = (*OperandTypeError)(nil)
+
+// }}}
+
+// NotAssignableError
+// {{{
+
+type NotAssignableError struct {
+	Target *Type
+}
+
+func (err *NotAssignableError) Error() string {
+	return fmt.Sprintf("cannot assign to %s: only mutable values may be assigned", err.Target.CanonicalName())
+}
+
+var _ error = (*NotAssignableError)(nil)
+
+// }}}
+
+// NoMatchingOverloadError
+// {{{
+
+type NoMatchingOverloadError struct {
+	Args       []*Type
+	Named      map[string]*Type
+	Candidates []*Function
+}
+
+func (err *NoMatchingOverloadError) Error() string {
+	return fmt.Sprintf("no overload accepts %s; candidates are %s", formatCallArgs(err.Args, err.Named), formatCandidates(err.Candidates))
+}
+
+var _ error = (*NoMatchingOverloadError)(nil)
+
+// }}}
+
+// AmbiguousCallError
+// {{{
+
+type AmbiguousCallError struct {
+	Args       []*Type
+	Named      map[string]*Type
+	Candidates []*Function
+}
+
+func (err *AmbiguousCallError) Error() string {
+	return fmt.Sprintf("ambiguous call with %s; candidates are %s", formatCallArgs(err.Args, err.Named), formatCandidates(err.Candidates))
+}
+
+var _ error = (*AmbiguousCallError)(nil)
+
+// }}}
+
+func formatCallArgs(args []*Type, named map[string]*Type) string {
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list := make([]string, 0, len(args)+len(names))
+	for _, t := range args {
+		list = append(list, t.CanonicalName())
+	}
+	for _, name := range names {
+		list = append(list, name+": "+named[name].CanonicalName())
+	}
+	return "(" + strings.Join(list, ", ") + ")"
+}
+
+func formatCandidates(candidates []*Function) string {
+	if len(candidates) == 0 {
+		return "none"
+	}
+	list := make([]string, len(candidates))
+	for index, fn := range candidates {
+		list[index] = fn.CanonicalName()
+	}
+	return strings.Join(list, " and ")
+}
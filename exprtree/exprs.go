@@ -0,0 +1,1193 @@
+package exprtree
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/chronos-tachyon/go-spiderscript/operator"
+)
+
+// walkExpr visits self and its children in the given order.  InOrder visits
+// the first child, then self, then the rest.
+func walkExpr(self Expr, children []Expr, to TraversalOrder, fn func(Expr)) {
+	switch to {
+	case PreOrder:
+		fn(self)
+		for _, child := range children {
+			child.Walk(to, fn)
+		}
+
+	case InOrder:
+		if len(children) == 0 {
+			fn(self)
+			return
+		}
+		children[0].Walk(to, fn)
+		fn(self)
+		for _, child := range children[1:] {
+			child.Walk(to, fn)
+		}
+
+	case PostOrder:
+		for _, child := range children {
+			child.Walk(to, fn)
+		}
+		fn(self)
+
+	default:
+		panic(fmt.Errorf("BUG: unknown TraversalOrder %v", to))
+	}
+}
+
+func checkOut(expr Expr, out *Value) error {
+	checkNotNil("out", out)
+	if !sameType(out.Type(), expr.Type()) {
+		return fmt.Errorf("cannot evaluate %s into %s", expr.Type().CanonicalName(), out.Type().CanonicalName())
+	}
+	return nil
+}
+
+// ConstantExpr
+// {{{
+
+// ConstantExpr is an expression with a fixed value.
+type ConstantExpr struct {
+	value Value
+}
+
+// NewConstantExpr returns a constant of type t.  The value in is given as for
+// (Value).Set, except that any Go integer or float that fits in t may be used
+// for the numeric kinds.
+func (interp *Interp) NewConstantExpr(t *Type, in interface{}) (*ConstantExpr, error) {
+	checkNotNil("t", t)
+
+	value := interp.NewTemporaryValue(t)
+	kind := t.Chase().Kind()
+	switch numberClassOf(kind) {
+	case unsignedNumber:
+		u64, err := toUInt(kind, in)
+		if err != nil {
+			return nil, err
+		}
+		storeBits(value, u64)
+
+	case signedNumber:
+		s64, err := toSInt(kind, in)
+		if err != nil {
+			return nil, err
+		}
+		storeBits(value, uint64(s64))
+
+	case floatNumber:
+		f64, err := toFloat(in)
+		if err != nil {
+			return nil, err
+		}
+		storeFloat(value, f64)
+
+	case complexNumber:
+		c128, err := toComplex(in)
+		if err != nil {
+			return nil, err
+		}
+		storeComplex(value, c128)
+
+	default:
+		var ok bool
+		switch kind {
+		case EnumKind, BitfieldKind:
+			ok = true
+		case StringKind:
+			_, ok = in.(*String)
+		case ErrorKind:
+			_, ok = in.(*Error)
+		case ReflectedTypeKind:
+			_, ok = in.(*Type)
+		}
+		if !ok {
+			return nil, fmt.Errorf("cannot make a %s constant from %T", t.CanonicalName(), in)
+		}
+		if err := value.Set(in); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ConstantExpr{value: value}, nil
+}
+
+func (expr *ConstantExpr) Interp() *Interp {
+	return expr.value.Interp()
+}
+
+func (expr *ConstantExpr) Type() *Type {
+	return expr.value.Type()
+}
+
+func (expr *ConstantExpr) Value() interface{} {
+	return expr.value.Get()
+}
+
+func (expr *ConstantExpr) Walk(to TraversalOrder, fn func(Expr)) {
+	walkExpr(expr, nil, to, fn)
+}
+
+func (expr *ConstantExpr) EvalInto(out *Value) error {
+	if err := checkOut(expr, out); err != nil {
+		return err
+	}
+	return out.CopyFrom(expr.value)
+}
+
+var _ Expr = (*ConstantExpr)(nil)
+
+// }}}
+
+// SymbolExpr
+// {{{
+
+// SymbolExpr is a reference to the current value of a symbol.
+type SymbolExpr struct {
+	value Value
+}
+
+// NewSymbolExpr returns a reference to value, which must belong to a symbol.
+func NewSymbolExpr(value Value) *SymbolExpr {
+	checkNotNil("value.Symbol()", value.Symbol())
+	return &SymbolExpr{value: value}
+}
+
+func (expr *SymbolExpr) Interp() *Interp {
+	return expr.value.Interp()
+}
+
+func (expr *SymbolExpr) Type() *Type {
+	return expr.value.Type()
+}
+
+func (expr *SymbolExpr) Symbol() *Symbol {
+	return expr.value.Symbol()
+}
+
+func (expr *SymbolExpr) Walk(to TraversalOrder, fn func(Expr)) {
+	walkExpr(expr, nil, to, fn)
+}
+
+func (expr *SymbolExpr) EvalInto(out *Value) error {
+	if err := checkOut(expr, out); err != nil {
+		return err
+	}
+	return out.CopyFrom(expr.value)
+}
+
+var _ Expr = (*SymbolExpr)(nil)
+
+// }}}
+
+// UnaryOperatorExpr
+// {{{
+
+// UnaryOperatorExpr applies one of the unary operators UnaryPos, UnaryNeg,
+// BitwiseNOT or LogicalNOT.
+type UnaryOperatorExpr struct {
+	op      operator.Operator
+	operand Expr
+}
+
+func (interp *Interp) NewUnaryOperatorExpr(op operator.Operator, operand Expr) (*UnaryOperatorExpr, error) {
+	checkNotNil("operand", operand)
+
+	t := operand.Type()
+	var ok bool
+	switch op {
+	case operator.UnaryPos, operator.UnaryNeg:
+		ok = numberClassOf(t.Chase().Kind()) != notNumber
+	case operator.BitwiseNOT:
+		ok = isInteger(numberClassOf(t.Chase().Kind())) || t.Chase().Kind() == BitfieldKind
+	case operator.LogicalNOT:
+		ok = isBool(t)
+	default:
+		return nil, fmt.Errorf("operator %v is not a supported unary operator", op)
+	}
+	if !ok {
+		return nil, fmt.Errorf("operator %v cannot be applied to %s", op, t.CanonicalName())
+	}
+
+	return &UnaryOperatorExpr{op: op, operand: operand}, nil
+}
+
+func (expr *UnaryOperatorExpr) Interp() *Interp {
+	return expr.operand.Interp()
+}
+
+func (expr *UnaryOperatorExpr) Type() *Type {
+	return expr.operand.Type()
+}
+
+func (expr *UnaryOperatorExpr) Operator() operator.Operator {
+	return expr.op
+}
+
+func (expr *UnaryOperatorExpr) Operand() Expr {
+	return expr.operand
+}
+
+func (expr *UnaryOperatorExpr) Walk(to TraversalOrder, fn func(Expr)) {
+	walkExpr(expr, []Expr{expr.operand}, to, fn)
+}
+
+func (expr *UnaryOperatorExpr) EvalInto(out *Value) error {
+	if err := checkOut(expr, out); err != nil {
+		return err
+	}
+
+	frame := expr.Interp().newTempFrame()
+	defer frame.release()
+
+	x, err := frame.eval(expr.operand)
+	if err != nil {
+		return err
+	}
+
+	switch expr.op {
+	case operator.UnaryPos:
+		return out.CopyFrom(x)
+
+	case operator.UnaryNeg:
+		switch numberClassOf(expr.Type().Chase().Kind()) {
+		case floatNumber:
+			storeFloat(*out, -loadFloat(x))
+		case complexNumber:
+			storeComplex(*out, -loadComplex(x))
+		default:
+			storeBits(*out, -loadBits(x))
+		}
+
+	case operator.BitwiseNOT:
+		storeBits(*out, ^loadBits(x))
+
+	case operator.LogicalNOT:
+		storeBool(*out, !loadBool(x))
+	}
+	return nil
+}
+
+var _ Expr = (*UnaryOperatorExpr)(nil)
+
+// }}}
+
+// BinaryOperatorExpr
+// {{{
+
+// BinaryOperatorExpr applies a binary operator.  The arithmetic, bitwise,
+// shift and rotate, comparison and logical operators are supported.  Both
+// operands must have the same type, except for shifts and rotates, whose
+// right operand may be any integer.  LogicalAND and LogicalOR only evaluate
+// their right operand if they need to.
+type BinaryOperatorExpr struct {
+	op    operator.Operator
+	left  Expr
+	right Expr
+	type_ *Type
+}
+
+func (interp *Interp) NewBinaryOperatorExpr(op operator.Operator, left Expr, right Expr) (*BinaryOperatorExpr, error) {
+	checkNotNil("left", left)
+	checkNotNil("right", right)
+
+	lt := left.Type()
+	rt := right.Type()
+	lkind := lt.Chase().Kind()
+	lclass := numberClassOf(lkind)
+
+	var t *Type
+	var ok bool
+	switch op {
+	case operator.Add, operator.Sub, operator.Mul, operator.Div:
+		t, ok = lt, lclass != notNumber
+
+	case operator.Mod:
+		t, ok = lt, lclass != notNumber && lclass != complexNumber
+
+	case operator.LShift, operator.RShift, operator.LRotate, operator.RRotate:
+		if !isInteger(lclass) || !isInteger(numberClassOf(rt.Chase().Kind())) {
+			return nil, fmt.Errorf("operator %v cannot be applied to %s and %s", op, lt.CanonicalName(), rt.CanonicalName())
+		}
+		return &BinaryOperatorExpr{op: op, left: left, right: right, type_: lt}, nil
+
+	case operator.BitwiseAND, operator.BitwiseXOR, operator.BitwiseOR:
+		t, ok = lt, isInteger(lclass) || lkind == BitfieldKind
+
+	case operator.CmpEQ, operator.CmpNE:
+		t, ok = interp.BoolType(), lclass != notNumber || lkind == EnumKind || lkind == BitfieldKind
+
+	case operator.CmpLT, operator.CmpLE, operator.CmpGT, operator.CmpGE:
+		t, ok = interp.BoolType(), (lclass != notNumber && lclass != complexNumber) || lkind == EnumKind
+
+	case operator.CmpCMP:
+		t, ok = interp.OrderType(), (lclass != notNumber && lclass != complexNumber) || lkind == EnumKind
+
+	case operator.LogicalAND, operator.LogicalXOR, operator.LogicalOR:
+		t, ok = interp.BoolType(), isBool(lt)
+
+	default:
+		return nil, fmt.Errorf("operator %v is not a supported binary operator", op)
+	}
+	if !ok || !sameType(lt, rt) {
+		return nil, fmt.Errorf("operator %v cannot be applied to %s and %s", op, lt.CanonicalName(), rt.CanonicalName())
+	}
+
+	return &BinaryOperatorExpr{op: op, left: left, right: right, type_: t}, nil
+}
+
+func (expr *BinaryOperatorExpr) Interp() *Interp {
+	return expr.type_.Interp()
+}
+
+func (expr *BinaryOperatorExpr) Type() *Type {
+	return expr.type_
+}
+
+func (expr *BinaryOperatorExpr) Operator() operator.Operator {
+	return expr.op
+}
+
+func (expr *BinaryOperatorExpr) Left() Expr {
+	return expr.left
+}
+
+func (expr *BinaryOperatorExpr) Right() Expr {
+	return expr.right
+}
+
+func (expr *BinaryOperatorExpr) Walk(to TraversalOrder, fn func(Expr)) {
+	walkExpr(expr, []Expr{expr.left, expr.right}, to, fn)
+}
+
+func (expr *BinaryOperatorExpr) EvalInto(out *Value) error {
+	if err := checkOut(expr, out); err != nil {
+		return err
+	}
+
+	frame := expr.Interp().newTempFrame()
+	defer frame.release()
+
+	x, err := frame.eval(expr.left)
+	if err != nil {
+		return err
+	}
+
+	switch expr.op {
+	case operator.LogicalAND:
+		if !loadBool(x) {
+			storeBool(*out, false)
+			return nil
+		}
+		return expr.right.EvalInto(out)
+
+	case operator.LogicalOR:
+		if loadBool(x) {
+			storeBool(*out, true)
+			return nil
+		}
+		return expr.right.EvalInto(out)
+	}
+
+	y, err := frame.eval(expr.right)
+	if err != nil {
+		return err
+	}
+
+	switch expr.op {
+	case operator.Add, operator.Sub, operator.Mul, operator.Div, operator.Mod:
+		return arithmetic(expr.op, *out, x, y)
+
+	case operator.LShift, operator.RShift, operator.LRotate, operator.RRotate:
+		return shift(expr.op, *out, x, y)
+
+	case operator.BitwiseAND:
+		storeBits(*out, loadBits(x)&loadBits(y))
+
+	case operator.BitwiseXOR:
+		storeBits(*out, loadBits(x)^loadBits(y))
+
+	case operator.BitwiseOR:
+		storeBits(*out, loadBits(x)|loadBits(y))
+
+	case operator.LogicalXOR:
+		storeBool(*out, loadBool(x) != loadBool(y))
+
+	case operator.CmpEQ, operator.CmpNE:
+		equal := false
+		if cmp, ordered := compare(x, y); ordered {
+			equal = (cmp == 0)
+		}
+		storeBool(*out, equal == (expr.op == operator.CmpEQ))
+
+	case operator.CmpLT, operator.CmpLE, operator.CmpGT, operator.CmpGE:
+		cmp, ordered := compare(x, y)
+		var result bool
+		if ordered {
+			switch expr.op {
+			case operator.CmpLT:
+				result = (cmp < 0)
+			case operator.CmpLE:
+				result = (cmp <= 0)
+			case operator.CmpGT:
+				result = (cmp > 0)
+			case operator.CmpGE:
+				result = (cmp >= 0)
+			}
+		}
+		storeBool(*out, result)
+
+	case operator.CmpCMP:
+		cmp, ordered := compare(x, y)
+		if !ordered {
+			return fmt.Errorf("cannot order %v and %v", x.Get(), y.Get())
+		}
+		storeBits(*out, uint64(int64(cmp)))
+	}
+	return nil
+}
+
+var _ Expr = (*BinaryOperatorExpr)(nil)
+
+func arithmetic(op operator.Operator, out Value, x Value, y Value) error {
+	switch numberClassOf(x.Type().Chase().Kind()) {
+	case unsignedNumber:
+		a, b := loadBits(x), loadBits(y)
+		var c uint64
+		switch op {
+		case operator.Add:
+			c = a + b
+		case operator.Sub:
+			c = a - b
+		case operator.Mul:
+			c = a * b
+		case operator.Div, operator.Mod:
+			if b == 0 {
+				return &DivisionByZeroError{Type: x.Type()}
+			}
+			if op == operator.Div {
+				c = a / b
+			} else {
+				c = a % b
+			}
+		}
+		storeBits(out, c)
+
+	case signedNumber:
+		a, b := int64(loadBits(x)), int64(loadBits(y))
+		var c int64
+		switch op {
+		case operator.Add:
+			c = a + b
+		case operator.Sub:
+			c = a - b
+		case operator.Mul:
+			c = a * b
+		case operator.Div, operator.Mod:
+			if b == 0 {
+				return &DivisionByZeroError{Type: x.Type()}
+			}
+			if op == operator.Div {
+				c = a / b
+			} else {
+				c = a % b
+			}
+		}
+		storeBits(out, uint64(c))
+
+	case floatNumber:
+		a, b := loadFloat(x), loadFloat(y)
+		var c float64
+		switch op {
+		case operator.Add:
+			c = a + b
+		case operator.Sub:
+			c = a - b
+		case operator.Mul:
+			c = a * b
+		case operator.Div:
+			c = a / b
+		case operator.Mod:
+			c = math.Mod(a, b)
+		}
+		storeFloat(out, c)
+
+	case complexNumber:
+		a, b := loadComplex(x), loadComplex(y)
+		var c complex128
+		switch op {
+		case operator.Add:
+			c = a + b
+		case operator.Sub:
+			c = a - b
+		case operator.Mul:
+			c = a * b
+		case operator.Div:
+			c = a / b
+		}
+		storeComplex(out, c)
+	}
+	return nil
+}
+
+func shift(op operator.Operator, out Value, x Value, y Value) error {
+	count := loadBits(y)
+	if numberClassOf(y.Type().Chase().Kind()) == signedNumber && int64(count) < 0 {
+		return fmt.Errorf("negative shift count %d", int64(count))
+	}
+
+	width := 8 * integerBytes(integerKind(x.Type()))
+	mask := ^uint64(0) >> (64 - width)
+	a := loadBits(x)
+
+	var c uint64
+	switch op {
+	case operator.LShift:
+		if count < 64 {
+			c = a << count
+		}
+
+	case operator.RShift:
+		if numberClassOf(x.Type().Chase().Kind()) == signedNumber {
+			if count > 63 {
+				count = 63
+			}
+			c = uint64(int64(a) >> count)
+		} else if count < 64 {
+			c = a >> count
+		}
+
+	case operator.LRotate, operator.RRotate:
+		n := uint(count % uint64(width))
+		if op == operator.RRotate && n != 0 {
+			n = width - n
+		}
+		a &= mask
+		c = a
+		if n != 0 {
+			c = (a << n) | (a >> (width - n))
+		}
+	}
+	storeBits(out, c&mask)
+	return nil
+}
+
+// compare returns the order of x and y, which have the same type.  It
+// returns false if the two are unordered, as NaN is with everything.
+// Complex numbers and bitfields are only ordered if they are equal.
+func compare(x Value, y Value) (int, bool) {
+	chased := x.Type().Chase()
+	switch class := numberClassOf(integerKind(chased)); {
+	case class == unsignedNumber && chased.Kind() != BitfieldKind:
+		return compareUint64(loadBits(x), loadBits(y)), true
+
+	case class == signedNumber:
+		return compareInt64(int64(loadBits(x)), int64(loadBits(y))), true
+
+	case chased.Kind() == BitfieldKind:
+		return 0, loadBits(x) == loadBits(y)
+	}
+
+	switch numberClassOf(chased.Kind()) {
+	case floatNumber:
+		a, b := loadFloat(x), loadFloat(y)
+		switch {
+		case a < b:
+			return -1, true
+		case a > b:
+			return 1, true
+		case a == b:
+			return 0, true
+		}
+		return 0, false
+
+	case complexNumber:
+		return 0, loadComplex(x) == loadComplex(y)
+	}
+
+	panic(fmt.Errorf("BUG: cannot compare values of type %s", x.Type().CanonicalName()))
+}
+
+func compareUint64(a uint64, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareInt64(a int64, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// }}}
+
+// TernaryOperatorExpr
+// {{{
+
+// TernaryOperatorExpr is "cond ? then : else".  Only the chosen branch is
+// evaluated.
+type TernaryOperatorExpr struct {
+	cond  Expr
+	then  Expr
+	else_ Expr
+}
+
+func (interp *Interp) NewTernaryOperatorExpr(cond Expr, then Expr, else_ Expr) (*TernaryOperatorExpr, error) {
+	checkNotNil("cond", cond)
+	checkNotNil("then", then)
+	checkNotNil("else_", else_)
+
+	if !isBool(cond.Type()) {
+		return nil, fmt.Errorf("condition must be %s, not %s", interp.BoolType().CanonicalName(), cond.Type().CanonicalName())
+	}
+	if !sameType(then.Type(), else_.Type()) {
+		return nil, fmt.Errorf("branches have different types: %s and %s", then.Type().CanonicalName(), else_.Type().CanonicalName())
+	}
+
+	return &TernaryOperatorExpr{cond: cond, then: then, else_: else_}, nil
+}
+
+func (expr *TernaryOperatorExpr) Interp() *Interp {
+	return expr.then.Interp()
+}
+
+func (expr *TernaryOperatorExpr) Type() *Type {
+	return expr.then.Type()
+}
+
+func (expr *TernaryOperatorExpr) Operator() operator.Operator {
+	return operator.Ternary
+}
+
+func (expr *TernaryOperatorExpr) Condition() Expr {
+	return expr.cond
+}
+
+func (expr *TernaryOperatorExpr) Then() Expr {
+	return expr.then
+}
+
+func (expr *TernaryOperatorExpr) Else() Expr {
+	return expr.else_
+}
+
+func (expr *TernaryOperatorExpr) Walk(to TraversalOrder, fn func(Expr)) {
+	walkExpr(expr, []Expr{expr.cond, expr.then, expr.else_}, to, fn)
+}
+
+func (expr *TernaryOperatorExpr) EvalInto(out *Value) error {
+	if err := checkOut(expr, out); err != nil {
+		return err
+	}
+
+	frame := expr.Interp().newTempFrame()
+	defer frame.release()
+
+	cond, err := frame.eval(expr.cond)
+	if err != nil {
+		return err
+	}
+	if loadBool(cond) {
+		return expr.then.EvalInto(out)
+	}
+	return expr.else_.EvalInto(out)
+}
+
+var _ Expr = (*TernaryOperatorExpr)(nil)
+
+// }}}
+
+// CallExpr
+// {{{
+
+// CallExpr calls a function with positional and named arguments.
+type CallExpr struct {
+	fn    *Function
+	args  []Expr
+	named []Expr
+}
+
+// NewCallExpr returns an expression that calls fn.  Each argument must have
+// exactly the type of its parameter, and every named parameter of fn must be
+// given a value in named.
+func (interp *Interp) NewCallExpr(fn *Function, args []Expr, named map[string]Expr) (*CallExpr, error) {
+	checkNotNil("fn", fn)
+
+	sig := fn.Signature()
+	params := sig.PositionalArgs()
+	numRequired := uint(len(params))
+	if numRequired != 0 && params[numRequired-1].IsRepeated() {
+		numRequired--
+	}
+	if uint(len(args)) < numRequired {
+		return nil, fmt.Errorf("not enough arguments to %s: expected %d, got %d", fn.CanonicalName(), numRequired, len(args))
+	}
+
+	for index, arg := range args {
+		checkNotNil("arg", arg)
+		var param FunctionArg
+		switch {
+		case index < len(params):
+			param = params[index]
+		case len(params) != 0 && params[len(params)-1].IsRepeated():
+			param = params[len(params)-1]
+		default:
+			return nil, fmt.Errorf("too many arguments to %s: expected %d, got %d", fn.CanonicalName(), len(params), len(args))
+		}
+		if !sameType(arg.Type(), param.Type()) {
+			return nil, fmt.Errorf("argument %d to %s must be %s, not %s", index, fn.CanonicalName(), param.Type().CanonicalName(), arg.Type().CanonicalName())
+		}
+	}
+
+	for name := range named {
+		if _, found := sig.NamedArgs()[name]; !found {
+			return nil, fmt.Errorf("%s has no argument named %q", fn.CanonicalName(), name)
+		}
+	}
+
+	names := sig.ArgNames()
+	namedList := make([]Expr, len(names))
+	for index, name := range names {
+		arg, found := named[name]
+		if !found {
+			return nil, fmt.Errorf("missing argument %q to %s", name, fn.CanonicalName())
+		}
+		checkNotNil("arg", arg)
+		param := sig.NamedArg(name)
+		if !sameType(arg.Type(), param.Type()) {
+			return nil, fmt.Errorf("argument %q to %s must be %s, not %s", name, fn.CanonicalName(), param.Type().CanonicalName(), arg.Type().CanonicalName())
+		}
+		namedList[index] = arg
+	}
+
+	return &CallExpr{fn: fn, args: cloneExprs(args), named: namedList}, nil
+}
+
+func (expr *CallExpr) Interp() *Interp {
+	return expr.fn.Signature().Interp()
+}
+
+func (expr *CallExpr) Type() *Type {
+	return expr.fn.Signature().Return()
+}
+
+func (expr *CallExpr) Function() *Function {
+	return expr.fn
+}
+
+func (expr *CallExpr) Args() []Expr {
+	return cloneExprs(expr.args)
+}
+
+func (expr *CallExpr) NamedArgs() map[string]Expr {
+	names := expr.fn.Signature().ArgNames()
+	out := make(map[string]Expr, len(names))
+	for index, name := range names {
+		out[name] = expr.named[index]
+	}
+	return out
+}
+
+func (expr *CallExpr) Walk(to TraversalOrder, fn func(Expr)) {
+	children := make([]Expr, 0, len(expr.args)+len(expr.named))
+	children = append(children, expr.args...)
+	children = append(children, expr.named...)
+	walkExpr(expr, children, to, fn)
+}
+
+func (expr *CallExpr) EvalInto(out *Value) error {
+	if err := checkOut(expr, out); err != nil {
+		return err
+	}
+
+	frame := expr.Interp().newTempFrame()
+	defer frame.release()
+
+	values := make([]Value, 0, len(expr.args)+len(expr.named))
+	for _, list := range [][]Expr{expr.args, expr.named} {
+		for _, arg := range list {
+			value, err := frame.eval(arg)
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+		}
+	}
+	return expr.fn.Call(out, values)
+}
+
+var _ Expr = (*CallExpr)(nil)
+
+// }}}
+
+// FieldExpr
+// {{{
+
+// FieldExpr reads one field of a struct.
+type FieldExpr struct {
+	operand Expr
+	field   *StructField
+}
+
+func (interp *Interp) NewFieldExpr(operand Expr, name string) (*FieldExpr, error) {
+	checkNotNil("operand", operand)
+
+	t := operand.Type()
+	s, ok := t.Chase().Details().(*Struct)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a struct", t.CanonicalName())
+	}
+	field := s.FieldByName(name)
+	if field == nil {
+		return nil, fmt.Errorf("%s has no field %q", t.CanonicalName(), name)
+	}
+
+	return &FieldExpr{operand: operand, field: field}, nil
+}
+
+func (expr *FieldExpr) Interp() *Interp {
+	return expr.operand.Interp()
+}
+
+func (expr *FieldExpr) Type() *Type {
+	return expr.field.Type()
+}
+
+func (expr *FieldExpr) Operand() Expr {
+	return expr.operand
+}
+
+func (expr *FieldExpr) Field() *StructField {
+	return expr.field
+}
+
+func (expr *FieldExpr) Walk(to TraversalOrder, fn func(Expr)) {
+	walkExpr(expr, []Expr{expr.operand}, to, fn)
+}
+
+func (expr *FieldExpr) EvalInto(out *Value) error {
+	if err := checkOut(expr, out); err != nil {
+		return err
+	}
+
+	frame := expr.Interp().newTempFrame()
+	defer frame.release()
+
+	x, err := frame.eval(expr.operand)
+	if err != nil {
+		return err
+	}
+
+	i := expr.field.Offset()
+	j := i + expr.field.Length()
+	return out.CopyFrom(x.Span(i, j, expr.field.Type()))
+}
+
+var _ Expr = (*FieldExpr)(nil)
+
+// }}}
+
+// IndexExpr
+// {{{
+
+// IndexExpr reads one byte of a String.
+type IndexExpr struct {
+	operand Expr
+	index   Expr
+}
+
+func (interp *Interp) NewIndexExpr(operand Expr, index Expr) (*IndexExpr, error) {
+	checkNotNil("operand", operand)
+	checkNotNil("index", index)
+
+	if t := operand.Type(); t.Chase().Kind() != StringKind {
+		return nil, fmt.Errorf("%s cannot be indexed", t.CanonicalName())
+	}
+	if t := index.Type(); !isInteger(numberClassOf(t.Chase().Kind())) {
+		return nil, fmt.Errorf("index must be an integer, not %s", t.CanonicalName())
+	}
+
+	return &IndexExpr{operand: operand, index: index}, nil
+}
+
+func (expr *IndexExpr) Interp() *Interp {
+	return expr.operand.Interp()
+}
+
+func (expr *IndexExpr) Type() *Type {
+	return expr.Interp().UInt8Type()
+}
+
+func (expr *IndexExpr) Operand() Expr {
+	return expr.operand
+}
+
+func (expr *IndexExpr) Index() Expr {
+	return expr.index
+}
+
+func (expr *IndexExpr) Walk(to TraversalOrder, fn func(Expr)) {
+	walkExpr(expr, []Expr{expr.operand, expr.index}, to, fn)
+}
+
+func (expr *IndexExpr) EvalInto(out *Value) error {
+	if err := checkOut(expr, out); err != nil {
+		return err
+	}
+
+	frame := expr.Interp().newTempFrame()
+	defer frame.release()
+
+	x, err := frame.eval(expr.operand)
+	if err != nil {
+		return err
+	}
+	y, err := frame.eval(expr.index)
+	if err != nil {
+		return err
+	}
+
+	str := x.Get().(String)
+	index := int64(loadBits(y))
+	isNegative := (numberClassOf(y.Type().Chase().Kind()) == signedNumber && index < 0)
+	if isNegative || uint64(index) >= uint64(str.Length) {
+		return &IndexOutOfRangeError{Index: index, Length: str.Length}
+	}
+
+	var b byte
+	err = str.Buffer.WithReadLock(func(bytes []byte) error {
+		b = bytes[str.Offset+uint(index)]
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	storeBits(*out, uint64(b))
+	return nil
+}
+
+var _ Expr = (*IndexExpr)(nil)
+
+// }}}
+
+// CastExpr
+// {{{
+
+// CastExpr converts a value to another type.  Numbers convert to any other
+// number type, except that complex numbers only convert to complex; enums and
+// bitfields convert to and from integers; and any type converts to itself.
+type CastExpr struct {
+	operand Expr
+	type_   *Type
+}
+
+func (interp *Interp) NewCastExpr(operand Expr, t *Type) (*CastExpr, error) {
+	checkNotNil("operand", operand)
+	checkNotNil("t", t)
+
+	from := operand.Type()
+	if !sameType(from, t) {
+		fromClass := numberClassOf(from.Chase().Kind())
+		toClass := numberClassOf(t.Chase().Kind())
+		fromInt := integerKind(from) != InvalidTypeKind
+		toInt := integerKind(t) != InvalidTypeKind
+
+		var ok bool
+		switch {
+		case fromClass != notNumber && toClass != notNumber:
+			ok = (fromClass != complexNumber || toClass == complexNumber)
+		case fromInt && toInt:
+			ok = isInteger(fromClass) || isInteger(toClass)
+		}
+		if !ok {
+			return nil, fmt.Errorf("cannot convert %s to %s", from.CanonicalName(), t.CanonicalName())
+		}
+	}
+
+	return &CastExpr{operand: operand, type_: t}, nil
+}
+
+func (expr *CastExpr) Interp() *Interp {
+	return expr.type_.Interp()
+}
+
+func (expr *CastExpr) Type() *Type {
+	return expr.type_
+}
+
+func (expr *CastExpr) Operand() Expr {
+	return expr.operand
+}
+
+func (expr *CastExpr) Walk(to TraversalOrder, fn func(Expr)) {
+	walkExpr(expr, []Expr{expr.operand}, to, fn)
+}
+
+func (expr *CastExpr) EvalInto(out *Value) error {
+	if err := checkOut(expr, out); err != nil {
+		return err
+	}
+
+	frame := expr.Interp().newTempFrame()
+	defer frame.release()
+
+	x, err := frame.eval(expr.operand)
+	if err != nil {
+		return err
+	}
+	if sameType(x.Type(), expr.type_) {
+		return out.CopyFrom(x)
+	}
+
+	from := x.Type().Chase()
+	to := expr.type_.Chase()
+	fromClass := numberClassOf(integerKind(from))
+	if fromClass == notNumber {
+		fromClass = numberClassOf(from.Kind())
+	}
+
+	switch toClass := numberClassOf(to.Kind()); {
+	case integerKind(to) != InvalidTypeKind && isInteger(fromClass):
+		storeBits(*out, loadBits(x))
+		if e, ok := to.Details().(*Enum); ok {
+			if s64 := int64(loadBits(*out)); e.ByNumber(s64) == nil {
+				return fmt.Errorf("%s has no item with value %d", expr.type_.CanonicalName(), s64)
+			}
+		}
+
+	case isInteger(toClass):
+		f64 := loadFloat(x)
+		return storeFloatAsInteger(*out, f64)
+
+	case toClass == floatNumber:
+		switch fromClass {
+		case unsignedNumber:
+			storeFloat(*out, float64(loadBits(x)))
+		case signedNumber:
+			storeFloat(*out, float64(int64(loadBits(x))))
+		default:
+			storeFloat(*out, loadFloat(x))
+		}
+
+	case toClass == complexNumber:
+		switch fromClass {
+		case unsignedNumber:
+			storeComplex(*out, complex(float64(loadBits(x)), 0))
+		case signedNumber:
+			storeComplex(*out, complex(float64(int64(loadBits(x))), 0))
+		case floatNumber:
+			storeComplex(*out, complex(loadFloat(x), 0))
+		default:
+			storeComplex(*out, loadComplex(x))
+		}
+	}
+	return nil
+}
+
+var _ Expr = (*CastExpr)(nil)
+
+func storeFloatAsInteger(out Value, f64 float64) error {
+	kind := out.Type().Chase().Kind()
+	width := 8 * integerBytes(kind)
+	if numberClassOf(kind) == signedNumber {
+		limit := math.Ldexp(1, int(width-1))
+		if !(f64 >= -limit && f64 < limit) {
+			return fmt.Errorf("%v is out of range for %s", f64, out.Type().CanonicalName())
+		}
+		storeBits(out, uint64(int64(f64)))
+		return nil
+	}
+
+	limit := math.Ldexp(1, int(width))
+	if !(f64 > -1 && f64 < limit) {
+		return fmt.Errorf("%v is out of range for %s", f64, out.Type().CanonicalName())
+	}
+	storeBits(out, uint64(f64))
+	return nil
+}
+
+// }}}
+
+// AssignExpr
+// {{{
+
+// AssignExpr stores a value into a mutable symbol.  It evaluates to the value
+// that was stored.
+type AssignExpr struct {
+	target *SymbolExpr
+	value  Expr
+}
+
+func (interp *Interp) NewAssignExpr(target *SymbolExpr, value Expr) (*AssignExpr, error) {
+	checkNotNil("target", target)
+	checkNotNil("value", value)
+
+	if target.Type().Kind() != MutableKind {
+		return nil, &NotAssignableError{Target: target.Type()}
+	}
+	if !sameType(target.Type(), value.Type()) {
+		return nil, &TypeMismatchError{Context: "assignment", Expected: target.Type(), Actual: value.Type()}
+	}
+
+	return &AssignExpr{target: target, value: value}, nil
+}
+
+func (expr *AssignExpr) Interp() *Interp {
+	return expr.value.Interp()
+}
+
+func (expr *AssignExpr) Type() *Type {
+	return expr.value.Type()
+}
+
+func (expr *AssignExpr) Operator() operator.Operator {
+	return operator.Assign
+}
+
+func (expr *AssignExpr) Target() *SymbolExpr {
+	return expr.target
+}
+
+func (expr *AssignExpr) Value() Expr {
+	return expr.value
+}
+
+func (expr *AssignExpr) Walk(to TraversalOrder, fn func(Expr)) {
+	walkExpr(expr, []Expr{expr.target, expr.value}, to, fn)
+}
+
+func (expr *AssignExpr) EvalInto(out *Value) error {
+	if err := checkOut(expr, out); err != nil {
+		return err
+	}
+
+	frame := expr.Interp().newTempFrame()
+	defer frame.release()
+
+	x, err := frame.eval(expr.value)
+	if err != nil {
+		return err
+	}
+	if err := expr.target.value.CopyFrom(x); err != nil {
+		return err
+	}
+	return out.CopyFrom(x)
+}
+
+var _ Expr = (*AssignExpr)(nil)
+
+// }}}
@@ -0,0 +1,392 @@
+package exprtree
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chronos-tachyon/go-spiderscript/memory"
+	"github.com/chronos-tachyon/go-spiderscript/operator"
+)
+
+func mustConstant(t *testing.T, interp *Interp, typ *Type, in interface{}) Expr {
+	t.Helper()
+	expr, err := interp.NewConstantExpr(typ, in)
+	if err != nil {
+		t.Fatalf("NewConstantExpr(%s, %#v): unexpected error: %v", typ.CanonicalName(), in, err)
+	}
+	return expr
+}
+
+func mustExpr(t *testing.T, expr Expr, err error) Expr {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return expr
+}
+
+func evalExpr(t *testing.T, expr Expr) (interface{}, error) {
+	t.Helper()
+	out := expr.Interp().NewTemporaryValue(expr.Type())
+	if err := expr.EvalInto(&out); err != nil {
+		return nil, err
+	}
+	return out.Get(), nil
+}
+
+func TestExpr_Operators(t *testing.T) {
+	interp := NewSystemInterp()
+	u8 := interp.UInt8Type()
+	s8 := interp.SInt8Type()
+	s32 := interp.SInt32Type()
+	f64 := interp.Float64Type()
+	boolType := interp.BoolType()
+	boolEnum := boolType.Chase().Details().(*Enum)
+	orderEnum := interp.OrderType().Chase().Details().(*Enum)
+
+	constant := func(typ *Type, in interface{}) Expr {
+		return mustConstant(t, interp, typ, in)
+	}
+	unary := func(op operator.Operator, x Expr) Expr {
+		expr, err := interp.NewUnaryOperatorExpr(op, x)
+		return mustExpr(t, expr, err)
+	}
+	binary := func(op operator.Operator, x Expr, y Expr) Expr {
+		expr, err := interp.NewBinaryOperatorExpr(op, x, y)
+		return mustExpr(t, expr, err)
+	}
+	ternary := func(cond Expr, x Expr, y Expr) Expr {
+		expr, err := interp.NewTernaryOperatorExpr(cond, x, y)
+		return mustExpr(t, expr, err)
+	}
+	cast := func(x Expr, typ *Type) Expr {
+		expr, err := interp.NewCastExpr(x, typ)
+		return mustExpr(t, expr, err)
+	}
+
+	divByZero := binary(operator.Div, constant(s32, 1), constant(s32, 0))
+	isTrue := binary(operator.CmpEQ, constant(s32, 0), constant(s32, 0))
+	isFalse := unary(operator.LogicalNOT, isTrue)
+
+	type testRow struct {
+		Name     string
+		Expr     Expr
+		Expected interface{}
+		Error    string
+	}
+
+	testData := []testRow{
+		{"add/wrap", binary(operator.Add, constant(u8, 200), constant(u8, 100)), uint8(44), ""},
+		{"sub/signed", binary(operator.Sub, constant(s8, -100), constant(s8, 50)), int8(106), ""},
+		{"mul", binary(operator.Mul, constant(s32, -6), constant(s32, 7)), int32(-42), ""},
+		{"div/signed", binary(operator.Div, constant(s32, -7), constant(s32, 2)), int32(-3), ""},
+		{"mod/unsigned", binary(operator.Mod, constant(u8, 250), constant(u8, 7)), uint8(5), ""},
+		{"div/zero", divByZero, nil, "division by zero"},
+		{"div/float", binary(operator.Div, constant(f64, 1.0), constant(f64, 4.0)), float64(0.25), ""},
+		{"neg", unary(operator.UnaryNeg, constant(s32, 5)), int32(-5), ""},
+		{"neg/float", unary(operator.UnaryNeg, constant(f64, 1.5)), float64(-1.5), ""},
+		{"not/bitwise", unary(operator.BitwiseNOT, constant(u8, 0x0f)), uint8(0xf0), ""},
+		{"and", binary(operator.BitwiseAND, constant(u8, 0x3c), constant(u8, 0x0f)), uint8(0x0c), ""},
+		{"or", binary(operator.BitwiseOR, constant(u8, 0x30), constant(u8, 0x03)), uint8(0x33), ""},
+		{"xor", binary(operator.BitwiseXOR, constant(u8, 0xff), constant(u8, 0x0f)), uint8(0xf0), ""},
+		{"lshift", binary(operator.LShift, constant(u8, 0x81), constant(u8, 1)), uint8(0x02), ""},
+		{"rshift/signed", binary(operator.RShift, constant(s8, -128), constant(u8, 3)), int8(-16), ""},
+		{"rshift/unsigned", binary(operator.RShift, constant(u8, 0x80), constant(u8, 3)), uint8(0x10), ""},
+		{"lrotate", binary(operator.LRotate, constant(u8, 0x81), constant(u8, 1)), uint8(0x03), ""},
+		{"rrotate", binary(operator.RRotate, constant(u8, 0x81), constant(u8, 1)), uint8(0xc0), ""},
+		{"shift/negative", binary(operator.LShift, constant(u8, 1), constant(s8, -1)), nil, "negative shift count"},
+		{"eq", isTrue, boolEnum.ByName("true"), ""},
+		{"ne", binary(operator.CmpNE, constant(u8, 1), constant(u8, 1)), boolEnum.ByName("false"), ""},
+		{"lt/signed", binary(operator.CmpLT, constant(s8, -1), constant(s8, 1)), boolEnum.ByName("true"), ""},
+		{"ge/float", binary(operator.CmpGE, constant(f64, 1.0), constant(f64, 2.0)), boolEnum.ByName("false"), ""},
+		{"cmp", binary(operator.CmpCMP, constant(u8, 9), constant(u8, 3)), orderEnum.ByName("GT"), ""},
+		{"not/logical", isFalse, boolEnum.ByName("false"), ""},
+		{"and/short-circuit", binary(operator.LogicalAND, isFalse, binary(operator.CmpEQ, divByZero, divByZero)), boolEnum.ByName("false"), ""},
+		{"or/short-circuit", binary(operator.LogicalOR, isTrue, binary(operator.CmpEQ, divByZero, divByZero)), boolEnum.ByName("true"), ""},
+		{"or/right", binary(operator.LogicalOR, isFalse, binary(operator.CmpEQ, divByZero, divByZero)), nil, "division by zero"},
+		{"xor/logical", binary(operator.LogicalXOR, isTrue, isFalse), boolEnum.ByName("true"), ""},
+		{"ternary/then", ternary(isTrue, constant(s32, 1), divByZero), int32(1), ""},
+		{"ternary/else", ternary(isFalse, divByZero, constant(s32, 2)), int32(2), ""},
+		{"cast/truncate", cast(constant(s32, 300), u8), uint8(44), ""},
+		{"cast/sign-extend", cast(constant(s8, -2), s32), int32(-2), ""},
+		{"cast/float", cast(constant(s32, -3), f64), float64(-3), ""},
+		{"cast/to-int", cast(constant(f64, 2.75), s32), int32(2), ""},
+		{"cast/out-of-range", cast(constant(f64, 300.0), u8), nil, "out of range"},
+		{"cast/enum", cast(constant(s8, -1), boolType), boolEnum.ByName("true"), ""},
+		{"cast/enum-invalid", cast(constant(s8, 5), boolType), nil, "has no item with value 5"},
+	}
+
+	for _, row := range testData {
+		t.Run(row.Name, func(t *testing.T) {
+			actual, err := evalExpr(t, row.Expr)
+			switch {
+			case row.Error != "" && err == nil:
+				t.Errorf("EvalInto: expected error %q, got %#v", row.Error, actual)
+			case row.Error != "" && !strings.Contains(err.Error(), row.Error):
+				t.Errorf("EvalInto: expected error %q, got %q", row.Error, err.Error())
+			case row.Error == "" && err != nil:
+				t.Errorf("EvalInto: unexpected error: %v", err)
+			case row.Error == "" && actual != row.Expected:
+				t.Errorf("EvalInto: expected %#v, got %#v", row.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestExpr_TypeErrors(t *testing.T) {
+	interp := NewSystemInterp()
+	u8 := mustConstant(t, interp, interp.UInt8Type(), 1)
+	s8 := mustConstant(t, interp, interp.SInt8Type(), 1)
+	f64 := mustConstant(t, interp, interp.Float64Type(), 1.0)
+	c128 := mustConstant(t, interp, interp.Complex128Type(), complex(1, 1))
+
+	type testRow struct {
+		Name  string
+		Error error
+	}
+
+	newBinary := func(op operator.Operator, x Expr, y Expr) error {
+		_, err := interp.NewBinaryOperatorExpr(op, x, y)
+		return err
+	}
+	newCast := func(x Expr, typ *Type) error {
+		_, err := interp.NewCastExpr(x, typ)
+		return err
+	}
+
+	_, errUnary := interp.NewUnaryOperatorExpr(operator.LogicalNOT, u8)
+	_, errTernary := interp.NewTernaryOperatorExpr(u8, s8, s8)
+	_, errIndex := interp.NewIndexExpr(u8, u8)
+	_, errField := interp.NewFieldExpr(u8, "x")
+	_, errConstant := interp.NewConstantExpr(interp.UInt8Type(), 256)
+
+	testData := []testRow{
+		{"add/mixed", newBinary(operator.Add, u8, s8)},
+		{"mod/complex", newBinary(operator.Mod, c128, c128)},
+		{"and/float", newBinary(operator.BitwiseAND, f64, f64)},
+		{"lt/complex", newBinary(operator.CmpLT, c128, c128)},
+		{"pow", newBinary(operator.Pow, u8, u8)},
+		{"cast/complex", newCast(c128, interp.Float64Type())},
+		{"cast/string", newCast(u8, interp.StringType())},
+		{"unary", errUnary},
+		{"ternary", errTernary},
+		{"index", errIndex},
+		{"field", errField},
+		{"constant", errConstant},
+	}
+
+	for _, row := range testData {
+		t.Run(row.Name, func(t *testing.T) {
+			if row.Error == nil {
+				t.Errorf("expected an error, got none")
+			}
+		})
+	}
+}
+
+func TestExpr_SymbolFieldIndexCall(t *testing.T) {
+	interp := NewSystemInterp()
+	mod, err := interp.NewModule("exprs")
+	if err != nil {
+		t.Fatalf("NewModule: unexpected error: %v", err)
+	}
+	u16 := interp.UInt16Type()
+	u64 := interp.UInt64Type()
+
+	point, err := interp.StructType(Statements{
+		{Kind: StructFieldStatement, FieldName: "x", FieldType: u16},
+		{Kind: StructFieldStatement, FieldName: "y", FieldType: u64},
+	})
+	if err != nil {
+		t.Fatalf("StructType: unexpected error: %v", err)
+	}
+
+	sym, err := mod.Symbols().NewSymbol(SymbolData{Kind: SimpleSymbol, Name: "origin", Type: point})
+	if err != nil {
+		t.Fatalf("NewSymbol: unexpected error: %v", err)
+	}
+	mem := memory.New("test", memory.HugePagesOff, false)
+	mem.Grow(point.PaddedBytes())
+	origin := NewValue(sym, mem.UInt8s())
+
+	s := point.Details().(*Struct)
+	for _, field := range s.Fields() {
+		var in interface{} = uint16(7)
+		if field.Name() == "y" {
+			in = uint64(1) << 40
+		}
+		value := origin.Span(field.Offset(), field.Offset()+field.Length(), field.Type())
+		if err := value.Set(in); err != nil {
+			t.Fatalf("Set: unexpected error: %v", err)
+		}
+	}
+
+	ref := NewSymbolExpr(origin)
+	y, err := interp.NewFieldExpr(ref, "y")
+	if err != nil {
+		t.Fatalf("NewFieldExpr: unexpected error: %v", err)
+	}
+	if actual, err := evalExpr(t, y); err != nil || actual != uint64(1)<<40 {
+		t.Errorf("origin.y: expected %d, got %#v, %v", uint64(1)<<40, actual, err)
+	}
+
+	buf := interp.NewBuffer()
+	buf.AppendString("xhello")
+	str := mustConstant(t, interp, interp.StringType(), &String{Buffer: buf, Offset: 1, Length: 5})
+	for _, row := range []struct {
+		Index    int
+		Expected interface{}
+	}{
+		{0, uint8('h')},
+		{4, uint8('o')},
+		{5, nil},
+		{-1, nil},
+	} {
+		index := mustConstant(t, interp, interp.SInt32Type(), row.Index)
+		expr, err := interp.NewIndexExpr(str, index)
+		if err != nil {
+			t.Fatalf("NewIndexExpr: unexpected error: %v", err)
+		}
+		actual, err := evalExpr(t, expr)
+		if row.Expected == nil {
+			if _, ok := err.(*IndexOutOfRangeError); !ok {
+				t.Errorf("str[%d]: expected *IndexOutOfRangeError, got %#v, %v", row.Index, actual, err)
+			}
+		} else if err != nil || actual != row.Expected {
+			t.Errorf("str[%d]: expected %#v, got %#v, %v", row.Index, row.Expected, actual, err)
+		}
+	}
+
+	sig := interp.FunctionSignatureBuilder().WithReturn(u64).WithPositionalArg(u64).WithPositionalArg(u16).Build()
+	fnSym, err := mod.Symbols().NewSymbol(SymbolData{
+		Kind:     SimpleFunctionSymbol,
+		Name:     "scale",
+		Type:     interp.VoidType(),
+		Function: FunctionSymbolData{Signature: sig, PositionalNames: []string{"a", "b"}},
+	})
+	if err != nil {
+		t.Fatalf("NewSymbol: unexpected error: %v", err)
+	}
+	fn, err := interp.NewNativeFunction(fnSym, func(out *Value, args []Value) error {
+		a := args[0].Get().(uint64)
+		b := args[1].Get().(uint16)
+		return out.Set(a * uint64(b))
+	})
+	if err != nil {
+		t.Fatalf("NewNativeFunction: unexpected error: %v", err)
+	}
+
+	x, err := interp.NewFieldExpr(ref, "x")
+	if err != nil {
+		t.Fatalf("NewFieldExpr: unexpected error: %v", err)
+	}
+	call, err := interp.NewCallExpr(fn, []Expr{y, x}, nil)
+	if err != nil {
+		t.Fatalf("NewCallExpr: unexpected error: %v", err)
+	}
+	if actual, err := evalExpr(t, call); err != nil || actual != uint64(7)<<40 {
+		t.Errorf("scale(origin.y, origin.x): expected %d, got %#v, %v", uint64(7)<<40, actual, err)
+	}
+	if _, err := interp.NewCallExpr(fn, []Expr{y}, nil); err == nil {
+		t.Errorf("scale(origin.y): expected an error, got none")
+	}
+	if _, err := interp.NewCallExpr(fn, []Expr{x, y}, nil); err == nil {
+		t.Errorf("scale(origin.x, origin.y): expected an error, got none")
+	}
+
+	wrong := interp.NewTemporaryValue(u16)
+	if err := call.EvalInto(&wrong); err == nil {
+		t.Errorf("EvalInto(UInt16): expected an error, got none")
+	}
+}
+
+func TestExpr_Walk(t *testing.T) {
+	interp := NewSystemInterp()
+	s32 := interp.SInt32Type()
+
+	one := mustConstant(t, interp, s32, 1)
+	two := mustConstant(t, interp, s32, 2)
+	three := mustConstant(t, interp, s32, 3)
+	sum, err := interp.NewBinaryOperatorExpr(operator.Add, one, two)
+	if err != nil {
+		t.Fatalf("NewBinaryOperatorExpr: unexpected error: %v", err)
+	}
+	product, err := interp.NewBinaryOperatorExpr(operator.Mul, sum, three)
+	if err != nil {
+		t.Fatalf("NewBinaryOperatorExpr: unexpected error: %v", err)
+	}
+
+	names := map[Expr]string{one: "1", two: "2", three: "3", sum: "+", product: "*"}
+
+	type testRow struct {
+		Order    TraversalOrder
+		Expected string
+	}
+
+	testData := []testRow{
+		{PreOrder, "* + 1 2 3"},
+		{InOrder, "1 + 2 * 3"},
+		{PostOrder, "1 2 + 3 *"},
+	}
+
+	for _, row := range testData {
+		t.Run(row.Order.String(), func(t *testing.T) {
+			var list []string
+			product.Walk(row.Order, func(expr Expr) {
+				list = append(list, names[expr])
+			})
+			if actual := strings.Join(list, " "); actual != row.Expected {
+				t.Errorf("Walk: expected %q, got %q", row.Expected, actual)
+			}
+		})
+	}
+
+	if actual, err := evalExpr(t, product); err != nil || actual != int32(9) {
+		t.Errorf("(1 + 2) * 3: expected 9, got %#v, %v", actual, err)
+	}
+}
+
+func TestTempFrame(t *testing.T) {
+	interp := NewSystemInterp()
+	u8 := interp.UInt8Type()
+	u64 := interp.UInt64Type()
+
+	frame := interp.newTempFrame()
+	a := frame.alloc(u8)
+	b := frame.alloc(u64)
+	if start := b.UInt8Span().StartOffset(); start != 8 {
+		t.Errorf("alloc: expected UInt64 at offset 8 after a UInt8, got %d", start)
+	}
+	if err := a.Set(uint8(7)); err != nil {
+		t.Fatalf("Set: unexpected error: %v", err)
+	}
+	frame.release()
+
+	// An expression evaluated over and over must keep reusing the same
+	// frame memory rather than mapping more.
+	sum := mustConstant(t, interp, u64, 1)
+	for i := 0; i < 100; i++ {
+		expr, err := interp.NewBinaryOperatorExpr(operator.Add, sum, mustConstant(t, interp, u64, 1))
+		sum = mustExpr(t, expr, err)
+	}
+	for i := 0; i < 1000; i++ {
+		frame := interp.newTempFrame()
+		if frame.used != 0 {
+			t.Fatalf("newTempFrame: expected an empty frame, got %d bytes in use", frame.used)
+		}
+		out := frame.alloc(u64)
+		if err := sum.EvalInto(&out); err != nil {
+			t.Fatalf("EvalInto: unexpected error: %v", err)
+		}
+		if actual := out.Get(); actual != uint64(101) {
+			t.Fatalf("EvalInto: expected 101, got %#v", actual)
+		}
+		frame.release()
+	}
+
+	frame = interp.newTempFrame()
+	defer frame.release()
+	if c := frame.alloc(u8); c.Get() != uint8(0) {
+		t.Errorf("alloc: expected a zeroed value, got %#v", c.Get())
+	}
+}
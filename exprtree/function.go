@@ -1,6 +1,7 @@
 package exprtree
 
 import (
+	"fmt"
 	"sync"
 )
 
@@ -8,10 +9,36 @@ type FunctionType struct {
 	Signature *FunctionSignature
 }
 
+// NativeFunc implements a function in Go.  The arguments arrive in the order
+// of the function's positional arguments, followed by its named arguments in
+// the order of FunctionSignature.ArgNames, and the result is written to out.
+// The argument values are temporaries that are reused once fn returns, so fn
+// must copy anything it wants to keep.
+type NativeFunc func(out *Value, args []Value) error
+
 type Function struct {
-	mu  sync.Mutex
-	sym *Symbol
-	sig *FunctionSignature
+	mu     sync.Mutex
+	sym    *Symbol
+	sig    *FunctionSignature
+	native NativeFunc
+}
+
+// NewNativeFunction returns a function that is implemented by fn.  The
+// function's signature is taken from sym, which must be a function symbol.
+func (interp *Interp) NewNativeFunction(sym *Symbol, fn NativeFunc) (*Function, error) {
+	checkNotNil("sym", sym)
+	checkNotNil("fn", fn)
+
+	if sym.Function() == nil {
+		return nil, fmt.Errorf("%s is a %v, not a function symbol", sym.CanonicalName(), sym.Kind())
+	}
+
+	f := &Function{
+		sym:    sym,
+		sig:    sym.Function().Signature(),
+		native: fn,
+	}
+	return f, nil
 }
 
 func (f *Function) Symbol() *Symbol {
@@ -29,3 +56,12 @@ func (f *Function) MangledName() string {
 func (f *Function) Signature() *FunctionSignature {
 	return f.sig
 }
+
+// Call runs the function with the given arguments and writes its result to
+// out.
+func (f *Function) Call(out *Value, args []Value) error {
+	if f.native == nil {
+		return fmt.Errorf("%s has no implementation", f.CanonicalName())
+	}
+	return f.native(out, args)
+}
@@ -0,0 +1,402 @@
+package exprtree
+
+import (
+	"fmt"
+	"sync"
+)
+
+// GenericType
+// {{{
+
+// GenericType is the compile-time value of an UnboundGenericTypeSymbol.  It
+// holds the body of the generic: the statements of a struct, union, enum or
+// bitfield, in which each type parameter appears as a placeholder type of
+// kind TypeParamKind.  Interp.Instantiate binds the parameters and builds
+// the concrete type.
+type GenericType struct {
+	mu     sync.RWMutex
+	sym    *Symbol
+	kind   TypeKind
+	params []*Type
+	list   Statements
+	symtab SymbolTable
+}
+
+// NewGenericType declares an unbound generic type in symtab.  The data must
+// describe an UnboundGenericTypeSymbol, and kind must be StructKind,
+// UnionKind, EnumKind or BitfieldKind.  The body function is given one
+// placeholder per parameter (nil for integer and enum parameters) and
+// returns the statements of the generic.
+//
+// Only type parameters are substituted into the body, wherever a FieldType
+// or TagType mentions their placeholder, possibly through pointer, mutable
+// or const wrappers, anonymous structs and unions, or the arguments of
+// other generic instances.  Integer and enum parameters have no slot in Statements
+// yet; they only distinguish one instance from another.
+func (interp *Interp) NewGenericType(symtab *SymbolTable, data SymbolData, kind TypeKind, body func(params []*Type) Statements) (*GenericType, error) {
+	checkNotNil("symtab", symtab)
+	checkNotNil("body", body)
+
+	if data.Kind != UnboundGenericTypeSymbol {
+		return nil, fmt.Errorf("SymbolData.Kind is %v, expected %v", data.Kind, UnboundGenericTypeSymbol)
+	}
+
+	var context StatementContext
+	switch kind {
+	case StructKind:
+		context = StructStatementContext
+	case UnionKind:
+		context = UnionStatementContext
+	case EnumKind:
+		context = EnumStatementContext
+	case BitfieldKind:
+		context = BitfieldStatementContext
+	default:
+		return nil, fmt.Errorf("TypeKind %v cannot be generic", kind)
+	}
+
+	data.Type = interp.TypeType()
+	sym, err := symtab.NewSymbol(data)
+	if err != nil {
+		return nil, err
+	}
+
+	mname := sym.MangledName()
+	xname := mname[:len(mname)-1]
+
+	g := &GenericType{sym: sym, kind: kind}
+	g.symtab.Init(interp, &g.mu, g.mu.RLocker(), sym.CanonicalName()+".", xname+"P")
+
+	gsn := sym.Generic()
+	sig := gsn.Signature()
+	names := gsn.ParamNames()
+	g.params = make([]*Type, sig.NumParams())
+	for index, param := range sig.Params() {
+		if param.Kind() != TypeGenericParam {
+			continue
+		}
+		placeholder := &TypeParam{generic: g, name: names[index], index: uint(index)}
+		g.params[index], err = interp.createType(
+			&g.symtab,
+			SymbolData{Kind: SimpleSymbol, Name: names[index]},
+			func(t *Type) {
+				t.kind = TypeParamKind
+				t.details = placeholder
+			})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	list := body(cloneTypes(g.params))
+	list.Check(context)
+	g.list = append(Statements(nil), list...)
+
+	sym.SetCompileTimeValue(g)
+	return g, nil
+}
+
+func (g *GenericType) Interp() *Interp {
+	return g.sym.Interp()
+}
+
+func (g *GenericType) Symbol() *Symbol {
+	return g.sym
+}
+
+func (g *GenericType) CanonicalName() string {
+	return g.sym.CanonicalName()
+}
+
+func (g *GenericType) Kind() TypeKind {
+	return g.kind
+}
+
+func (g *GenericType) Signature() *GenericSignature {
+	return g.sym.Generic().Signature()
+}
+
+// Params returns the placeholder for each parameter, or nil for parameters
+// that are not types.
+func (g *GenericType) Params() []*Type {
+	return cloneTypes(g.params)
+}
+
+func (g *GenericType) Statements() Statements {
+	return append(Statements(nil), g.list...)
+}
+
+// substitute replaces the placeholders of g within t by the matching
+// values.  It looks through pointer, mutable and const wrappers, into the
+// fields of anonymous structs and unions, and into the arguments of other
+// generic instances, which are instantiated again with the substituted
+// arguments.  Types that mention no placeholder of g are returned as is.
+func (g *GenericType) substitute(t *Type, values []interface{}) (*Type, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	interp := g.Interp()
+	switch t.Kind() {
+	case TypeParamKind:
+		param := t.Details().(*TypeParam)
+		if param.generic != g {
+			return nil, fmt.Errorf("%s belongs to %s, not to %s", t.CanonicalName(), param.generic.CanonicalName(), g.CanonicalName())
+		}
+		return values[param.index].(*Type), nil
+
+	case PointerKind:
+		inner, err := g.substitute(t.Details().(*Type), values)
+		if err != nil || inner == t.Details() {
+			return t, err
+		}
+		return interp.PointerType(inner)
+
+	case MutableKind:
+		inner, err := g.substitute(t.Details().(*Type), values)
+		if err != nil || inner == t.Details() {
+			return t, err
+		}
+		return interp.MutableType(inner)
+
+	case ConstKind:
+		inner, err := g.substitute(t.Details().(*Type), values)
+		if err != nil || inner == t.Details() {
+			return t, err
+		}
+		return interp.ConstType(inner)
+
+	case StructKind:
+		list, changed, err := g.substituteStatements(t.Details().(*Struct).Statements(), values)
+		if err != nil || !changed {
+			return t, err
+		}
+		return interp.StructType(list)
+
+	case UnionKind:
+		list, changed, err := g.substituteStatements(t.Details().(*Union).Statements(), values)
+		if err != nil || !changed {
+			return t, err
+		}
+		return interp.UnionType(list)
+
+	case NamedKind:
+		origin := interp.genericTypeOf(t)
+		if origin == nil {
+			return t, nil
+		}
+		args := t.Symbol().Generic().ParamValues()
+		changed := false
+		for index, arg := range args {
+			if inner, ok := arg.(*Type); ok {
+				out, err := g.substitute(inner, values)
+				if err != nil {
+					return nil, err
+				}
+				changed = changed || (out != inner)
+				args[index] = out
+			}
+		}
+		if !changed {
+			return t, nil
+		}
+		return interp.Instantiate(origin.sym, args...)
+
+	default:
+		return t, nil
+	}
+}
+
+// substituteStatements applies substitute to the FieldType and TagType of
+// each statement in a copy of list.  It reports whether any of them changed.
+func (g *GenericType) substituteStatements(list Statements, values []interface{}) (Statements, bool, error) {
+	list = append(Statements(nil), list...)
+	changed := false
+	for index := range list {
+		stmt := &list[index]
+
+		fieldType, err := g.substitute(stmt.FieldType, values)
+		if err != nil {
+			return nil, false, err
+		}
+		tagType, err := g.substitute(stmt.TagType, values)
+		if err != nil {
+			return nil, false, err
+		}
+
+		changed = changed || (fieldType != stmt.FieldType) || (tagType != stmt.TagType)
+		stmt.FieldType = fieldType
+		stmt.TagType = tagType
+	}
+	return list, changed, nil
+}
+
+// }}}
+
+// TypeParam
+// {{{
+
+// TypeParam is the Details of a TypeParamKind type: the placeholder that
+// stands for one type parameter inside the body of a GenericType.
+type TypeParam struct {
+	generic *GenericType
+	name    string
+	index   uint
+}
+
+func (param *TypeParam) Generic() *GenericType {
+	return param.generic
+}
+
+func (param *TypeParam) Name() string {
+	return param.name
+}
+
+func (param *TypeParam) Index() uint {
+	return param.index
+}
+
+// }}}
+
+// Instantiate binds the parameters of an unbound generic type, declared with
+// NewGenericType, and returns the resulting type.  Each argument must match
+// the kind of its parameter: a *Type for a type parameter, a non-negative Go
+// integer for an integer parameter, or an *EnumItem of the right enum for an
+// enum parameter.
+//
+// The result is a named type with a BoundGenericTypeSymbol, declared beside
+// the unbound symbol.  Instances are cached by mangled name, so the same
+// arguments always yield the same *Type.
+func (interp *Interp) Instantiate(unbound *Symbol, args ...interface{}) (*Type, error) {
+	checkNotNil("unbound", unbound)
+
+	g, ok := unbound.CompileTimeValue().(*GenericType)
+	if unbound.Kind() != UnboundGenericTypeSymbol || !ok {
+		return nil, fmt.Errorf("%s is not a generic type", unbound.CanonicalName())
+	}
+
+	gsn := unbound.Generic()
+	sig := gsn.Signature()
+	if uint(len(args)) != sig.NumParams() {
+		return nil, fmt.Errorf("%s takes %d generic arguments, got %d", unbound.CanonicalName(), sig.NumParams(), len(args))
+	}
+
+	values := make([]interface{}, len(args))
+	for index, param := range sig.Params() {
+		value, err := checkGenericArg(param, args[index])
+		if err != nil {
+			return nil, fmt.Errorf("generic argument %d to %s: %w", index, unbound.CanonicalName(), err)
+		}
+		values[index] = value
+	}
+
+	data := SymbolData{
+		Kind: BoundGenericTypeSymbol,
+		Name: unbound.HumanName(),
+		Generic: GenericSymbolData{
+			Signature:   sig,
+			ParamNames:  gsn.ParamNames(),
+			ParamValues: values,
+		},
+	}
+
+	symtab := unbound.SymbolTable()
+	sn, err := NewSymbolName(data, symtab.canonPrefix, symtab.manglePrefix)
+	if err != nil {
+		return nil, err
+	}
+	key := sn.MangledName()
+
+	var out *Type
+	var found bool
+	locked(&interp.mu, func() {
+		for {
+			out, found = interp.genericTypeCache[key]
+			if !found || out != nil {
+				break
+			}
+			interp.cv.Wait()
+		}
+		if !found {
+			interp.genericTypeCache[key] = nil
+		}
+	})
+	if found {
+		return out, nil
+	}
+
+	out, err = interp.instantiateImpl(g, data, values)
+
+	locked(&interp.mu, func() {
+		if err != nil {
+			delete(interp.genericTypeCache, key)
+		} else {
+			interp.genericTypeCache[key] = out
+			interp.genericTypeOrigin[out] = g
+		}
+		interp.cv.Broadcast()
+	})
+
+	return out, err
+}
+
+func (interp *Interp) instantiateImpl(g *GenericType, data SymbolData, values []interface{}) (*Type, error) {
+	list, _, err := g.substituteStatements(g.list, values)
+	if err != nil {
+		return nil, err
+	}
+
+	var in *Type
+	switch g.kind {
+	case StructKind:
+		in, err = interp.StructType(list)
+	case UnionKind:
+		in, err = interp.UnionType(list)
+	case EnumKind:
+		in, err = interp.EnumType(list)
+	case BitfieldKind:
+		in, err = interp.BitfieldType(list)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return interp.NamedType(g.sym.SymbolTable(), data, in)
+}
+
+// genericTypeOf returns the unbound generic that t was instantiated from, or
+// nil if t is not a generic instance.
+func (interp *Interp) genericTypeOf(t *Type) *GenericType {
+	var g *GenericType
+	locked(&interp.mu, func() {
+		g = interp.genericTypeOrigin[t]
+	})
+	return g
+}
+
+func checkGenericArg(param GenericParam, arg interface{}) (interface{}, error) {
+	switch param.Kind() {
+	case TypeGenericParam:
+		t, ok := arg.(*Type)
+		if !ok || t == nil {
+			return nil, fmt.Errorf("expected a type, got %T", arg)
+		}
+		return t, nil
+
+	case IntegerGenericParam:
+		return toUInt(U64Kind, arg)
+
+	case EnumGenericParam:
+		item, ok := arg.(*EnumItem)
+		if !ok || item == nil {
+			return nil, fmt.Errorf("expected an item of %s, got %T", param.Type().CanonicalName(), arg)
+		}
+		if item.parent != param.Type().Chase().Details().(*Enum) {
+			return nil, fmt.Errorf("expected an item of %s, got %s", param.Type().CanonicalName(), item.Name())
+		}
+		return item, nil
+
+	default:
+		panic(fmt.Errorf("BUG: GenericParam.Kind() is %v, which is not implemented", param.Kind()))
+	}
+}
@@ -0,0 +1,255 @@
+package exprtree
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInstantiate(t *testing.T) {
+	interp := NewSystemInterp()
+	mod, err := interp.NewModule("generic")
+	if err != nil {
+		t.Fatalf("NewModule: unexpected error: %v", err)
+	}
+
+	u8 := interp.UInt8Type()
+	u32 := interp.UInt32Type()
+	orderType := interp.OrderType()
+	orderEnum := orderType.Chase().Details().(*Enum)
+	boolEnum := interp.BoolType().Chase().Details().(*Enum)
+
+	newGeneric := func(name string, sig *GenericSignature, paramNames []string, kind TypeKind, body func(params []*Type) Statements) *GenericType {
+		g, err := interp.NewGenericType(
+			mod.Symbols(),
+			SymbolData{
+				Kind:    UnboundGenericTypeSymbol,
+				Name:    name,
+				Generic: GenericSymbolData{Signature: sig, ParamNames: paramNames},
+			},
+			kind,
+			body)
+		if err != nil {
+			t.Fatalf("NewGenericType: unexpected error: %v", err)
+		}
+		return g
+	}
+
+	box := newGeneric(
+		"Box",
+		interp.GenericSignatureBuilder().WithType().Build(),
+		[]string{"T"},
+		StructKind,
+		func(params []*Type) Statements {
+			ptr, err := interp.PointerType(params[0])
+			if err != nil {
+				t.Fatalf("PointerType: unexpected error: %v", err)
+			}
+			return Statements{
+				{Kind: StructFieldStatement, FieldName: "value", FieldType: params[0]},
+				{Kind: StructFieldStatement, FieldName: "next", FieldType: ptr},
+			}
+		})
+	buffer := newGeneric(
+		"Buffer",
+		interp.GenericSignatureBuilder().WithType().WithUInt().Build(),
+		[]string{"T", "N"},
+		StructKind,
+		func(params []*Type) Statements {
+			if params[1] != nil {
+				t.Errorf("NewGenericType: expected no placeholder for an integer parameter, got %s", params[1].CanonicalName())
+			}
+			return Statements{
+				{Kind: StructFieldStatement, FieldName: "data", FieldType: params[0]},
+			}
+		})
+	tagged := newGeneric(
+		"Tagged",
+		interp.GenericSignatureBuilder().WithEnum(orderType).Build(),
+		[]string{"K"},
+		StructKind,
+		func(params []*Type) Statements {
+			return Statements{
+				{Kind: StructFieldStatement, FieldName: "value", FieldType: u8},
+			}
+		})
+	borrowed := newGeneric(
+		"Borrowed",
+		interp.GenericSignatureBuilder().WithType().Build(),
+		[]string{"U"},
+		StructKind,
+		func(params []*Type) Statements {
+			return Statements{
+				{Kind: StructFieldStatement, FieldName: "value", FieldType: box.Params()[0]},
+			}
+		})
+
+	outer := newGeneric(
+		"Outer",
+		interp.GenericSignatureBuilder().WithType().Build(),
+		[]string{"U"},
+		StructKind,
+		func(params []*Type) Statements {
+			boxU, err := interp.Instantiate(box.Symbol(), params[0])
+			if err != nil {
+				t.Fatalf("Instantiate: unexpected error: %v", err)
+			}
+			inline, err := interp.StructType(Statements{
+				{Kind: StructFieldStatement, FieldName: "value", FieldType: params[0]},
+			})
+			if err != nil {
+				t.Fatalf("StructType: unexpected error: %v", err)
+			}
+			return Statements{
+				{Kind: StructFieldStatement, FieldName: "boxed", FieldType: boxU},
+				{Kind: StructFieldStatement, FieldName: "inline", FieldType: inline},
+			}
+		})
+
+	t.Run("struct", func(t *testing.T) {
+		boxU8, err := interp.Instantiate(box.Symbol(), u8)
+		if err != nil {
+			t.Fatalf("Instantiate: unexpected error: %v", err)
+		}
+		if expected, actual := "generic::Box#[builtin::UInt8]", boxU8.CanonicalName(); expected != actual {
+			t.Errorf("CanonicalName: expected %q, got %q", expected, actual)
+		}
+		if kind := boxU8.Symbol().Kind(); kind != BoundGenericTypeSymbol {
+			t.Errorf("Symbol().Kind: expected %v, got %v", BoundGenericTypeSymbol, kind)
+		}
+
+		chased := boxU8.Chase()
+		if chased.Kind() != StructKind {
+			t.Fatalf("Chase().Kind: expected %v, got %v", StructKind, chased.Kind())
+		}
+		s := chased.Details().(*Struct)
+		ptrU8, _ := interp.PointerType(u8)
+		for name, expected := range map[string]*Type{"value": u8, "next": ptrU8} {
+			field := s.FieldByName(name)
+			if field == nil {
+				t.Errorf("FieldByName(%q): not found", name)
+				continue
+			}
+			if field.Type() != expected {
+				t.Errorf("FieldByName(%q): expected %s, got %s", name, expected.CanonicalName(), field.Type().CanonicalName())
+			}
+		}
+
+		again, err := interp.Instantiate(box.Symbol(), u8)
+		if err != nil {
+			t.Fatalf("Instantiate: unexpected error: %v", err)
+		}
+		if again != boxU8 {
+			t.Errorf("Instantiate: expected the cached %s, got a new type", boxU8.CanonicalName())
+		}
+
+		boxU32, err := interp.Instantiate(box.Symbol(), u32)
+		if err != nil {
+			t.Fatalf("Instantiate: unexpected error: %v", err)
+		}
+		if boxU32 == boxU8 || boxU32.MangledName() == boxU8.MangledName() {
+			t.Errorf("Instantiate: expected distinct types for UInt8 and UInt32")
+		}
+		if boxU32.MinimumBytes() <= boxU8.MinimumBytes() {
+			t.Errorf("MinimumBytes: expected %s to be larger than %s", boxU32.CanonicalName(), boxU8.CanonicalName())
+		}
+	})
+
+	t.Run("integer", func(t *testing.T) {
+		a, err := interp.Instantiate(buffer.Symbol(), u8, 4)
+		if err != nil {
+			t.Fatalf("Instantiate: unexpected error: %v", err)
+		}
+		b, err := interp.Instantiate(buffer.Symbol(), u8, uint64(4))
+		if err != nil {
+			t.Fatalf("Instantiate: unexpected error: %v", err)
+		}
+		if a != b {
+			t.Errorf("Instantiate: expected 4 and uint64(4) to give the same type")
+		}
+		if expected, actual := "generic::Buffer#[builtin::UInt8, 4]", a.CanonicalName(); expected != actual {
+			t.Errorf("CanonicalName: expected %q, got %q", expected, actual)
+		}
+	})
+
+	t.Run("enum", func(t *testing.T) {
+		lt, err := interp.Instantiate(tagged.Symbol(), orderEnum.ByName("LT"))
+		if err != nil {
+			t.Fatalf("Instantiate: unexpected error: %v", err)
+		}
+		gt, err := interp.Instantiate(tagged.Symbol(), orderEnum.ByName("GT"))
+		if err != nil {
+			t.Fatalf("Instantiate: unexpected error: %v", err)
+		}
+		if lt == gt {
+			t.Errorf("Instantiate: expected distinct types for LT and GT")
+		}
+		if expected, actual := "generic::Tagged#[LT]", lt.CanonicalName(); expected != actual {
+			t.Errorf("CanonicalName: expected %q, got %q", expected, actual)
+		}
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		outerU8, err := interp.Instantiate(outer.Symbol(), u8)
+		if err != nil {
+			t.Fatalf("Instantiate: unexpected error: %v", err)
+		}
+		boxU8, err := interp.Instantiate(box.Symbol(), u8)
+		if err != nil {
+			t.Fatalf("Instantiate: unexpected error: %v", err)
+		}
+
+		s := outerU8.Chase().Details().(*Struct)
+		if field := s.FieldByName("boxed"); field == nil {
+			t.Errorf("FieldByName(%q): not found", "boxed")
+		} else if field.Type() != boxU8 {
+			t.Errorf("FieldByName(%q): expected %s, got %s", "boxed", boxU8.CanonicalName(), field.Type().CanonicalName())
+		}
+
+		field := s.FieldByName("inline")
+		if field == nil {
+			t.Fatalf("FieldByName(%q): not found", "inline")
+		}
+		inner := field.Type().Chase().Details().(*Struct).FieldByName("value")
+		if inner == nil {
+			t.Fatalf("FieldByName(%q): not found", "value")
+		}
+		if inner.Type() != u8 {
+			t.Errorf("FieldByName(%q): expected %s, got %s", "value", u8.CanonicalName(), inner.Type().CanonicalName())
+		}
+	})
+
+	type testRow struct {
+		Name     string
+		Symbol   *Symbol
+		Args     []interface{}
+		Expected string
+	}
+
+	testData := []testRow{
+		{"too few", box.Symbol(), nil, "generic::Box[T: type] takes 1 generic arguments, got 0"},
+		{"too many", box.Symbol(), []interface{}{u8, u8}, "generic::Box[T: type] takes 1 generic arguments, got 2"},
+		{"not a type", box.Symbol(), []interface{}{uint64(1)}, "generic argument 0 to generic::Box[T: type]: expected a type, got uint64"},
+		{"not an integer", buffer.Symbol(), []interface{}{u8, u8}, "generic argument 1 to generic::Buffer[T: type, N: uint]: expected integer type"},
+		{"negative", buffer.Symbol(), []interface{}{u8, -1}, "generic argument 1 to generic::Buffer[T: type, N: uint]: value -1 out of range"},
+		{"wrong enum", tagged.Symbol(), []interface{}{boolEnum.ByName("true")}, "generic argument 0 to generic::Tagged[K: builtin::Order]: expected an item of builtin::Order, got true"},
+		{"not generic", u8.Symbol(), []interface{}{u8}, "builtin::UInt8 is not a generic type"},
+		{"foreign placeholder", borrowed.Symbol(), []interface{}{u8}, "generic::Box[T: type].T belongs to generic::Box[T: type], not to generic::Borrowed[U: type]"},
+	}
+
+	for _, row := range testData {
+		t.Run(row.Name, func(t *testing.T) {
+			out, err := interp.Instantiate(row.Symbol, row.Args...)
+			if err == nil {
+				t.Fatalf("expected error %q, got %s", row.Expected, out.CanonicalName())
+			}
+			if actual := err.Error(); !strings.HasPrefix(actual, row.Expected) {
+				t.Errorf("expected error %q, got %q", row.Expected, actual)
+			}
+		})
+	}
+
+	// A failed instantiation must not leave its cache entry reserved.
+	if _, err := interp.Instantiate(borrowed.Symbol(), u8); err == nil {
+		t.Errorf("Instantiate: expected the foreign placeholder to fail again")
+	}
+}
@@ -58,6 +58,10 @@ type Interp struct {
 	bitfieldTypeCache map[string]*Type
 	structTypeCache   map[string]*Type
 	unionTypeCache    map[string]*Type
+	genericTypeCache  map[string]*Type
+	genericTypeOrigin map[*Type]*GenericType
+
+	tempFrames sync.Pool
 
 	lastSymbolID  SymbolID
 	lastTypeID    TypeID
@@ -111,6 +115,8 @@ func (interp *Interp) Init(cpu RuntimeCPU, os RuntimeOS) {
 		bitfieldTypeCache: make(map[string]*Type, 256),
 		structTypeCache:   make(map[string]*Type, 256),
 		unionTypeCache:    make(map[string]*Type, 256),
+		genericTypeCache:  make(map[string]*Type, 256),
+		genericTypeOrigin: make(map[*Type]*GenericType, 256),
 		lastSymbolID:      0,
 		lastTypeID:        0,
 		lastBufferID:      0,
@@ -166,6 +172,13 @@ func (interp *Interp) NewModule(cname string) (*Module, error) {
 	return interp.newModuleCommon(cname, true)
 }
 
+// NewMainModule creates the "main" module, which holds the script that the
+// interpreter was started with.  NewModule refuses that name because it is
+// reserved.
+func (interp *Interp) NewMainModule() (*Module, error) {
+	return interp.newModuleCommon("main", true)
+}
+
 func (interp *Interp) AllSymbols(out map[SymbolID]*Symbol) {
 	checkNotNil("out", out)
 	locked(interp.mu.RLocker(), func() {
@@ -649,7 +662,11 @@ func (interp *Interp) populateBuiltinTypes() {
 		{C32Kind, 2, -1, true, "Complex%d", "_Ac%d", &interp.complex32TypeSingleton},
 		{C64Kind, 3, -1, true, "Complex%d", "_Ac%d", &interp.complex64TypeSingleton},
 		{C128Kind, 4, -1, true, "Complex%d", "_Ac%d", &interp.complex128TypeSingleton},
-		{StringKind, 3, 0, false, "String", "_As", &interp.stringTypeSingleton},
+		// A String holds a BufferID, an offset and a length, 4 bytes each.
+		// The size of a primitive is 1 << AlignShift, so those 12 bytes
+		// need a shift of 4: with 3, Value.Set would write the length past
+		// the end of an 8-byte String.
+		{StringKind, 4, 0, false, "String", "_As", &interp.stringTypeSingleton},
 		{ErrorKind, 3, 0, false, "Error", "_Ae", &interp.errorTypeSingleton},
 	}
 
@@ -1038,6 +1055,24 @@ func (interp *Interp) registerSymbol(ptr *Symbol) {
 	})
 }
 
+func (interp *Interp) unregisterSymbol(ptr *Symbol) {
+	t, isType := ptr.CompileTimeValue().(*Type)
+	isType = isType && t.Symbol() == ptr
+	locked(&interp.mu, func() {
+		delete(interp.symbolsByID, ptr.ID())
+		delete(interp.symbolsByName, ptr.MangledName())
+		if !isType {
+			return
+		}
+		delete(interp.typesByID, t.ID())
+		delete(interp.typesByName, t.MangledName())
+		if interp.genericTypeCache[ptr.MangledName()] == t {
+			delete(interp.genericTypeCache, ptr.MangledName())
+			delete(interp.genericTypeOrigin, t)
+		}
+	})
+}
+
 func (interp *Interp) registerType(ptr *Type) {
 	locked(&interp.mu, func() {
 		interp.typesByID[ptr.ID()] = ptr
@@ -33,3 +33,72 @@ func dumpModule(t *testing.T, mod *Module) {
 	}
 	t.Logf("%s.", mod.CanonicalName())
 }
+
+func TestInterp_NewMainModule(t *testing.T) {
+	interp := NewSystemInterp()
+
+	if _, err := interp.NewModule("main"); err == nil {
+		t.Errorf("NewModule(\"main\"): expected error, got nil")
+	}
+
+	mod, err := interp.NewMainModule()
+	if err != nil {
+		t.Fatalf("NewMainModule: unexpected error: %v", err)
+	}
+	mod.Check()
+	if name := mod.CanonicalName(); name != "main" {
+		t.Errorf("CanonicalName: expected %q, got %q", "main", name)
+	}
+	if found, ok := interp.ModuleByName("main"); !ok || found != mod {
+		t.Errorf("ModuleByName(\"main\"): expected %p, got %p", mod, found)
+	}
+	if mod.Import("this") != mod {
+		t.Errorf("Import(\"this\"): expected the module itself")
+	}
+
+	if _, err := interp.NewMainModule(); err == nil {
+		t.Errorf("second NewMainModule: expected error, got nil")
+	}
+}
+
+func TestSymbolTable_Remove(t *testing.T) {
+	interp := NewSystemInterp()
+	mod, err := interp.NewModule("undo")
+	if err != nil {
+		t.Fatalf("NewModule: unexpected error: %v", err)
+	}
+
+	data := SymbolData{Kind: SimpleSymbol, Name: "Celsius"}
+	first, err := interp.NamedType(mod.Symbols(), data, interp.Float64Type())
+	if err != nil {
+		t.Fatalf("NamedType: unexpected error: %v", err)
+	}
+
+	if !mod.Symbols().Remove("Celsius") {
+		t.Errorf("Remove: expected true for a declared symbol")
+	}
+	if _, found := mod.Symbols().Get("Celsius"); found {
+		t.Errorf("Get: expected the removed symbol to be gone")
+	}
+	if mod.Symbols().Remove("Celsius") {
+		t.Errorf("Remove: expected false for a symbol that was already removed")
+	}
+
+	second, err := interp.NamedType(mod.Symbols(), data, interp.Float64Type())
+	if err != nil {
+		t.Fatalf("NamedType: unexpected error declaring the name again: %v", err)
+	}
+	if second == first {
+		t.Errorf("NamedType: expected a new type")
+	}
+
+	if err := mod.AddImport("core::io", interp.BuiltinModule()); err != nil {
+		t.Fatalf("AddImport: unexpected error: %v", err)
+	}
+	if !mod.RemoveImport("core::io") || mod.Import("core::io") != nil {
+		t.Errorf("RemoveImport: expected the import to be gone")
+	}
+	if mod.RemoveImport("core::io") {
+		t.Errorf("RemoveImport: expected false for a module that is not imported")
+	}
+}
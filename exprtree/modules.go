@@ -83,3 +83,14 @@ func (mod *Module) AddImport(name string, imported *Module) error {
 	}
 	return nil
 }
+
+// RemoveImport undoes AddImport.  It returns false if name was not
+// imported.
+func (mod *Module) RemoveImport(name string) bool {
+	var found bool
+	locked(&mod.mu, func() {
+		_, found = mod.imports[name]
+		delete(mod.imports, name)
+	})
+	return found
+}
@@ -0,0 +1,172 @@
+package exprtree
+
+import (
+	"sort"
+)
+
+// conversionCost returns how far a value of type from must be converted to
+// be passed as type to, or -1 if ConvertsImplicitly forbids it.  Mutable and
+// const are ignored.  Passing a type as itself costs nothing, unwrapping a
+// named type costs 1 for each name removed, and widening a number costs 1 for
+// each step along its family, so that UInt8 → UInt16 is cheaper than UInt8 →
+// UInt64.
+func conversionCost(from *Type, to *Type) int {
+	from = Unqualified(from)
+	to = Unqualified(to)
+
+	cost := 0
+	for t := from; ; {
+		if t == to {
+			return cost
+		}
+		switch t.Kind() {
+		case NamedKind:
+			cost++
+			t = t.Details().(*Type)
+		case MutableKind, ConstKind:
+			t = t.Details().(*Type)
+		default:
+			return widenDistance(from.Kind(), to.Kind())
+		}
+	}
+}
+
+// overloadMatch records how well the arguments of one call fit one
+// candidate.  The costs hold one entry per argument: the positional
+// arguments first, then the named arguments sorted by name.
+type overloadMatch struct {
+	fn       *Function
+	costs    []int
+	repeated bool
+}
+
+// betterThan returns true if m is a better fit than other: no argument costs
+// more with m, and either some argument costs less or only other had to bind
+// arguments to a repeated parameter.
+func (m *overloadMatch) betterThan(other *overloadMatch) bool {
+	strictly := false
+	for index := range m.costs {
+		switch {
+		case m.costs[index] > other.costs[index]:
+			return false
+		case m.costs[index] < other.costs[index]:
+			strictly = true
+		}
+	}
+	return strictly || (!m.repeated && other.repeated)
+}
+
+// matchOverload returns the match of the arguments against fn, or nil if fn
+// cannot be called with them.
+func matchOverload(fn *Function, args []*Type, names []string, named map[string]*Type) *overloadMatch {
+	sig := fn.Signature()
+	params := sig.PositionalArgs()
+	numParams := len(params)
+	hasRepeated := numParams != 0 && params[numParams-1].IsRepeated()
+
+	numRequired := numParams
+	if hasRepeated {
+		numRequired--
+	}
+	if len(args) < numRequired || (len(args) > numParams && !hasRepeated) {
+		return nil
+	}
+	if uint(len(names)) != sig.NumNamedArgs() {
+		return nil
+	}
+
+	m := &overloadMatch{fn: fn, costs: make([]int, 0, len(args)+len(names))}
+	for index, arg := range args {
+		param := params[numParams-1]
+		if index < numParams {
+			param = params[index]
+		}
+		if param.IsRepeated() {
+			m.repeated = true
+		}
+		cost := conversionCost(arg, param.Type())
+		if cost < 0 {
+			return nil
+		}
+		m.costs = append(m.costs, cost)
+	}
+
+	for _, name := range names {
+		param, found := sig.NamedArgs()[name]
+		if !found {
+			return nil
+		}
+		cost := conversionCost(named[name], param.Type())
+		if cost < 0 {
+			return nil
+		}
+		m.costs = append(m.costs, cost)
+	}
+
+	return m
+}
+
+// ResolveOverload picks which of the candidates a call with the given
+// argument types should invoke.
+//
+// A candidate is viable if every named parameter is given, no unknown name
+// is given, the positional arguments fill its positional parameters (any
+// extras going to a repeated last parameter), and every argument converts
+// implicitly to its parameter.  Among the viable candidates, the one chosen
+// must be at least as cheap as every other for each argument, as measured by
+// conversionCost, and strictly cheaper for at least one; when the costs tie,
+// a candidate without a repeated parameter beats one that needs it.
+//
+// If no candidate is viable, the result is a *NoMatchingOverloadError
+// listing them all.  If no viable candidate beats all the others, the result
+// is an *AmbiguousCallError listing those that nothing beats.
+func ResolveOverload(candidates []*Function, args []*Type, named map[string]*Type) (*Function, error) {
+	for _, arg := range args {
+		checkNotNil("arg", arg)
+	}
+	names := make([]string, 0, len(named))
+	for name, arg := range named {
+		checkNotNil("arg", arg)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	matches := make([]*overloadMatch, 0, len(candidates))
+	for _, fn := range candidates {
+		checkNotNil("fn", fn)
+		if m := matchOverload(fn, args, names, named); m != nil {
+			matches = append(matches, m)
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil, &NoMatchingOverloadError{
+			Args:       cloneTypes(args),
+			Named:      cloneTypeMap(named),
+			Candidates: cloneFunctions(candidates),
+		}
+	}
+
+	var best []*Function
+	for _, m := range matches {
+		beaten := false
+		for _, other := range matches {
+			if other != m && other.betterThan(m) {
+				beaten = true
+				break
+			}
+		}
+		if !beaten {
+			best = append(best, m.fn)
+		}
+	}
+
+	if len(best) != 1 {
+		return nil, &AmbiguousCallError{
+			Args:       cloneTypes(args),
+			Named:      cloneTypeMap(named),
+			Candidates: best,
+		}
+	}
+	return best[0], nil
+}
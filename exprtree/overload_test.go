@@ -0,0 +1,271 @@
+package exprtree
+
+import (
+	"testing"
+)
+
+func TestResolveOverload(t *testing.T) {
+	interp := NewSystemInterp()
+	mod, err := interp.NewModule("overload")
+	if err != nil {
+		t.Fatalf("NewModule: unexpected error: %v", err)
+	}
+
+	u8 := interp.UInt8Type()
+	u16 := interp.UInt16Type()
+	u64 := interp.UInt64Type()
+	s8 := interp.SInt8Type()
+	s32 := interp.SInt32Type()
+	f32 := interp.Float32Type()
+	mutableU8, _ := interp.MutableType(u8)
+	byteType, err := interp.NamedType(mod.Symbols(), SymbolData{Kind: SimpleSymbol, Name: "Byte"}, u8)
+	if err != nil {
+		t.Fatalf("NamedType: unexpected error: %v", err)
+	}
+
+	// Each function returns its own id, so that a call shows which one ran.
+	var nextID uint8
+	newFunction := func(name string, builder *FunctionSignatureBuilder, positionalNames ...string) *Function {
+		sig := builder.WithReturn(u8).Build()
+		sym, err := mod.Symbols().NewSymbol(SymbolData{
+			Kind:     SimpleFunctionSymbol,
+			Name:     name,
+			Type:     interp.VoidType(),
+			Function: FunctionSymbolData{Signature: sig, PositionalNames: positionalNames},
+		})
+		if err != nil {
+			t.Fatalf("NewSymbol: unexpected error: %v", err)
+		}
+		nextID++
+		id := nextID
+		fn, err := interp.NewNativeFunction(sym, func(out *Value, args []Value) error {
+			return out.Set(id)
+		})
+		if err != nil {
+			t.Fatalf("NewNativeFunction: unexpected error: %v", err)
+		}
+		return fn
+	}
+	newBuilder := interp.FunctionSignatureBuilder
+
+	takeU8 := newFunction("f", newBuilder().WithPositionalArg(u8), "x")
+	takeU16 := newFunction("f", newBuilder().WithPositionalArg(u16), "x")
+	takeU64 := newFunction("f", newBuilder().WithPositionalArg(u64), "x")
+	takeS32 := newFunction("f", newBuilder().WithPositionalArg(s32), "x")
+	takeByte := newFunction("f", newBuilder().WithPositionalArg(byteType), "x")
+	takeMany := newFunction("f", newBuilder().WithRepeatedPositionalArg(u8), "xs")
+	takeScaled := newFunction("f", newBuilder().WithPositionalArg(u8).WithNamedArg("scale", f32), "x")
+	takeU16U8 := newFunction("g", newBuilder().WithPositionalArg(u16).WithPositionalArg(u8), "x", "y")
+	takeU8U16 := newFunction("g", newBuilder().WithPositionalArg(u8).WithPositionalArg(u16), "x", "y")
+
+	type testRow struct {
+		Name       string
+		Candidates []*Function
+		Args       []*Type
+		Named      map[string]*Type
+		Expected   *Function
+		Error      string
+	}
+
+	testData := []testRow{
+		{
+			Name:       "exact",
+			Candidates: []*Function{takeU64, takeU16, takeU8},
+			Args:       []*Type{u8},
+			Expected:   takeU8,
+		},
+		{
+			Name:       "narrowest widening",
+			Candidates: []*Function{takeU64, takeU16, takeS32},
+			Args:       []*Type{u8},
+			Expected:   takeU16,
+		},
+		{
+			Name:       "mutable ignored",
+			Candidates: []*Function{takeU16, takeU8},
+			Args:       []*Type{mutableU8},
+			Expected:   takeU8,
+		},
+		{
+			Name:       "named type",
+			Candidates: []*Function{takeU8, takeByte},
+			Args:       []*Type{byteType},
+			Expected:   takeByte,
+		},
+		{
+			Name:       "named type unwrapped",
+			Candidates: []*Function{takeU16, takeU8},
+			Args:       []*Type{byteType},
+			Expected:   takeU8,
+		},
+		{
+			Name:       "fixed beats repeated",
+			Candidates: []*Function{takeMany, takeU8},
+			Args:       []*Type{u8},
+			Expected:   takeU8,
+		},
+		{
+			Name:       "exact repeated beats widening",
+			Candidates: []*Function{takeU16, takeMany},
+			Args:       []*Type{u8},
+			Expected:   takeMany,
+		},
+		{
+			Name:       "repeated",
+			Candidates: []*Function{takeU8, takeMany},
+			Args:       []*Type{u8, u8, u8},
+			Expected:   takeMany,
+		},
+		{
+			Name:       "repeated empty",
+			Candidates: []*Function{takeU8, takeMany},
+			Args:       nil,
+			Expected:   takeMany,
+		},
+		{
+			Name:       "named",
+			Candidates: []*Function{takeU8, takeScaled},
+			Args:       []*Type{u8},
+			Named:      map[string]*Type{"scale": f32},
+			Expected:   takeScaled,
+		},
+		{
+			Name:       "named missing",
+			Candidates: []*Function{takeU16, takeScaled},
+			Args:       []*Type{u8},
+			Expected:   takeU16,
+		},
+		{
+			Name:       "named unknown",
+			Candidates: []*Function{takeScaled},
+			Args:       []*Type{u8},
+			Named:      map[string]*Type{"scale": f32, "offset": f32},
+			Error:      "no overload accepts (builtin::UInt8, offset: builtin::Float32, scale: builtin::Float32); candidates are " + takeScaled.CanonicalName(),
+		},
+		{
+			Name:       "no match",
+			Candidates: []*Function{takeU8, takeU16},
+			Args:       []*Type{s8},
+			Error:      "no overload accepts (builtin::SInt8); candidates are " + takeU8.CanonicalName() + " and " + takeU16.CanonicalName(),
+		},
+		{
+			Name:       "no candidates",
+			Candidates: nil,
+			Args:       []*Type{u8},
+			Error:      "no overload accepts (builtin::UInt8); candidates are none",
+		},
+		{
+			Name:       "ambiguous",
+			Candidates: []*Function{takeU16U8, takeU8U16},
+			Args:       []*Type{u8, u8},
+			Error:      "ambiguous call with (builtin::UInt8, builtin::UInt8); candidates are " + takeU16U8.CanonicalName() + " and " + takeU8U16.CanonicalName(),
+		},
+		{
+			Name:       "ambiguous duplicate",
+			Candidates: []*Function{takeU8, takeU8, takeU16},
+			Args:       []*Type{u8},
+			Error:      "ambiguous call with (builtin::UInt8); candidates are " + takeU8.CanonicalName() + " and " + takeU8.CanonicalName(),
+		},
+	}
+
+	for _, row := range testData {
+		t.Run(row.Name, func(t *testing.T) {
+			fn, err := ResolveOverload(row.Candidates, row.Args, row.Named)
+			if row.Error != "" {
+				if err == nil {
+					t.Fatalf("expected error %q, got %s", row.Error, fn.CanonicalName())
+				}
+				switch err.(type) {
+				case *NoMatchingOverloadError, *AmbiguousCallError:
+					// pass
+				default:
+					t.Errorf("expected a structured error, got %T", err)
+				}
+				if actual := err.Error(); actual != row.Error {
+					t.Errorf("expected error %q, got %q", row.Error, actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fn != row.Expected {
+				t.Errorf("expected %s, got %s", row.Expected.CanonicalName(), fn.CanonicalName())
+			}
+		})
+	}
+}
+
+func TestTypeChecker_CallOverloaded(t *testing.T) {
+	interp := NewSystemInterp()
+	mod, err := interp.NewModule("overload")
+	if err != nil {
+		t.Fatalf("NewModule: unexpected error: %v", err)
+	}
+	tc := interp.TypeChecker()
+
+	u8 := interp.UInt8Type()
+	u32 := interp.UInt32Type()
+	u64 := interp.UInt64Type()
+	f32 := interp.Float32Type()
+	f64 := interp.Float64Type()
+
+	newFunction := func(sig *FunctionSignature, fn NativeFunc) *Function {
+		sym, err := mod.Symbols().NewSymbol(SymbolData{
+			Kind:     SimpleFunctionSymbol,
+			Name:     "scale",
+			Type:     interp.VoidType(),
+			Function: FunctionSymbolData{Signature: sig, PositionalNames: []string{"x"}},
+		})
+		if err != nil {
+			t.Fatalf("NewSymbol: unexpected error: %v", err)
+		}
+		f, err := interp.NewNativeFunction(sym, fn)
+		if err != nil {
+			t.Fatalf("NewNativeFunction: unexpected error: %v", err)
+		}
+		return f
+	}
+
+	scaleInt := newFunction(
+		interp.FunctionSignatureBuilder().WithReturn(u64).WithPositionalArg(u64).WithNamedArg("by", u64).Build(),
+		func(out *Value, args []Value) error {
+			return out.Set(args[0].Get().(uint64) * args[1].Get().(uint64))
+		})
+	scaleFloat := newFunction(
+		interp.FunctionSignatureBuilder().WithReturn(f64).WithPositionalArg(f64).WithNamedArg("by", f64).Build(),
+		func(out *Value, args []Value) error {
+			return out.Set(args[0].Get().(float64) * args[1].Get().(float64))
+		})
+	candidates := []*Function{scaleInt, scaleFloat}
+
+	call, err := tc.CallOverloaded(
+		candidates,
+		[]Expr{mustConstant(t, interp, u8, 6)},
+		map[string]Expr{"by": mustConstant(t, interp, u32, 7)})
+	if err != nil {
+		t.Fatalf("CallOverloaded: unexpected error: %v", err)
+	}
+	if actual, err := evalExpr(t, call); err != nil || actual != uint64(42) {
+		t.Errorf("EvalInto: expected 42, got %#v, %v", actual, err)
+	}
+
+	call, err = tc.CallOverloaded(
+		candidates,
+		[]Expr{mustConstant(t, interp, f32, 1.5)},
+		map[string]Expr{"by": mustConstant(t, interp, f64, 3.0)})
+	if err != nil {
+		t.Fatalf("CallOverloaded: unexpected error: %v", err)
+	}
+	if actual, err := evalExpr(t, call); err != nil || actual != float64(4.5) {
+		t.Errorf("EvalInto: expected 4.5, got %#v, %v", actual, err)
+	}
+
+	_, err = tc.CallOverloaded(
+		candidates,
+		[]Expr{mustConstant(t, interp, u8, 6)},
+		map[string]Expr{"by": mustConstant(t, interp, f32, 0.5)})
+	if _, ok := err.(*NoMatchingOverloadError); !ok {
+		t.Errorf("CallOverloaded: expected *NoMatchingOverloadError, got %v", err)
+	}
+}
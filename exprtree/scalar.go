@@ -0,0 +1,176 @@
+package exprtree
+
+import (
+	"fmt"
+)
+
+// numberClass groups the scalar kinds by how arithmetic treats them.
+type numberClass uint8
+
+const (
+	notNumber numberClass = iota
+	unsignedNumber
+	signedNumber
+	floatNumber
+	complexNumber
+)
+
+func numberClassOf(kind TypeKind) numberClass {
+	switch kind {
+	case U8Kind, U16Kind, U32Kind, U64Kind:
+		return unsignedNumber
+	case S8Kind, S16Kind, S32Kind, S64Kind:
+		return signedNumber
+	case F16Kind, F32Kind, F64Kind:
+		return floatNumber
+	case C32Kind, C64Kind, C128Kind:
+		return complexNumber
+	default:
+		return notNumber
+	}
+}
+
+func isInteger(class numberClass) bool {
+	return class == unsignedNumber || class == signedNumber
+}
+
+// integerKind returns the kind of integer that holds a value of type t:
+// the kind itself for integers, or the backing kind for enums and bitfields.
+// It returns InvalidTypeKind for all other types.
+func integerKind(t *Type) TypeKind {
+	chased := t.Chase()
+	switch kind := chased.Kind(); kind {
+	case EnumKind:
+		return chased.Details().(*Enum).Kind()
+	case BitfieldKind:
+		return chased.Details().(*Bitfield).Kind()
+	default:
+		if isInteger(numberClassOf(kind)) {
+			return kind
+		}
+		return InvalidTypeKind
+	}
+}
+
+func integerBytes(kind TypeKind) uint {
+	switch kind {
+	case U8Kind, S8Kind:
+		return 1
+	case U16Kind, S16Kind:
+		return 2
+	case U32Kind, S32Kind:
+		return 4
+	case U64Kind, S64Kind:
+		return 8
+	default:
+		panic(fmt.Errorf("BUG: TypeKind %v is not an integer", kind))
+	}
+}
+
+func sameType(a *Type, b *Type) bool {
+	return a.Chase() == b.Chase()
+}
+
+func isBool(t *Type) bool {
+	return sameType(t, t.Interp().BoolType())
+}
+
+// loadBits reads an integer, enum or bitfield as 64 bits, sign-extended if
+// its kind is signed.
+func loadBits(value Value) uint64 {
+	kind := integerKind(value.Type())
+	n := integerBytes(kind)
+	bo := value.Interp().ByteOrder()
+
+	var u64 uint64
+	_ = value.span.WithReadLock(0, n, func(bytes []byte) error {
+		switch n {
+		case 1:
+			u64 = uint64(bytes[0])
+		case 2:
+			u64 = uint64(bo.Uint16(bytes))
+		case 4:
+			u64 = uint64(bo.Uint32(bytes))
+		case 8:
+			u64 = bo.Uint64(bytes)
+		}
+		return nil
+	})
+
+	if numberClassOf(kind) == signedNumber && n < 8 {
+		shift := 64 - 8*n
+		u64 = uint64(int64(u64<<shift) >> shift)
+	}
+	return u64
+}
+
+// storeBits writes the low bits of u64 to an integer, enum or bitfield.
+func storeBits(value Value, u64 uint64) {
+	kind := integerKind(value.Type())
+	n := integerBytes(kind)
+	bo := value.Interp().ByteOrder()
+
+	_ = value.span.WithWriteLock(0, n, func(bytes []byte) error {
+		switch n {
+		case 1:
+			bytes[0] = uint8(u64)
+		case 2:
+			bo.PutUint16(bytes, uint16(u64))
+		case 4:
+			bo.PutUint32(bytes, uint32(u64))
+		case 8:
+			bo.PutUint64(bytes, u64)
+		}
+		return nil
+	})
+}
+
+func loadFloat(value Value) float64 {
+	f64, err := toFloat(value.Get())
+	checkBug(err)
+	return f64
+}
+
+func storeFloat(value Value, f64 float64) {
+	var err error
+	switch kind := value.Type().Chase().Kind(); kind {
+	case F16Kind, F32Kind:
+		err = value.Set(float32(f64))
+	case F64Kind:
+		err = value.Set(f64)
+	default:
+		panic(fmt.Errorf("BUG: TypeKind %v is not a float", kind))
+	}
+	checkBug(err)
+}
+
+func loadComplex(value Value) complex128 {
+	c128, err := toComplex(value.Get())
+	checkBug(err)
+	return c128
+}
+
+func storeComplex(value Value, c128 complex128) {
+	var err error
+	switch kind := value.Type().Chase().Kind(); kind {
+	case C32Kind, C64Kind:
+		err = value.Set(complex64(c128))
+	case C128Kind:
+		err = value.Set(c128)
+	default:
+		panic(fmt.Errorf("BUG: TypeKind %v is not a complex", kind))
+	}
+	checkBug(err)
+}
+
+func loadBool(value Value) bool {
+	return loadBits(value) != 0
+}
+
+func storeBool(value Value, b bool) {
+	var u64 uint64
+	if b {
+		u64 = ^uint64(0)
+	}
+	storeBits(value, u64)
+}
@@ -545,3 +545,15 @@ var statementsNoDuplicatesAllowed = map[StatementKind]bool{
 	BitfieldKindStatement:             true,
 	UnionTagStatement:                 true,
 }
+
+// IsValidIn returns true if a statement of this kind may appear in a list
+// for context.
+func (kind StatementKind) IsValidIn(context StatementContext) bool {
+	return statementsValid[context][kind]
+}
+
+// IsUnique returns true if a statement of this kind may appear at most once
+// in a list.
+func (kind StatementKind) IsUnique() bool {
+	return statementsNoDuplicatesAllowed[kind]
+}
@@ -380,6 +380,24 @@ func (symtab *SymbolTable) Put(name string, sym *Symbol) error {
 	return nil
 }
 
+// Remove deletes the symbol stored under name, and makes the interpreter
+// forget it and the type it names, if any, including a cached generic
+// instance.  It is for undoing declarations that turned out to be invalid.
+// It returns false if there was no such symbol.
+func (symtab *SymbolTable) Remove(name string) bool {
+	var sym *Symbol
+	locked(symtab.wmu, func() {
+		sym = symtab.tbl[name]
+		delete(symtab.tbl, name)
+	})
+
+	if sym == nil {
+		return false
+	}
+	symtab.interp.unregisterSymbol(sym)
+	return true
+}
+
 func (symtab *SymbolTable) NewSymbol(data SymbolData) (*Symbol, error) {
 	sn, err := NewSymbolName(data, symtab.canonPrefix, symtab.manglePrefix)
 	if err != nil {
@@ -765,10 +783,10 @@ func writeFunctionCanonicalNameTo(buf *strings.Builder, fsn *FunctionSymbolName)
 	for index := uint(0); index < posLength; index++ {
 		argName := fsn.posNames[index]
 		argData := fsn.sig.PositionalArg(index)
-		if first {
+		if !first {
 			buf.WriteString(", ")
-			first = false
 		}
+		first = false
 		buf.WriteString(argName)
 		buf.WriteString(": ")
 		if argData.IsRepeated() {
@@ -781,10 +799,10 @@ func writeFunctionCanonicalNameTo(buf *strings.Builder, fsn *FunctionSymbolName)
 		argName := fsn.namedNames[index]
 		argData := fsn.sig.NamedArg(argName)
 
-		if first {
+		if !first {
 			buf.WriteString(", ")
-			first = false
 		}
+		first = false
 		buf.WriteString(argName)
 		buf.WriteString(": ")
 		if argData.IsRepeated() {
@@ -878,6 +896,9 @@ func writeFunctionMangledNameTo(buf *strings.Builder, fsn *FunctionSymbolName) {
 		// FIXME: builtin::List[T] if argData.IsRepeated()
 		tname := t.MangledName()
 
+		if argData.IsRepeated() {
+			buf.WriteByte('R')
+		}
 		buf.WriteString(tname[2:])
 	}
 
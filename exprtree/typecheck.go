@@ -0,0 +1,276 @@
+package exprtree
+
+import (
+	"fmt"
+
+	"github.com/chronos-tachyon/go-spiderscript/operator"
+)
+
+// wideningFamilies lists the primitive kinds that convert implicitly, from
+// narrowest to widest.  A kind converts to any kind to its right in the same
+// family, and never to another family.
+var wideningFamilies = [][]TypeKind{
+	{U8Kind, U16Kind, U32Kind, U64Kind},
+	{S8Kind, S16Kind, S32Kind, S64Kind},
+	{F16Kind, F32Kind, F64Kind},
+	{C32Kind, C64Kind, C128Kind},
+}
+
+// widenDistance returns how many steps to the right of from that to lies in
+// their widening family, or -1 if from does not widen to to.
+func widenDistance(from TypeKind, to TypeKind) int {
+	for _, family := range wideningFamilies {
+		fromIndex, toIndex := -1, -1
+		for index, kind := range family {
+			if kind == from {
+				fromIndex = index
+			}
+			if kind == to {
+				toIndex = index
+			}
+		}
+		if fromIndex >= 0 && toIndex >= 0 && fromIndex <= toIndex {
+			return toIndex - fromIndex
+		}
+	}
+	return -1
+}
+
+func widensTo(from TypeKind, to TypeKind) bool {
+	return widenDistance(from, to) >= 0
+}
+
+// Unqualified strips the mutable or const wrapper, if any, from t.
+func Unqualified(t *Type) *Type {
+	switch t.Kind() {
+	case MutableKind, ConstKind:
+		return t.Details().(*Type)
+	default:
+		return t
+	}
+}
+
+// ConvertsImplicitly returns true if a value of type from may be used where
+// a value of type to is expected, without a cast.  Mutable and const are
+// ignored.  Beyond that, from must either be to, or be a named type for to,
+// or both must be primitive numbers of the same family with to at least as
+// wide: UInt8 → UInt16 → UInt32 → UInt64, SInt8 → SInt16 → SInt32 →
+// SInt64, Float16 → Float32 → Float64, or Complex32 → Complex64 →
+// Complex128.  Signed and unsigned integers never mix, and integers never
+// become floats.
+func ConvertsImplicitly(from *Type, to *Type) bool {
+	checkNotNil("from", from)
+	checkNotNil("to", to)
+
+	from = Unqualified(from)
+	to = Unqualified(to)
+	return from.Is(to) || widensTo(from.Kind(), to.Kind())
+}
+
+// commonType returns the type that both a and b convert to implicitly, or
+// nil if there is none.
+func commonType(a *Type, b *Type) *Type {
+	switch {
+	case ConvertsImplicitly(a, b):
+		return Unqualified(b)
+	case ConvertsImplicitly(b, a):
+		return Unqualified(a)
+	default:
+		return nil
+	}
+}
+
+// TypeChecker
+// {{{
+
+// TypeChecker builds expressions whose operands are known to have the right
+// types.  Where the operands differ but ConvertsImplicitly allows it, the
+// narrower one is widened with a CastExpr.  Failures are reported as
+// *TypeMismatchError, *OperandTypeError or *NotAssignableError.
+type TypeChecker struct {
+	interp *Interp
+}
+
+func (interp *Interp) TypeChecker() *TypeChecker {
+	return &TypeChecker{interp: interp}
+}
+
+// Convert returns expr as a value of type t.  The context describes where
+// the value is used, for the error message.
+func (tc *TypeChecker) Convert(expr Expr, t *Type, context string) (Expr, error) {
+	checkNotNil("expr", expr)
+	checkNotNil("t", t)
+
+	from := expr.Type()
+	if Unqualified(from).Is(Unqualified(t)) {
+		return expr, nil
+	}
+	if !ConvertsImplicitly(from, t) {
+		return nil, &TypeMismatchError{Context: context, Expected: t, Actual: from}
+	}
+	return tc.interp.NewCastExpr(expr, Unqualified(t))
+}
+
+func (tc *TypeChecker) Unary(op operator.Operator, x Expr) (Expr, error) {
+	expr, err := tc.interp.NewUnaryOperatorExpr(op, x)
+	if err != nil {
+		return nil, &OperandTypeError{Operator: op, Types: []*Type{x.Type()}}
+	}
+	return expr, nil
+}
+
+// Binary applies a binary operator.  The operands of the logical operators
+// are converted to Bool.  The right operand of a shift or rotate is left
+// alone.  For every other operator, both operands are converted to whichever
+// of their two types is wider.
+func (tc *TypeChecker) Binary(op operator.Operator, x Expr, y Expr) (Expr, error) {
+	checkNotNil("x", x)
+	checkNotNil("y", y)
+
+	var err error
+	switch op {
+	case operator.LShift, operator.RShift, operator.LRotate, operator.RRotate:
+		// pass
+
+	case operator.LogicalAND, operator.LogicalXOR, operator.LogicalOR:
+		boolType := tc.interp.BoolType()
+		if x, err = tc.Convert(x, boolType, fmt.Sprintf("left operand of %v", op)); err != nil {
+			return nil, err
+		}
+		if y, err = tc.Convert(y, boolType, fmt.Sprintf("right operand of %v", op)); err != nil {
+			return nil, err
+		}
+
+	default:
+		t := commonType(x.Type(), y.Type())
+		if t == nil {
+			return nil, &TypeMismatchError{
+				Context:  fmt.Sprintf("right operand of %v", op),
+				Expected: x.Type(),
+				Actual:   y.Type(),
+			}
+		}
+		if x, err = tc.Convert(x, t, fmt.Sprintf("left operand of %v", op)); err != nil {
+			return nil, err
+		}
+		if y, err = tc.Convert(y, t, fmt.Sprintf("right operand of %v", op)); err != nil {
+			return nil, err
+		}
+	}
+
+	expr, err := tc.interp.NewBinaryOperatorExpr(op, x, y)
+	if err != nil {
+		return nil, &OperandTypeError{Operator: op, Types: []*Type{x.Type(), y.Type()}}
+	}
+	return expr, nil
+}
+
+// Ternary builds "cond ? then : else_".  The branches are converted to
+// whichever of their two types is wider.
+func (tc *TypeChecker) Ternary(cond Expr, then Expr, else_ Expr) (Expr, error) {
+	checkNotNil("then", then)
+	checkNotNil("else_", else_)
+
+	cond, err := tc.Convert(cond, tc.interp.BoolType(), "condition")
+	if err != nil {
+		return nil, err
+	}
+
+	t := commonType(then.Type(), else_.Type())
+	if t == nil {
+		return nil, &TypeMismatchError{Context: "else branch", Expected: then.Type(), Actual: else_.Type()}
+	}
+	if then, err = tc.Convert(then, t, "then branch"); err != nil {
+		return nil, err
+	}
+	if else_, err = tc.Convert(else_, t, "else branch"); err != nil {
+		return nil, err
+	}
+
+	return tc.interp.NewTernaryOperatorExpr(cond, then, else_)
+}
+
+// Call calls fn, converting each argument to the type of its parameter.
+func (tc *TypeChecker) Call(fn *Function, args []Expr, named map[string]Expr) (Expr, error) {
+	checkNotNil("fn", fn)
+
+	sig := fn.Signature()
+	params := sig.PositionalArgs()
+	converted := make([]Expr, len(args))
+	for index, arg := range args {
+		var param FunctionArg
+		switch {
+		case index < len(params):
+			param = params[index]
+		case len(params) != 0 && params[len(params)-1].IsRepeated():
+			param = params[len(params)-1]
+		default:
+			// Let NewCallExpr report the count.
+			return tc.interp.NewCallExpr(fn, args, named)
+		}
+
+		var err error
+		context := fmt.Sprintf("argument %d to %s", index, fn.CanonicalName())
+		if converted[index], err = tc.Convert(arg, param.Type(), context); err != nil {
+			return nil, err
+		}
+	}
+
+	convertedNamed := make(map[string]Expr, len(named))
+	for name, arg := range named {
+		param, found := sig.NamedArgs()[name]
+		if !found {
+			// Let NewCallExpr report the unknown name.
+			return tc.interp.NewCallExpr(fn, args, named)
+		}
+
+		var err error
+		context := fmt.Sprintf("argument %q to %s", name, fn.CanonicalName())
+		if convertedNamed[name], err = tc.Convert(arg, param.Type(), context); err != nil {
+			return nil, err
+		}
+	}
+
+	return tc.interp.NewCallExpr(fn, converted, convertedNamed)
+}
+
+// CallOverloaded picks the best of the candidates with ResolveOverload, then
+// calls it as Call does.
+func (tc *TypeChecker) CallOverloaded(candidates []*Function, args []Expr, named map[string]Expr) (Expr, error) {
+	argTypes := make([]*Type, len(args))
+	for index, arg := range args {
+		checkNotNil("arg", arg)
+		argTypes[index] = arg.Type()
+	}
+	namedTypes := make(map[string]*Type, len(named))
+	for name, arg := range named {
+		checkNotNil("arg", arg)
+		namedTypes[name] = arg.Type()
+	}
+
+	fn, err := ResolveOverload(candidates, argTypes, namedTypes)
+	if err != nil {
+		return nil, err
+	}
+	return tc.Call(fn, args, named)
+}
+
+// Assign stores value into target, which must be a reference to a mutable
+// symbol.  The value is converted to the symbol's type.
+func (tc *TypeChecker) Assign(target Expr, value Expr) (Expr, error) {
+	checkNotNil("target", target)
+	checkNotNil("value", value)
+
+	ref, ok := target.(*SymbolExpr)
+	if !ok || target.Type().Kind() != MutableKind {
+		return nil, &NotAssignableError{Target: target.Type()}
+	}
+
+	value, err := tc.Convert(value, target.Type(), "assignment")
+	if err != nil {
+		return nil, err
+	}
+	return tc.interp.NewAssignExpr(ref, value)
+}
+
+// }}}
@@ -0,0 +1,276 @@
+package exprtree
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chronos-tachyon/go-spiderscript/memory"
+	"github.com/chronos-tachyon/go-spiderscript/operator"
+)
+
+func TestConvertsImplicitly(t *testing.T) {
+	interp := NewSystemInterp()
+	mod, err := interp.NewModule("typecheck")
+	if err != nil {
+		t.Fatalf("NewModule: unexpected error: %v", err)
+	}
+
+	u8 := interp.UInt8Type()
+	u32 := interp.UInt32Type()
+	s16 := interp.SInt16Type()
+	mutableU8, _ := interp.MutableType(u8)
+	constU8, _ := interp.ConstType(u8)
+	byteType, err := interp.NamedType(mod.Symbols(), SymbolData{Kind: SimpleSymbol, Name: "Byte"}, u8)
+	if err != nil {
+		t.Fatalf("NamedType: unexpected error: %v", err)
+	}
+
+	type testRow struct {
+		From     *Type
+		To       *Type
+		Expected bool
+	}
+
+	testData := []testRow{
+		{u8, u8, true},
+		{u8, interp.UInt16Type(), true},
+		{u8, interp.UInt64Type(), true},
+		{u32, u8, false},
+		{u8, s16, false},
+		{interp.SInt8Type(), interp.SInt64Type(), true},
+		{s16, interp.SInt8Type(), false},
+		{interp.Float16Type(), interp.Float64Type(), true},
+		{interp.Float64Type(), interp.Float32Type(), false},
+		{interp.Complex32Type(), interp.Complex128Type(), true},
+		{interp.Float32Type(), interp.Complex64Type(), false},
+		{s16, interp.Float32Type(), false},
+		{mutableU8, u32, true},
+		{u8, constU8, true},
+		{constU8, mutableU8, true},
+		{byteType, u8, true},
+		{u8, byteType, false},
+		{byteType, u32, false},
+		{interp.BoolType(), u8, false},
+	}
+
+	for _, row := range testData {
+		name := row.From.CanonicalName() + " → " + row.To.CanonicalName()
+		t.Run(name, func(t *testing.T) {
+			if actual := ConvertsImplicitly(row.From, row.To); actual != row.Expected {
+				t.Errorf("ConvertsImplicitly: expected %v, got %v", row.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestTypeChecker(t *testing.T) {
+	interp := NewSystemInterp()
+	mod, err := interp.NewModule("typecheck")
+	if err != nil {
+		t.Fatalf("NewModule: unexpected error: %v", err)
+	}
+	tc := interp.TypeChecker()
+
+	u8 := interp.UInt8Type()
+	u32 := interp.UInt32Type()
+	s8 := interp.SInt8Type()
+	f32 := interp.Float32Type()
+	f64 := interp.Float64Type()
+
+	newVariable := func(name string, typ *Type) *SymbolExpr {
+		sym, err := mod.Symbols().NewSymbol(SymbolData{Kind: SimpleSymbol, Name: name, Type: typ})
+		if err != nil {
+			t.Fatalf("NewSymbol: unexpected error: %v", err)
+		}
+		mem := memory.New(name, memory.HugePagesOff, false)
+		mem.Grow(typ.PaddedBytes())
+		return NewSymbolExpr(NewValue(sym, mem.UInt8s()))
+	}
+
+	mutableU32, _ := interp.MutableType(u32)
+	constU32, _ := interp.ConstType(u32)
+	counter := newVariable("counter", mutableU32)
+	limit := newVariable("limit", constU32)
+	plain := newVariable("plain", u32)
+
+	small := mustConstant(t, interp, u8, 200)
+	big := mustConstant(t, interp, u32, 100000)
+	negative := mustConstant(t, interp, s8, -1)
+	half := mustConstant(t, interp, f32, 0.5)
+	quarter := mustConstant(t, interp, f64, 0.25)
+	isTrue, err := tc.Binary(operator.CmpEQ, small, small)
+	if err != nil {
+		t.Fatalf("Binary: unexpected error: %v", err)
+	}
+
+	t.Run("widen", func(t *testing.T) {
+		for _, pair := range [][2]Expr{{small, big}, {big, small}} {
+			sum, err := tc.Binary(operator.Add, pair[0], pair[1])
+			if err != nil {
+				t.Fatalf("Binary: unexpected error: %v", err)
+			}
+			if !sum.Type().Is(u32) {
+				t.Errorf("Binary: expected %s, got %s", u32.CanonicalName(), sum.Type().CanonicalName())
+			}
+			if actual, err := evalExpr(t, sum); err != nil || actual != uint32(100200) {
+				t.Errorf("EvalInto: expected 100200, got %#v, %v", actual, err)
+			}
+		}
+
+		product, err := tc.Binary(operator.Mul, half, quarter)
+		if err != nil {
+			t.Fatalf("Binary: unexpected error: %v", err)
+		}
+		if actual, err := evalExpr(t, product); err != nil || actual != float64(0.125) {
+			t.Errorf("EvalInto: expected 0.125, got %#v, %v", actual, err)
+		}
+
+		choice, err := tc.Ternary(isTrue, small, big)
+		if err != nil {
+			t.Fatalf("Ternary: unexpected error: %v", err)
+		}
+		if actual, err := evalExpr(t, choice); err != nil || actual != uint32(200) {
+			t.Errorf("EvalInto: expected 200, got %#v, %v", actual, err)
+		}
+	})
+
+	t.Run("assign", func(t *testing.T) {
+		assign, err := tc.Assign(counter, small)
+		if err != nil {
+			t.Fatalf("Assign: unexpected error: %v", err)
+		}
+		if actual, err := evalExpr(t, assign); err != nil || actual != uint32(200) {
+			t.Errorf("EvalInto: expected 200, got %#v, %v", actual, err)
+		}
+		if actual, err := evalExpr(t, counter); err != nil || actual != uint32(200) {
+			t.Errorf("counter: expected 200, got %#v, %v", actual, err)
+		}
+	})
+
+	type testRow struct {
+		Name     string
+		Build    func() (Expr, error)
+		Expected string
+	}
+
+	testData := []testRow{
+		{
+			"mixed signs",
+			func() (Expr, error) { return tc.Binary(operator.Add, small, negative) },
+			"right operand of +: expected builtin::UInt8, got builtin::SInt8",
+		},
+		{
+			"int and float",
+			func() (Expr, error) { return tc.Binary(operator.Mul, big, half) },
+			"right operand of *: expected builtin::UInt32, got builtin::Float32",
+		},
+		{
+			"logical",
+			func() (Expr, error) { return tc.Binary(operator.LogicalAND, isTrue, small) },
+			"right operand of &&: expected builtin::Bool, got builtin::UInt8",
+		},
+		{
+			"condition",
+			func() (Expr, error) { return tc.Ternary(small, small, big) },
+			"condition: expected builtin::Bool, got builtin::UInt8",
+		},
+		{
+			"branches",
+			func() (Expr, error) { return tc.Ternary(isTrue, small, negative) },
+			"else branch: expected builtin::UInt8, got builtin::SInt8",
+		},
+		{
+			"operand",
+			func() (Expr, error) { return tc.Unary(operator.LogicalNOT, small) },
+			"operator ! cannot be applied to builtin::UInt8",
+		},
+		{
+			"assign narrowing",
+			func() (Expr, error) { return tc.Assign(counter, negative) },
+			"assignment: expected mutable builtin::UInt32, got builtin::SInt8",
+		},
+		{
+			"assign const",
+			func() (Expr, error) { return tc.Assign(limit, big) },
+			"cannot assign to const builtin::UInt32",
+		},
+		{
+			"assign plain",
+			func() (Expr, error) { return tc.Assign(plain, big) },
+			"cannot assign to builtin::UInt32",
+		},
+		{
+			"assign expression",
+			func() (Expr, error) { return tc.Assign(big, big) },
+			"cannot assign to builtin::UInt32",
+		},
+	}
+
+	for _, row := range testData {
+		t.Run(row.Name, func(t *testing.T) {
+			expr, err := row.Build()
+			if err == nil {
+				t.Fatalf("expected error %q, got %v", row.Expected, expr.Type().CanonicalName())
+			}
+			switch err.(type) {
+			case *TypeMismatchError, *OperandTypeError, *NotAssignableError:
+				// pass
+			default:
+				t.Errorf("expected a structured error, got %T", err)
+			}
+			if actual := err.Error(); !strings.HasPrefix(actual, row.Expected) {
+				t.Errorf("expected error %q, got %q", row.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestTypeChecker_Call(t *testing.T) {
+	interp := NewSystemInterp()
+	mod, err := interp.NewModule("typecheck")
+	if err != nil {
+		t.Fatalf("NewModule: unexpected error: %v", err)
+	}
+	tc := interp.TypeChecker()
+	u64 := interp.UInt64Type()
+
+	sig := interp.FunctionSignatureBuilder().WithReturn(u64).WithRepeatedPositionalArg(u64).Build()
+	sym, err := mod.Symbols().NewSymbol(SymbolData{
+		Kind:     SimpleFunctionSymbol,
+		Name:     "sum",
+		Type:     interp.VoidType(),
+		Function: FunctionSymbolData{Signature: sig, PositionalNames: []string{"values"}},
+	})
+	if err != nil {
+		t.Fatalf("NewSymbol: unexpected error: %v", err)
+	}
+	fn, err := interp.NewNativeFunction(sym, func(out *Value, args []Value) error {
+		var total uint64
+		for _, arg := range args {
+			total += arg.Get().(uint64)
+		}
+		return out.Set(total)
+	})
+	if err != nil {
+		t.Fatalf("NewNativeFunction: unexpected error: %v", err)
+	}
+
+	args := []Expr{
+		mustConstant(t, interp, interp.UInt8Type(), 1),
+		mustConstant(t, interp, interp.UInt16Type(), 2),
+		mustConstant(t, interp, u64, 3),
+	}
+	call, err := tc.Call(fn, args, nil)
+	if err != nil {
+		t.Fatalf("Call: unexpected error: %v", err)
+	}
+	if actual, err := evalExpr(t, call); err != nil || actual != uint64(6) {
+		t.Errorf("EvalInto: expected 6, got %#v, %v", actual, err)
+	}
+
+	args = append(args, mustConstant(t, interp, interp.SInt8Type(), 4))
+	_, err = tc.Call(fn, args, nil)
+	if _, ok := err.(*TypeMismatchError); !ok {
+		t.Errorf("Call: expected *TypeMismatchError, got %v", err)
+	}
+}
@@ -166,6 +166,18 @@ func cloneValues(in []Value) []Value {
 	return out
 }
 
+func cloneExprs(in []Expr) []Expr {
+	out := make([]Expr, len(in))
+	copy(out, in)
+	return out
+}
+
+func cloneFunctions(in []*Function) []*Function {
+	out := make([]*Function, len(in))
+	copy(out, in)
+	return out
+}
+
 func cloneEnumItems(in []*EnumItem) []*EnumItem {
 	out := make([]*EnumItem, len(in))
 	copy(out, in)
@@ -204,6 +216,14 @@ func cloneFunctionArgMap(in map[string]FunctionArg) map[string]FunctionArg {
 	return out
 }
 
+func cloneTypeMap(in map[string]*Type) map[string]*Type {
+	out := make(map[string]*Type, len(in))
+	for key, value := range in {
+		out[key] = value
+	}
+	return out
+}
+
 func toUInt(kind TypeKind, in interface{}) (uint64, error) {
 	var maxValue uint64
 	switch kind {
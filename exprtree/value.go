@@ -56,9 +56,89 @@ func (frame *Frame) ValueByOffset(offset uint) (Value, bool) {
 
 type Value struct {
 	sym  *Symbol
+	typ  *Type
 	span memory.UInt8Span
 }
 
+// NewValue returns the value of sym, stored in span.
+func NewValue(sym *Symbol, span memory.UInt8Span) Value {
+	checkNotNil("sym", sym)
+	checkValueSpan(sym.Type(), span)
+	return Value{sym: sym, typ: sym.Type(), span: span}
+}
+
+// NewTemporaryValue returns a zeroed value of type t, stored in memory of its
+// own.  The value has no symbol.  Each call maps at least one page, so
+// EvalInto takes its intermediate values from a tempFrame instead.
+func (interp *Interp) NewTemporaryValue(t *Type) Value {
+	checkNotNil("t", t)
+	mem := memory.New("temporary", memory.HugePagesOff, false)
+	mem.Grow(t.PaddedBytes())
+	return Value{sym: nil, typ: t, span: mem.UInt8s()}
+}
+
+// NewStaticValue returns a zeroed value for sym, stored in memory of its own,
+// as for a variable that lives as long as its module.
+func (interp *Interp) NewStaticValue(sym *Symbol) Value {
+	checkNotNil("sym", sym)
+	mem := memory.New(sym.CanonicalName(), memory.HugePagesOff, false)
+	mem.Grow(sym.Type().PaddedBytes())
+	return NewValue(sym, mem.UInt8s())
+}
+
+// tempFrame holds the intermediate values of one call to EvalInto.  They are
+// carved out of a single region of memory, which goes back to the Interp's
+// pool when the frame is released, so that evaluating an expression over and
+// over reuses the same memory.  A released frame's values must not be used.
+type tempFrame struct {
+	interp *Interp
+	mem    *memory.Memory
+	used   uint
+}
+
+func (interp *Interp) newTempFrame() *tempFrame {
+	if frame, ok := interp.tempFrames.Get().(*tempFrame); ok {
+		return frame
+	}
+	return &tempFrame{
+		interp: interp,
+		mem:    memory.New("temporaries", memory.HugePagesOff, false),
+	}
+}
+
+func (frame *tempFrame) release() {
+	frame.used = 0
+	frame.interp.tempFrames.Put(frame)
+}
+
+// alloc returns a zeroed value of type t, aligned as t requires.
+func (frame *tempFrame) alloc(t *Type) Value {
+	mask := t.AlignBytes() - 1
+	start := (frame.used + mask) &^ mask
+	end := start + t.PaddedBytes()
+	if size := frame.mem.Size(); end > size {
+		frame.mem.Grow(end - size)
+	}
+	frame.used = end
+
+	value := Value{sym: nil, typ: t, span: frame.mem.UInt8s().Span(start, end)}
+	value.Zero()
+	return value
+}
+
+// eval evaluates expr into a new value of the frame.
+func (frame *tempFrame) eval(expr Expr) (Value, error) {
+	tmp := frame.alloc(expr.Type())
+	err := expr.EvalInto(&tmp)
+	return tmp, err
+}
+
+func checkValueSpan(t *Type, span memory.UInt8Span) {
+	if size, minSize := span.Size(), t.MinimumBytes(); size < minSize {
+		panic(fmt.Errorf("BUG: span holds %d bytes, but %s needs %d", size, t.CanonicalName(), minSize))
+	}
+}
+
 func (value Value) Symbol() *Symbol {
 	return value.sym
 }
@@ -68,19 +148,53 @@ func (value Value) UInt8Span() memory.UInt8Span {
 }
 
 func (value Value) Interp() *Interp {
-	return value.sym.Interp()
+	return value.typ.Interp()
 }
 
 func (value Value) CanonicalName() string {
+	if value.sym == nil {
+		return ""
+	}
 	return value.sym.CanonicalName()
 }
 
 func (value Value) MangledName() string {
+	if value.sym == nil {
+		return ""
+	}
 	return value.sym.MangledName()
 }
 
 func (value Value) Type() *Type {
-	return value.sym.Type()
+	return value.typ
+}
+
+// Span returns the value of the bytes [i, j) of value, as a value of type t.
+func (value Value) Span(i, j uint, t *Type) Value {
+	checkNotNil("t", t)
+	span := value.span.Span(i, j)
+	checkValueSpan(t, span)
+	return Value{sym: nil, typ: t, span: span}
+}
+
+// CopyFrom overwrites value with the bytes of src, which must have the same
+// minimum size.
+func (value Value) CopyFrom(src Value) error {
+	n := value.typ.MinimumBytes()
+	if srcN := src.typ.MinimumBytes(); srcN != n {
+		return fmt.Errorf("cannot copy %d bytes of %s into %d bytes of %s", srcN, src.typ.CanonicalName(), n, value.typ.CanonicalName())
+	}
+
+	// Copy through a buffer, as both spans may share a lock.
+	tmp := make([]byte, n)
+	_ = src.span.WithReadLock(0, n, func(bytes []byte) error {
+		copy(tmp, bytes)
+		return nil
+	})
+	return value.span.WithWriteLock(0, n, func(bytes []byte) error {
+		copy(bytes, tmp)
+		return nil
+	})
 }
 
 func (value Value) WithWriteLock(fn func(bytes []byte) error) error {
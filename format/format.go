@@ -0,0 +1,299 @@
+// Package format reprints SpiderScript source in a canonical layout.
+//
+// Formatting happens in two passes.  The first pass edits the whitespace
+// stored in the AST: binary, ternary and assignment operators get exactly
+// one space on either side, trailing pragmas are pulled onto the line they
+// decorate, pragma argument lists are written compactly, and runs of
+// adjacent imports are sorted by module name.  Whitespace that contains a
+// comment is never touched, so comments stay attached to the nodes they
+// were written next to.  The second pass works on the tokens of the
+// reprinted file and fixes up the layout of each line: indentation (one tab
+// per open bracket), trailing whitespace, blank lines and line endings.
+package format
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/chronos-tachyon/go-spiderscript/ast"
+	"github.com/chronos-tachyon/go-spiderscript/token"
+)
+
+// Source formats the contents of the file at path.  It returns an error,
+// and no output, if the file does not parse cleanly or is not valid UTF-8.
+func Source(path string, src []byte) ([]byte, error) {
+	var lexer token.Lexer
+	lexer.InitReader(path, bytes.NewReader(src))
+
+	var parser ast.Parser
+	parser.Init(&lexer)
+
+	var file ast.File
+	parser.Parse(&file)
+
+	if errors := parser.Errors(); errors != nil {
+		return nil, errors[0]
+	}
+
+	var err error
+	ast.Inspect(&file, func(node ast.Node) bool {
+		if stmt, ok := node.(*ast.ErrorStatement); ok && err == nil {
+			err = stmt.Err
+		}
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	File(&file)
+	return []byte(Layout(path, file.String())), nil
+}
+
+// File normalises the whitespace stored in file, in place.  It does not
+// change indentation; see Layout.
+func File(file *ast.File) {
+	sortImports(file.Statements)
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch x := node.(type) {
+		case *ast.BinaryOperatorExpr:
+			setSpace(&x.WS0)
+			setSpace(&x.WS1)
+
+		case *ast.TernaryOperatorExpr:
+			setSpace(&x.WS0)
+			setSpace(&x.WS1)
+			setSpace(&x.WS2)
+			setSpace(&x.WS3)
+
+		case *ast.AssignmentStatement:
+			setSpace(&x.WS1)
+			setSpace(&x.WS2)
+
+		case *ast.ImportStatement:
+			setSpace(&x.WS1)
+			setPragmaSpaces(x.WS2)
+
+		case *ast.AliasStatement:
+			setPragmaSpaces(x.WS4)
+
+		case *ast.TypeDeclStatement:
+			setPragmaSpaces(x.WS4)
+
+		case *ast.VarDeclStatement:
+			setPragmaSpaces(x.WS6)
+
+		case *ast.FuncDeclStatement:
+			setPragmaSpaces(x.WS7)
+
+		case *ast.MethodDeclStatement:
+			setPragmaSpaces(x.WS7)
+
+		case *ast.FieldMember:
+			setPragmaSpaces(x.WS7)
+
+		case *ast.EnumMember:
+			setPragmaSpaces(x.WS3)
+
+		case *ast.VersionPragma:
+			x.Raw = strings.Map(dropSpace, x.Raw)
+
+		case *ast.GenericPragma:
+			x.WS0 = ast.HorizontalWhitespace{}
+			x.WS3 = ast.HorizontalWhitespace{}
+			for index := range x.Expressions {
+				x.WS1[index] = &ast.HorizontalWhitespace{}
+				x.WS2[index] = &ast.HorizontalWhitespace{}
+			}
+		}
+		return true
+	})
+}
+
+// hasComment reports whether any of items is a comment.
+func hasComment(items []ast.Node) bool {
+	for _, item := range items {
+		switch item.(type) {
+		case *ast.SingleLineCommentWhitespace:
+			return true
+		case *ast.MultiLineCommentWhitespace:
+			return true
+		}
+	}
+	return false
+}
+
+// hasLineBreak reports whether any of items ends a line.
+func hasLineBreak(items []ast.Node) bool {
+	for _, item := range items {
+		switch item.(type) {
+		case *ast.VerticalWhitespace:
+			return true
+		case *ast.SingleLineCommentWhitespace:
+			return true
+		}
+	}
+	return false
+}
+
+// setSpace replaces ws with a single space, unless it spans lines or holds
+// a comment.
+func setSpace(ws *ast.InternalWhitespaceRun) {
+	if hasComment(ws.Items) || hasLineBreak(ws.Items) {
+		return
+	}
+	ws.Items = []ast.Node{&ast.HorizontalWhitespace{Count: 1}}
+}
+
+// dropSpace is a strings.Map function that deletes whitespace.
+func dropSpace(ch rune) rune {
+	if unicode.IsSpace(ch) {
+		return -1
+	}
+	return ch
+}
+
+// setPragmaSpaces replaces each whitespace run before a trailing pragma
+// with a single space, joining the pragma to the line it decorates.
+func setPragmaSpaces(list []ast.Node) {
+	for _, node := range list {
+		ws := node.(*ast.InternalWhitespaceRun)
+		if hasComment(ws.Items) {
+			continue
+		}
+		ws.Items = []ast.Node{&ast.HorizontalWhitespace{Count: 1}}
+	}
+}
+
+// importEntry is an import together with the comment lines right above it.
+type importEntry struct {
+	nodes []ast.Node
+	stmt  *ast.ImportStatement
+}
+
+// sortImports sorts each group of adjacent imports in list by module name.
+// A blank line or a statement other than an import ends a group.  Comment
+// lines directly above an import move with it.
+func sortImports(list []ast.Node) {
+	i := 0
+	for i < len(list) {
+		var entries []importEntry
+		j := i
+		for j < len(list) {
+			k := j
+			for k < len(list) && isCommentLine(list[k]) {
+				k++
+			}
+			if k == len(list) {
+				break
+			}
+			stmt, ok := list[k].(*ast.ImportStatement)
+			if !ok || (len(entries) > 0 && hasVerticalWhitespace(stmt.WS0.Items)) {
+				break
+			}
+
+			nodes := make([]ast.Node, k+1-j)
+			copy(nodes, list[j:k+1])
+			entries = append(entries, importEntry{nodes: nodes, stmt: stmt})
+			j = k + 1
+
+			if endsWithBlankLine(&stmt.WS3) {
+				break
+			}
+		}
+
+		if len(entries) == 0 {
+			i++
+			continue
+		}
+
+		sortImportGroup(entries)
+		for _, entry := range entries {
+			i += copy(list[i:], entry.nodes)
+		}
+	}
+}
+
+// sortImportGroup sorts group, making sure that every import still ends its
+// own line and that any blank line after the group stays after the group.
+func sortImportGroup(group []importEntry) {
+	last := &group[len(group)-1].stmt.WS3
+	blank := endsWithBlankLine(last)
+	if blank {
+		vws := last.Items[len(last.Items)-1].(*ast.VerticalWhitespace)
+		*vws = ast.VerticalWhitespace{Count: 1}
+	}
+	needBreak := hasLineBreak(last.Items)
+
+	sort.SliceStable(group, func(i, j int) bool {
+		a := group[i].stmt.ModuleName.String()
+		b := group[j].stmt.ModuleName.String()
+		return a < b
+	})
+
+	for index, entry := range group {
+		ws := &entry.stmt.WS3
+		isLast := (index == len(group)-1)
+		if (!isLast || needBreak) && !hasLineBreak(ws.Items) {
+			ws.Items = append(ws.Items, &ast.VerticalWhitespace{Count: 1})
+		}
+		if isLast && blank {
+			if vws, ok := ws.Items[len(ws.Items)-1].(*ast.VerticalWhitespace); ok {
+				*vws = ast.VerticalWhitespace{Count: 2}
+			} else {
+				ws.Items = append(ws.Items, &ast.VerticalWhitespace{Count: 1})
+			}
+		}
+	}
+}
+
+// isCommentLine reports whether node is a line holding nothing but a "//"
+// comment, with no blank line after it.
+func isCommentLine(node ast.Node) bool {
+	stmt, ok := node.(*ast.EmptyStatement)
+	if !ok || len(stmt.WS.Items) == 0 {
+		return false
+	}
+	items := stmt.WS.Items
+	comment, ok := items[len(items)-1].(*ast.SingleLineCommentWhitespace)
+	if !ok || countLineBreaks(comment.Raw) > 1 {
+		return false
+	}
+	for _, item := range items[:len(items)-1] {
+		switch item.(type) {
+		case *ast.HorizontalWhitespace:
+		case *ast.MultiLineCommentWhitespace:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// countLineBreaks counts the line breaks in s, treating "\r\n" as one.
+func countLineBreaks(s string) int {
+	s = strings.Replace(s, "\r\n", "\n", -1)
+	return strings.Count(s, "\n") + strings.Count(s, "\r")
+}
+
+// endsWithBlankLine reports whether ws ends with a blank line.
+func endsWithBlankLine(ws *ast.TerminalWhitespaceRun) bool {
+	if len(ws.Items) == 0 {
+		return false
+	}
+	vws, ok := ws.Items[len(ws.Items)-1].(*ast.VerticalWhitespace)
+	return ok && vws.Count >= 2
+}
+
+// hasVerticalWhitespace reports whether any of items is a line break.
+func hasVerticalWhitespace(items []ast.Node) bool {
+	for _, item := range items {
+		if _, ok := item.(*ast.VerticalWhitespace); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package format
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+	type testRow struct {
+		Name     string
+		Input    string
+		Expected string
+	}
+
+	testData := []testRow{
+		{
+			Name:     "empty",
+			Input:    "",
+			Expected: "",
+		},
+		{
+			Name:     "final-newline",
+			Input:    "let x = 1",
+			Expected: "let x = 1\n",
+		},
+		{
+			Name:     "blank-lines",
+			Input:    "\n\nlet x = 1\n\n\n\nlet y = 2\n\n\n",
+			Expected: "let x = 1\n\nlet y = 2\n",
+		},
+		{
+			Name:     "crlf",
+			Input:    "let x = 1\r\n\r\nlet y = 2\r\n",
+			Expected: "let x = 1\n\nlet y = 2\n",
+		},
+		{
+			Name:     "binary-operators",
+			Input:    "x = a  +   b\ny = a+ b*  c\n",
+			Expected: "x = a + b\ny = a + b * c\n",
+		},
+		{
+			Name:     "ternary-operators",
+			Input:    "x = a?b  :c\ny = a>1 ?b:  c ? d:e\n",
+			Expected: "x = a ? b : c\ny = a > 1 ? b : c ? d : e\n",
+		},
+		{
+			Name:     "assignments",
+			Input:    "x=1\ny  =  2\nz+=  3\nw\t:=4\n",
+			Expected: "x = 1\ny = 2\nz += 3\nw := 4\n",
+		},
+		{
+			Name:     "operator-at-line-end",
+			Input:    "x = a +\n  b\n",
+			Expected: "x = a +\nb\n",
+		},
+		{
+			Name:     "indentation",
+			Input:    "func f(x: Int) {\n    if x < 1 {\n  return x\n        }\n}\n",
+			Expected: "func f(x: Int) {\n\tif x < 1 {\n\t\treturn x\n\t}\n}\n",
+		},
+		{
+			Name:     "else",
+			Input:    "func f() {\nif a {\ng()\n} else {\nh()\n}\n}\n",
+			Expected: "func f() {\n\tif a {\n\t\tg()\n\t} else {\n\t\th()\n\t}\n}\n",
+		},
+		{
+			Name:     "switch",
+			Input:    "func f() {\n  switch i {\n    case 1, 2:\n      g()\n    case _:\n      h()\n  }\n}\n",
+			Expected: "func f() {\n\tswitch i {\n\tcase 1, 2:\n\t\tg()\n\tcase _:\n\t\th()\n\t}\n}\n",
+		},
+		{
+			Name:     "continued-call",
+			Input:    "f(a,\nb)\n",
+			Expected: "f(a,\n\tb)\n",
+		},
+		{
+			Name:     "comments",
+			Input:    "func f() {\n// leading   \n  g() /* inline */   // trailing   \n}\n",
+			Expected: "func f() {\n\t// leading\n\tg() /* inline */   // trailing\n}\n",
+		},
+		{
+			Name:     "pragmas",
+			Input:    "func f() #inline   #noalias( 1 , 2 ) {\n}\n",
+			Expected: "func f() #inline #noalias(1,2) {\n}\n",
+		},
+		{
+			Name:     "version-pragma",
+			Input:    "#version( 1, 2 )\n",
+			Expected: "#version(1,2)\n",
+		},
+		{
+			Name:     "version-pragma-short",
+			Input:    "#version(1)\n",
+			Expected: "#version(1)\n",
+		},
+		{
+			Name:     "imports",
+			Input:    "import core::strings\nimport   core::io\n// fmt is for printing\nimport core::fmt\n\nimport zeta\nimport alpha\nlet x = 1\n",
+			Expected: "// fmt is for printing\nimport core::fmt\nimport core::io\nimport core::strings\n\nimport alpha\nimport zeta\nlet x = 1\n",
+		},
+		{
+			Name:     "imports-blank-line-after",
+			Input:    "import b\nimport a\n\nlet x = 1\n",
+			Expected: "import a\nimport b\n\nlet x = 1\n",
+		},
+		{
+			Name:     "imports-comment-before-blank-line",
+			Input:    "import b\n// about b\n\nimport a\n",
+			Expected: "import b\n// about b\n\nimport a\n",
+		},
+		{
+			Name:     "imports-on-one-line",
+			Input:    "import b; import a\nlet x = 1\n",
+			Expected: "import a\nimport b;\nlet x = 1\n",
+		},
+	}
+
+	for _, row := range testData {
+		t.Run(row.Name, func(t *testing.T) {
+			actual, err := Source("test.spider", []byte(row.Input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(actual) != row.Expected {
+				t.Errorf("wrong output:\n\texpected: %q\n\t  actual: %q", row.Expected, string(actual))
+			}
+
+			again, err := Source("test.spider", actual)
+			if err != nil {
+				t.Fatalf("unexpected error on second pass: %v", err)
+			}
+			if string(again) != string(actual) {
+				t.Errorf("not idempotent:\n\t first: %q\n\tsecond: %q", string(actual), string(again))
+			}
+		})
+	}
+}
+
+func TestSource_SyntaxError(t *testing.T) {
+	_, err := Source("test.spider", []byte("let x = = 1\n"))
+	if err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestSource_InvalidUTF8(t *testing.T) {
+	_, err := Source("test.spider", []byte("let x = \"\xff\"\n"))
+	if err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestSource_Corpus(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "ast", "testdata", "*.spider"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no *.spider files found in ../ast/testdata")
+	}
+
+	for _, path := range paths {
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+
+		first, err := Source(path, raw)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", path, err)
+			continue
+		}
+
+		second, err := Source(path, first)
+		if err != nil {
+			t.Errorf("%s: unexpected error on second pass: %v", path, err)
+			continue
+		}
+
+		if string(first) != string(second) {
+			t.Errorf("%s: formatting is not idempotent:\n\t first: %q\n\tsecond: %q", path, string(first), string(second))
+		}
+	}
+}
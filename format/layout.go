@@ -0,0 +1,154 @@
+package format
+
+import (
+	"strings"
+
+	"github.com/chronos-tachyon/go-spiderscript/token"
+)
+
+// opener is an open bracket that has not been closed yet.
+type opener struct {
+	indent   bool
+	isSwitch bool
+}
+
+// Layout rewrites the line structure of src, the text of the file at path.
+// Each line is indented with one tab per bracket left open by an earlier
+// line; "case" labels are outdented to the level of their "switch".
+// Trailing whitespace is removed, runs of blank lines are collapsed into
+// one, leading and trailing blank lines are dropped, and every line ends
+// with "\n".  Apart from trailing whitespace after a "//" comment, the
+// insides of comments and literals are left alone.
+//
+// If src does not lex cleanly, it is returned unchanged.
+func Layout(path string, src string) string {
+	var lexer token.Lexer
+	lexer.Init(path, []rune(src))
+
+	var lines [][]token.Token
+	var line []token.Token
+	for lexer.HasNext() {
+		tok := lexer.Token()
+		switch tok.Type {
+		case token.EOF:
+			// pass
+
+		case token.Invalid:
+			return src
+
+		case token.VWS:
+			// The "\n" of a "\r\n" pair is a token of its own, but
+			// it does not start another line.
+			for n := tok.End.RawLineNumber - tok.Start.RawLineNumber; n > 0; n-- {
+				lines = append(lines, line)
+				line = nil
+			}
+
+		default:
+			line = append(line, tok)
+		}
+	}
+	lines = append(lines, line)
+
+	var out strings.Builder
+	out.Grow(len(src))
+
+	var stack []opener
+	blank := false
+	started := false
+	for _, line := range lines {
+		line = trimHWS(line)
+		if len(line) == 0 {
+			blank = started
+			continue
+		}
+		if blank {
+			out.WriteByte('\n')
+			blank = false
+		}
+		started = true
+
+		closers := 0
+		for closers < len(line) && isCloser(line[closers].Type) {
+			closers++
+		}
+		outer := stack
+		if closers > len(outer) {
+			outer = nil
+		} else {
+			outer = outer[:len(outer)-closers]
+		}
+
+		depth := 0
+		for _, o := range outer {
+			if o.indent {
+				depth++
+			}
+		}
+		if line[0].Type == token.KeywordCase && len(outer) > 0 && outer[len(outer)-1].isSwitch && depth > 0 {
+			depth--
+		}
+
+		for i := 0; i < depth; i++ {
+			out.WriteByte('\t')
+		}
+		for index, tok := range line {
+			raw := string(tok.Raw)
+			if index == len(line)-1 && tok.Type == token.SingleLineComment {
+				raw = strings.TrimRight(raw, " \t")
+			}
+			out.WriteString(raw)
+		}
+		out.WriteByte('\n')
+
+		low := len(stack)
+		for _, tok := range line {
+			switch {
+			case isOpener(tok.Type):
+				isSwitch := tok.Type == token.LBrace && line[0].Type == token.KeywordSwitch
+				stack = append(stack, opener{isSwitch: isSwitch})
+
+			case isCloser(tok.Type) && len(stack) > 0:
+				stack = stack[:len(stack)-1]
+				if len(stack) < low {
+					low = len(stack)
+				}
+			}
+		}
+		if len(stack) > low {
+			stack[len(stack)-1].indent = true
+		}
+	}
+
+	if !started {
+		return ""
+	}
+	return out.String()
+}
+
+// trimHWS removes horizontal whitespace from both ends of line.
+func trimHWS(line []token.Token) []token.Token {
+	for len(line) > 0 && line[0].Type == token.HWS {
+		line = line[1:]
+	}
+	for len(line) > 0 && line[len(line)-1].Type == token.HWS {
+		line = line[:len(line)-1]
+	}
+	return line
+}
+
+func isOpener(tt token.Type) bool {
+	switch tt {
+	case token.LParen, token.LBracket, token.LBrace:
+		return true
+	}
+	return false
+}
+
+func isCloser(tt token.Type) bool {
+	switch tt {
+	case token.RParen, token.RBracket, token.RBrace:
+		return true
+	}
+	return false
+}
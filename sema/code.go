@@ -0,0 +1,394 @@
+package sema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chronos-tachyon/go-spiderscript/ast"
+	"github.com/chronos-tachyon/go-spiderscript/exprtree"
+	"github.com/chronos-tachyon/go-spiderscript/operator"
+)
+
+// compoundOperators maps each compound assignment that exprtree can evaluate
+// to the binary operator it applies.
+var compoundOperators = map[operator.Operator]operator.Operator{
+	operator.AssignAdd:        operator.Add,
+	operator.AssignSub:        operator.Sub,
+	operator.AssignMul:        operator.Mul,
+	operator.AssignDiv:        operator.Div,
+	operator.AssignMod:        operator.Mod,
+	operator.AssignLShift:     operator.LShift,
+	operator.AssignRShift:     operator.RShift,
+	operator.AssignLRotate:    operator.LRotate,
+	operator.AssignRRotate:    operator.RRotate,
+	operator.AssignBitwiseAND: operator.BitwiseAND,
+	operator.AssignBitwiseXOR: operator.BitwiseXOR,
+	operator.AssignBitwiseOR:  operator.BitwiseOR,
+	operator.AssignLogicalAND: operator.LogicalAND,
+	operator.AssignLogicalXOR: operator.LogicalXOR,
+	operator.AssignLogicalOR:  operator.LogicalOR,
+}
+
+// scope holds the local variables of a block.  Variables declared outside of
+// any block are module symbols instead.
+type scope struct {
+	parent *scope
+	values map[string]exprtree.Value
+}
+
+func (s *scope) get(name string) (exprtree.Value, bool) {
+	for ; s != nil; s = s.parent {
+		if value, found := s.values[name]; found {
+			return value, true
+		}
+	}
+	return exprtree.Value{}, false
+}
+
+// enterScope starts a block and returns the function that ends it.
+func (c *checker) enterScope() func() {
+	c.scope = &scope{parent: c.scope, values: make(map[string]exprtree.Value)}
+	return func() { c.scope = c.scope.parent }
+}
+
+// code lowers a statement that runs, or returns nil for a declaration or
+// after reporting an error.
+func (c *checker) code(node ast.Node) step {
+	switch x := node.(type) {
+	case *ast.ExpressionStatement:
+		return c.evalStmt(x.Expr)
+
+	case *ast.AssignmentStatement:
+		return c.assignmentStatement(x)
+
+	case *ast.MutationStatement:
+		return c.mutationStatement(x)
+
+	case *ast.BlockStatement:
+		return c.blockStatement(x)
+
+	case *ast.IfStatement:
+		return c.ifStatement(x)
+
+	case *ast.WhileStatement:
+		cond := c.condition(x.Cond)
+		body := c.loopBody(&x.Body)
+		if cond == nil || body == nil {
+			return nil
+		}
+		return &loopStmt{cond: cond, body: body}
+
+	case *ast.ForStatement:
+		return c.forStatement(x)
+
+	case *ast.BranchStatement:
+		return c.branchStatement(x)
+
+	case *ast.ReturnStatement:
+		return c.returnStatement(x)
+
+	case *ast.VarDeclStatement:
+		return c.varDeclStatement(x)
+
+	case *ast.ShebangLineStatement, *ast.EmptyStatement, *ast.ErrorStatement:
+		return nil
+
+	case *ast.ImportStatement, *ast.AliasStatement, *ast.TypeDeclStatement, *ast.FuncDeclStatement, *ast.MethodDeclStatement:
+		if c.scope != nil {
+			c.errorf(node, "declarations inside a block are not supported yet")
+			return nil
+		}
+		c.statement(node)
+		return nil
+
+	default:
+		name := strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast.")
+		c.errorf(node, "%s is not supported yet", name)
+		return nil
+	}
+}
+
+// clause lowers the first or last clause of a for statement, which is an
+// assignment, a mutation or an expression.
+func (c *checker) clause(node ast.Node) step {
+	switch node.(type) {
+	case *ast.AssignmentStatement, *ast.MutationStatement:
+		return c.code(node)
+	default:
+		return c.evalStmt(node)
+	}
+}
+
+func (c *checker) evalStmt(node ast.Node) step {
+	expr := c.expr(node)
+	if expr == nil {
+		return nil
+	}
+	return &evalStmt{expr: expr, out: c.interp.NewTemporaryValue(expr.Type())}
+}
+
+// assignmentStatement lowers "x = y", "x := y" and compound assignments such
+// as "x += y".  The target must be a variable.
+func (c *checker) assignmentStatement(stmt *ast.AssignmentStatement) step {
+	if stmt.Op == operator.DeclareAndAssign {
+		ident, ok := stmt.Target.(*ast.IdentifierExpr)
+		if !ok {
+			c.errorf(stmt.Target, "%q cannot be declared", stmt.Target.String())
+			return nil
+		}
+		return c.declareVariable(stmt, ast.DeclVar, ident.Name, nil, stmt.Value)
+	}
+
+	target := c.expr(stmt.Target)
+	if target == nil {
+		return nil
+	}
+	value := c.exprAs(stmt.Value, target.Type())
+	if value == nil {
+		return nil
+	}
+
+	tc := c.interp.TypeChecker()
+	if stmt.Op != operator.Assign {
+		op, found := compoundOperators[stmt.Op]
+		if !found {
+			c.errorf(stmt, "operator %s is not supported yet", stmt.Op.Facts().SimpleName)
+			return nil
+		}
+		if value = c.check(stmt, func() (exprtree.Expr, error) { return tc.Binary(op, target, value) }); value == nil {
+			return nil
+		}
+	}
+
+	expr := c.check(stmt, func() (exprtree.Expr, error) { return tc.Assign(target, value) })
+	if expr == nil {
+		return nil
+	}
+	return &evalStmt{expr: expr, out: c.interp.NewTemporaryValue(expr.Type())}
+}
+
+// mutationStatement lowers "x++", "x--" and "x~~".
+func (c *checker) mutationStatement(stmt *ast.MutationStatement) step {
+	target := c.expr(stmt.Target)
+	if target == nil {
+		return nil
+	}
+
+	tc := c.interp.TypeChecker()
+	var value exprtree.Expr
+	switch stmt.Op {
+	case operator.MutateINC, operator.MutateDEC:
+		op := operator.Add
+		if stmt.Op == operator.MutateDEC {
+			op = operator.Sub
+		}
+		one := c.constant(stmt, exprtree.Unqualified(target.Type()), 1)
+		if one == nil {
+			return nil
+		}
+		value = c.check(stmt, func() (exprtree.Expr, error) { return tc.Binary(op, target, one) })
+
+	default:
+		value = c.check(stmt, func() (exprtree.Expr, error) { return tc.Unary(operator.BitwiseNOT, target) })
+	}
+	if value == nil {
+		return nil
+	}
+
+	expr := c.check(stmt, func() (exprtree.Expr, error) { return tc.Assign(target, value) })
+	if expr == nil {
+		return nil
+	}
+	return &evalStmt{expr: expr, out: c.interp.NewTemporaryValue(expr.Type())}
+}
+
+func (c *checker) blockStatement(block *ast.BlockStatement) step {
+	defer c.enterScope()()
+
+	out := &blockStmt{}
+	ok := true
+	for _, node := range block.Statements {
+		numErrors := len(c.errors)
+		if s := c.code(node); s != nil {
+			out.list = append(out.list, s)
+		} else if len(c.errors) != numErrors {
+			ok = false
+		}
+	}
+	if !ok {
+		return nil
+	}
+	return out
+}
+
+func (c *checker) ifStatement(stmt *ast.IfStatement) step {
+	cond := c.condition(stmt.Cond)
+	then := c.blockStatement(&stmt.Then)
+	var else_ step
+	if stmt.Else != nil {
+		if else_ = c.code(stmt.Else); else_ == nil {
+			return nil
+		}
+	}
+	if cond == nil || then == nil {
+		return nil
+	}
+	return &ifStmt{cond: *cond, then: then, else_: else_}
+}
+
+func (c *checker) forStatement(stmt *ast.ForStatement) step {
+	defer c.enterScope()()
+
+	out := &blockStmt{}
+	loop := &loopStmt{}
+	ok := true
+	if stmt.Pre != nil {
+		pre := c.clause(stmt.Pre)
+		ok = ok && pre != nil
+		out.list = append(out.list, pre)
+	}
+	if stmt.Cond != nil {
+		loop.cond = c.condition(stmt.Cond)
+		ok = ok && loop.cond != nil
+	}
+	if stmt.Post != nil {
+		loop.post = c.clause(stmt.Post)
+		ok = ok && loop.post != nil
+	}
+	loop.body = c.loopBody(&stmt.Body)
+	if !ok || loop.body == nil {
+		return nil
+	}
+	out.list = append(out.list, loop)
+	return out
+}
+
+func (c *checker) loopBody(body *ast.BlockStatement) step {
+	c.loops++
+	defer func() { c.loops-- }()
+	return c.blockStatement(body)
+}
+
+func (c *checker) condition(node ast.Node) *condition {
+	expr := c.expr(node)
+	if expr == nil {
+		return nil
+	}
+	boolType := c.interp.BoolType()
+	expr = c.check(node, func() (exprtree.Expr, error) { return c.interp.TypeChecker().Convert(expr, boolType, "condition") })
+	if expr == nil {
+		return nil
+	}
+	return &condition{expr: expr, out: c.interp.NewTemporaryValue(boolType)}
+}
+
+func (c *checker) branchStatement(stmt *ast.BranchStatement) step {
+	if stmt.Kind == ast.BranchGoto || stmt.Label != "" {
+		c.errorf(stmt, "labels and goto are not supported yet")
+		return nil
+	}
+	if c.loops == 0 {
+		c.errorf(stmt, "%s is not inside a loop", stmt.Kind)
+		return nil
+	}
+	if stmt.Kind == ast.BranchBreak {
+		return &branchStmt{flow: flowBreak}
+	}
+	return &branchStmt{flow: flowContinue}
+}
+
+// returnStatement lowers a return from the function being lowered, whose
+// value must convert to the function's return type, or from the program,
+// whose value may have any type.
+func (c *checker) returnStatement(stmt *ast.ReturnStatement) step {
+	t := c.interp.VoidType()
+	if c.fn != nil {
+		t = c.fn.sym.Function().Signature().Return()
+	}
+
+	var expr exprtree.Expr
+	if stmt.Value != nil {
+		var expected *exprtree.Type
+		if c.fn != nil {
+			expected = t
+		}
+		if expr = c.exprAs(stmt.Value, expected); expr == nil {
+			return nil
+		}
+		if c.fn == nil {
+			t = exprtree.Unqualified(expr.Type())
+		} else {
+			tc := c.interp.TypeChecker()
+			if expr = c.check(stmt.Value, func() (exprtree.Expr, error) { return tc.Convert(expr, t, "return value") }); expr == nil {
+				return nil
+			}
+		}
+	} else if !t.Is(c.interp.VoidType()) {
+		c.errorf(stmt, "missing return value: expected %s", t.CanonicalName())
+		return nil
+	}
+
+	return &returnStmt{expr: expr, out: c.interp.NewTemporaryValue(t), slot: c.slot}
+}
+
+// declareVariable declares a variable with the given type, or with the type
+// of its initializer if t is nil, and returns the statement that sets its
+// initial value.  Inside a block, the variable is local to the block.
+func (c *checker) declareVariable(node ast.Node, kind ast.DeclKind, name string, t *exprtree.Type, init ast.Node) step {
+	var value exprtree.Expr
+	if init != nil {
+		if value = c.exprAs(init, t); value == nil {
+			return nil
+		}
+		if t == nil {
+			t = exprtree.Unqualified(value.Type())
+		} else {
+			tc := c.interp.TypeChecker()
+			if value = c.check(init, func() (exprtree.Expr, error) { return tc.Convert(value, t, "initializer") }); value == nil {
+				return nil
+			}
+		}
+	}
+
+	if t == nil {
+		c.errorf(node, "%s %s needs a type or an initializer", kind, name)
+		return nil
+	}
+	if kind == ast.DeclVar {
+		var err error
+		if t, err = c.interp.MutableType(t); err != nil {
+			c.errorf(node, "%v", err)
+			return nil
+		}
+	}
+
+	var target exprtree.Value
+	if c.scope != nil {
+		if _, found := c.scope.values[name]; found {
+			c.errorf(node, "%q is already declared in this block", name)
+			return nil
+		}
+		target = c.interp.NewStaticValue(c.mod.Symbols().NewGenSym(t))
+		c.scope.values[name] = target
+		if c.fn != nil {
+			c.fn.locals = append(c.fn.locals, target)
+		}
+	} else {
+		if !c.checkUnique(node, name) {
+			return nil
+		}
+		data := exprtree.SymbolData{
+			Kind: exprtree.SimpleSymbol,
+			Name: name,
+			Type: t,
+		}
+		sym, err := c.mod.Symbols().NewSymbol(data)
+		if err != nil {
+			c.errorf(node, "%v", err)
+			return nil
+		}
+		target = c.interp.NewStaticValue(sym)
+		sym.SetCompileTimeValue(target)
+	}
+	return &initStmt{target: target, value: value}
+}
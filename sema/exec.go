@@ -0,0 +1,269 @@
+package sema
+
+import (
+	"fmt"
+
+	"github.com/chronos-tachyon/go-spiderscript/exprtree"
+)
+
+// flow says where execution goes after a statement.
+type flow uint8
+
+const (
+	flowNext flow = iota
+	flowBreak
+	flowContinue
+	flowReturn
+)
+
+// step is a lowered statement.
+type step interface {
+	exec() (flow, error)
+}
+
+// returnSlot receives the value of whichever return statement ran last.
+type returnSlot struct {
+	value exprtree.Value
+}
+
+// Program
+// {{{
+
+// Program is the code that a file runs when it is loaded: the initializers
+// of its variables and its other top-level statements, in order.
+type Program struct {
+	list []step
+	slot returnSlot
+}
+
+// Run runs the program.  The value of each top-level expression statement
+// is passed to show, unless show is nil.  If a top-level return statement
+// runs, the program stops there and Run returns its value and true; the
+// value is only valid until the program runs again.
+func (prog *Program) Run(show func(exprtree.Value)) (exprtree.Value, bool, error) {
+	for _, s := range prog.list {
+		f, err := s.exec()
+		if err != nil {
+			return exprtree.Value{}, false, err
+		}
+		if f == flowReturn {
+			return prog.slot.value, true, nil
+		}
+		if x, ok := s.(*evalStmt); ok && show != nil {
+			show(x.out)
+		}
+	}
+	return exprtree.Value{}, false, nil
+}
+
+// }}}
+
+// evalStmt evaluates an expression into out.
+type evalStmt struct {
+	expr exprtree.Expr
+	out  exprtree.Value
+}
+
+func (s *evalStmt) exec() (flow, error) {
+	return flowNext, s.expr.EvalInto(&s.out)
+}
+
+// initStmt sets a variable to its initial value, or to zero if value is nil.
+type initStmt struct {
+	target exprtree.Value
+	value  exprtree.Expr
+}
+
+func (s *initStmt) exec() (flow, error) {
+	if s.value == nil {
+		s.target.Zero()
+		return flowNext, nil
+	}
+	return flowNext, s.value.EvalInto(&s.target)
+}
+
+// blockStmt runs its statements in order, stopping at the first one that
+// breaks, continues or returns.
+type blockStmt struct {
+	list []step
+}
+
+func (s *blockStmt) exec() (flow, error) {
+	for _, x := range s.list {
+		if f, err := x.exec(); f != flowNext || err != nil {
+			return f, err
+		}
+	}
+	return flowNext, nil
+}
+
+// condition is a Bool expression together with a place to evaluate it.
+type condition struct {
+	expr exprtree.Expr
+	out  exprtree.Value
+}
+
+func (cond *condition) test() (bool, error) {
+	if err := cond.expr.EvalInto(&cond.out); err != nil {
+		return false, err
+	}
+	item, _ := cond.out.Get().(*exprtree.EnumItem)
+	return item != nil && item.Number() != 0, nil
+}
+
+// ifStmt runs then if the condition holds, or else_, which may be nil.
+type ifStmt struct {
+	cond  condition
+	then  step
+	else_ step
+}
+
+func (s *ifStmt) exec() (flow, error) {
+	ok, err := s.cond.test()
+	switch {
+	case err != nil:
+		return flowNext, err
+	case ok:
+		return s.then.exec()
+	case s.else_ != nil:
+		return s.else_.exec()
+	default:
+		return flowNext, nil
+	}
+}
+
+// loopStmt runs body, then post, for as long as the condition holds.  A nil
+// condition always holds, and post may be nil.
+type loopStmt struct {
+	cond *condition
+	body step
+	post step
+}
+
+func (s *loopStmt) exec() (flow, error) {
+	for {
+		if s.cond != nil {
+			ok, err := s.cond.test()
+			if err != nil || !ok {
+				return flowNext, err
+			}
+		}
+
+		f, err := s.body.exec()
+		if err != nil || f == flowReturn {
+			return f, err
+		}
+		if f == flowBreak {
+			return flowNext, nil
+		}
+
+		if s.post != nil {
+			if _, err := s.post.exec(); err != nil {
+				return flowNext, err
+			}
+		}
+	}
+}
+
+// branchStmt breaks out of or continues the innermost loop.
+type branchStmt struct {
+	flow flow
+}
+
+func (s *branchStmt) exec() (flow, error) {
+	return s.flow, nil
+}
+
+// returnStmt stores its value, if any, in slot and returns.
+type returnStmt struct {
+	expr exprtree.Expr
+	out  exprtree.Value
+	slot *returnSlot
+}
+
+func (s *returnStmt) exec() (flow, error) {
+	if s.expr != nil {
+		if err := s.expr.EvalInto(&s.out); err != nil {
+			return flowNext, err
+		}
+	}
+	s.slot.value = s.out
+	return flowReturn, nil
+}
+
+// function
+// {{{
+
+// function is the body of a function declared in SpiderScript.  Its
+// parameters and local variables have storage of their own, which a
+// recursive call saves and restores around itself.
+type function struct {
+	sym    *exprtree.Symbol
+	params []exprtree.Value
+	locals []exprtree.Value
+	body   step
+	slot   returnSlot
+	depth  uint
+}
+
+// call runs the function.  The arguments are in the order of NativeFunc.
+func (fn *function) call(out *exprtree.Value, args []exprtree.Value) error {
+	if fn.body == nil {
+		return fmt.Errorf("%s has no body", fn.sym.CanonicalName())
+	}
+
+	// The outer call's locals are restored before its result is stored,
+	// since out may be one of them.
+	var saved [][]byte
+	if fn.depth != 0 {
+		saved = fn.save()
+	}
+	fn.depth++
+	f, err := fn.run(args)
+	fn.depth--
+	if saved != nil {
+		fn.restore(saved)
+	}
+
+	if err != nil {
+		return err
+	}
+	if f == flowReturn {
+		return out.CopyFrom(fn.slot.value)
+	}
+	if !out.Type().Is(out.Interp().VoidType()) {
+		return fmt.Errorf("%s finished without returning a value", fn.sym.CanonicalName())
+	}
+	return nil
+}
+
+func (fn *function) run(args []exprtree.Value) (flow, error) {
+	for index, arg := range args {
+		if err := fn.params[index].CopyFrom(arg); err != nil {
+			return flowNext, err
+		}
+	}
+	return fn.body.exec()
+}
+
+func (fn *function) save() [][]byte {
+	saved := make([][]byte, len(fn.locals))
+	for index, value := range fn.locals {
+		_ = value.WithReadLock(func(bytes []byte) error {
+			saved[index] = append([]byte(nil), bytes...)
+			return nil
+		})
+	}
+	return saved
+}
+
+func (fn *function) restore(saved [][]byte) {
+	for index, value := range fn.locals {
+		_ = value.WithWriteLock(func(bytes []byte) error {
+			copy(bytes, saved[index])
+			return nil
+		})
+	}
+}
+
+// }}}
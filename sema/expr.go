@@ -0,0 +1,333 @@
+package sema
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/chronos-tachyon/go-spiderscript/ast"
+	"github.com/chronos-tachyon/go-spiderscript/exprtree"
+	"github.com/chronos-tachyon/go-spiderscript/operator"
+)
+
+// unaryOperators maps the unary operators that exprtree can evaluate to
+// their exprtree equivalents.
+var unaryOperators = map[ast.UnaryOperator]operator.Operator{
+	ast.OpPos:        operator.UnaryPos,
+	ast.OpNeg:        operator.UnaryNeg,
+	ast.OpBitwiseNOT: operator.BitwiseNOT,
+	ast.OpLogicalNOT: operator.LogicalNOT,
+}
+
+// binaryOperators maps the binary operators that exprtree can evaluate to
+// their exprtree equivalents.
+var binaryOperators = map[ast.BinaryOperator]operator.Operator{
+	ast.OpMul:            operator.Mul,
+	ast.OpDiv:            operator.Div,
+	ast.OpMod:            operator.Mod,
+	ast.OpAdd:            operator.Add,
+	ast.OpSub:            operator.Sub,
+	ast.OpBitwiseLShift:  operator.LShift,
+	ast.OpBitwiseRShift:  operator.RShift,
+	ast.OpBitwiseLRotate: operator.LRotate,
+	ast.OpBitwiseRRotate: operator.RRotate,
+	ast.OpBitwiseAND:     operator.BitwiseAND,
+	ast.OpBitwiseXOR:     operator.BitwiseXOR,
+	ast.OpBitwiseOR:      operator.BitwiseOR,
+	ast.OpCmpCMP:         operator.CmpCMP,
+	ast.OpCmpEQ:          operator.CmpEQ,
+	ast.OpCmpNE:          operator.CmpNE,
+	ast.OpCmpLT:          operator.CmpLT,
+	ast.OpCmpLE:          operator.CmpLE,
+	ast.OpCmpGT:          operator.CmpGT,
+	ast.OpCmpGE:          operator.CmpGE,
+	ast.OpLogicalAND:     operator.LogicalAND,
+	ast.OpLogicalXOR:     operator.LogicalXOR,
+	ast.OpLogicalOR:      operator.LogicalOR,
+}
+
+// expr lowers an expression, or returns nil after reporting an error.
+// Integer literals are SInt64, or UInt64 if they are too large for SInt64,
+// and other number literals are Float64.
+func (c *checker) expr(node ast.Node) exprtree.Expr {
+	tc := c.interp.TypeChecker()
+	switch x := node.(type) {
+	case *ast.ParenExpr:
+		return c.expr(x.Inner)
+
+	case *ast.LiteralNumber:
+		return c.numberLiteral(x, nil)
+
+	case *ast.LiteralString:
+		if len(x.Value.Formats) != 0 {
+			c.errorf(x, "string interpolation is not supported yet")
+			return nil
+		}
+		buf := c.interp.NewBuffer()
+		buf.AppendString(strings.Join(x.Value.Segments, ""))
+		str := &exprtree.String{Buffer: buf, Offset: 0, Length: buf.Len()}
+		return c.constant(x, c.interp.StringType(), str)
+
+	case *ast.IdentifierExpr:
+		return c.identifier(x)
+
+	case *ast.ThisExpr:
+		value, found := c.scope.get("this")
+		if !found {
+			c.errorf(x, "this is only defined inside a method")
+			return nil
+		}
+		return exprtree.NewSymbolExpr(value)
+
+	case *ast.UnaryOperatorExpr:
+		op, found := unaryOperators[x.Operator]
+		if !found {
+			c.errorf(x, "operator %s is not supported yet", x.Operator)
+			return nil
+		}
+		operand := c.expr(x.Operand)
+		if operand == nil {
+			return nil
+		}
+		return c.check(x, func() (exprtree.Expr, error) { return tc.Unary(op, operand) })
+
+	case *ast.BinaryOperatorExpr:
+		op, found := binaryOperators[x.Operator]
+		if !found {
+			c.errorf(x, "operator %s is not supported yet", x.Operator)
+			return nil
+		}
+		left := c.expr(x.Operand0)
+		right := c.expr(x.Operand1)
+		if left == nil || right == nil {
+			return nil
+		}
+		return c.check(x, func() (exprtree.Expr, error) { return tc.Binary(op, left, right) })
+
+	case *ast.TernaryOperatorExpr:
+		cond := c.expr(x.Operand0)
+		then := c.expr(x.Operand1)
+		else_ := c.expr(x.Operand2)
+		if cond == nil || then == nil || else_ == nil {
+			return nil
+		}
+		return c.check(x, func() (exprtree.Expr, error) { return tc.Ternary(cond, then, else_) })
+
+	case *ast.CallExpr:
+		return c.call(x)
+
+	case *ast.FieldExpr:
+		operand := c.expr(x.Operand)
+		if operand == nil {
+			return nil
+		}
+		return c.check(x, func() (exprtree.Expr, error) { return c.interp.NewFieldExpr(operand, x.Field) })
+
+	case *ast.IndexExpr:
+		operand := c.expr(x.Operand)
+		index := c.expr(x.Index)
+		if operand == nil || index == nil {
+			return nil
+		}
+		return c.check(x, func() (exprtree.Expr, error) { return c.interp.NewIndexExpr(operand, index) })
+
+	default:
+		c.errorf(node, "%q is not a supported expression", node.String())
+		return nil
+	}
+}
+
+// check returns the expression that fn builds, or nil after reporting its
+// error at node.
+func (c *checker) check(node ast.Node, fn func() (exprtree.Expr, error)) exprtree.Expr {
+	expr, err := fn()
+	if err != nil {
+		c.errorf(node, "%v", err)
+		return nil
+	}
+	return expr
+}
+
+func (c *checker) constant(node ast.Node, t *exprtree.Type, in interface{}) exprtree.Expr {
+	return c.check(node, func() (exprtree.Expr, error) { return c.interp.NewConstantExpr(t, in) })
+}
+
+// exprAs lowers an expression whose value is expected to have type t, which
+// may be nil.  A number literal, possibly in parentheses, takes type t if t
+// is a number type of the same family; the caller still converts the result
+// to t.
+func (c *checker) exprAs(node ast.Node, t *exprtree.Type) exprtree.Expr {
+	switch x := node.(type) {
+	case *ast.ParenExpr:
+		return c.exprAs(x.Inner, t)
+
+	case *ast.LiteralNumber:
+		return c.numberLiteral(x, t)
+
+	default:
+		return c.expr(node)
+	}
+}
+
+// numberLiteral lowers a number literal.  An integer literal takes the
+// expected type if that is an integer type, and any other literal takes it
+// if it is a float type; it is an error if the value does not fit.
+// Otherwise the literal has its default type, as described for expr.
+func (c *checker) numberLiteral(lit *ast.LiteralNumber, expected *exprtree.Type) exprtree.Expr {
+	var kind exprtree.TypeKind
+	if expected != nil {
+		expected = exprtree.Unqualified(expected)
+		kind = expected.Chase().Kind()
+	}
+
+	num := &lit.Value
+	if num.FractionalDigits != nil || num.ExponentSymbol != 0 {
+		f64, err := strconv.ParseFloat(num.String(), 64)
+		if err != nil {
+			c.errorf(lit, "%v", err)
+			return nil
+		}
+		if max, found := floatLimits[kind]; found {
+			if math.Abs(f64) > max {
+				c.errorf(lit, "%s overflows %s", lit.String(), expected.CanonicalName())
+				return nil
+			}
+			return c.constant(lit, expected, f64)
+		}
+		return c.constant(lit, c.interp.Float64Type(), f64)
+	}
+
+	var value interface{}
+	t := c.interp.SInt64Type()
+	if s64, err := num.AsInt64(); err == nil {
+		value = s64
+	} else if u64, err := num.AsUint64(); err == nil {
+		value = u64
+		t = c.interp.UInt64Type()
+	} else {
+		c.errorf(lit, "%v", err)
+		return nil
+	}
+	if integerKinds[kind] {
+		expr, err := c.interp.NewConstantExpr(expected, value)
+		if err != nil {
+			c.errorf(lit, "%s overflows %s", lit.String(), expected.CanonicalName())
+			return nil
+		}
+		return expr
+	}
+	return c.constant(lit, t, value)
+}
+
+// integerKinds lists the kinds that an integer literal may take.
+var integerKinds = map[exprtree.TypeKind]bool{
+	exprtree.U8Kind:  true,
+	exprtree.U16Kind: true,
+	exprtree.U32Kind: true,
+	exprtree.U64Kind: true,
+	exprtree.S8Kind:  true,
+	exprtree.S16Kind: true,
+	exprtree.S32Kind: true,
+	exprtree.S64Kind: true,
+}
+
+// floatLimits maps the kinds that a float literal may take to their largest
+// finite value.
+var floatLimits = map[exprtree.TypeKind]float64{
+	exprtree.F16Kind: 65504,
+	exprtree.F32Kind: math.MaxFloat32,
+	exprtree.F64Kind: math.MaxFloat64,
+}
+
+// identifier lowers a reference to a variable, which is looked for in the
+// enclosing blocks before the module.  The items of Bool, "true" and
+// "false", may be used unqualified unless something else has their name.
+func (c *checker) identifier(expr *ast.IdentifierExpr) exprtree.Expr {
+	if expr.Module.IsEmpty() {
+		if value, found := c.scope.get(expr.Name); found {
+			return exprtree.NewSymbolExpr(value)
+		}
+	}
+	if expr.Module.IsEmpty() && !c.isDeclared(expr.Name) {
+		boolType := c.interp.BoolType()
+		if item := boolType.Chase().Details().(*exprtree.Enum).ByName(expr.Name); item != nil {
+			return c.constant(expr, boolType, item)
+		}
+	}
+
+	sym := c.lookup(expr, expr.Module.Names, expr.Name)
+	if sym == nil {
+		return nil
+	}
+	value, ok := sym.CompileTimeValue().(exprtree.Value)
+	if !ok {
+		c.errorf(expr, "%s is not a variable", sym.CanonicalName())
+		return nil
+	}
+	return exprtree.NewSymbolExpr(value)
+}
+
+// isVariable reports whether expr names a variable of an enclosing block.
+func (c *checker) isVariable(expr *ast.IdentifierExpr) bool {
+	if !expr.Module.IsEmpty() {
+		return false
+	}
+	_, found := c.scope.get(expr.Name)
+	return found
+}
+
+// call lowers a call to a function, or to a method when the callee is
+// "operand.name", in which case operand is the first argument.
+func (c *checker) call(expr *ast.CallExpr) exprtree.Expr {
+	var module []string
+	var name string
+	var args []exprtree.Expr
+	switch callee := expr.Callee.(type) {
+	case *ast.IdentifierExpr:
+		module = callee.Module.Names
+		name = callee.Name
+
+	case *ast.FieldExpr:
+		this := c.expr(callee.Operand)
+		if this == nil {
+			return nil
+		}
+		name = callee.Field
+		args = append(args, this)
+
+	default:
+		c.errorf(expr.Callee, "%q is not a function name", expr.Callee.String())
+		return nil
+	}
+
+	candidates := c.functions(module, name)
+	if len(candidates) == 0 {
+		if ident, ok := expr.Callee.(*ast.IdentifierExpr); ok && !c.isVariable(ident) {
+			// lookup reports a name that is not declared at all.
+			if c.lookup(ident, module, name) == nil {
+				return nil
+			}
+		}
+		c.errorf(expr.Callee, "%q is not a function", expr.Callee.String())
+		return nil
+	}
+
+	ok := true
+	for _, arg := range expr.Args.Positional() {
+		x := c.expr(arg)
+		ok = ok && x != nil
+		args = append(args, x)
+	}
+	named := make(map[string]exprtree.Expr)
+	for _, arg := range expr.Args.Named() {
+		x := c.expr(arg.Value)
+		ok = ok && x != nil
+		named[arg.Name] = x
+	}
+	if !ok {
+		return nil
+	}
+
+	tc := c.interp.TypeChecker()
+	return c.check(expr, func() (exprtree.Expr, error) { return tc.CallOverloaded(candidates, args, named) })
+}
@@ -0,0 +1,584 @@
+// Package sema lowers a parsed ast.File into an exprtree.Module.  It
+// registers the file's imports, declares its aliases, types, variables,
+// functions and methods as symbols, and builds the exprtree types that its
+// struct, enum, bitfield and union bodies describe.  A type declared with
+// generic parameters becomes a generic type, and "Name#[args]" instantiates
+// it.
+//
+// Declarations are processed in order, so a name must be declared before it
+// is used.  A variable without a type takes the type of its initializer.
+// Functions may be overloaded: each overload is a function symbol of its
+// own, and a call picks among all the functions with the called name.
+// A method is a function whose first parameter, "this", is the receiver.
+package sema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chronos-tachyon/go-spiderscript/ast"
+	"github.com/chronos-tachyon/go-spiderscript/exprtree"
+	"github.com/chronos-tachyon/go-spiderscript/token"
+)
+
+// Error reports a declaration that parsed but does not make sense, such as a
+// reference to a type that was never declared.
+type Error struct {
+	Pos     token.Position
+	Message string
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("%v: %s", err.Pos, err.Message)
+}
+
+var _ error = (*Error)(nil)
+
+// Compile creates a module named cname in interp and declares the contents
+// of file in it.  It returns nil errors on success.  If the module could not
+// be created, it returns a nil module.
+func Compile(interp *exprtree.Interp, cname string, file *ast.File) (*exprtree.Module, []error) {
+	mod, err := interp.NewModule(cname)
+	if err != nil {
+		return nil, []error{&Error{Pos: file.Span().Start, Message: err.Error()}}
+	}
+	return mod, Declare(mod, file)
+}
+
+// Declare declares the contents of file in mod, which may already hold
+// declarations from earlier files.  It returns nil errors on success.  The
+// declarations that were valid are kept even if others were not.  The
+// file's variables are left zeroed, as its Program is not run; see Lower.
+func Declare(mod *exprtree.Module, file *ast.File) []error {
+	_, errs := Lower(mod, file)
+	return errs
+}
+
+// Lower declares the contents of file in mod, as Declare does, and returns
+// the Program that runs the file's top-level statements.  The Program is nil
+// if there were errors.
+func Lower(mod *exprtree.Module, file *ast.File) (*Program, []error) {
+	c := &checker{
+		interp:   mod.Interp(),
+		mod:      mod,
+		funcs:    map[*exprtree.Module]map[string][]*exprtree.Function{mod: indexFunctions(mod)},
+		generics: map[*exprtree.Module]map[string]*exprtree.Symbol{mod: indexGenerics(mod)},
+	}
+	prog := &Program{}
+	c.slot = &prog.slot
+	for _, node := range file.Statements {
+		if s := c.code(node); s != nil {
+			prog.list = append(prog.list, s)
+		}
+	}
+	if len(c.errors) != 0 {
+		return nil, c.errors
+	}
+	return prog, nil
+}
+
+// checker holds the state of a lowering pass.  While a block is lowered,
+// scope holds its local variables; while a function body is lowered, fn is
+// the function.  The return statements that are lowered store their values
+// in slot.  While the body of a generic type is lowered, typeParams holds
+// the placeholders of its type parameters.  funcs indexes the functions of
+// mod, and of each module that a call has looked in, by human name; generics
+// does the same for unbound generic types.
+type checker struct {
+	interp     *exprtree.Interp
+	mod        *exprtree.Module
+	funcs      map[*exprtree.Module]map[string][]*exprtree.Function
+	generics   map[*exprtree.Module]map[string]*exprtree.Symbol
+	errors     []error
+	scope      *scope
+	fn         *function
+	slot       *returnSlot
+	loops      uint
+	typeParams map[string]*exprtree.Type
+}
+
+func (c *checker) errorf(node ast.Node, format string, args ...interface{}) {
+	c.errors = append(c.errors, &Error{
+		Pos:     node.Span().Start,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *checker) statement(node ast.Node) {
+	switch stmt := node.(type) {
+	case *ast.ImportStatement:
+		c.importStatement(stmt)
+
+	case *ast.AliasStatement:
+		c.aliasStatement(stmt)
+
+	case *ast.TypeDeclStatement:
+		c.typeDeclStatement(stmt)
+
+	case *ast.FuncDeclStatement:
+		c.funcDeclStatement(stmt)
+
+	case *ast.MethodDeclStatement:
+		c.methodDeclStatement(stmt)
+	}
+}
+
+func (c *checker) importStatement(stmt *ast.ImportStatement) {
+	name := strings.Join(stmt.ModuleName.Names, "::")
+	imported, found := c.interp.ModuleByName(name)
+	if !found {
+		c.errorf(&stmt.ModuleName, "module %q is not loaded", name)
+		return
+	}
+	if err := c.mod.AddImport(name, imported); err != nil {
+		c.errorf(&stmt.ModuleName, "%v", err)
+	}
+}
+
+func (c *checker) aliasStatement(stmt *ast.AliasStatement) {
+	target := &stmt.Target
+	if target.TypeSig != nil || target.FuncSig != nil {
+		c.errorf(target, "aliases of generic or overloaded symbols are not supported")
+		return
+	}
+
+	var module []string
+	if target.Module != nil {
+		module = target.Module.Names
+	}
+	sym := c.lookup(target, module, target.Identifier.Name)
+	if sym == nil {
+		return
+	}
+	if !c.checkUnique(stmt, stmt.Name) {
+		return
+	}
+	if err := c.mod.Symbols().Put(stmt.Name, sym); err != nil {
+		c.errorf(stmt, "%v", err)
+	}
+}
+
+func (c *checker) typeDeclStatement(stmt *ast.TypeDeclStatement) {
+	if stmt.IsAssertion {
+		sym, found := c.symbolIn(c.mod, stmt.Name)
+		if !found {
+			c.errorf(stmt, "%q is not declared", stmt.Name)
+			return
+		}
+		have := c.symbolType(stmt, sym)
+		want := c.typeExpr(stmt.Target)
+		if have != nil && want != nil && !have.Is(want) {
+			c.errorf(stmt.Target, "%s is %s, not %s", stmt.Name, have.Chase().CanonicalName(), want.CanonicalName())
+		}
+		return
+	}
+	if stmt.Generics != nil {
+		c.genericTypeDeclStatement(stmt)
+		return
+	}
+
+	t := c.typeExpr(stmt.Target)
+	if t == nil || !c.checkUnique(stmt, stmt.Name) {
+		return
+	}
+	data := exprtree.SymbolData{
+		Kind: exprtree.SimpleSymbol,
+		Name: stmt.Name,
+	}
+	if _, err := c.interp.NamedType(c.mod.Symbols(), data, t); err != nil {
+		c.errorf(stmt, "%v", err)
+	}
+}
+
+// genericTypeDeclStatement declares a generic struct, union, enum or
+// bitfield.  A parameter is a type, as in "T: type", an unsigned integer, as
+// in "N: UInt64", or an item of an enum.  The body is lowered once, with a
+// placeholder for each type parameter.
+func (c *checker) genericTypeDeclStatement(stmt *ast.TypeDeclStatement) {
+	body, ok := stmt.Target.(*ast.TypeBodyExpr)
+	if !ok || bodyKinds[body.Kind] == exprtree.InvalidTypeKind {
+		c.errorf(stmt.Target, "only struct, union, enum and bitfield types can be generic")
+		return
+	}
+	if !c.checkUnique(stmt, stmt.Name) {
+		return
+	}
+
+	builder := c.interp.GenericSignatureBuilder()
+	names := make([]string, 0, len(stmt.Generics.Params))
+	seen := make(map[string]bool, len(stmt.Generics.Params))
+	for _, node := range stmt.Generics.Params {
+		param := node.(*ast.GenericParameter)
+		if seen[param.Name] {
+			c.errorf(param, "generic parameter %s is repeated", param.Name)
+			ok = false
+		}
+		seen[param.Name] = true
+		names = append(names, param.Name)
+
+		if param.IsType {
+			builder.WithType()
+			continue
+		}
+		if param.Constraint == nil {
+			c.errorf(param, "generic parameter %s needs a type", param.Name)
+			ok = false
+			continue
+		}
+		t := c.typeExpr(param.Constraint)
+		if t == nil {
+			ok = false
+			continue
+		}
+		switch kind := t.Chase().Kind(); {
+		case kind == exprtree.EnumKind:
+			builder.WithEnum(t)
+		case integerBits[kind] != 0 && !isSigned(kind):
+			builder.WithUInt()
+		default:
+			c.errorf(param.Constraint, "generic parameter %s cannot be %s: only types, unsigned integers and enum items are supported", param.Name, t.CanonicalName())
+			ok = false
+		}
+	}
+	if !ok {
+		return
+	}
+
+	data := exprtree.SymbolData{
+		Kind: exprtree.UnboundGenericTypeSymbol,
+		Name: stmt.Name,
+		Generic: exprtree.GenericSymbolData{
+			Signature:  builder.Build(),
+			ParamNames: names,
+		},
+	}
+	g, err := c.interp.NewGenericType(c.mod.Symbols(), data, bodyKinds[body.Kind], func(params []*exprtree.Type) exprtree.Statements {
+		outer := c.typeParams
+		defer func() { c.typeParams = outer }()
+
+		c.typeParams = make(map[string]*exprtree.Type, len(params))
+		for index, t := range params {
+			if t != nil {
+				c.typeParams[names[index]] = t
+			}
+		}
+		list, _ := c.typeBody(body)
+		return list
+	})
+	if err != nil {
+		c.errorf(stmt, "%v", err)
+		return
+	}
+	c.generics[c.mod][stmt.Name] = g.Symbol()
+}
+
+// varDeclStatement declares a variable, with storage of its own.  A "var"
+// is mutable; a "let" or a "const" is not.
+func (c *checker) varDeclStatement(stmt *ast.VarDeclStatement) step {
+	var t *exprtree.Type
+	if stmt.Type != nil {
+		if t = c.typeExpr(stmt.Type); t == nil {
+			return nil
+		}
+	}
+	return c.declareVariable(stmt, stmt.Kind, stmt.Name, t, stmt.Value)
+}
+
+func (c *checker) funcDeclStatement(stmt *ast.FuncDeclStatement) {
+	if stmt.IsOperator || stmt.Generics != nil {
+		c.errorf(stmt, "generic and operator functions are not supported yet")
+		return
+	}
+	builder := c.interp.FunctionSignatureBuilder()
+	c.declareFunction(stmt, stmt.Name, builder, nil, &stmt.Params, stmt.Return, stmt.Body)
+}
+
+func (c *checker) methodDeclStatement(stmt *ast.MethodDeclStatement) {
+	if stmt.IsOperator || stmt.Generics != nil {
+		c.errorf(stmt, "generic and operator methods are not supported yet")
+		return
+	}
+	sym := c.lookup(stmt, nil, stmt.Receiver)
+	if sym == nil {
+		return
+	}
+	receiver := c.symbolType(stmt, sym)
+	if receiver == nil {
+		return
+	}
+	builder := c.interp.FunctionSignatureBuilder().WithPositionalArg(receiver)
+	c.declareFunction(stmt, stmt.Name, builder, []string{"this"}, &stmt.Params, stmt.Return, stmt.Body)
+}
+
+// declareFunction adds the parameters and the return type of a function to
+// builder, which may already hold the receiver of a method, declares the
+// function and lowers its body.  The function is declared before its body is
+// lowered, so that it may call itself.
+func (c *checker) declareFunction(stmt ast.Node, name string, builder *exprtree.FunctionSignatureBuilder, posNames []string, params *ast.FuncParameterList, ret ast.Node, body *ast.BlockStatement) {
+	if sym, found := c.symbolIn(c.mod, name); found {
+		c.errorf(stmt, "%q is already declared as %s", name, sym.CanonicalName())
+		return
+	}
+
+	ok := true
+	for index, node := range params.Params {
+		param := node.(*ast.Parameter)
+		if param.Type == nil {
+			c.errorf(param, "parameter %s needs a type", param.Name)
+			ok = false
+			continue
+		}
+		typeNode := param.Type
+		if param.IsRepeated() {
+			typeNode = param.Type.(*ast.SplatExpr).Operand
+		}
+		t := c.typeExpr(typeNode)
+		if t == nil {
+			ok = false
+			continue
+		}
+
+		switch {
+		case uint(index) >= params.NumPositional && param.IsRepeated():
+			c.errorf(param, "named parameter %s cannot be repeated", param.Name)
+			ok = false
+		case uint(index) >= params.NumPositional:
+			builder.WithNamedArg(param.Name, t)
+		case param.IsRepeated() && uint(index) != params.NumPositional-1:
+			c.errorf(param, "only the last positional parameter can be repeated")
+			ok = false
+		case param.IsRepeated() && body != nil:
+			c.errorf(param, "repeated parameters are only supported without a body")
+			ok = false
+		case param.IsRepeated():
+			builder.WithRepeatedPositionalArg(t)
+			posNames = append(posNames, param.Name)
+		default:
+			builder.WithPositionalArg(t)
+			posNames = append(posNames, param.Name)
+		}
+	}
+	if ret != nil {
+		if t := c.typeExpr(ret); t != nil {
+			builder.WithReturn(t)
+		} else {
+			ok = false
+		}
+	}
+	if !ok {
+		return
+	}
+
+	sym, err := c.mod.Symbols().NewSymbol(exprtree.SymbolData{
+		Kind: exprtree.SimpleFunctionSymbol,
+		Name: name,
+		Type: c.interp.VoidType(),
+		Function: exprtree.FunctionSymbolData{
+			Signature:       builder.Build(),
+			PositionalNames: posNames,
+		},
+	})
+	if err != nil {
+		c.errorf(stmt, "%v", err)
+		return
+	}
+	f := &function{sym: sym}
+	fn, err := c.interp.NewNativeFunction(sym, f.call)
+	if err != nil {
+		c.errorf(stmt, "%v", err)
+		return
+	}
+	sym.SetCompileTimeValue(fn)
+	hname := sym.HumanName()
+	c.funcs[c.mod][hname] = sortFunctions(append(c.funcs[c.mod][hname], fn))
+
+	if body != nil {
+		c.functionBody(f, posNames, body)
+	}
+}
+
+// functionBody lowers the body of f.  The parameters are variables in a
+// scope around the body, in the order in which NativeFunc passes them.
+func (c *checker) functionBody(f *function, posNames []string, body *ast.BlockStatement) {
+	outer := *c
+	defer func() {
+		outer.errors = c.errors
+		*c = outer
+	}()
+	c.scope = nil
+	c.fn = f
+	c.slot = &f.slot
+	c.loops = 0
+	defer c.enterScope()()
+
+	sig := f.sym.Function().Signature()
+	args := sig.PositionalArgs()
+	names := append([]string(nil), posNames...)
+	for _, name := range sig.ArgNames() {
+		args = append(args, sig.NamedArg(name))
+		names = append(names, name)
+	}
+	for index, arg := range args {
+		value := c.interp.NewStaticValue(c.mod.Symbols().NewGenSym(arg.Type()))
+		c.scope.values[names[index]] = value
+		f.params = append(f.params, value)
+		f.locals = append(f.locals, value)
+	}
+
+	f.body = c.blockStatement(body)
+}
+
+// checkUnique reports an error if name is already declared in the module,
+// whether as a function or as anything else.
+func (c *checker) checkUnique(node ast.Node, name string) bool {
+	if c.isDeclared(name) {
+		c.errorf(node, "%q is already declared", name)
+		return false
+	}
+	return true
+}
+
+// isDeclared reports whether name is declared in the module.
+func (c *checker) isDeclared(name string) bool {
+	if _, found := c.symbolIn(c.mod, name); found {
+		return true
+	}
+	return len(c.functionsIn(c.mod, name)) != 0
+}
+
+// functions finds the overloads of the function that name refers to.  An
+// unqualified name is looked for in the module, then in the builtin module;
+// a qualified name is looked for in the module it was imported as.
+func (c *checker) functions(module []string, name string) []*exprtree.Function {
+	if len(module) != 0 {
+		imported := c.mod.Import(strings.Join(module, "::"))
+		if imported == nil {
+			return nil
+		}
+		return c.functionsIn(imported, name)
+	}
+	if list := c.functionsIn(c.mod, name); len(list) != 0 {
+		return list
+	}
+	return c.functionsIn(c.interp.BuiltinModule(), name)
+}
+
+// functionsIn returns the functions in mod whose human name is name, in
+// order of mangled name.  Each overload is stored under its own mangled
+// local name, so the functions of a module are indexed by human name the
+// first time that it is looked in.
+func (c *checker) functionsIn(mod *exprtree.Module, name string) []*exprtree.Function {
+	index, found := c.funcs[mod]
+	if !found {
+		index = indexFunctions(mod)
+		c.funcs[mod] = index
+	}
+	return index[name]
+}
+
+// indexFunctions groups the functions in mod by human name.
+func indexFunctions(mod *exprtree.Module) map[string][]*exprtree.Function {
+	all := make(map[string]*exprtree.Symbol)
+	mod.Symbols().All(all)
+
+	index := make(map[string][]*exprtree.Function)
+	for _, sym := range all {
+		if sym.Function() == nil {
+			continue
+		}
+		if fn, ok := sym.CompileTimeValue().(*exprtree.Function); ok {
+			index[sym.HumanName()] = append(index[sym.HumanName()], fn)
+		}
+	}
+	for _, list := range index {
+		sortFunctions(list)
+	}
+	return index
+}
+
+// sortFunctions sorts list by mangled name, so that overloads are always
+// considered in the same order.
+func sortFunctions(list []*exprtree.Function) []*exprtree.Function {
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].MangledName() < list[j].MangledName()
+	})
+	return list
+}
+
+// symbolIn finds the symbol named name in mod.  Generic types are found by
+// name too, although the symbol table knows them by their mangled names, so
+// the generic types of a module are indexed by human name the first time
+// that it is looked in.
+func (c *checker) symbolIn(mod *exprtree.Module, name string) (*exprtree.Symbol, bool) {
+	if sym, found := mod.Symbols().Get(name); found {
+		return sym, true
+	}
+
+	index, found := c.generics[mod]
+	if !found {
+		index = indexGenerics(mod)
+		c.generics[mod] = index
+	}
+	sym, found := index[name]
+	return sym, found
+}
+
+// indexGenerics maps the human names of the unbound generic types in mod to
+// their symbols.
+func indexGenerics(mod *exprtree.Module) map[string]*exprtree.Symbol {
+	all := make(map[string]*exprtree.Symbol)
+	mod.Symbols().All(all)
+
+	index := make(map[string]*exprtree.Symbol)
+	for _, sym := range all {
+		if sym.Kind() == exprtree.UnboundGenericTypeSymbol {
+			index[sym.HumanName()] = sym
+		}
+	}
+	return index
+}
+
+// lookup finds the symbol that name refers to.  An unqualified name is
+// looked for in the module, then in the builtin module; a qualified name is
+// looked for in the module it was imported as.
+func (c *checker) lookup(node ast.Node, module []string, name string) *exprtree.Symbol {
+	if len(module) != 0 {
+		mname := strings.Join(module, "::")
+		imported := c.mod.Import(mname)
+		if imported == nil {
+			c.errorf(node, "module %q is not imported", mname)
+			return nil
+		}
+		sym, found := c.symbolIn(imported, name)
+		if !found {
+			c.errorf(node, "%s::%s is not declared", mname, name)
+			return nil
+		}
+		return sym
+	}
+
+	if sym, found := c.symbolIn(c.mod, name); found {
+		return sym
+	}
+	if sym, found := c.symbolIn(c.interp.BuiltinModule(), name); found {
+		return sym
+	}
+	c.errorf(node, "%q is not declared", name)
+	return nil
+}
+
+// symbolType returns the type that sym names.
+func (c *checker) symbolType(node ast.Node, sym *exprtree.Symbol) *exprtree.Type {
+	if sym.Type() == c.interp.TypeType() {
+		switch x := sym.CompileTimeValue().(type) {
+		case *exprtree.Type:
+			return x
+		case *exprtree.GenericType:
+			c.errorf(node, "%s is generic, so it needs arguments, as in %s#[...]", sym.CanonicalName(), sym.HumanName())
+			return nil
+		}
+	}
+	c.errorf(node, "%s is not a type", sym.CanonicalName())
+	return nil
+}
@@ -0,0 +1,506 @@
+package sema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/chronos-tachyon/go-spiderscript/ast"
+	"github.com/chronos-tachyon/go-spiderscript/exprtree"
+	"github.com/chronos-tachyon/go-spiderscript/token"
+)
+
+func testParseFile(t *testing.T, input string) *ast.File {
+	t.Helper()
+	p := ast.NewParser(token.NewLexer("test.spider", []rune(input)))
+
+	var file ast.File
+	p.Parse(&file)
+	for _, err := range p.Errors() {
+		t.Fatalf("Parse(%q): unexpected error: %v", input, err)
+	}
+	return &file
+}
+
+func testLookupType(t *testing.T, mod *exprtree.Module, name string) *exprtree.Type {
+	t.Helper()
+	sym, found := mod.Symbols().Get(name)
+	if !found {
+		t.Fatalf("%s: symbol %q not found", mod.CanonicalName(), name)
+	}
+	typ, ok := sym.CompileTimeValue().(*exprtree.Type)
+	if !ok {
+		t.Fatalf("%s: symbol %q is not a type: %#v", mod.CanonicalName(), name, sym)
+	}
+	return typ
+}
+
+func TestCompile(t *testing.T) {
+	interp := exprtree.NewSystemInterp()
+
+	lib := testParseFile(t, "type Handle = struct {\nfd: SInt32\n}\n")
+	if _, errs := Compile(interp, "core::io", lib); errs != nil {
+		t.Fatalf("Compile(core::io): unexpected errors: %v", errs)
+	}
+
+	const input = "" +
+		"import core::io\n" +
+		"alias Handle = core::io::Handle\n" +
+		"type Point = struct {\n#align(+16)\nx: Float64\ny: Float64\nstatic count: UInt64\n}\n" +
+		"type Color = enum: UInt8 {\nRed\nGreen\nBlue = +4\nBLUE = Blue\n}\n" +
+		"type Flags = bitfield: UInt32 {\nRead = +1\nWrite\nExec = +16\n}\n" +
+		"type Value = union {\ntag kind: Color\ncase Red {\nn: SInt64\n}\ncase Blue {\np: Point\n}\n}\n" +
+		"var origin: Point\n"
+
+	mod, errs := Compile(interp, "demo", testParseFile(t, input))
+	if errs != nil {
+		t.Fatalf("Compile(demo): unexpected errors: %v", errs)
+	}
+
+	if mod.Import("core::io") == nil {
+		t.Errorf("demo: core::io is not imported")
+	}
+	if handle := testLookupType(t, mod, "Handle"); handle.Chase().Kind() != exprtree.StructKind {
+		t.Errorf("Handle: expected struct, got %v", handle.Chase().Kind())
+	}
+
+	point := testLookupType(t, mod, "Point")
+	s, ok := point.Chase().Details().(*exprtree.Struct)
+	if !ok {
+		t.Fatalf("Point: expected *Struct, got %T", point.Chase().Details())
+	}
+	if n := len(s.Fields()); n != 2 {
+		t.Errorf("Point: expected 2 fields, got %d", n)
+	}
+	if s.FieldByName("y") == nil {
+		t.Errorf("Point: field y not found")
+	}
+	if shift := s.AlignShift(); shift != 4 {
+		t.Errorf("Point: expected align shift 4, got %d", shift)
+	}
+
+	color := testLookupType(t, mod, "Color")
+	e, ok := color.Chase().Details().(*exprtree.Enum)
+	if !ok {
+		t.Fatalf("Color: expected *Enum, got %T", color.Chase().Details())
+	}
+	for _, row := range []struct {
+		Name   string
+		Number int64
+	}{
+		{"Red", 0},
+		{"Green", 1},
+		{"Blue", 4},
+		{"BLUE", 4},
+	} {
+		item := e.ByName(row.Name)
+		if item == nil {
+			t.Errorf("Color: item %s not found", row.Name)
+		} else if item.Number() != row.Number {
+			t.Errorf("Color: item %s: expected %d, got %d", row.Name, row.Number, item.Number())
+		}
+	}
+
+	flags := testLookupType(t, mod, "Flags")
+	b, ok := flags.Chase().Details().(*exprtree.Bitfield)
+	if !ok {
+		t.Fatalf("Flags: expected *Bitfield, got %T", flags.Chase().Details())
+	}
+	for _, row := range []struct {
+		Name  string
+		Shift uint
+	}{
+		{"Read", 0},
+		{"Write", 1},
+		{"Exec", 4},
+	} {
+		item := b.ByName(row.Name)
+		if item == nil {
+			t.Errorf("Flags: item %s not found", row.Name)
+		} else if item.Shift() != row.Shift {
+			t.Errorf("Flags: item %s: expected shift %d, got %d", row.Name, row.Shift, item.Shift())
+		}
+	}
+
+	value := testLookupType(t, mod, "Value")
+	u, ok := value.Chase().Details().(*exprtree.Union)
+	if !ok {
+		t.Fatalf("Value: expected *Union, got %T", value.Chase().Details())
+	}
+	if !u.TagType().Is(color) {
+		t.Errorf("Value: expected tag type Color, got %s", u.TagType().CanonicalName())
+	}
+	if u.FieldByTagAndName(e.ByName("Blue"), "p") == nil {
+		t.Errorf("Value: field p of case Blue not found")
+	}
+	if n := len(u.FieldsByTag(e.ByName("Green"))); n != 0 {
+		t.Errorf("Value: expected no fields for case Green, got %d", n)
+	}
+
+	origin, found := mod.Symbols().Get("origin")
+	if !found {
+		t.Fatalf("demo: symbol origin not found")
+	}
+	if !origin.Type().Is(point) {
+		t.Errorf("origin: expected type Point, got %s", origin.Type().CanonicalName())
+	}
+}
+
+func TestCompile_Values(t *testing.T) {
+	const input = "" +
+		"type Celsius = Float64\n" +
+		"type Celsius: Float64\n" +
+		"type Point = struct {\nx: Float64\ny: Float64\n}\n" +
+		"func add(a: SInt64, b: SInt64): SInt64 { return a + b }\n" +
+		"func add(a: Float64, b: Float64): Float64 { return a + b }\n" +
+		"func log(parts: String...; level: UInt8)\n" +
+		"method Point.norm(): Float64 { return this.x }\n" +
+		"let one = 1\n" +
+		"var two = one + 1\n" +
+		"let half = 0.5\n" +
+		"let big = 18446744073709551615\n" +
+		"let name = \"spider\"\n" +
+		"let yes = one == 1\n" +
+		"let sum = add(one, 2)\n" +
+		"let fsum = add(half, 1.0)\n" +
+		"var p: Point\n" +
+		"let n = p.norm()\n" +
+		"let px = p.x\n" +
+		"var b: UInt8 = 3\n" +
+		"var c: SInt32 = 3\n" +
+		"var d: Float32 = 1.5\n" +
+		"let e: SInt8 = -128\n" +
+		"let f: Celsius = 20.5\n"
+
+	interp := exprtree.NewSystemInterp()
+	mod, errs := Compile(interp, "demo", testParseFile(t, input))
+	if errs != nil {
+		t.Fatalf("Compile(demo): unexpected errors: %v", errs)
+	}
+
+	for _, row := range []struct {
+		Name string
+		Type string
+	}{
+		{"one", "builtin::SInt64"},
+		{"two", "mutable builtin::SInt64"},
+		{"half", "builtin::Float64"},
+		{"big", "builtin::UInt64"},
+		{"name", "builtin::String"},
+		{"yes", "builtin::Bool"},
+		{"sum", "builtin::SInt64"},
+		{"fsum", "builtin::Float64"},
+		{"p", "mutable demo::Point"},
+		{"n", "builtin::Float64"},
+		{"px", "builtin::Float64"},
+		{"b", "mutable builtin::UInt8"},
+		{"c", "mutable builtin::SInt32"},
+		{"d", "mutable builtin::Float32"},
+		{"e", "builtin::SInt8"},
+		{"f", "demo::Celsius"},
+	} {
+		sym, found := mod.Symbols().Get(row.Name)
+		if !found {
+			t.Errorf("demo: symbol %q not found", row.Name)
+			continue
+		}
+		if _, ok := sym.CompileTimeValue().(exprtree.Value); !ok {
+			t.Errorf("%s: expected storage, got %#v", row.Name, sym.CompileTimeValue())
+		}
+		if name := sym.Type().CanonicalName(); name != row.Type {
+			t.Errorf("%s: expected type %s, got %s", row.Name, row.Type, name)
+		}
+	}
+
+	all := make(map[string]*exprtree.Symbol)
+	mod.Symbols().All(all)
+	var funcs []string
+	for _, sym := range all {
+		if fn, ok := sym.CompileTimeValue().(*exprtree.Function); ok {
+			funcs = append(funcs, fn.CanonicalName())
+		}
+	}
+	sort.Strings(funcs)
+	expected := []string{
+		"demo::add#(a: builtin::Float64, b: builtin::Float64): builtin::Float64",
+		"demo::add#(a: builtin::SInt64, b: builtin::SInt64): builtin::SInt64",
+		"demo::log#(parts: ...builtin::String, level: builtin::UInt8): builtin::Void",
+		"demo::norm#(this: demo::Point): builtin::Float64",
+	}
+	if strings.Join(funcs, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("demo: wrong functions:\n\texpected %q\n\tgot      %q", expected, funcs)
+	}
+}
+
+func TestCompile_Generics(t *testing.T) {
+	const input = "" +
+		"type Pair[A: type, B: type] = struct {\nfirst: A\nsecond: B\n}\n" +
+		"type Buffer[N: UInt64] = struct { len: UInt64 }\n" +
+		"type Color = enum: UInt8 { Red, Green }\n" +
+		"type Tinted[C: Color, T: type] = struct { value: T }\n" +
+		"var p: Pair#[SInt64, Bool]\n" +
+		"let q: Pair#[SInt64, Bool] = p\n" +
+		"let second = p.second\n" +
+		"var b: Buffer#[16]\n" +
+		"var r: Tinted#[Green, Float64]\n" +
+		"let value = r.value\n" +
+		"type Box[T: type] = struct { v: T }\n" +
+		"type Outer[U: type] = struct { b: Box#[U] }\n" +
+		"var o: Outer#[SInt64]\n" +
+		"let inner = o.b.v\n"
+
+	interp := exprtree.NewSystemInterp()
+	mod, errs := Compile(interp, "demo", testParseFile(t, input))
+	if errs != nil {
+		t.Fatalf("Compile(demo): unexpected errors: %v", errs)
+	}
+
+	for _, row := range []struct {
+		Name string
+		Type string
+	}{
+		{"p", "mutable demo::Pair#[builtin::SInt64, builtin::Bool]"},
+		{"q", "demo::Pair#[builtin::SInt64, builtin::Bool]"},
+		{"second", "builtin::Bool"},
+		{"b", "mutable demo::Buffer#[16]"},
+		{"r", "mutable demo::Tinted#[Green, builtin::Float64]"},
+		{"value", "builtin::Float64"},
+		{"o", "mutable demo::Outer#[builtin::SInt64]"},
+		{"inner", "builtin::SInt64"},
+	} {
+		sym, found := mod.Symbols().Get(row.Name)
+		if !found {
+			t.Errorf("demo: symbol %q not found", row.Name)
+			continue
+		}
+		if name := sym.Type().CanonicalName(); name != row.Type {
+			t.Errorf("%s: expected type %s, got %s", row.Name, row.Type, name)
+		}
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	type testRow struct {
+		Input    string
+		Position string
+		Message  string
+	}
+
+	testData := []testRow{
+		{"import core::io\n", "1:8", `module "core::io" is not loaded`},
+		{"alias X = Y\n", "1:11", `"Y" is not declared`},
+		{"alias X = foo::Y\n", "1:11", `module "foo" is not imported`},
+		{"type T = Nope\n", "1:10", `"Nope" is not declared`},
+		{"type T = UInt8\ntype T = UInt16\n", "2:1", `"T" is already declared`},
+		{"var x: UInt8\nvar y: x\n", "2:8", "x is not a type"},
+		{"var x\n", "1:1", "needs a type or an initializer"},
+		{"var x = y\n", "1:9", `"y" is not declared`},
+		{"let x: UInt8 = 1.5\n", "1:16", "initializer"},
+		{"let x: UInt8 = 256\n", "1:16", "256 overflows builtin::UInt8"},
+		{"let x: SInt8 = -129\n", "1:16", "-129 overflows builtin::SInt8"},
+		{"let x: Float16 = 1e10\n", "1:18", "1e10 overflows builtin::Float16"},
+		{"let x = 1\nlet x = 2\n", "2:1", `"x" is already declared`},
+		{"type T = UInt8\ntype T: UInt16\n", "2:9", "T is builtin::UInt8, not builtin::UInt16"},
+		{"let x = 1\nfunc x() {}\n", "2:1", `"x" is already declared`},
+		{"func f() {}\nlet f = 1\n", "2:1", `"f" is already declared`},
+		{"func f(a: UInt8) {}\nfunc f(b: UInt8) {}\n", "2:1", "duplicate symbol"},
+		{"func f(a) {}\n", "1:8", "parameter a needs a type"},
+		{"method Nope.f() {}\n", "1:1", `"Nope" is not declared`},
+		{"let x = f(1)\n", "1:9", `"f" is not declared`},
+		{"let f = 1\nlet x = f(1)\n", "2:9", `"f" is not a function`},
+		{"let x = foo::f(1)\n", "1:9", `module "foo" is not imported`},
+		{"func f(a: UInt8) {}\nlet x = f(true)\n", "2:9", "f"},
+		{"type P[A: type] = struct { x: A }\nvar x: P\n", "2:8", "demo::P[A: type] is generic, so it needs arguments, as in P#[...]"},
+		{"type P[A: type] = struct { x: A }\nvar x: P#[SInt64, Bool]\n", "2:9", "takes 1 generic arguments, got 2"},
+		{"type P[N: UInt8] = struct {}\nvar x: P#[Bool]\n", "2:11", `"Bool" is not an integer literal`},
+		{"type P[A: type] = struct { x: B }\n", "1:31", `"B" is not declared`},
+		{"type P[A: type] = SInt64\n", "1:19", "only struct, union, enum and bitfield types can be generic"},
+		{"type P[N: SInt8] = struct {}\n", "1:11", "generic parameter N cannot be builtin::SInt8"},
+		{"type P[A: type, A: type] = struct {}\n", "1:17", "generic parameter A is repeated"},
+		{"type P[A: type] = struct {}\ntype P = UInt8\n", "2:1", `"P" is already declared`},
+		{"var x: SInt64#[Bool]\n", "1:8", "builtin::SInt64 is not a generic type"},
+		{"type T = struct {\nx: UInt8\nx: UInt16\n}\n", "3:1", `"x" is already declared in this type`},
+		{"type T = struct {\n#align(+3)\n}\n", "2:1", "must be a power of two"},
+		{"type T = struct {\n#omit_copy()\n#omit_copy()\n}\n", "3:1", "appears more than once"},
+		{"type T = struct {\n#frobnicate()\n}\n", "2:1", "unknown type pragma #frobnicate"},
+		{"type T = struct {\nconst x: UInt8 = +1\n}\n", "2:1", "constant fields are not supported yet"},
+		{"type T = enum {\nA\n}\n", "1:10", "needs an underlying integer type"},
+		{"type T = enum: Float32 {\nA\n}\n", "1:16", "is not a legal underlying type for enum"},
+		{"type T = enum: UInt8 {\nA = +1\n}\n", "1:10", "needs an item with value 0"},
+		{"type T = enum: UInt8 {\nA = +256\n}\n", "2:1", "does not fit in"},
+		{"type T = enum: UInt8 {\nA\nB = +0\n}\n", "3:1", "same value as A"},
+		{"type T = enum: UInt8 {\nA\nB = C\n}\n", "3:5", "is not an earlier item"},
+		{"type T = bitfield: SInt8 {\nA\n}\n", "1:20", "is not a legal underlying type for bitfield"},
+		{"type T = bitfield: UInt8 {\nA = +3\n}\n", "2:5", "exactly one bit set"},
+		{"type T = union {\ncase A {\n}\n}\n", "1:10", "union needs a tag"},
+		{"type T = union {\ntag kind: UInt8\n}\n", "2:11", "must be an enum"},
+		{"type K = enum: UInt8 {\nA\n}\ntype T = union {\ntag kind: K\ncase B {\n}\n}\n", "6:1", "not an item of the union's tag"},
+	}
+
+	for _, row := range testData {
+		t.Run(row.Input, func(t *testing.T) {
+			interp := exprtree.NewSystemInterp()
+			_, errs := Compile(interp, "demo", testParseFile(t, row.Input))
+			if len(errs) == 0 {
+				t.Fatalf("Compile: expected error %q, got none", row.Message)
+			}
+			err, ok := errs[0].(*Error)
+			if !ok {
+				t.Fatalf("Compile: expected *Error, got %T", errs[0])
+			}
+			if pos := fmt.Sprintf("%d:%d", err.Pos.LineNumber(), err.Pos.ColumnNumber()); pos != row.Position {
+				t.Errorf("Compile: wrong position: expected %s, got %s (%v)", row.Position, pos, err)
+			}
+			if !strings.Contains(err.Message, row.Message) {
+				t.Errorf("Compile: wrong message: expected %q, got %q", row.Message, err.Message)
+			}
+		})
+	}
+}
+
+func TestLower(t *testing.T) {
+	type testRow struct {
+		Name     string
+		Input    string
+		Shown    []string
+		Returned string
+	}
+
+	testData := []testRow{
+		{
+			Name:  "expressions",
+			Input: "let x = 1\nx + 1\nx * 2 == 2\n3.5 / 2.0\n\"spider\"\n",
+			Shown: []string{"2", "true", "1.75", "spider"},
+		},
+		{
+			Name:  "assignment",
+			Input: "var x = 10\nx += 5\nx--\nx = x * 2\nx\n",
+			Shown: []string{"15", "14", "28", "28"},
+		},
+		{
+			Name:  "typed literals",
+			Input: "var x: UInt8 = 200\nx += 55\nx\nvar y: Float32 = 0.25\ny\n",
+			Shown: []string{"255", "255", "0.25"},
+		},
+		{
+			Name:     "loops",
+			Input:    "var sum = 0\nfor i := 1; i <= 10; i++ {\nif i == 3 { continue }\nsum += i\n}\nwhile sum > 0 { sum -= 100 }\nreturn sum\n",
+			Returned: "-48",
+		},
+		{
+			Name:     "break",
+			Input:    "var n = 0\nwhile true {\nn++\nif n >= 7 { break }\n}\nreturn n\n",
+			Returned: "7",
+		},
+		{
+			Name:     "if else",
+			Input:    "let x = 4\nif x < 3 { return 1 } else if x < 5 { return 2 } else { return 3 }\n",
+			Returned: "2",
+		},
+		{
+			Name:     "recursion",
+			Input:    "func fib(n: SInt64): SInt64 {\nif n < 2 { return n }\nlet a = fib(n - 1)\nlet b = fib(n - 2)\nreturn a + b\n}\nreturn fib(20)\n",
+			Returned: "6765",
+		},
+		{
+			Name:     "methods",
+			Input:    "type Point = struct {\nx: SInt64\ny: SInt64\n}\nmethod Point.sum(): SInt64 { return this.x + this.y }\nvar p: Point\nreturn p.sum()\n",
+			Returned: "0",
+		},
+		{
+			Name:     "named arguments",
+			Input:    "func scale(x: SInt64; by: SInt64): SInt64 { return x * by }\nreturn scale(7, by: 6)\n",
+			Returned: "42",
+		},
+	}
+
+	for _, row := range testData {
+		t.Run(row.Name, func(t *testing.T) {
+			interp := exprtree.NewSystemInterp()
+			mod, err := interp.NewMainModule()
+			if err != nil {
+				t.Fatalf("NewMainModule: unexpected error: %v", err)
+			}
+			prog, errs := Lower(mod, testParseFile(t, row.Input))
+			if errs != nil {
+				t.Fatalf("Lower: unexpected errors: %v", errs)
+			}
+
+			var shown []string
+			value, returned, err := prog.Run(func(value exprtree.Value) {
+				shown = append(shown, testFormat(value))
+			})
+			if err != nil {
+				t.Fatalf("Run: unexpected error: %v", err)
+			}
+			if strings.Join(shown, "\n") != strings.Join(row.Shown, "\n") {
+				t.Errorf("Run: wrong values:\n\texpected %q\n\tgot      %q", row.Shown, shown)
+			}
+			if returned != (row.Returned != "") {
+				t.Fatalf("Run: expected returned=%v, got %v", row.Returned != "", returned)
+			}
+			if returned && testFormat(value) != row.Returned {
+				t.Errorf("Run: expected to return %s, got %s", row.Returned, testFormat(value))
+			}
+		})
+	}
+}
+
+func TestLower_Errors(t *testing.T) {
+	type testRow struct {
+		Input    string
+		Position string
+		Message  string
+	}
+
+	testData := []testRow{
+		{"let x = 1\nx = 2\n", "2:1", "only mutable values may be assigned"},
+		{"break\n", "1:1", "break is not inside a loop"},
+		{"func f(): SInt64 { return }\n", "1:20", "missing return value"},
+		{"func f(): SInt64 { return \"x\" }\n", "1:27", "return value"},
+		{"if 1 { }\n", "1:4", "condition"},
+		{"{\nlet x = 1\nlet x = 2\n}\n", "3:1", `"x" is already declared in this block`},
+		{"{\nfunc f() {}\n}\n", "2:1", "declarations inside a block are not supported yet"},
+		{"let y = this\n", "1:9", "this is only defined inside a method"},
+	}
+
+	for _, row := range testData {
+		t.Run(row.Input, func(t *testing.T) {
+			interp := exprtree.NewSystemInterp()
+			mod, err := interp.NewMainModule()
+			if err != nil {
+				t.Fatalf("NewMainModule: unexpected error: %v", err)
+			}
+			prog, errs := Lower(mod, testParseFile(t, row.Input))
+			if len(errs) == 0 {
+				t.Fatalf("Lower: expected error %q, got none", row.Message)
+			}
+			if prog != nil {
+				t.Errorf("Lower: expected nil Program, got %v", prog)
+			}
+			serr, ok := errs[0].(*Error)
+			if !ok {
+				t.Fatalf("Lower: expected *Error, got %T", errs[0])
+			}
+			if pos := fmt.Sprintf("%d:%d", serr.Pos.LineNumber(), serr.Pos.ColumnNumber()); pos != row.Position {
+				t.Errorf("Lower: wrong position: expected %s, got %s (%v)", row.Position, pos, serr)
+			}
+			if !strings.Contains(serr.Message, row.Message) {
+				t.Errorf("Lower: wrong message: expected %q, got %q", row.Message, serr.Message)
+			}
+		})
+	}
+}
+
+// testFormat formats the value of a number, a Bool or a String.
+func testFormat(value exprtree.Value) string {
+	switch x := value.Get().(type) {
+	case *exprtree.EnumItem:
+		return x.Name()
+	case exprtree.String:
+		return x.Buffer.String()[x.Offset : x.Offset+x.Length]
+	default:
+		return fmt.Sprint(x)
+	}
+}
@@ -0,0 +1,571 @@
+package sema
+
+import (
+	"math/bits"
+	"strings"
+
+	"github.com/chronos-tachyon/go-spiderscript/ast"
+	"github.com/chronos-tachyon/go-spiderscript/exprtree"
+)
+
+// typePragmas maps the pragmas that may appear in a type body to the
+// statements they become.
+var typePragmas = map[string]exprtree.StatementKind{
+	"align":          exprtree.AlignPragmaStatement,
+	"minsize":        exprtree.MinimumSizePragmaStatement,
+	"preserve_order": exprtree.PreserveFieldOrderPragmaStatement,
+	"omit_new":       exprtree.OmitNewPragmaStatement,
+	"omit_copy":      exprtree.OmitCopyPragmaStatement,
+	"omit_move":      exprtree.OmitMovePragmaStatement,
+	"omit_hash":      exprtree.OmitHashPragmaStatement,
+	"omit_compare":   exprtree.OmitComparePragmaStatement,
+	"omit_to_string": exprtree.OmitToStringPragmaStatement,
+	"omit_to_repr":   exprtree.OmitToReprPragmaStatement,
+}
+
+// integerBits gives the width of each integer kind that can back an enum or
+// a bitfield.
+var integerBits = map[exprtree.TypeKind]uint{
+	exprtree.U8Kind:  8,
+	exprtree.U16Kind: 16,
+	exprtree.U32Kind: 32,
+	exprtree.U64Kind: 64,
+	exprtree.S8Kind:  8,
+	exprtree.S16Kind: 16,
+	exprtree.S32Kind: 32,
+	exprtree.S64Kind: 64,
+}
+
+func isSigned(kind exprtree.TypeKind) bool {
+	switch kind {
+	case exprtree.S8Kind, exprtree.S16Kind, exprtree.S32Kind, exprtree.S64Kind:
+		return true
+	default:
+		return false
+	}
+}
+
+// typeExpr returns the type that node describes, or nil after reporting an
+// error.
+func (c *checker) typeExpr(node ast.Node) *exprtree.Type {
+	switch expr := node.(type) {
+	case *ast.IdentifierExpr:
+		if t, found := c.typeParams[expr.Name]; found && expr.Module.IsEmpty() {
+			return t
+		}
+		sym := c.lookup(expr, expr.Module.Names, expr.Name)
+		if sym == nil {
+			return nil
+		}
+		return c.symbolType(expr, sym)
+
+	case *ast.GenericInstantiationExpr:
+		return c.instantiate(expr)
+
+	case *ast.TypeBodyExpr:
+		return c.bodyType(expr)
+
+	default:
+		c.errorf(node, "%q is not a supported type expression", node.String())
+		return nil
+	}
+}
+
+// bodyKinds gives the kind of type that each kind of type body describes.
+var bodyKinds = map[ast.TypeBodyKind]exprtree.TypeKind{
+	ast.TypeBodyStruct:   exprtree.StructKind,
+	ast.TypeBodyUnion:    exprtree.UnionKind,
+	ast.TypeBodyEnum:     exprtree.EnumKind,
+	ast.TypeBodyBitfield: exprtree.BitfieldKind,
+}
+
+func (c *checker) bodyType(expr *ast.TypeBodyExpr) *exprtree.Type {
+	list, ok := c.typeBody(expr)
+	if !ok {
+		return nil
+	}
+
+	var t *exprtree.Type
+	var err error
+	switch bodyKinds[expr.Kind] {
+	case exprtree.StructKind:
+		t, err = c.interp.StructType(list)
+	case exprtree.UnionKind:
+		t, err = c.interp.UnionType(list)
+	case exprtree.EnumKind:
+		t, err = c.interp.EnumType(list)
+	default:
+		t, err = c.interp.BitfieldType(list)
+	}
+	if err != nil {
+		c.errorf(expr, "%v", err)
+		return nil
+	}
+	return t
+}
+
+// typeBody lowers the members of a type body to statements, or returns false
+// after reporting an error.
+func (c *checker) typeBody(expr *ast.TypeBodyExpr) (exprtree.Statements, bool) {
+	switch expr.Kind {
+	case ast.TypeBodyStruct:
+		return c.structBody(expr)
+	case ast.TypeBodyUnion:
+		return c.unionBody(expr)
+	case ast.TypeBodyEnum, ast.TypeBodyBitfield:
+		return c.enumBody(expr)
+	default:
+		c.errorf(expr, "%s types are not supported yet", expr.Kind)
+		return nil, false
+	}
+}
+
+// instantiate returns the instance of a generic type that expr names, as in
+// "Pair#[SInt64, Bool]".
+func (c *checker) instantiate(expr *ast.GenericInstantiationExpr) *exprtree.Type {
+	ident, ok := expr.Operand.(*ast.IdentifierExpr)
+	if !ok || expr.TypeSig == nil {
+		c.errorf(expr, "%q is not a supported type expression", expr.String())
+		return nil
+	}
+	sym := c.lookup(ident, ident.Module.Names, ident.Name)
+	if sym == nil {
+		return nil
+	}
+	if _, ok := sym.CompileTimeValue().(*exprtree.GenericType); !ok {
+		c.errorf(ident, "%s is not a generic type", sym.CanonicalName())
+		return nil
+	}
+
+	sig := sym.Generic().Signature()
+	if uint(len(expr.TypeSig.Args)) != sig.NumParams() {
+		c.errorf(expr.TypeSig, "%s takes %d generic arguments, got %d", sym.CanonicalName(), sig.NumParams(), len(expr.TypeSig.Args))
+		return nil
+	}
+
+	args := make([]interface{}, len(expr.TypeSig.Args))
+	for index, node := range expr.TypeSig.Args {
+		param := sig.Param(uint(index))
+		switch param.Kind() {
+		case exprtree.TypeGenericParam:
+			t := c.typeExpr(node)
+			ok = ok && t != nil
+			args[index] = t
+
+		case exprtree.IntegerGenericParam:
+			n, valid := c.intValue(node)
+			ok = ok && valid
+			args[index] = n
+
+		default:
+			item := c.enumItem(node, param.Type())
+			ok = ok && item != nil
+			args[index] = item
+		}
+	}
+	if !ok {
+		return nil
+	}
+
+	t, err := c.interp.Instantiate(sym, args...)
+	if err != nil {
+		c.errorf(expr, "%v", err)
+		return nil
+	}
+	return t
+}
+
+// enumItem returns the item of t that node names, or nil after reporting an
+// error.
+func (c *checker) enumItem(node ast.Node, t *exprtree.Type) *exprtree.EnumItem {
+	if ident, ok := node.(*ast.IdentifierExpr); ok && ident.Module.IsEmpty() {
+		if item := t.Chase().Details().(*exprtree.Enum).ByName(ident.Name); item != nil {
+			return item
+		}
+	}
+	c.errorf(node, "%q is not an item of %s", node.String(), t.CanonicalName())
+	return nil
+}
+
+func (c *checker) structBody(expr *ast.TypeBodyExpr) (exprtree.Statements, bool) {
+	numErrors := len(c.errors)
+	context := exprtree.StructStatementContext
+	names := make(map[string]bool, len(expr.Members))
+
+	var list exprtree.Statements
+	var size uint
+	for _, member := range expr.Members {
+		switch x := member.(type) {
+		case *ast.PragmaStatement:
+			c.pragmaMember(&list, x, context)
+
+		case *ast.FieldMember:
+			if stmt, ok := c.fieldMember(x, exprtree.StructFieldStatement, names); ok {
+				list = append(list, stmt)
+				if stmt.Kind == exprtree.StructFieldStatement {
+					size += stmt.FieldType.PaddedBytes()
+				}
+			}
+		}
+	}
+
+	if size > exprtree.MaxStructSize {
+		c.errorf(expr, "struct is too large: %d bytes > %d bytes maximum", size, exprtree.MaxStructSize)
+	}
+	return list, len(c.errors) == numErrors
+}
+
+func (c *checker) unionBody(expr *ast.TypeBodyExpr) (exprtree.Statements, bool) {
+	numErrors := len(c.errors)
+	context := exprtree.UnionStatementContext
+	names := make(map[string]bool, len(expr.Members))
+
+	var list exprtree.Statements
+	var enum *exprtree.Enum
+	var hasTag bool
+	for _, member := range expr.Members {
+		x, ok := member.(*ast.UnionTagMember)
+		if !ok {
+			continue
+		}
+		if hasTag {
+			c.errorf(x, "union has more than one tag")
+			continue
+		}
+		hasTag = true
+
+		t := c.typeExpr(x.Type)
+		if t == nil {
+			continue
+		}
+		if t.Chase().Kind() != exprtree.EnumKind {
+			c.errorf(x.Type, "union tag %s must be an enum, not %s", x.Name, t.CanonicalName())
+			continue
+		}
+		names[x.Name] = true
+		enum = t.Chase().Details().(*exprtree.Enum)
+		list = append(list, exprtree.Statement{
+			Kind:      exprtree.UnionTagStatement,
+			TagSymbol: c.interp.BuiltinUnionModule().Symbols().NewGenSym(t),
+			TagType:   t,
+		})
+	}
+	if !hasTag {
+		c.errorf(expr, "union needs a tag, as in \"tag kind: KindEnum\"")
+	}
+
+	sizeByTag := make(map[*exprtree.EnumItem]uint)
+	for _, member := range expr.Members {
+		switch x := member.(type) {
+		case *ast.PragmaStatement:
+			c.pragmaMember(&list, x, context)
+
+		case *ast.FieldMember:
+			if stmt, ok := c.fieldMember(x, exprtree.InvalidStatementKind, names); ok {
+				list = append(list, stmt)
+			}
+
+		case *ast.UnionCaseMember:
+			if enum == nil {
+				continue
+			}
+			item := enum.ByName(x.Tag)
+			if item == nil {
+				c.errorf(x, "%q is not an item of the union's tag", x.Tag)
+				continue
+			}
+			if _, seen := sizeByTag[item]; seen {
+				c.errorf(x, "case %s appears more than once", x.Tag)
+				continue
+			}
+			sizeByTag[item] = 0
+
+			caseNames := make(map[string]bool, len(x.Members))
+			for name := range names {
+				caseNames[name] = true
+			}
+			for _, inner := range x.Members {
+				switch y := inner.(type) {
+				case *ast.PragmaStatement:
+					c.errorf(y, "pragmas are not allowed inside a union case")
+
+				case *ast.FieldMember:
+					stmt, ok := c.fieldMember(y, exprtree.UnionFieldStatement, caseNames)
+					if !ok {
+						continue
+					}
+					if stmt.Kind != exprtree.UnionFieldStatement {
+						c.errorf(y, "static fields are not allowed inside a union case")
+						continue
+					}
+					stmt.TagItem = item
+					list = append(list, stmt)
+					sizeByTag[item] += stmt.FieldType.PaddedBytes()
+				}
+			}
+			if size := sizeByTag[item]; size > exprtree.MaxStructSize {
+				c.errorf(x, "case %s is too large: %d bytes > %d bytes maximum", x.Tag, size, exprtree.MaxStructSize)
+			}
+		}
+	}
+
+	return list, len(c.errors) == numErrors
+}
+
+// enumBody lowers an enum or a bitfield.  Enum items are numbered from 0
+// unless given a value.  Bitfield values are written as masks with exactly
+// one bit set; items without a value take the next bit up.
+func (c *checker) enumBody(expr *ast.TypeBodyExpr) (exprtree.Statements, bool) {
+	numErrors := len(c.errors)
+	isBitfield := (expr.Kind == ast.TypeBodyBitfield)
+
+	context := exprtree.EnumStatementContext
+	kindStatement := exprtree.EnumKindStatement
+	valueStatement := exprtree.EnumValueStatement
+	aliasStatement := exprtree.EnumAliasStatement
+	if isBitfield {
+		context = exprtree.BitfieldStatementContext
+		kindStatement = exprtree.BitfieldKindStatement
+		valueStatement = exprtree.BitfieldValueStatement
+		aliasStatement = exprtree.BitfieldAliasStatement
+	}
+
+	if expr.Underlying == nil {
+		c.errorf(expr, "%s needs an underlying integer type, as in \"%s: UInt32 { ... }\"", expr.Kind, expr.Kind)
+		return nil, false
+	}
+	underlying := c.typeExpr(expr.Underlying)
+	if underlying == nil {
+		return nil, false
+	}
+	kind := underlying.Chase().Kind()
+	width, isInteger := integerBits[kind]
+	if !isInteger || (isBitfield && isSigned(kind)) {
+		c.errorf(expr.Underlying, "%s is not a legal underlying type for %s", underlying.CanonicalName(), expr.Kind)
+		return nil, false
+	}
+
+	list := exprtree.Statements{{Kind: kindStatement, EnumKind: kind}}
+	names := make(map[string]bool, len(expr.Members))
+	numbers := make(map[int64]string, len(expr.Members))
+	next := int64(0)
+	for _, member := range expr.Members {
+		switch x := member.(type) {
+		case *ast.PragmaStatement:
+			c.pragmaMember(&list, x, context)
+
+		case *ast.FieldMember:
+			if stmt, ok := c.fieldMember(x, exprtree.InvalidStatementKind, names); ok {
+				list = append(list, stmt)
+			}
+
+		case *ast.EnumMember:
+			if names[x.Name] {
+				c.errorf(x, "%q is already declared in this %s", x.Name, expr.Kind)
+				continue
+			}
+
+			if x.IsAlias() {
+				target := x.Value.(*ast.IdentifierExpr)
+				if !target.Module.IsEmpty() || !names[target.Name] {
+					c.errorf(target, "%q is not an earlier item of this %s", target.String(), expr.Kind)
+					continue
+				}
+				names[x.Name] = true
+				list = append(list, exprtree.Statement{
+					Kind:        aliasStatement,
+					EnumName:    x.Name,
+					EnumAliasOf: target.Name,
+				})
+				continue
+			}
+
+			number := next
+			if x.Value != nil {
+				value, ok := c.intValue(x.Value)
+				if !ok {
+					continue
+				}
+				number = value
+				if isBitfield {
+					if value <= 0 || value&(value-1) != 0 {
+						c.errorf(x.Value, "bitfield value %#x must have exactly one bit set", value)
+						continue
+					}
+					number = int64(bits.TrailingZeros64(uint64(value)))
+				}
+			}
+
+			if isBitfield {
+				if number >= int64(width) {
+					c.errorf(x, "%s does not fit in %s", x.Name, underlying.CanonicalName())
+					continue
+				}
+			} else if !fits(number, kind, width) {
+				c.errorf(x, "%s = %d does not fit in %s", x.Name, number, underlying.CanonicalName())
+				continue
+			}
+
+			if other, seen := numbers[number]; seen {
+				c.errorf(x, "%s has the same value as %s", x.Name, other)
+				continue
+			}
+
+			names[x.Name] = true
+			numbers[number] = x.Name
+			next = number + 1
+			list = append(list, exprtree.Statement{
+				Kind:       valueStatement,
+				EnumName:   x.Name,
+				EnumNumber: number,
+			})
+		}
+	}
+
+	if _, found := numbers[0]; !isBitfield && !found && len(c.errors) == numErrors {
+		c.errorf(expr, "enum needs an item with value 0")
+	}
+	return list, len(c.errors) == numErrors
+}
+
+// fits returns true if number can be stored in an integer of the given kind
+// and width.
+func fits(number int64, kind exprtree.TypeKind, width uint) bool {
+	if width == 64 {
+		return isSigned(kind) || number >= 0
+	}
+	if isSigned(kind) {
+		limit := int64(1) << (width - 1)
+		return number >= -limit && number < limit
+	}
+	return number >= 0 && number < int64(1)<<width
+}
+
+// fieldMember lowers a field of a type body.  Static fields become
+// StaticFieldStatements; other fields become statements of the given kind,
+// or are rejected if kind is InvalidStatementKind.
+func (c *checker) fieldMember(member *ast.FieldMember, kind exprtree.StatementKind, names map[string]bool) (exprtree.Statement, bool) {
+	if names[member.Name] {
+		c.errorf(member, "%q is already declared in this type", member.Name)
+		return exprtree.Statement{}, false
+	}
+	names[member.Name] = true
+
+	isStatic := false
+	for _, mod := range member.Modifiers {
+		switch mod {
+		case ast.ModStatic:
+			isStatic = true
+		default:
+			c.errorf(member, "%s fields are only allowed in interfaces", mod)
+			return exprtree.Statement{}, false
+		}
+	}
+	if member.IsConst {
+		c.errorf(member, "constant fields are not supported yet")
+		return exprtree.Statement{}, false
+	}
+	if isStatic {
+		kind = exprtree.StaticFieldStatement
+	}
+	if kind == exprtree.InvalidStatementKind {
+		c.errorf(member, "field %s must be static here", member.Name)
+		return exprtree.Statement{}, false
+	}
+
+	t := c.typeExpr(member.Type)
+	if t == nil {
+		return exprtree.Statement{}, false
+	}
+	stmt := exprtree.Statement{
+		Kind:      kind,
+		FieldName: member.Name,
+		FieldType: t,
+	}
+	return stmt, true
+}
+
+// pragmaMember lowers a pragma that applies to a whole type body.
+func (c *checker) pragmaMember(list *exprtree.Statements, member *ast.PragmaStatement, context exprtree.StatementContext) {
+	pragma, ok := member.Pragma.(*ast.GenericPragma)
+	if !ok {
+		c.errorf(member, "%q is not allowed in a type body", member.Pragma.String())
+		return
+	}
+
+	name := strings.TrimPrefix(pragma.Name, "#")
+	kind, found := typePragmas[name]
+	if !found {
+		c.errorf(pragma, "unknown type pragma #%s", name)
+		return
+	}
+	if !kind.IsValidIn(context) {
+		c.errorf(pragma, "#%s is not allowed here", name)
+		return
+	}
+	if kind.IsUnique() {
+		for _, stmt := range *list {
+			if stmt.Kind == kind {
+				c.errorf(pragma, "#%s appears more than once", name)
+				return
+			}
+		}
+	}
+
+	stmt := exprtree.Statement{Kind: kind}
+	switch kind {
+	case exprtree.AlignPragmaStatement, exprtree.MinimumSizePragmaStatement:
+		if len(pragma.Expressions) != 1 {
+			c.errorf(pragma, "#%s takes one argument", name)
+			return
+		}
+		value, ok := c.intValue(pragma.Expressions[0])
+		if !ok {
+			return
+		}
+		if kind == exprtree.AlignPragmaStatement {
+			if value <= 0 || value&(value-1) != 0 || value > 1<<exprtree.MaxAlignShift {
+				c.errorf(pragma, "#align(%d) must be a power of two no larger than %d", value, 1<<exprtree.MaxAlignShift)
+				return
+			}
+			stmt.MinAlign = uint(bits.TrailingZeros64(uint64(value)))
+		} else {
+			if value < 0 || value > exprtree.MaxStructSize {
+				c.errorf(pragma, "#minsize(%d) must be between 0 and %d", value, exprtree.MaxStructSize)
+				return
+			}
+			stmt.MinSize = uint(value)
+		}
+
+	default:
+		if len(pragma.Expressions) != 0 {
+			c.errorf(pragma, "#%s takes no arguments", name)
+			return
+		}
+	}
+	*list = append(*list, stmt)
+}
+
+// intValue returns the value of an integer literal, which may be negated.
+func (c *checker) intValue(node ast.Node) (int64, bool) {
+	switch expr := node.(type) {
+	case *ast.LiteralNumber:
+		value, err := expr.Value.AsInt64()
+		if err != nil {
+			c.errorf(expr, "%v", err)
+			return 0, false
+		}
+		return value, true
+
+	case *ast.UnaryOperatorExpr:
+		switch expr.Operator {
+		case ast.OpPos:
+			return c.intValue(expr.Operand)
+		case ast.OpNeg:
+			value, ok := c.intValue(expr.Operand)
+			return -value, ok
+		}
+	}
+	c.errorf(node, "%q is not an integer literal", node.String())
+	return 0, false
+}
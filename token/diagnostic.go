@@ -0,0 +1,235 @@
+package token
+
+import (
+	"fmt"
+
+	"github.com/chronos-tachyon/go-spiderscript/value"
+)
+
+// Severity
+// {{{
+
+// Severity says whether a Diagnostic stops the input from being used.
+type Severity byte
+
+const (
+	InvalidSeverity Severity = iota
+	SeverityError
+	SeverityWarning
+)
+
+var severityNames = []string{
+	"InvalidSeverity",
+	"SeverityError",
+	"SeverityWarning",
+}
+
+var severityLabels = []string{
+	"invalid",
+	"error",
+	"warning",
+}
+
+func (enum Severity) String() string {
+	if uint(enum) >= uint(len(severityLabels)) {
+		return fmt.Sprintf("Severity(%#x)", uint(enum))
+	}
+	return severityLabels[enum]
+}
+
+func (enum Severity) GoString() string {
+	if uint(enum) >= uint(len(severityNames)) {
+		return fmt.Sprintf("Severity(%#x)", uint(enum))
+	}
+	return severityNames[enum]
+}
+
+var _ fmt.Stringer = Severity(0)
+var _ fmt.GoStringer = Severity(0)
+
+// }}}
+
+// Code
+// {{{
+
+// Code identifies the kind of problem that a Diagnostic reports.
+type Code uint
+
+const (
+	InvalidCode Code = iota
+	CodeInvalidUTF8
+	CodeNULByte
+	CodeMisplacedBOM
+	CodeReadError
+	CodeIllegalRune
+	CodeUnterminatedString
+	CodeUnterminatedRegex
+	CodeUnterminatedPEG
+	CodeUnterminatedComment
+	CodeBadNumber
+	CodeBadString
+	CodeBadRegex
+	CodeBadPEG
+)
+
+var codeNames = []string{
+	"InvalidCode",
+	"CodeInvalidUTF8",
+	"CodeNULByte",
+	"CodeMisplacedBOM",
+	"CodeReadError",
+	"CodeIllegalRune",
+	"CodeUnterminatedString",
+	"CodeUnterminatedRegex",
+	"CodeUnterminatedPEG",
+	"CodeUnterminatedComment",
+	"CodeBadNumber",
+	"CodeBadString",
+	"CodeBadRegex",
+	"CodeBadPEG",
+}
+
+func (enum Code) String() string {
+	if uint(enum) >= uint(len(codeNames)) {
+		return fmt.Sprintf("Code(%#x)", uint(enum))
+	}
+	return codeNames[enum]
+}
+
+func (enum Code) GoString() string {
+	return enum.String()
+}
+
+var _ fmt.Stringer = Code(0)
+var _ fmt.GoStringer = Code(0)
+
+// }}}
+
+// Diagnostic
+// {{{
+
+// Diagnostic reports a problem that the lexer found in its input.  Start and
+// End delimit the offending text.  For a construct that is never closed,
+// such as a string literal that runs to the end of the file, they delimit
+// the opening delimiter instead.
+type Diagnostic struct {
+	Severity Severity
+	Code     Code
+	Message  string
+	Start    Position
+	End      Position
+}
+
+func (diag *Diagnostic) Error() string {
+	return fmt.Sprintf("%v: %s", diag.Start, diag.Message)
+}
+
+var _ error = (*Diagnostic)(nil)
+
+// }}}
+
+func (lexer *Lexer) report(severity Severity, code Code, message string, start Position, end Position) {
+	lexer.diags = append(lexer.diags, Diagnostic{
+		Severity: severity,
+		Code:     code,
+		Message:  message,
+		Start:    start,
+		End:      end,
+	})
+}
+
+// Diagnostics returns the problems found in the input so far, in the order
+// in which they were found.
+func (lexer *Lexer) Diagnostics() []Diagnostic {
+	return lexer.diags
+}
+
+// diagnose reports any problem with the token that was just completed.
+func (lexer *Lexer) diagnose() {
+	tok := &lexer.tok
+
+	switch tok.Type {
+	case Invalid:
+		if lexer.bad[tok.Start.RuneOffset] {
+			// Already reported as CodeInvalidUTF8 by fill.
+			delete(lexer.bad, tok.Start.RuneOffset)
+		} else if len(tok.Raw) == 1 && tok.Raw[0] == 0 {
+			lexer.report(SeverityError, CodeNULByte, "NUL byte in input", tok.Start, tok.End)
+		} else {
+			lexer.report(SeverityError, CodeIllegalRune, fmt.Sprintf("illegal character %U", tok.Raw[0]), tok.Start, tok.End)
+		}
+		return
+
+	case Partial:
+		code, message, opening := unterminated(lexer.st)
+		if code == InvalidCode {
+			return
+		}
+		if n := openingLength(tok.Raw, opening); n != 0 {
+			end := tok.Start
+			for _, ch := range tok.Raw[:n] {
+				end.Advance(ch)
+			}
+			lexer.report(SeverityError, code, message, tok.Start, end)
+		}
+		return
+	}
+
+	ev, ok := tok.Parsed.(*value.Error)
+	if !ok {
+		return
+	}
+
+	var code Code
+	switch tok.Type {
+	case Number:
+		code = CodeBadNumber
+	case String:
+		code = CodeBadString
+	case Regex:
+		code = CodeBadRegex
+	case PEG:
+		code = CodeBadPEG
+	default:
+		return
+	}
+	lexer.report(SeverityError, code, ev.Err.Error(), tok.Start, tok.End)
+}
+
+// unterminated describes the construct that the lexer was in the middle of
+// when it stopped in state st, if that construct needs a closing delimiter.
+// opening lists the runes that end the construct's opening delimiter.
+func unterminated(st State) (code Code, message string, opening []rune) {
+	switch st {
+	case StateInSingleQuoteString, StateInSingleQuoteStringWithBackslash:
+		return CodeUnterminatedString, "unterminated string literal", []rune{'\''}
+	case StateInDoubleQuoteString, StateInDoubleQuoteStringWithBackslash:
+		return CodeUnterminatedString, "unterminated string literal", []rune{'"'}
+	case StateInTickQuoteString, StateInTickQuoteStringWithBackslash:
+		return CodeUnterminatedString, "unterminated string literal", []rune{'`'}
+	case StateInRegexSlash, StateInRegexSlashWithBackslash,
+		StateInRegexExclaim, StateInRegexExclaimWithBackslash,
+		StateInRegexAt, StateInRegexAtWithBackslash,
+		StateInRegexBrace, StateInRegexBraceWithBackslash:
+		return CodeUnterminatedRegex, "unterminated regex literal", []rune{'/', '!', '@', '{'}
+	case StateInPEG, StateInPEGWithBackslash:
+		return CodeUnterminatedPEG, "unterminated PEG literal", []rune{'{'}
+	case StateInMultiLineComment, StateInMultiLineCommentWithStar:
+		return CodeUnterminatedComment, "unterminated comment", []rune{'*'}
+	default:
+		return InvalidCode, "", nil
+	}
+}
+
+// openingLength returns the length of the opening delimiter at the start of
+// raw: everything up to and including the first rune from opening.
+func openingLength(raw []rune, opening []rune) int {
+	for index, ch := range raw {
+		for _, delim := range opening {
+			if ch == delim {
+				return index + 1
+			}
+		}
+	}
+	return 0
+}
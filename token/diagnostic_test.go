@@ -0,0 +1,64 @@
+package token
+
+import (
+	"testing"
+)
+
+func TestLexer_Diagnostics(t *testing.T) {
+	type testRow struct {
+		Name     string
+		Input    string
+		Code     Code
+		Start    uint
+		End      uint
+		Severity Severity
+	}
+
+	testData := []testRow{
+		{"unterminated-double-quote", "let x = \"abc\n", CodeUnterminatedString, 8, 9, SeverityError},
+		{"unterminated-single-quote", "f('a\\')", CodeUnterminatedString, 2, 3, SeverityError},
+		{"unterminated-regex-brace", "let r = #rx{a{b}", CodeUnterminatedRegex, 8, 12, SeverityError},
+		{"unterminated-regex-slash", "#rx/abc", CodeUnterminatedRegex, 0, 4, SeverityError},
+		{"unterminated-peg", "x = #peg{ a <- 'b'", CodeUnterminatedPEG, 4, 9, SeverityError},
+		{"unterminated-comment", "x /* abc", CodeUnterminatedComment, 2, 4, SeverityError},
+		{"bad-number", "let x = 1e\n", CodeBadNumber, 8, 10, SeverityError},
+		{"bad-escape", "let s = \"a\\qb\"\n", CodeBadString, 8, 14, SeverityError},
+		{"illegal-rune", "let x = 1 §\n", CodeIllegalRune, 10, 11, SeverityError},
+		{"nul-byte", "let x\x00 = 1\n", CodeNULByte, 5, 6, SeverityError},
+	}
+
+	for _, row := range testData {
+		t.Run(row.Name, func(t *testing.T) {
+			lexer := NewLexer("test.spider", []rune(row.Input))
+			for lexer.HasNext() {
+				lexer.Token()
+			}
+
+			diags := lexer.Diagnostics()
+			if len(diags) != 1 {
+				t.Fatalf("expected 1 diagnostic, got %v", diags)
+			}
+			diag := diags[0]
+			if diag.Code != row.Code || diag.Severity != row.Severity {
+				t.Errorf("expected %v %v, got %v %v", row.Severity, row.Code, diag.Severity, diag.Code)
+			}
+			if diag.Start.RuneOffset != row.Start || diag.End.RuneOffset != row.End {
+				t.Errorf("expected range [%d,%d), got [%d,%d)", row.Start, row.End, diag.Start.RuneOffset, diag.End.RuneOffset)
+			}
+			if diag.Message == "" {
+				t.Errorf("empty message")
+			}
+		})
+	}
+}
+
+func TestLexer_NoDiagnostics(t *testing.T) {
+	input := "#!/bin/spider\nlet x = \"abc\" + 'd' // comment\nlet r = #rx{a{b}c}i\n/* ok */\n"
+	lexer := NewLexer("test.spider", []rune(input))
+	for lexer.HasNext() {
+		lexer.Token()
+	}
+	if diags := lexer.Diagnostics(); diags != nil {
+		t.Errorf("unexpected diagnostics: %v", diags)
+	}
+}
@@ -1,6 +1,8 @@
 package token
 
 import (
+	"bufio"
+
 	"github.com/chronos-tachyon/go-spiderscript/internal/util"
 	"github.com/chronos-tachyon/go-spiderscript/value"
 )
@@ -13,6 +15,17 @@ type Lexer struct {
 	ctr int32
 	rdy bool
 	eof bool
+
+	// Reader mode; see NewReaderLexer.  inp holds the runes from offset
+	// base onward, and dec is the position of the next rune to decode.
+	// bad holds the offsets of the U+FFFD runes that were decoded from
+	// invalid UTF-8, so that they are not reported again as illegal.
+	rd   *bufio.Reader
+	base uint
+	dec  Position
+	bad  map[uint]bool
+
+	diags []Diagnostic
 }
 
 func NewLexer(path string, input []rune) *Lexer {
@@ -29,6 +42,21 @@ func (lexer *Lexer) Init(path string, input []rune) {
 	}
 }
 
+// InitAt prepares the lexer to resume lexing input at pos.  The text before
+// pos is not looked at, so pos must be the start of a token from an earlier
+// run over a text that agrees with input up to that point.
+func (lexer *Lexer) InitAt(input []rune, pos Position) {
+	st := StateReady
+	if pos.RuneOffset == 0 {
+		st = StateReadyAtStartOfFile
+	}
+	*lexer = Lexer{
+		inp: input,
+		pos: pos,
+		st:  st,
+	}
+}
+
 func (lexer *Lexer) HasNext() bool {
 	if lexer.rdy {
 		return true
@@ -48,8 +76,11 @@ func (lexer *Lexer) HasNext() bool {
 		End:   lexer.pos,
 	}
 
-	for lexer.pos.RuneOffset < uint(len(lexer.inp)) {
-		ch := lexer.inp[lexer.pos.RuneOffset]
+	for {
+		ch, ok := lexer.runeAt(lexer.pos.RuneOffset)
+		if !ok {
+			break
+		}
 		consumeRune, isComplete := lexer.processRune(ch)
 		if consumeRune {
 			lexer.pos.Advance(ch)
@@ -69,7 +100,7 @@ func (lexer *Lexer) HasNext() bool {
 		return true
 	}
 
-	lexer.tok.Raw = lexer.inp[lexer.tok.Start.RuneOffset:lexer.pos.RuneOffset]
+	lexer.tok.Raw = lexer.slice(lexer.tok.Start.RuneOffset, lexer.pos.RuneOffset)
 	rawStr := string(lexer.tok.Raw)
 
 	if lexer.tok.Type == Partial {
@@ -153,6 +184,7 @@ func (lexer *Lexer) HasNext() bool {
 		util.EstimateLengths(est)
 	}
 	util.EstimateLengths(&lexer.tok)
+	lexer.diagnose()
 	lexer.rdy = true
 	return true
 }
@@ -184,7 +216,7 @@ func (lexer *Lexer) processRune(ch rune) (consumeRune bool, isComplete bool) {
 		}
 
 		if row.CurrentRaw != nil {
-			raw := lexer.inp[lexer.tok.Start.RuneOffset:lexer.pos.RuneOffset]
+			raw := lexer.slice(lexer.tok.Start.RuneOffset, lexer.pos.RuneOffset)
 			if !util.EqualRunes(raw, row.CurrentRaw) {
 				continue
 			}
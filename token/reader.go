@@ -0,0 +1,86 @@
+package token
+
+import (
+	"bufio"
+	"io"
+	"unicode/utf8"
+)
+
+// NewReaderLexer returns a lexer that decodes UTF-8 from r as it goes,
+// rather than needing the whole input up front.
+func NewReaderLexer(path string, r io.Reader) *Lexer {
+	lexer := new(Lexer)
+	lexer.InitReader(path, r)
+	return lexer
+}
+
+// InitReader is like Init, but decodes the input from r as it goes.  Invalid
+// UTF-8 is decoded as U+FFFD, one rune per bad byte.  Invalid UTF-8, byte
+// order marks after the start of the file, and read errors are reported by
+// Diagnostics.
+func (lexer *Lexer) InitReader(path string, r io.Reader) {
+	*lexer = Lexer{
+		inp: make([]rune, 0, 4096),
+		pos: Position{Path: path},
+		st:  StateReadyAtStartOfFile,
+		rd:  bufio.NewReader(r),
+		dec: Position{Path: path},
+		bad: make(map[uint]bool),
+	}
+}
+
+// runeAt returns the rune at offset, decoding more input if needed.
+func (lexer *Lexer) runeAt(offset uint) (rune, bool) {
+	for offset >= lexer.base+uint(len(lexer.inp)) {
+		if !lexer.fill() {
+			return 0, false
+		}
+	}
+	return lexer.inp[offset-lexer.base], true
+}
+
+// slice returns the runes from start up to end, which must already have
+// been decoded.
+func (lexer *Lexer) slice(start uint, end uint) []rune {
+	return lexer.inp[start-lexer.base : end-lexer.base : end-lexer.base]
+}
+
+// fill decodes one more rune from the reader.  When the buffer is full, the
+// runes before the current token are dropped; tokens already returned keep
+// their own references to them.
+func (lexer *Lexer) fill() bool {
+	if lexer.rd == nil {
+		return false
+	}
+
+	ch, size, err := lexer.rd.ReadRune()
+	if err != nil {
+		if err != io.EOF {
+			lexer.report(SeverityError, CodeReadError, err.Error(), lexer.dec, lexer.dec)
+		}
+		lexer.rd = nil
+		return false
+	}
+
+	end := lexer.dec
+	end.Advance(ch)
+	switch {
+	case ch == utf8.RuneError && size == 1:
+		lexer.report(SeverityError, CodeInvalidUTF8, "invalid UTF-8 encoding", lexer.dec, end)
+		lexer.bad[lexer.dec.RuneOffset] = true
+	case ch == 0xfeff && lexer.dec.RuneOffset != 0:
+		lexer.report(SeverityWarning, CodeMisplacedBOM, "byte order mark is only allowed at the start of the file", lexer.dec, end)
+	}
+
+	if len(lexer.inp) == cap(lexer.inp) {
+		live := lexer.inp[lexer.tok.Start.RuneOffset-lexer.base:]
+		inp := make([]rune, len(live), 2*len(live)+4096)
+		copy(inp, live)
+		lexer.inp = inp
+		lexer.base = lexer.tok.Start.RuneOffset
+	}
+
+	lexer.inp = append(lexer.inp, ch)
+	lexer.dec = end
+	return true
+}
@@ -0,0 +1,78 @@
+package token
+
+import (
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReaderLexer_MatchesLexer(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 500; i++ {
+		sb.WriteString("let x = \"café\" // ☃ comment\r\n")
+		sb.WriteString("/* a\nlonger\ncomment */ f(x, 1.5)\n")
+	}
+	input := sb.String()
+
+	expected := NewLexer("test.spider", []rune(input))
+	actual := NewReaderLexer("test.spider", iotest.OneByteReader(strings.NewReader(input)))
+
+	index := 0
+	for expected.HasNext() {
+		if !actual.HasNext() {
+			t.Fatalf("token %d: reader lexer ended early", index)
+		}
+		a, b := actual.Token(), expected.Token()
+		if a.Type != b.Type || string(a.Raw) != string(b.Raw) || a.Start != b.Start || a.End != b.End {
+			t.Fatalf("token %d: expected %v %v-%v, got %v %v-%v", index, b, b.Start, b.End, a, a.Start, a.End)
+		}
+		index++
+	}
+	if actual.HasNext() {
+		t.Errorf("token %d: reader lexer has extra tokens", index)
+	}
+	if diags := actual.Diagnostics(); diags != nil {
+		t.Errorf("unexpected diagnostics: %v", diags)
+	}
+}
+
+func TestReaderLexer_Diagnostics(t *testing.T) {
+	input := "\ufefflet x = 1\nlet \xffy = \x00 2 \ufeff\n\ufffd\n"
+	lexer := NewReaderLexer("test.spider", strings.NewReader(input))
+
+	var raw strings.Builder
+	for lexer.HasNext() {
+		raw.WriteString(string(lexer.Token().Raw))
+	}
+	if expected := strings.Replace(input, "\xff", "\ufffd", 1); raw.String() != expected {
+		t.Errorf("wrong runes: expected %q, got %q", expected, raw.String())
+	}
+
+	type testRow struct {
+		Severity Severity
+		Code     Code
+		Line     uint
+		Column   uint
+	}
+
+	expected := []testRow{
+		{SeverityError, CodeInvalidUTF8, 2, 5},
+		{SeverityError, CodeNULByte, 2, 10},
+		{SeverityWarning, CodeMisplacedBOM, 2, 14},
+		{SeverityError, CodeIllegalRune, 3, 1},
+	}
+
+	diags := lexer.Diagnostics()
+	if len(diags) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %v", len(expected), diags)
+	}
+	for index, row := range expected {
+		diag := diags[index]
+		if diag.Severity != row.Severity || diag.Code != row.Code {
+			t.Errorf("diagnostic %d: expected %v %v, got %v %v", index, row.Severity, row.Code, diag.Severity, diag.Code)
+		}
+		if diag.Start.LineNumber() != row.Line || diag.Start.ColumnNumber() != row.Column {
+			t.Errorf("diagnostic %d: expected L%d C%d, got %v", index, row.Line, row.Column, diag.Start)
+		}
+	}
+}
@@ -917,6 +917,7 @@ var earlyEOFTable = map[State]Type{
 	StateInPragma:            Pragma,
 	StateInIdentifier:        Identifier,
 	StateInNumber:            Number,
+	StateInRegexFlags:        Regex,
 }
 
 var keywordTable = map[string]Type{
@@ -9,6 +9,21 @@ type TokenPredicate interface {
 	MatchToken(token.Token) bool
 }
 
+// Expecter is implemented by predicates that can list the token types they
+// accept, so that a parser can report what it expected to find.
+type Expecter interface {
+	ExpectedTypes() []token.Type
+}
+
+// ExpectedTypes returns the token types that pred accepts, or nil if pred
+// does not implement Expecter.
+func ExpectedTypes(pred TokenPredicate) []token.Type {
+	if x, ok := pred.(Expecter); ok {
+		return x.ExpectedTypes()
+	}
+	return nil
+}
+
 // None
 // {{{
 
@@ -72,6 +87,15 @@ func (pred andPredicate) MatchToken(tok token.Token) bool {
 	return true
 }
 
+func (pred andPredicate) ExpectedTypes() []token.Type {
+	for _, child := range pred.Children {
+		if list := ExpectedTypes(child); list != nil {
+			return list
+		}
+	}
+	return nil
+}
+
 func And(children ...TokenPredicate) TokenPredicate {
 	return andPredicate{children}
 }
@@ -94,6 +118,14 @@ func (pred orPredicate) MatchToken(tok token.Token) bool {
 	return false
 }
 
+func (pred orPredicate) ExpectedTypes() []token.Type {
+	var list []token.Type
+	for _, child := range pred.Children {
+		list = append(list, ExpectedTypes(child)...)
+	}
+	return list
+}
+
 func Or(children ...TokenPredicate) TokenPredicate {
 	return orPredicate{children}
 }
@@ -111,6 +143,10 @@ func (pred typePredicate) MatchToken(tok token.Token) bool {
 	return tok.Type == pred.Type
 }
 
+func (pred typePredicate) ExpectedTypes() []token.Type {
+	return []token.Type{pred.Type}
+}
+
 func Type(tt token.Type) TokenPredicate {
 	return typePredicate{tt}
 }